@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/reshifr/secure-env/core/agent"
+	agimpl "github.com/reshifr/secure-env/core/agent/impl"
+	"github.com/reshifr/secure-env/core/failure"
+	"github.com/reshifr/secure-env/core/run"
+)
+
+const (
+	AgentSocketFile = "senv-agent.sock"
+	AgentDirPattern = "senv-agent-"
+)
+
+// socketPath falls back to a fresh 0700 directory, ssh-agent style, so
+// that no other user can bind the socket before the agent does. The
+// returned cleanup removes that directory.
+func (app *App) socketPath() (string, func(), error) {
+	if dir := app.lookup(run.RuntimeDirEnv, ""); dir != "" {
+		return filepath.Join(dir, AgentSocketFile), func() {}, nil
+	}
+	dir, err := os.MkdirTemp("", AgentDirPattern)
+	if err != nil {
+		return "", nil, err
+	}
+	return filepath.Join(dir, AgentSocketFile),
+		func() { os.RemoveAll(dir) }, nil
+}
+
+func (app *App) cmdAgent(args []string) error {
+	opts := options{}
+	flags := app.flags("agent", &opts)
+	path := flags.String("socket", "",
+		"socket path (default $XDG_RUNTIME_DIR/"+AgentSocketFile+
+			" or a private temporary directory)")
+	idleTTL := flags.Duration("idle-ttl", agimpl.ServerIdleTTL,
+		"drop a key unused for this long; 0 disables")
+	ttl := flags.Duration("ttl", agimpl.ServerTTL,
+		"drop a key this long after it was added; 0 disables")
+	if err := app.parse(flags, args, 0, 0); err != nil {
+		return err
+	}
+	if *path == "" {
+		socket, cleanup, err := app.socketPath()
+		if err != nil {
+			return failure.New(OpListen, ErrListenFailed, err)
+		}
+		defer cleanup()
+		*path = socket
+	}
+	listener, err := agimpl.Listen(*path)
+	if err != nil {
+		return failure.New(OpListen, ErrListenFailed, err)
+	}
+	defer listener.Close()
+	store := agimpl.NewKeystore(agimpl.FnKeystore{Now: app.fn.Now})
+	done := make(chan struct{})
+	defer close(done)
+	go store.Run(done)
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		<-signals
+		listener.Close()
+	}()
+	fmt.Fprintf(app.stdout, "%s=%s; export %s;\n",
+		agent.AuthSockEnv, *path, agent.AuthSockEnv)
+	return agimpl.NewServer(store, *idleTTL, *ttl).Serve(listener)
+}
+
+func (app *App) cmdLock(args []string) error {
+	opts := options{}
+	flags := app.flags("lock", &opts)
+	if err := app.parse(flags, args, 0, 0); err != nil {
+		return err
+	}
+	client, ok := app.agent()
+	if !ok {
+		return agent.ErrAgentUnavailable
+	}
+	return client.Lock()
+}
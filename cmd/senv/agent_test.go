@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/reshifr/secure-env/core/agent"
+	"github.com/reshifr/secure-env/core/failure"
+	"github.com/reshifr/secure-env/core/run"
+	"github.com/stretchr/testify/assert"
+)
+
+func (ta *testApp) startAgent(t *testing.T, args ...string) string {
+	r, w := io.Pipe()
+	t.Cleanup(func() { r.Close() })
+	path := ta.path(AgentSocketFile)
+	app := NewApp(FnApp{
+		LookupEnv: func(string) (string, bool) { return "", false },
+		Unsetenv:  func(string) error { return nil },
+		Now:       time.Now,
+	}, strings.NewReader(""), w, io.Discard)
+	go app.Run(append([]string{"agent", "--socket", path}, args...))
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	ta.vars[agent.AuthSockEnv] = path
+	return line
+}
+
+func Test_App_socketPath(t *testing.T) {
+	t.Parallel()
+	t.Run("Runtime dir", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.vars[run.RuntimeDirEnv] = ta.dir
+		expPath := ta.path(AgentSocketFile)
+
+		path, cleanup, err := ta.app.socketPath()
+		assert.Equal(t, expPath, path)
+		assert.ErrorIs(t, err, nil)
+		cleanup()
+		_, err = os.Stat(ta.dir)
+		assert.ErrorIs(t, err, nil)
+	})
+	t.Run("Private temporary dir", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+
+		path, cleanup, err := ta.app.socketPath()
+		assert.ErrorIs(t, err, nil)
+		dir := filepath.Dir(path)
+		assert.Equal(t, AgentSocketFile, filepath.Base(path))
+		assert.True(t, strings.HasPrefix(filepath.Base(dir), AgentDirPattern))
+		info, err := os.Stat(dir)
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, os.FileMode(0700), info.Mode().Perm())
+		cleanup()
+		_, err = os.Stat(dir)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func Test_App_cmdAgent(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrListenFailed error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+
+		code := ta.run("", "agent", "--socket", ta.path("missing/agent.sock"))
+		assert.Equal(t, failure.ExitIO, code)
+		assert.Contains(t, ta.stderr.String(), "ErrListenFailed")
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		path := ta.path(AgentSocketFile)
+		expLine := "SENV_AUTH_SOCK=" + path + "; export SENV_AUTH_SOCK;\n"
+
+		line := ta.startAgent(t)
+		assert.Equal(t, expLine, line)
+		ta.setup(t, []string{"init"}, []string{"set", "A=1"})
+		code := ta.run("", "export")
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		assert.Equal(t, "A=\"1\"\n", ta.stdout.String())
+	})
+	t.Run("Idle TTL", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.startAgent(t, "--idle-ttl", "1ns")
+		ta.setup(t, []string{"init"}, []string{"set", "A=1"})
+
+		code := ta.run("wrong-"+testPassphrase, "export")
+		assert.Equal(t, failure.ExitAuth, code)
+		assert.Contains(t, ta.stderr.String(), "ErrAuthFailed")
+	})
+}
+
+func Test_App_cmdLock(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrAgentUnavailable error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+
+		code := ta.run("", "lock")
+		assert.Equal(t, failure.ExitIO, code)
+		assert.Contains(t, ta.stderr.String(), "ErrAgentUnavailable")
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.startAgent(t)
+		ta.setup(t, []string{"init"}, []string{"set", "A=1"})
+
+		code := ta.run("", "lock")
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		code = ta.run("wrong-"+testPassphrase, "export")
+		assert.Equal(t, failure.ExitAuth, code)
+		assert.Contains(t, ta.stderr.String(), "ErrAuthFailed")
+	})
+}
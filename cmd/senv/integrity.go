@@ -0,0 +1,185 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	cimpl "github.com/reshifr/secure-env/core/crypto/impl"
+	"github.com/reshifr/secure-env/core/vault"
+	vimpl "github.com/reshifr/secure-env/core/vault/impl"
+)
+
+func (app *App) cmdAuditVerify(args []string) error {
+	opts := options{}
+	flags := app.flags("audit verify", &opts)
+	if err := app.parse(flags, args, 0, 0); err != nil {
+		return err
+	}
+	s, err := app.session(opts, false)
+	if err != nil {
+		return err
+	}
+	defer s.close()
+	s.keyring = vault.Keyring{}
+	for _, e := range s.v.Envs {
+		if _, err := e.Slot(opts.role); err != nil {
+			continue
+		}
+		keyring, err := s.open(e.Name)
+		if err != nil {
+			return err
+		}
+		for name, key := range keyring {
+			if _, ok := s.keyring[name]; ok {
+				key.Destroy()
+				continue
+			}
+			s.keyring[name] = key
+		}
+	}
+	heads, err := s.keeper.AuditHeads(s.v, s.keyring)
+	if err != nil {
+		return err
+	}
+	keys, err := s.keeper.AuditKeys(s.v, s.keyring)
+	if err != nil {
+		return err
+	}
+	defer keys.Destroy()
+	summary, err := s.log.Verify(keys, heads)
+	fmt.Fprintf(app.stdout, "Verified %d of %d records",
+		summary.Verified, summary.Records)
+	if summary.Skipped != 0 {
+		fmt.Fprintf(app.stdout,
+			", skipped %d from environments the role cannot open",
+			summary.Skipped)
+	}
+	fmt.Fprintln(app.stdout)
+	return err
+}
+
+func loadSigner(path string) (cimpl.Ed25519, error) {
+	seed, err := loadKey(path)
+	if err != nil {
+		return cimpl.Ed25519{}, err
+	}
+	signer, err := cimpl.LoadEd25519(seed)
+	if err != nil {
+		seed.Destroy()
+		return cimpl.Ed25519{}, err
+	}
+	return signer, nil
+}
+
+func (app *App) cmdKeygen(args []string) error {
+	opts := options{}
+	flags := app.flags("signer keygen", &opts)
+	if err := app.parse(flags, args, 1, 1); err != nil {
+		return err
+	}
+	rng := cimpl.NewStdRNG(cimpl.FnStdRNG{Read: rand.Read})
+	signer, err := cimpl.NewEd25519(rng)
+	if err != nil {
+		return err
+	}
+	defer signer.Seed().Destroy()
+	if err := writeKey(flags.Arg(0), signer.Seed().Bytes()); err != nil {
+		return err
+	}
+	fmt.Fprintln(app.stdout,
+		base64.StdEncoding.EncodeToString(signer.PublicKey()))
+	return nil
+}
+
+func (app *App) cmdSignerAdd(args []string) error {
+	opts := options{}
+	flags := app.flags("signer add", &opts)
+	if err := app.parse(flags, args, 2, 2); err != nil {
+		return err
+	}
+	publicKey, err := base64.StdEncoding.DecodeString(flags.Arg(1))
+	if err != nil || len(publicKey) != cimpl.Ed25519PublicKeyLen {
+		return crypto.ErrInvalidPublicKeyLen
+	}
+	v, err := loadVault(opts.vault)
+	if err != nil {
+		return err
+	}
+	if err := v.PinSigner(flags.Arg(0), publicKey); err != nil {
+		return err
+	}
+	return saveVault(opts.vault, v)
+}
+
+func (app *App) cmdSignerRemove(args []string) error {
+	opts := options{}
+	flags := app.flags("signer remove", &opts)
+	if err := app.parse(flags, args, 1, 1); err != nil {
+		return err
+	}
+	v, err := loadVault(opts.vault)
+	if err != nil {
+		return err
+	}
+	if err := v.UnpinSigner(flags.Arg(0)); err != nil {
+		return err
+	}
+	return saveVault(opts.vault, v)
+}
+
+func (app *App) cmdSign(args []string) error {
+	opts := options{}
+	flags := app.flags("sign", &opts)
+	key := flags.String("key", "", "signing key file from signer keygen")
+	if err := app.parse(flags, args, 1, 1); err != nil {
+		return err
+	}
+	if *key == "" {
+		flags.Usage()
+		return ErrUsage
+	}
+	v, err := loadVault(opts.vault)
+	if err != nil {
+		return err
+	}
+	signer, err := loadSigner(*key)
+	if err != nil {
+		return err
+	}
+	defer signer.Seed().Destroy()
+	notary := vimpl.NewNotary(cimpl.Ed25519Verifier{})
+	if err := notary.Sign(v, flags.Arg(0), signer); err != nil {
+		return err
+	}
+	return saveVault(opts.vault, v)
+}
+
+func (app *App) cmdVerify(args []string) error {
+	opts := options{}
+	flags := app.flags("verify", &opts)
+	if err := app.parse(flags, args, 0, 0); err != nil {
+		return err
+	}
+	if opts.trust == "" {
+		flags.Usage()
+		return ErrUsage
+	}
+	roots, err := loadTrust(opts.trust)
+	if err != nil {
+		return err
+	}
+	v, err := loadVault(opts.vault)
+	if err != nil {
+		return err
+	}
+	notary := vimpl.NewNotary(cimpl.Ed25519Verifier{})
+	if err := notary.Verify(v, roots); err != nil {
+		return err
+	}
+	last := v.Signatures[len(v.Signatures)-1]
+	fmt.Fprintf(app.stdout, "Verified %d signatures, last by %s\n",
+		len(v.Signatures), last.Signer)
+	return nil
+}
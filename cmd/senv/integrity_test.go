@@ -0,0 +1,364 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/reshifr/secure-env/core/audit"
+	"github.com/reshifr/secure-env/core/failure"
+	"github.com/reshifr/secure-env/core/vault"
+	"github.com/stretchr/testify/assert"
+)
+
+func (ta *testApp) editLog(t *testing.T, edit func(lines []string) []string) {
+	path := ta.path(DefaultVaultPath + audit.LogSuffix)
+	buf, err := os.ReadFile(path)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	lines := strings.SplitAfter(string(buf), "\n")
+	buf = []byte(strings.Join(edit(lines), ""))
+	if !assert.NoError(t, os.WriteFile(path, buf, 0600)) {
+		t.FailNow()
+	}
+}
+
+func (ta *testApp) editVault(t *testing.T, edit func(v *vault.Vault)) {
+	path := ta.vars[VaultPathEnv]
+	v, err := loadVault(path)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	edit(v)
+	if !assert.NoError(t, saveVault(path, v)) {
+		t.FailNow()
+	}
+}
+
+func Test_App_cmdAuditVerify(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrOpenLogFailed error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+		os.Remove(ta.path(DefaultVaultPath + audit.LogSuffix))
+
+		code := ta.run(testPassphrase, "audit", "verify")
+		assert.Equal(t, failure.ExitIO, code)
+		assert.Contains(t, ta.stderr.String(), "ErrOpenLogFailed")
+	})
+	t.Run("ErrChainBroken error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"},
+			[]string{"set", "A=1"}, []string{"set", "B=2"})
+		ta.editLog(t, func(lines []string) []string {
+			return append(lines[:len(lines)-3], lines[len(lines)-2:]...)
+		})
+
+		code := ta.run(testPassphrase, "audit", "verify")
+		assert.Equal(t, failure.ExitIntegrity, code)
+		assert.Contains(t, ta.stderr.String(), "ErrChainBroken")
+	})
+	t.Run("ErrMACMismatch error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"}, []string{"set", "A=1"})
+		ta.editLog(t, func(lines []string) []string {
+			last := len(lines) - 2
+			lines[last] = strings.Replace(lines[last], `"A"`, `"B"`, 1)
+			return lines
+		})
+
+		code := ta.run(testPassphrase, "audit", "verify")
+		assert.Equal(t, failure.ExitIntegrity, code)
+		assert.Contains(t, ta.stderr.String(), "ErrMACMismatch")
+	})
+	t.Run("ErrLogTruncated error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"},
+			[]string{"set", "A=1"}, []string{"set", "B=2"})
+		ta.editLog(t, func(lines []string) []string {
+			return append(lines[:len(lines)-2], "")
+		})
+
+		code := ta.run(testPassphrase, "audit", "verify")
+		assert.Equal(t, failure.ExitIntegrity, code)
+		assert.Contains(t, ta.stderr.String(), "ErrLogTruncated")
+	})
+	t.Run("Denied record", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"}, []string{"set", "A=1"})
+		code := ta.run("wrong-"+testPassphrase, "export")
+		assert.Equal(t, failure.ExitAuth, code)
+
+		code = ta.run(testPassphrase, "audit", "verify")
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		assert.Equal(t, "Verified 2 of 3 records\n", ta.stdout.String())
+	})
+	t.Run("Skipped records", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"},
+			[]string{"init", "--env", "dev", "--parent", DefaultEnv},
+			[]string{"init", "--env", "prod", "--parent", DefaultEnv},
+			[]string{"set", "--env", "prod", "A=1"})
+		fd := ta.passphraseFD(t, testPassphrase, testNewPassphrase)
+		code := ta.run("", "role", "add",
+			"--env", "dev", "--passphrase-fd", fd, "devteam")
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+
+		code = ta.run(testNewPassphrase,
+			"audit", "verify", "--env", "dev", "--role", "devteam")
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		assert.Equal(t, "Verified 4 of 6 records, "+
+			"skipped 2 from environments the role cannot open\n",
+			ta.stdout.String())
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"},
+			[]string{"set", "A=1"}, []string{"set", "B=2"},
+			[]string{"export"})
+
+		code := ta.run(testPassphrase, "audit", "verify")
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		assert.Equal(t, "Verified 4 of 4 records\n", ta.stdout.String())
+		buf, _ := os.ReadFile(ta.path(DefaultVaultPath + audit.LogSuffix))
+		assert.Equal(t, 4, strings.Count(string(buf), `"Role":"admin"`))
+	})
+}
+
+func (ta *testApp) keygen(t *testing.T, name string) string {
+	ta.setup(t, []string{"signer", "keygen", ta.path(name + ".key")})
+	return strings.TrimSpace(ta.stdout.String())
+}
+
+func (ta *testApp) trust(t *testing.T, name string, publicKey string) string {
+	raw, _ := base64.StdEncoding.DecodeString(publicKey)
+	buf, _ := json.Marshal([]vault.Signer{{Name: name, PublicKey: raw}})
+	path := ta.path(name + ".trust")
+	if !assert.NoError(t, os.WriteFile(path, buf, 0600)) {
+		t.FailNow()
+	}
+	return path
+}
+
+func Test_App_cmdKeygen(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrWriteFileFailed error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+
+		code := ta.run("", "signer", "keygen", ta.path("missing/alice.key"))
+		assert.Equal(t, failure.ExitIO, code)
+		assert.Contains(t, ta.stderr.String(), "ErrWriteFileFailed")
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+
+		code := ta.run("", "signer", "keygen", ta.path("alice.key"))
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		publicKey, err := base64.StdEncoding.DecodeString(
+			strings.TrimSpace(ta.stdout.String()))
+		assert.NoError(t, err)
+		assert.Len(t, publicKey, 32)
+		info, err := os.Stat(ta.path("alice.key"))
+		assert.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	})
+}
+
+func Test_App_cmdSignerAdd(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrInvalidPublicKeyLen error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+
+		code := ta.run("", "signer", "add", "alice", "AQID")
+		assert.Equal(t, failure.ExitInvalid, code)
+		assert.Contains(t, ta.stderr.String(), "ErrInvalidPublicKeyLen")
+	})
+	t.Run("ErrSignerExists error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		publicKey := ta.keygen(t, "alice")
+		ta.setup(t, []string{"init"},
+			[]string{"signer", "add", "alice", publicKey})
+
+		code := ta.run("", "signer", "add", "alice", publicKey)
+		assert.Equal(t, failure.ExitExists, code)
+		assert.Contains(t, ta.stderr.String(), "ErrSignerExists")
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		publicKey := ta.keygen(t, "alice")
+		ta.setup(t, []string{"init"})
+
+		code := ta.run("", "signer", "add", "alice", publicKey)
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		v, err := loadVault(ta.path(DefaultVaultPath))
+		assert.NoError(t, err)
+		assert.Len(t, v.Signers, 1)
+	})
+}
+
+func Test_App_cmdSignerRemove(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrSignerNotPinned error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+
+		code := ta.run("", "signer", "remove", "alice")
+		assert.Equal(t, failure.ExitNotFound, code)
+		assert.Contains(t, ta.stderr.String(), "ErrSignerNotPinned")
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		publicKey := ta.keygen(t, "alice")
+		ta.setup(t, []string{"init"},
+			[]string{"signer", "add", "alice", publicKey})
+
+		code := ta.run("", "signer", "remove", "alice")
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		v, err := loadVault(ta.path(DefaultVaultPath))
+		assert.NoError(t, err)
+		assert.Empty(t, v.Signers)
+	})
+}
+
+func Test_App_cmdSign(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrUsage error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+
+		code := ta.run("", "sign", "alice")
+		assert.Equal(t, failure.ExitUsage, code)
+		assert.Contains(t, ta.stderr.String(), "ErrUsage")
+	})
+	t.Run("ErrSignerNotPinned error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.keygen(t, "alice")
+		ta.setup(t, []string{"init"})
+
+		code := ta.run("", "sign", "--key", ta.path("alice.key"), "alice")
+		assert.Equal(t, failure.ExitNotFound, code)
+		assert.Contains(t, ta.stderr.String(), "ErrSignerNotPinned")
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		publicKey := ta.keygen(t, "alice")
+		ta.setup(t, []string{"init"},
+			[]string{"signer", "add", "alice", publicKey})
+
+		code := ta.run("", "sign", "--key", ta.path("alice.key"), "alice")
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		v, err := loadVault(ta.path(DefaultVaultPath))
+		assert.NoError(t, err)
+		assert.Len(t, v.Signatures, 1)
+	})
+}
+
+func Test_App_cmdVerify(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrUsage error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+
+		code := ta.run("", "verify")
+		assert.Equal(t, failure.ExitUsage, code)
+		assert.Contains(t, ta.stderr.String(), "ErrUsage")
+	})
+	t.Run("ErrUnsigned error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		publicKey := ta.keygen(t, "alice")
+		trust := ta.trust(t, "alice", publicKey)
+		ta.setup(t, []string{"init"})
+
+		code := ta.run("", "verify", "--trust", trust)
+		assert.Equal(t, failure.ExitIntegrity, code)
+		assert.Contains(t, ta.stderr.String(), "ErrUnsigned")
+	})
+	t.Run("ErrSignatureMismatch error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		publicKey := ta.keygen(t, "alice")
+		trust := ta.trust(t, "alice", publicKey)
+		ta.setup(t, []string{"init"},
+			[]string{"signer", "add", "alice", publicKey},
+			[]string{"sign", "--key", ta.path("alice.key"), "alice"},
+			[]string{"set", "A=1"})
+
+		code := ta.run("", "verify", "--trust", trust)
+		assert.Equal(t, failure.ExitIntegrity, code)
+		assert.Contains(t, ta.stderr.String(), "ErrSignatureMismatch")
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		publicKey := ta.keygen(t, "alice")
+		trust := ta.trust(t, "alice", publicKey)
+		ta.setup(t, []string{"init"},
+			[]string{"signer", "add", "alice", publicKey},
+			[]string{"sign", "--key", ta.path("alice.key"), "alice"})
+
+		code := ta.run("", "verify", "--trust", trust)
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		assert.Equal(t, "Verified 1 signatures, last by alice\n",
+			ta.stdout.String())
+	})
+}
+
+func Test_App_trust(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrUnsigned error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		publicKey := ta.keygen(t, "alice")
+		trust := ta.trust(t, "alice", publicKey)
+		ta.setup(t, []string{"init"}, []string{"set", "A=1"})
+
+		code := ta.run(testPassphrase, "export", "--trust", trust)
+		assert.Equal(t, failure.ExitIntegrity, code)
+		assert.Contains(t, ta.stderr.String(), "ErrUnsigned")
+	})
+	t.Run("ErrInvalidFile error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+		os.WriteFile(ta.path("bad.trust"), []byte("{"), 0600)
+
+		code := ta.run(testPassphrase, "export", "--trust",
+			ta.path("bad.trust"))
+		assert.Equal(t, failure.ExitInvalid, code)
+		assert.Contains(t, ta.stderr.String(), "ErrInvalidFile")
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		publicKey := ta.keygen(t, "alice")
+		trust := ta.trust(t, "alice", publicKey)
+		ta.setup(t, []string{"init"}, []string{"set", "A=1"},
+			[]string{"signer", "add", "alice", publicKey},
+			[]string{"sign", "--key", ta.path("alice.key"), "alice"})
+
+		code := ta.run(testPassphrase, "export", "--trust", trust)
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		assert.Equal(t, "A=\"1\"\n", ta.stdout.String())
+	})
+}
@@ -1,3 +1,200 @@
 package main
 
-func main() {}
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/reshifr/secure-env/core/failure"
+)
+
+const (
+	VaultPathEnv     = "SENV_VAULT"
+	DefaultVaultPath = "secrets.senv"
+	DefaultEnv       = "default"
+	DefaultRole      = "admin"
+)
+
+const (
+	OpReadVault = "read-vault"
+	OpReadFile  = "read-file"
+	OpWriteFile = "write-file"
+	OpListen    = "listen"
+)
+
+type CLIError int
+
+const (
+	ErrUsage CLIError = iota + 1
+	ErrVaultNotFound
+	ErrInvalidVault
+	ErrReadFileFailed
+	ErrWriteFileFailed
+	ErrInvalidFile
+	ErrListenFailed
+)
+
+func (err CLIError) Error() string {
+	switch err {
+	case ErrUsage:
+		return "ErrUsage: invalid command line."
+	case ErrVaultNotFound:
+		return "ErrVaultNotFound: the vault file does not exist."
+	case ErrInvalidVault:
+		return "ErrInvalidVault: the vault file is malformed."
+	case ErrReadFileFailed:
+		return "ErrReadFileFailed: failed to read the file."
+	case ErrWriteFileFailed:
+		return "ErrWriteFileFailed: failed to write the file."
+	case ErrInvalidFile:
+		return "ErrInvalidFile: the file is malformed."
+	case ErrListenFailed:
+		return "ErrListenFailed: failed to listen on the agent socket."
+	default:
+		return "Error: unknown."
+	}
+}
+
+func (err CLIError) Kind() failure.Kind {
+	switch err {
+	case ErrUsage, ErrInvalidVault, ErrInvalidFile:
+		return failure.KindInvalid
+	case ErrVaultNotFound:
+		return failure.KindNotFound
+	case ErrReadFileFailed, ErrWriteFileFailed, ErrListenFailed:
+		return failure.KindIO
+	default:
+		return failure.KindInternal
+	}
+}
+
+type FnApp struct {
+	LookupEnv func(key string) (value string, ok bool)
+	Unsetenv  func(key string) error
+	Environ   func() []string
+	Now       func() time.Time
+}
+
+type App struct {
+	fn     FnApp
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	json   bool
+	exit   int
+}
+
+type command struct {
+	name    string
+	summary string
+	run     func(app *App, args []string) error
+}
+
+var commands = []command{
+	{"init", "create a vault environment", (*App).cmdInit},
+	{"set", "set variables or file secrets", (*App).cmdSet},
+	{"import", "import variables from a file", (*App).cmdImport},
+	{"export", "export decrypted variables", (*App).cmdExport},
+	{"list", "list variable names", (*App).cmdList},
+	{"run", "run a command with the variables", (*App).cmdRun},
+	{"check", "check variables against the schema", (*App).cmdCheck},
+	{"schema", "replace the vault schema", (*App).cmdSchema},
+	{"policy", "replace the passphrase policy", (*App).cmdPolicy},
+	{"passwd", "change a role's passphrase", (*App).cmdPasswd},
+	{"role add", "add a role", (*App).cmdRoleAdd},
+	{"role remove", "remove a role", (*App).cmdRoleRemove},
+	{"role list", "list roles", (*App).cmdRoleList},
+	{"identity keygen", "generate an X25519+ML-KEM identity",
+		(*App).cmdIdentityKeygen},
+	{"audit verify", "verify the audit log", (*App).cmdAuditVerify},
+	{"signer keygen", "generate an Ed25519 signing key", (*App).cmdKeygen},
+	{"signer add", "pin a signer in the vault", (*App).cmdSignerAdd},
+	{"signer remove", "unpin a signer", (*App).cmdSignerRemove},
+	{"sign", "sign the vault", (*App).cmdSign},
+	{"verify", "verify the vault signatures", (*App).cmdVerify},
+	{"agent", "run the key-caching agent", (*App).cmdAgent},
+	{"lock", "drop every key held by the agent", (*App).cmdLock},
+}
+
+func NewApp(fn FnApp,
+	stdin io.Reader, stdout io.Writer, stderr io.Writer) *App {
+	return &App{fn: fn, stdin: stdin, stdout: stdout, stderr: stderr}
+}
+
+func (app *App) lookup(key string, fallback string) string {
+	if value, ok := app.fn.LookupEnv(key); ok && value != "" {
+		return value
+	}
+	return fallback
+}
+
+func findCommand(args []string) (command, []string, bool) {
+	for _, cmd := range commands {
+		words := strings.Fields(cmd.name)
+		if len(args) < len(words) {
+			continue
+		}
+		if strings.Join(args[:len(words)], " ") == cmd.name {
+			return cmd, args[len(words):], true
+		}
+	}
+	return command{}, nil, false
+}
+
+func (app *App) usage() {
+	fmt.Fprintln(app.stderr, "Usage: senv <command> [flags] [args]")
+	fmt.Fprintln(app.stderr)
+	fmt.Fprintln(app.stderr, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(app.stderr, "  %-15s %s\n", cmd.name, cmd.summary)
+	}
+}
+
+func (app *App) report(err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		return failure.ExitOK
+	}
+	if errors.Is(err, ErrUsage) {
+		fmt.Fprintln(app.stderr, "senv: "+err.Error())
+		return failure.ExitUsage
+	}
+	if app.json {
+		fmt.Fprintln(app.stderr, string(failure.JSON(err)))
+	} else {
+		fmt.Fprintln(app.stderr, "senv: "+err.Error())
+	}
+	if app.exit != 0 {
+		return app.exit
+	}
+	return failure.ExitCode(err)
+}
+
+func (app *App) Run(args []string) int {
+	cmd, rest, ok := findCommand(args)
+	if !ok {
+		app.usage()
+		if len(args) == 0 || args[0] == "help" ||
+			args[0] == "-h" || args[0] == "--help" {
+			return failure.ExitOK
+		}
+		return failure.ExitUsage
+	}
+	if err := cmd.run(app, rest); err != nil {
+		return app.report(err)
+	}
+	return app.exit
+}
+
+func main() {
+	app := NewApp(FnApp{
+		LookupEnv: os.LookupEnv,
+		Unsetenv:  os.Unsetenv,
+		Environ:   os.Environ,
+		Now:       time.Now,
+	}, os.Stdin, os.Stdout, os.Stderr)
+	os.Exit(app.Run(os.Args[1:]))
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/reshifr/secure-env/core/failure"
+	"github.com/reshifr/secure-env/core/passphrase"
+	"github.com/reshifr/secure-env/core/vault"
+	"github.com/stretchr/testify/assert"
+)
+
+const testPassphrase = "+DF7Rc-X/MOYjkNj"
+
+type testApp struct {
+	app    *App
+	dir    string
+	vars   map[string]string
+	stdout *bytes.Buffer
+	stderr *bytes.Buffer
+}
+
+func newTestApp(t *testing.T) *testApp {
+	dir := t.TempDir()
+	vars := map[string]string{
+		VaultPathEnv:            filepath.Join(dir, DefaultVaultPath),
+		passphrase.OrderEnv:     passphrase.ProviderEnv,
+		vault.WatermarksPathEnv: filepath.Join(dir, "watermarks.json"),
+	}
+	ta := &testApp{
+		dir:    dir,
+		vars:   vars,
+		stdout: &bytes.Buffer{},
+		stderr: &bytes.Buffer{},
+	}
+	ta.app = NewApp(FnApp{
+		LookupEnv: func(key string) (string, bool) {
+			value, ok := vars[key]
+			return value, ok
+		},
+		Unsetenv: func(key string) error {
+			delete(vars, key)
+			return nil
+		},
+		Environ: func() []string {
+			environ := []string{}
+			for key, value := range vars {
+				environ = append(environ, key+"="+value)
+			}
+			return environ
+		},
+		Now: time.Now,
+	}, strings.NewReader(""), ta.stdout, ta.stderr)
+	return ta
+}
+
+func (ta *testApp) path(name string) string {
+	return filepath.Join(ta.dir, name)
+}
+
+func (ta *testApp) run(secret string, args ...string) int {
+	ta.stdout.Reset()
+	ta.stderr.Reset()
+	delete(ta.vars, passphrase.PassphraseEnv)
+	if secret != "" {
+		ta.vars[passphrase.PassphraseEnv] = secret
+	}
+	return ta.app.Run(args)
+}
+
+func (ta *testApp) runInput(input string, secret string, args ...string) int {
+	ta.app.stdin = strings.NewReader(input)
+	return ta.run(secret, args...)
+}
+
+func (ta *testApp) passphraseFD(t *testing.T, secrets ...string) string {
+	r, w, err := os.Pipe()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() { r.Close() })
+	for _, secret := range secrets {
+		w.WriteString(secret + "\n")
+	}
+	w.Close()
+	ta.vars[passphrase.OrderEnv] = "fd,env"
+	return strconv.Itoa(int(r.Fd()))
+}
+
+func (ta *testApp) setup(t *testing.T, cmds ...[]string) {
+	for _, args := range cmds {
+		ta.setupInput(t, "", args...)
+	}
+}
+
+func (ta *testApp) setupInput(t *testing.T, input string, args ...string) {
+	code := ta.runInput(input, testPassphrase, args...)
+	if !assert.Equal(t, failure.ExitOK, code, ta.stderr.String()) {
+		t.FailNow()
+	}
+}
+
+func Test_CLIError_Error(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrUsage value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrUsage
+		const expMsg = "ErrUsage: invalid command line."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrVaultNotFound value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrVaultNotFound
+		const expMsg = "ErrVaultNotFound: the vault file does not exist."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrInvalidVault value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrInvalidVault
+		const expMsg = "ErrInvalidVault: the vault file is malformed."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrReadFileFailed value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrReadFileFailed
+		const expMsg = "ErrReadFileFailed: failed to read the file."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrWriteFileFailed value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrWriteFileFailed
+		const expMsg = "ErrWriteFileFailed: failed to write the file."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrInvalidFile value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrInvalidFile
+		const expMsg = "ErrInvalidFile: the file is malformed."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrListenFailed value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrListenFailed
+		const expMsg = "ErrListenFailed: " +
+			"failed to listen on the agent socket."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("Unknown value", func(t *testing.T) {
+		t.Parallel()
+		const err = CLIError(613724)
+		const expMsg = "Error: unknown."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+}
+
+func Test_CLIError_Kind(t *testing.T) {
+	t.Parallel()
+	t.Run("KindInvalid value", func(t *testing.T) {
+		t.Parallel()
+		errs := []CLIError{ErrUsage, ErrInvalidVault, ErrInvalidFile}
+		const expKind = failure.KindInvalid
+
+		for _, err := range errs {
+			kind := err.Kind()
+			assert.Equal(t, expKind, kind, err.Error())
+		}
+	})
+	t.Run("KindNotFound value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrVaultNotFound
+		const expKind = failure.KindNotFound
+
+		kind := err.Kind()
+		assert.Equal(t, expKind, kind)
+	})
+	t.Run("KindIO value", func(t *testing.T) {
+		t.Parallel()
+		errs := []CLIError{
+			ErrReadFileFailed,
+			ErrWriteFileFailed,
+			ErrListenFailed,
+		}
+		const expKind = failure.KindIO
+
+		for _, err := range errs {
+			kind := err.Kind()
+			assert.Equal(t, expKind, kind, err.Error())
+		}
+	})
+	t.Run("KindInternal value", func(t *testing.T) {
+		t.Parallel()
+		const err = CLIError(613724)
+		const expKind = failure.KindInternal
+
+		kind := err.Kind()
+		assert.Equal(t, expKind, kind)
+	})
+}
+
+func Test_findCommand(t *testing.T) {
+	t.Parallel()
+	t.Run("Known command", func(t *testing.T) {
+		t.Parallel()
+		args := []string{"export", "--format", "json"}
+		expRest := []string{"--format", "json"}
+
+		cmd, rest, ok := findCommand(args)
+		assert.True(t, ok)
+		assert.Equal(t, "export", cmd.name)
+		assert.Equal(t, expRest, rest)
+	})
+	t.Run("Unknown command", func(t *testing.T) {
+		t.Parallel()
+		args := []string{"frobnicate"}
+
+		_, _, ok := findCommand(args)
+		assert.False(t, ok)
+	})
+}
+
+func Test_App_Run(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		args    []string
+		expCode int
+		expErr  string
+	}{
+		{"No command", nil, failure.ExitOK, "Usage: senv"},
+		{"Help", []string{"help"}, failure.ExitOK, "Usage: senv"},
+		{"Unknown command", []string{"frobnicate"},
+			failure.ExitUsage, "Usage: senv"},
+		{"Unknown flag", []string{"list", "--frobnicate"},
+			failure.ExitUsage, "ErrUsage"},
+		{"Flag help", []string{"list", "-h"},
+			failure.ExitOK, "Usage of senv list"},
+		{"Missing argument", []string{"set"},
+			failure.ExitUsage, "ErrUsage"},
+		{"Extra argument", []string{"export", "NAME"},
+			failure.ExitUsage, "ErrUsage"},
+		{"Run without command", []string{"run"},
+			failure.ExitUsage, "ErrUsage"},
+		{"SSH agent without key", []string{"role", "add", "--ssh-agent", "bob"},
+			failure.ExitUsage, "ErrUsage"},
+		{"ErrVaultNotFound error", []string{"list"},
+			failure.ExitNotFound, "ErrVaultNotFound"},
+		{"JSON error", []string{"list", "--json"}, failure.ExitNotFound,
+			`{"Error":{"Code":"ErrVaultNotFound"`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			ta := newTestApp(t)
+
+			code := ta.run("", test.args...)
+			assert.Equal(t, test.expCode, code)
+			assert.Contains(t, ta.stderr.String(), test.expErr)
+		})
+	}
+}
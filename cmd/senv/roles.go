@@ -0,0 +1,244 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	cimpl "github.com/reshifr/secure-env/core/crypto/impl"
+	"github.com/reshifr/secure-env/core/failure"
+)
+
+func (app *App) cmdPasswd(args []string) error {
+	opts := options{}
+	flags := app.flags("passwd", &opts)
+	if err := app.parse(flags, args, 0, 0); err != nil {
+		return err
+	}
+	s, err := app.session(opts, false)
+	if err != nil {
+		return err
+	}
+	defer s.close()
+	if _, err := s.rolePassphrase(); err != nil {
+		return err
+	}
+	if err := s.unlock(); err != nil {
+		return err
+	}
+	newPassphrase, err := s.newPassphrase(opts.role)
+	if err != nil {
+		return err
+	}
+	defer newPassphrase.Destroy()
+	err = s.keeper.Passwd(s.iv, s.v, opts.role, s.passphrase, newPassphrase)
+	if err != nil {
+		return err
+	}
+	return s.commit()
+}
+
+func (app *App) cmdRoleAdd(args []string) error {
+	opts := options{}
+	flags := app.flags("role add", &opts)
+	recipient := flags.String("recipient", "",
+		"wrap the role to this public key file instead of a passphrase")
+	sshKey := flags.String("ssh-key", "",
+		"wrap the role to this authorized_keys line instead of a passphrase")
+	sshAgent := flags.Bool("ssh-agent", false,
+		"unlock the role by signing with the --ssh-key key in ssh-agent")
+	if err := app.parse(flags, args, 1, 1); err != nil {
+		return err
+	}
+	if (*recipient != "" && *sshKey != "") || (*sshAgent && *sshKey == "") {
+		flags.Usage()
+		return ErrUsage
+	}
+	s, err := app.session(opts, false)
+	if err != nil {
+		return err
+	}
+	defer s.close()
+	if _, err := s.rolePassphrase(); err != nil {
+		return err
+	}
+	if err := s.unlock(); err != nil {
+		return err
+	}
+	name := flags.Arg(0)
+	switch {
+	case *recipient != "":
+		err = s.addRecipientRole(name, *recipient)
+	case *sshKey != "":
+		err = s.addSSHRole(name, *sshKey, *sshAgent)
+	default:
+		err = s.addPassphraseRole(name)
+	}
+	if err != nil {
+		return err
+	}
+	return s.commit()
+}
+
+func (s *session) addPassphraseRole(name string) error {
+	newPassphrase, err := s.newPassphrase(name)
+	if err != nil {
+		return err
+	}
+	defer newPassphrase.Destroy()
+	return s.keeper.AddRole(s.iv, s.v,
+		s.opts.env, s.opts.role, s.passphrase, name, newPassphrase)
+}
+
+func (s *session) addRecipientRole(name string, path string) error {
+	publicKey, err := readKey(path)
+	if err != nil {
+		return err
+	}
+	recipient, _ := s.recipient(cimpl.HybridRecipientKind)
+	return s.keeper.AddRecipient(s.iv, s.v, s.opts.env,
+		s.opts.role, s.passphrase, name, recipient, publicKey)
+}
+
+func (s *session) addSSHRole(name string, path string, sshAgent bool) error {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return failure.New(OpReadFile, ErrReadFileFailed, err)
+	}
+	publicKey := bytes.TrimSpace(buf)
+	var recipient crypto.Recipient
+	if sshAgent {
+		var done func()
+		recipient, done, err = s.sshAgentRecipient()
+		if err != nil {
+			return err
+		}
+		defer done()
+	} else {
+		kind, err := cimpl.SSHRecipientKind(publicKey)
+		if err != nil {
+			return err
+		}
+		recipient, _ = s.recipient(kind)
+	}
+	return s.keeper.AddRecipient(s.iv, s.v, s.opts.env,
+		s.opts.role, s.passphrase, name, recipient, publicKey)
+}
+
+func (app *App) cmdIdentityKeygen(args []string) error {
+	opts := options{}
+	flags := app.flags("identity keygen", &opts)
+	if err := app.parse(flags, args, 1, 1); err != nil {
+		return err
+	}
+	rng := cimpl.NewStdRNG(cimpl.FnStdRNG{Read: rand.Read})
+	identity, err := cimpl.NewHybridIdentity(rng)
+	if err != nil {
+		return err
+	}
+	defer identity.Destroy()
+	publicKey, err := cimpl.HybridPublicKey(identity)
+	if err != nil {
+		return err
+	}
+	if err := writeKey(flags.Arg(0), identity.Bytes()); err != nil {
+		return err
+	}
+	fmt.Fprintln(app.stdout, base64.StdEncoding.EncodeToString(publicKey))
+	return nil
+}
+
+func (app *App) cmdRoleRemove(args []string) error {
+	opts := options{}
+	flags := app.flags("role remove", &opts)
+	rotate := flags.Bool("rotate", false,
+		"re-key every environment the role could open")
+	if err := app.parse(flags, args, 1, 1); err != nil {
+		return err
+	}
+	s, err := app.session(opts, false)
+	if err != nil {
+		return err
+	}
+	defer s.close()
+	if _, err := s.rolePassphrase(); err != nil {
+		return err
+	}
+	if err := s.unlock(); err != nil {
+		return err
+	}
+	recipients := s.recipients()
+	if *rotate {
+		if recipient, done, err := s.sshAgentRecipient(); err == nil {
+			defer done()
+			recipients = append(recipients, recipient)
+		}
+	}
+	report, err := s.keeper.RemoveRole(s.iv, s.v, opts.role,
+		s.passphrase, flags.Arg(0), *rotate, recipients...)
+	if err != nil {
+		return err
+	}
+	if *rotate {
+		if err := s.reopen(); err != nil {
+			return err
+		}
+	}
+	if err := s.commit(); err != nil {
+		return err
+	}
+	if report.Warning != "" {
+		fmt.Fprintln(app.stderr, "senv: warning: "+report.Warning)
+	}
+	fmt.Fprintf(app.stdout,
+		"Removed from: %s\n", strings.Join(report.Envs, ", "))
+	if len(report.Dropped) != 0 {
+		fmt.Fprintf(app.stdout,
+			"Dropped, add again: %s\n", strings.Join(report.Dropped, ", "))
+	}
+	return nil
+}
+
+func (app *App) cmdRoleList(args []string) error {
+	opts := options{}
+	flags := app.flags("role list", &opts)
+	if err := app.parse(flags, args, 0, 0); err != nil {
+		return err
+	}
+	v, err := loadVault(opts.vault)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(app.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tKIND\tKDF\tCREATED\tENVS")
+	for _, role := range v.Roles() {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			role.Name,
+			roleKind(role.Kind),
+			kdfParams(role.KDF),
+			role.Created.Format(time.RFC3339),
+			strings.Join(role.Envs, ","))
+	}
+	return w.Flush()
+}
+
+func roleKind(kind string) string {
+	if kind == "" {
+		return cimpl.RoleAuthorizerKind
+	}
+	return kind
+}
+
+func kdfParams(params crypto.KDFParams) string {
+	if params.Algorithm == "" {
+		return "-"
+	}
+	return fmt.Sprintf("%s t=%d m=%dKiB p=%d",
+		params.Algorithm, params.Time, params.Memory, params.Threads)
+}
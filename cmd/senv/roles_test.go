@@ -0,0 +1,467 @@
+package main
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/pem"
+	"net"
+	"os"
+	"strings"
+	"testing"
+
+	cimpl "github.com/reshifr/secure-env/core/crypto/impl"
+	"github.com/reshifr/secure-env/core/failure"
+	"github.com/reshifr/secure-env/core/passphrase"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+	sshagent "golang.org/x/crypto/ssh/agent"
+)
+
+const testNewPassphrase = "Wq7#vL2p!zR9-mKe"
+
+func Test_App_cmdPasswd(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrAuthFailed error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+		fd := ta.passphraseFD(t, "wrong-"+testPassphrase, testNewPassphrase)
+
+		code := ta.run("", "passwd", "--passphrase-fd", fd)
+		assert.Equal(t, failure.ExitAuth, code)
+		assert.Contains(t, ta.stderr.String(), "ErrAuthFailed")
+	})
+	t.Run("ErrNewPassphraseUnavailable error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+		ta.vars[passphrase.OrderEnv] = "env,tty"
+
+		code := ta.run(testPassphrase, "passwd")
+		assert.Equal(t, failure.ExitIO, code)
+		assert.Contains(t, ta.stderr.String(), "ErrNewPassphraseUnavailable")
+		assert.Contains(t, ta.stderr.String(), passphrase.NewPassphraseEnv)
+	})
+	t.Run("ErrPassphraseReused error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+		fd := ta.passphraseFD(t, testPassphrase, testPassphrase)
+
+		code := ta.run("", "passwd", "--passphrase-fd", fd)
+		assert.Equal(t, failure.ExitPolicy, code)
+		assert.Contains(t, ta.stderr.String(), "ErrPassphraseReused")
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"}, []string{"set", "A=1"})
+		fd := ta.passphraseFD(t, testPassphrase, testNewPassphrase)
+
+		code := ta.run("", "passwd", "--passphrase-fd", fd)
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		code = ta.run(testPassphrase, "export")
+		assert.Equal(t, failure.ExitAuth, code)
+		assert.Contains(t, ta.stderr.String(), "ErrAuthFailed")
+		code = ta.run(testNewPassphrase, "export")
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		assert.Equal(t, "A=\"1\"\n", ta.stdout.String())
+	})
+	t.Run("Succeed new passphrase variable", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"}, []string{"set", "A=1"})
+		ta.vars[passphrase.NewPassphraseEnv] = testNewPassphrase
+
+		code := ta.run(testPassphrase, "passwd")
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		assert.NotContains(t, ta.vars, passphrase.NewPassphraseEnv)
+		code = ta.run(testNewPassphrase, "export")
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		assert.Equal(t, "A=\"1\"\n", ta.stdout.String())
+	})
+}
+
+func Test_App_cmdRoleAdd(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrRoleExists error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+		fd := ta.passphraseFD(t, testPassphrase, testNewPassphrase)
+
+		code := ta.run("", "role", "add", "--passphrase-fd", fd, DefaultRole)
+		assert.Equal(t, failure.ExitExists, code)
+		assert.Contains(t, ta.stderr.String(), "ErrRoleExists")
+	})
+	t.Run("ErrAuthFailed error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+		fd := ta.passphraseFD(t, "wrong-"+testPassphrase, testNewPassphrase)
+
+		code := ta.run("", "role", "add", "--passphrase-fd", fd, "bob")
+		assert.Equal(t, failure.ExitAuth, code)
+		assert.Contains(t, ta.stderr.String(), "ErrAuthFailed")
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"}, []string{"set", "A=1"})
+		fd := ta.passphraseFD(t, testPassphrase, testNewPassphrase)
+
+		code := ta.run("", "role", "add", "--passphrase-fd", fd, "bob")
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		code = ta.run(testNewPassphrase, "export", "--role", "bob")
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		assert.Equal(t, "A=\"1\"\n", ta.stdout.String())
+	})
+	t.Run("Scoped to env", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"},
+			[]string{"init", "--env", "dev", "--parent", DefaultEnv},
+			[]string{"init", "--env", "prod", "--parent", DefaultEnv})
+		fd := ta.passphraseFD(t, testPassphrase, testNewPassphrase)
+
+		code := ta.run("", "role", "add",
+			"--env", "dev", "--passphrase-fd", fd, "devteam")
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		code = ta.run(testNewPassphrase,
+			"export", "--env", "dev", "--role", "devteam")
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		code = ta.run(testNewPassphrase,
+			"export", "--env", "prod", "--role", "devteam")
+		assert.Equal(t, failure.ExitNotFound, code)
+		assert.Contains(t, ta.stderr.String(), "ErrSlotNotFound")
+	})
+	t.Run("Recipient", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"}, []string{"set", "A=1"})
+		ta.addRecipient(t, "ci")
+
+		code := ta.run("", "export", "--role", "ci")
+		assert.Equal(t, failure.ExitUsage, code)
+		assert.Contains(t, ta.stderr.String(), "ErrUsage")
+		code = ta.run("", "export",
+			"--role", "ci", "--identity", ta.path("ci.key"))
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		assert.Equal(t, "A=\"1\"\n", ta.stdout.String())
+		ta.setup(t, []string{"role", "list"})
+		assert.Regexp(t, `\nci +x25519-mlkem768 +- `, ta.stdout.String())
+	})
+	t.Run("SSH key", func(t *testing.T) {
+		t.Parallel()
+		_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+		rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+		tests := []struct {
+			name       string
+			key        any
+			passphrase string
+			secret     string
+			expCode    int
+			expErr     string
+		}{
+			{"Ed25519", edKey, "", "", failure.ExitOK, ""},
+			{"Ed25519 with passphrase", edKey, testNewPassphrase,
+				testNewPassphrase, failure.ExitOK, ""},
+			{"RSA", rsaKey, "", "", failure.ExitOK, ""},
+			{"ErrAuthFailed error", edKey, testNewPassphrase,
+				"wrong-" + testNewPassphrase, failure.ExitAuth,
+				"ErrAuthFailed"},
+		}
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				t.Parallel()
+				ta := newTestApp(t)
+				ta.setup(t, []string{"init"}, []string{"set", "A=1"})
+				ta.addSSHRole(t, "dev", test.key, test.passphrase)
+
+				code := ta.run(test.secret, "export",
+					"--role", "dev", "--identity", ta.path("dev.key"))
+				assert.Equal(t, test.expCode, code, ta.stderr.String())
+				assert.Contains(t, ta.stderr.String(), test.expErr)
+				if test.expCode == failure.ExitOK {
+					assert.Equal(t, "A=\"1\"\n", ta.stdout.String())
+				}
+			})
+		}
+	})
+	t.Run("SSH agent", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"}, []string{"set", "A=1"})
+		_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+		ta.startSSHAgent(t, edKey)
+		ta.addSSHRole(t, "dev", edKey, "", "--ssh-agent")
+
+		code := ta.run("", "export", "--role", "dev")
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		assert.Equal(t, "A=\"1\"\n", ta.stdout.String())
+		ta.setup(t, []string{"role", "list"})
+		assert.Regexp(t, `\ndev +ssh-agent-ed25519 `, ta.stdout.String())
+	})
+	t.Run("ErrAgentFailed error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+		_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+		ta.startSSHAgent(t, edKey)
+		ta.addSSHRole(t, "dev", edKey, "", "--ssh-agent")
+		delete(ta.vars, cimpl.SSHAuthSockEnv)
+
+		code := ta.run("", "export", "--role", "dev")
+		assert.Equal(t, failure.ExitAuth, code)
+		assert.Contains(t, ta.stderr.String(), "ErrAgentFailed")
+	})
+	t.Run("ErrSlotKindMismatch error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+		_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+		rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+		ta.addSSHRole(t, "dev", edKey, "")
+		ta.addSSHRole(t, "ops", rsaKey, "")
+
+		code := ta.run("", "export",
+			"--role", "dev", "--identity", ta.path("ops.key"))
+		assert.Equal(t, failure.ExitInvalid, code)
+		assert.Contains(t, ta.stderr.String(), "ErrSlotKindMismatch")
+	})
+	t.Run("ErrInvalidRecipient error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+		pub := ta.path("dev.pub")
+		err := os.WriteFile(pub, []byte("ssh-ed25519 AAAA\n"), 0600)
+		assert.NoError(t, err)
+
+		code := ta.run(testPassphrase, "role", "add", "--ssh-key", pub, "dev")
+		assert.Equal(t, failure.ExitInvalid, code)
+		assert.Contains(t, ta.stderr.String(), "ErrInvalidRecipient")
+	})
+}
+
+func (ta *testApp) addSSHRole(t *testing.T,
+	name string, key any, passphrase string, args ...string) {
+	publicKey, err := ssh.NewPublicKey(key.(crypto.Signer).Public())
+	assert.NoError(t, err)
+	pub := ta.path(name + ".pub")
+	err = os.WriteFile(pub, ssh.MarshalAuthorizedKey(publicKey), 0600)
+	assert.NoError(t, err)
+	block, err := ssh.MarshalPrivateKey(key, "")
+	if passphrase != "" {
+		block, err = ssh.MarshalPrivateKeyWithPassphrase(
+			key, "", []byte(passphrase))
+	}
+	assert.NoError(t, err)
+	err = os.WriteFile(ta.path(name+".key"), pem.EncodeToMemory(block), 0600)
+	assert.NoError(t, err)
+	args = append([]string{"role", "add", "--ssh-key", pub}, args...)
+	ta.setup(t, append(args, name))
+}
+
+func (ta *testApp) startSSHAgent(t *testing.T, key any) {
+	keyring := sshagent.NewKeyring()
+	err := keyring.Add(sshagent.AddedKey{PrivateKey: key})
+	assert.NoError(t, err)
+	path := ta.path("ssh-agent.sock")
+	listener, err := net.Listen("unix", path)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go sshagent.ServeAgent(keyring, conn)
+		}
+	}()
+	ta.vars[cimpl.SSHAuthSockEnv] = path
+}
+
+func (ta *testApp) addRecipient(t *testing.T, name string) {
+	ta.setup(t, []string{"identity", "keygen", ta.path(name + ".key")})
+	pub := ta.path(name + ".pub")
+	err := os.WriteFile(pub, ta.stdout.Bytes(), 0600)
+	assert.NoError(t, err)
+	ta.setup(t, []string{"role", "add", "--recipient", pub, name})
+}
+
+func (ta *testApp) addRole(t *testing.T, name string, secret string) {
+	fd := ta.passphraseFD(t, testPassphrase, secret)
+	ta.setup(t, []string{"role", "add", "--passphrase-fd", fd, name})
+}
+
+func Test_App_cmdIdentityKeygen(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrWriteFileFailed error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+
+		code := ta.run("", "identity", "keygen", ta.path("none/ci.key"))
+		assert.Equal(t, failure.ExitIO, code)
+		assert.Contains(t, ta.stderr.String(), "ErrWriteFileFailed")
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		expLen := base64.StdEncoding.EncodedLen(cimpl.HybridPublicKeyLen)
+
+		code := ta.run("", "identity", "keygen", ta.path("ci.key"))
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		assert.Len(t, strings.TrimSpace(ta.stdout.String()), expLen)
+		assert.FileExists(t, ta.path("ci.key"))
+	})
+}
+
+func Test_App_cmdRoleRemove(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrSlotNotFound error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+
+		code := ta.run(testPassphrase, "role", "remove", "bob")
+		assert.Equal(t, failure.ExitNotFound, code)
+		assert.Contains(t, ta.stderr.String(), "ErrSlotNotFound")
+	})
+	t.Run("ErrLastSlot error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+
+		code := ta.run(testPassphrase, "role", "remove", DefaultRole)
+		assert.Equal(t, failure.ExitInvalid, code)
+		assert.Contains(t, ta.stderr.String(), "ErrLastSlot")
+	})
+	t.Run("ErrNotOwner error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+		ta.addRole(t, "bob", testNewPassphrase)
+
+		code := ta.run(testNewPassphrase,
+			"role", "remove", "--role", "bob", DefaultRole)
+		assert.Equal(t, failure.ExitAuth, code)
+		assert.Contains(t, ta.stderr.String(), "ErrNotOwner")
+	})
+	t.Run("Owner steps down", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+		ta.addRole(t, "bob", testNewPassphrase)
+
+		code := ta.run(testPassphrase, "role", "remove", DefaultRole)
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		code = ta.runInput(`{"MinLen": 8}`, testNewPassphrase,
+			"policy", "--role", "bob", "-")
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+		ta.addRole(t, "bob", testNewPassphrase)
+
+		code := ta.run(testPassphrase, "role", "remove", "bob")
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		assert.Equal(t, "Removed from: default\n", ta.stdout.String())
+		assert.Contains(t, ta.stderr.String(), "warning: Removing a role")
+		code = ta.run(testNewPassphrase, "export", "--role", "bob")
+		assert.Equal(t, failure.ExitNotFound, code)
+		assert.Contains(t, ta.stderr.String(), "ErrSlotNotFound")
+	})
+	t.Run("Rotate", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"}, []string{"set", "A=1"})
+		ta.addRole(t, "bob", testNewPassphrase)
+		ta.addRole(t, "carol", testNewPassphrase)
+		const expOut = "Removed from: default\nDropped, add again: carol\n"
+
+		code := ta.run(testPassphrase, "role", "remove", "--rotate", "bob")
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		assert.Equal(t, expOut, ta.stdout.String())
+		assert.Empty(t, ta.stderr.String())
+		ta.setup(t, []string{"export"})
+		assert.Equal(t, "A=\"1\"\n", ta.stdout.String())
+		ta.setup(t, []string{"audit", "verify"})
+	})
+	t.Run("Rotate with recipient", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"}, []string{"set", "A=1"})
+		ta.addRole(t, "bob", testNewPassphrase)
+		ta.addRecipient(t, "ci")
+		_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+		ta.addSSHRole(t, "dev", edKey, "")
+		ta.startSSHAgent(t, edKey)
+		ta.addSSHRole(t, "bot", edKey, "", "--ssh-agent")
+
+		code := ta.run(testPassphrase, "role", "remove", "--rotate", "bob")
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		assert.Equal(t, "Removed from: default\n", ta.stdout.String())
+		ta.setup(t, []string{"export", "--role", "bot"})
+		assert.Equal(t, "A=\"1\"\n", ta.stdout.String())
+		for _, role := range []string{"ci", "dev"} {
+			ta.setup(t, []string{"export",
+				"--role", role, "--identity", ta.path(role + ".key")})
+			assert.Equal(t, "A=\"1\"\n", ta.stdout.String())
+		}
+	})
+	t.Run("Rotate across envs", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"},
+			[]string{"init", "--env", "prod", "--parent", "default"},
+			[]string{"set", "A=1"}, []string{"set", "--env", "prod", "B=2"})
+		fd := ta.passphraseFD(t, testPassphrase, testNewPassphrase)
+		ta.setup(t, []string{"role", "add", "--env", "prod",
+			"--passphrase-fd", fd, "bob"})
+		const expOut = "Removed from: default, prod\n"
+
+		code := ta.run(testPassphrase, "role", "remove", "--rotate", "bob")
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		assert.Equal(t, expOut, ta.stdout.String())
+		ta.setup(t, []string{"export"})
+		assert.Equal(t, "A=\"1\"\n", ta.stdout.String())
+		ta.setup(t, []string{"export", "--env", "prod"})
+		assert.Equal(t, "A=\"1\"\nB=\"2\"\n", ta.stdout.String())
+		ta.setup(t, []string{"audit", "verify"})
+	})
+}
+
+func Test_App_cmdRoleList(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrVaultNotFound error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+
+		code := ta.run("", "role", "list")
+		assert.Equal(t, failure.ExitNotFound, code)
+		assert.Contains(t, ta.stderr.String(), "ErrVaultNotFound")
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"}, []string{"init", "--env", "prod"})
+		ta.addRole(t, "bob", testNewPassphrase)
+		const expOut = `^NAME +KIND +KDF +CREATED +ENVS\n` +
+			`admin +passphrase +argon2i t=\d+ m=\d+KiB p=\d+ +\S+ +` +
+			`default,prod\n` +
+			`bob +passphrase +argon2i t=\d+ m=\d+KiB p=\d+ +\S+ +` +
+			`default\n$`
+
+		code := ta.run("", "role", "list")
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		assert.Regexp(t, expOut, ta.stdout.String())
+	})
+}
@@ -0,0 +1,488 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/reshifr/secure-env/core/agent"
+	agimpl "github.com/reshifr/secure-env/core/agent/impl"
+	"github.com/reshifr/secure-env/core/audit"
+	auimpl "github.com/reshifr/secure-env/core/audit/impl"
+	"github.com/reshifr/secure-env/core/crypto"
+	cimpl "github.com/reshifr/secure-env/core/crypto/impl"
+	"github.com/reshifr/secure-env/core/failure"
+	"github.com/reshifr/secure-env/core/passphrase"
+	pimpl "github.com/reshifr/secure-env/core/passphrase/impl"
+	"github.com/reshifr/secure-env/core/vault"
+	vimpl "github.com/reshifr/secure-env/core/vault/impl"
+)
+
+const (
+	StateHomeEnv = "XDG_STATE_HOME"
+	HomeEnv      = "HOME"
+	KDFInfo      = "secure-env"
+)
+
+type Keeper = vimpl.Keeper[
+	crypto.Authorizer,
+	cimpl.ChaChaPoly,
+	cimpl.Stream[cimpl.ChaChaPoly],
+	cimpl.HKDF]
+
+type options struct {
+	vault         string
+	env           string
+	role          string
+	passphraseFD  int
+	trust         string
+	allowRollback bool
+	identity      string
+}
+
+type session struct {
+	app        *App
+	opts       options
+	rng        cimpl.StdRNG
+	iv         *cimpl.IV96
+	v          *vault.Vault
+	cipher     cimpl.ChaChaPoly
+	keeper     Keeper
+	log        auimpl.FileLog
+	guard      vimpl.Guard[vimpl.FileWatermarks]
+	provider   passphrase.Provider
+	renewer    passphrase.Provider
+	passphrase *crypto.Secret
+	keyring    vault.Keyring
+}
+
+func (app *App) flags(name string, opts *options) *flag.FlagSet {
+	flags := flag.NewFlagSet("senv "+name, flag.ContinueOnError)
+	flags.SetOutput(app.stderr)
+	flags.StringVar(&opts.vault, "vault",
+		app.lookup(VaultPathEnv, DefaultVaultPath), "vault file")
+	flags.StringVar(&opts.env, "env", DefaultEnv, "environment name")
+	flags.StringVar(&opts.role, "role", DefaultRole, "role name")
+	flags.IntVar(&opts.passphraseFD, "passphrase-fd", -1,
+		"read passphrases from this file descriptor")
+	flags.StringVar(&opts.trust, "trust", "",
+		"JSON file of trusted signers; require a valid signature")
+	flags.BoolVar(&opts.allowRollback, "allow-rollback", false,
+		"open vaults older than the last one seen")
+	flags.StringVar(&opts.identity, "identity", "",
+		"identity file for recipient roles")
+	flags.BoolVar(&app.json, "json", false, "report errors as JSON")
+	return flags
+}
+
+func (app *App) parse(
+	flags *flag.FlagSet, args []string, minArgs int, maxArgs int) error {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return ErrUsage
+	}
+	if flags.NArg() < minArgs || (maxArgs >= 0 && flags.NArg() > maxArgs) {
+		flags.Usage()
+		return ErrUsage
+	}
+	return nil
+}
+
+// provider builds the chain that reads passphrases from the variable key.
+// Without a terminal the pinentry and tty providers are left out, so a
+// missing passphrase fails instead of waiting on a prompt.
+func (app *App) provider(
+	fd int, key string, terminal bool) (passphrase.Provider, error) {
+	order := app.lookup(passphrase.OrderEnv, passphrase.DefaultOrder)
+	providers := map[string]passphrase.Provider{
+		passphrase.ProviderFD: pimpl.NewFD(fd),
+		passphrase.ProviderEnv: pimpl.NewEnv(pimpl.FnEnv{
+			LookupEnv: app.fn.LookupEnv,
+			Unsetenv:  app.fn.Unsetenv,
+		}, key),
+		passphrase.ProviderAskpass: pimpl.NewAskpass(
+			pimpl.FnAskpass{LookupEnv: app.fn.LookupEnv}),
+		passphrase.ProviderPinentry: pimpl.NewPinentry(
+			pimpl.FnPinentry{LookupEnv: app.fn.LookupEnv}),
+		passphrase.ProviderTTY: pimpl.NewTTY(pimpl.TTYPath),
+	}
+	if !terminal {
+		providers[passphrase.ProviderPinentry] = pimpl.NewChain()
+		providers[passphrase.ProviderTTY] = pimpl.NewChain()
+	}
+	return pimpl.NewChainFromOrder(order, providers)
+}
+
+func (app *App) agent() (agimpl.Client, bool) {
+	path, ok := app.fn.LookupEnv(agent.AuthSockEnv)
+	if !ok || path == "" {
+		return agimpl.Client{}, false
+	}
+	return agimpl.NewClient(path), true
+}
+
+func (app *App) watermarksPath() string {
+	home, _ := app.fn.LookupEnv(HomeEnv)
+	if home == "" {
+		home, _ = os.UserHomeDir()
+	}
+	return vault.WatermarksPath(
+		app.lookup(vault.WatermarksPathEnv, ""),
+		app.lookup(StateHomeEnv, ""),
+		home)
+}
+
+func loadTrust(path string) ([]vault.Signer, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, failure.New(OpReadFile, ErrReadFileFailed, err)
+	}
+	signers := []vault.Signer{}
+	if err := json.Unmarshal(buf, &signers); err != nil {
+		return nil, failure.New(OpReadFile, ErrInvalidFile, err)
+	}
+	return signers, nil
+}
+
+func (app *App) session(opts options, create bool) (*session, error) {
+	rng := cimpl.NewStdRNG(cimpl.FnStdRNG{Read: rand.Read})
+	rawIV, err := rng.Block(cimpl.IV96Len)
+	if err != nil {
+		return nil, err
+	}
+	iv, err := cimpl.LoadIV96(rawIV)
+	if err != nil {
+		return nil, err
+	}
+	v, err := loadVault(opts.vault)
+	if create && errors.Is(err, ErrVaultNotFound) {
+		v = &vault.Vault{}
+		v.ID, err = vimpl.NewVaultID(rng)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if opts.trust != "" {
+		signers, err := loadTrust(opts.trust)
+		if err != nil {
+			return nil, err
+		}
+		notary := vimpl.NewNotary(cimpl.Ed25519Verifier{})
+		trust := vault.Trust{Require: true, Signers: signers}
+		if err := notary.Check(v, trust); err != nil {
+			return nil, err
+		}
+	}
+	// SENV_PASSPHRASE marks a non-interactive run; new passphrases must then
+	// come from SENV_NEW_PASSPHRASE, the descriptor or askpass.
+	_, scripted := app.fn.LookupEnv(passphrase.PassphraseEnv)
+	provider, err := app.provider(
+		opts.passphraseFD, passphrase.PassphraseEnv, true)
+	if err != nil {
+		return nil, err
+	}
+	renewer, err := app.provider(
+		opts.passphraseFD, passphrase.NewPassphraseEnv, !scripted)
+	if err != nil {
+		return nil, err
+	}
+	cipher := cimpl.ChaChaPoly{}
+	stream, err := cimpl.NewStream(cipher, cimpl.StreamChunkLen)
+	if err != nil {
+		return nil, err
+	}
+	var authorizer crypto.Authorizer = cimpl.NewRoleAuthorizer(
+		cimpl.Argon{}, rng, cipher)
+	if client, ok := app.agent(); ok {
+		authorizer = agimpl.NewCachingAuthorizer(authorizer, client)
+	}
+	authorizer = pimpl.NewPolicyAuthorizer(authorizer,
+		pimpl.NewEstimator(pimpl.FnEstimator{Now: app.fn.Now}),
+		v.PassphrasePolicy())
+	log := auimpl.NewFileLog(
+		audit.LogPath(opts.vault, app.lookup(audit.LogPathEnv, "")))
+	watermarks := vimpl.NewFileWatermarks(app.watermarksPath())
+	keeper := vimpl.NewKeeper(
+		vimpl.FnKeeper{Now: app.fn.Now, Audit: log.Append, Head: log.Head},
+		authorizer, cipher, stream, cimpl.NewHKDF([]byte(KDFInfo)))
+	return &session{
+		app:      app,
+		opts:     opts,
+		rng:      rng,
+		iv:       iv,
+		v:        v,
+		cipher:   cipher,
+		keeper:   keeper,
+		log:      log,
+		guard:    vimpl.NewGuard(watermarks),
+		provider: provider,
+		renewer:  renewer,
+	}, nil
+}
+
+func (s *session) close() {
+	s.passphrase.Destroy()
+	s.keyring.Destroy()
+}
+
+func (s *session) prompt(prompt string) (*crypto.Secret, error) {
+	return s.provider.Passphrase(prompt)
+}
+
+func (s *session) rolePassphrase() (*crypto.Secret, error) {
+	if s.passphrase != nil {
+		return s.passphrase, nil
+	}
+	secret, err := s.prompt(
+		fmt.Sprintf("Passphrase for role %s:", s.opts.role))
+	if err != nil {
+		return nil, err
+	}
+	s.passphrase = secret
+	return secret, nil
+}
+
+func (s *session) promptNew(prompt string) (*crypto.Secret, error) {
+	secret, err := s.renewer.Passphrase(prompt)
+	if errors.Is(err, passphrase.ErrProviderUnavailable) {
+		return nil, passphrase.ErrNewPassphraseUnavailable
+	}
+	return secret, err
+}
+
+func (s *session) newPassphrase(role string) (*crypto.Secret, error) {
+	return s.promptNew(fmt.Sprintf("New passphrase for role %s:", role))
+}
+
+func (s *session) cached(name string) bool {
+	client, ok := s.app.agent()
+	if !ok {
+		return false
+	}
+	chain, err := s.v.Chain(name)
+	if err != nil {
+		return false
+	}
+	for _, e := range chain {
+		slot, err := e.Slot(s.opts.role)
+		if err != nil {
+			return false
+		}
+		key, err := client.Get(agimpl.BlockID(slot.Block))
+		clear(key)
+		if err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *session) slotKind(name string) string {
+	e, err := s.v.Env(name)
+	if err != nil {
+		return ""
+	}
+	slot, err := e.Slot(s.opts.role)
+	if err != nil {
+		return ""
+	}
+	return slot.Kind
+}
+
+func (s *session) open(name string) (vault.Keyring, error) {
+	switch kind := s.slotKind(name); kind {
+	case "", cimpl.RoleAuthorizerKind:
+		if s.passphrase == nil && s.cached(name) {
+			empty, err := crypto.NewSecret(0)
+			if err != nil {
+				return nil, err
+			}
+			return s.keeper.Open(s.v, name, s.opts.role, empty)
+		}
+		secret, err := s.rolePassphrase()
+		if err != nil {
+			return nil, err
+		}
+		return s.keeper.Open(s.v, name, s.opts.role, secret)
+	case cimpl.SSHAgentRecipientKind:
+		recipient, done, err := s.sshAgentRecipient()
+		if err != nil {
+			return nil, err
+		}
+		defer done()
+		return s.keeper.OpenRecipient(
+			s.v, name, s.opts.role, recipient, nil)
+	default:
+		return s.openIdentity(name, kind)
+	}
+}
+
+func (s *session) openIdentity(name string, kind string) (
+	vault.Keyring, error) {
+	recipient, ok := s.recipient(kind)
+	if !ok {
+		return nil, failure.Wrap(vault.ErrSlotKindMismatch, failure.Error{
+			Op: vault.OpOpen, Env: name, Role: s.opts.role})
+	}
+	if s.opts.identity == "" {
+		return nil, failure.Wrap(ErrUsage, failure.Error{
+			Op: vault.OpOpen, Env: name, Role: s.opts.role})
+	}
+	identity, err := s.loadIdentity(kind)
+	if err != nil {
+		return nil, err
+	}
+	defer identity.Destroy()
+	return s.keeper.OpenRecipient(
+		s.v, name, s.opts.role, recipient, identity)
+}
+
+func (s *session) loadIdentity(kind string) (*crypto.Secret, error) {
+	if kind == cimpl.HybridRecipientKind {
+		return loadKey(s.opts.identity)
+	}
+	pem, err := os.ReadFile(s.opts.identity)
+	if err != nil {
+		return nil, failure.New(OpReadFile, ErrReadFileFailed, err)
+	}
+	defer clear(pem)
+	identity, identityKind, err := cimpl.LoadSSHIdentity(pem, nil)
+	if errors.Is(err, crypto.ErrIdentityLocked) {
+		var secret *crypto.Secret
+		secret, err = s.prompt(
+			fmt.Sprintf("Passphrase for %s:", s.opts.identity))
+		if err != nil {
+			return nil, err
+		}
+		identity, identityKind, err = cimpl.LoadSSHIdentity(pem, secret)
+		secret.Destroy()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if identityKind != kind {
+		identity.Destroy()
+		return nil, vault.ErrSlotKindMismatch
+	}
+	return identity, nil
+}
+
+func (s *session) recipient(kind string) (crypto.Recipient, bool) {
+	switch kind {
+	case cimpl.HybridRecipientKind:
+		return cimpl.NewHybridRecipient(s.rng, s.cipher), true
+	case cimpl.SSHEd25519RecipientKind:
+		return cimpl.NewSSHEd25519Recipient(s.rng, s.cipher), true
+	case cimpl.SSHRSARecipientKind:
+		return cimpl.NewSSHRSARecipient(s.rng), true
+	default:
+		return nil, false
+	}
+}
+
+func (s *session) sshAgentRecipient() (crypto.Recipient, func(), error) {
+	client, conn, err := cimpl.DialSSHAgent(cimpl.FnSSHAgent{
+		LookupEnv: s.app.fn.LookupEnv,
+		Dial:      net.Dial,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	recipient := cimpl.NewSSHAgentRecipient(client, s.rng, s.cipher)
+	return recipient, func() { conn.Close() }, nil
+}
+
+func (s *session) recipients() []crypto.Recipient {
+	return []crypto.Recipient{
+		cimpl.NewHybridRecipient(s.rng, s.cipher),
+		cimpl.NewSSHEd25519Recipient(s.rng, s.cipher),
+		cimpl.NewSSHRSARecipient(s.rng),
+	}
+}
+
+func (s *session) unlock() error {
+	keyring, err := s.open(s.opts.env)
+	if err != nil {
+		return err
+	}
+	s.keyring = keyring
+	generations, err := s.keeper.Generations(s.v, keyring)
+	if err != nil {
+		return err
+	}
+	rolledBack, err := s.guard.Check(
+		s.v, generations, s.opts.allowRollback)
+	if err != nil {
+		return err
+	}
+	if rolledBack {
+		fmt.Fprintln(s.app.stderr, "senv: warning: "+vault.RollbackWarning)
+	}
+	return nil
+}
+
+// unlockOwner opens every environment without a parent through the
+// role's own slot and requires the role to have created it, so that no
+// other role can change vault-wide settings such as the passphrase policy
+// and the schema. Their stamps bind the owner, and their keys stay in the
+// keyring so that commit stamps the new settings.
+func (s *session) unlockOwner() error {
+	for _, e := range s.v.Envs {
+		if e.Parent != "" {
+			continue
+		}
+		if e.Owner() != s.opts.role {
+			return failure.Wrap(vault.ErrNotOwner, failure.Error{
+				Op: vault.OpOpen, Env: e.Name, Role: s.opts.role})
+		}
+		if _, ok := s.keyring[e.Name]; ok {
+			continue
+		}
+		keyring, err := s.open(e.Name)
+		if err != nil {
+			return err
+		}
+		s.keyring[e.Name] = keyring[e.Name]
+	}
+	_, err := s.keeper.Generations(s.v, s.keyring)
+	return err
+}
+
+func (s *session) reopen() error {
+	keyring, err := s.open(s.opts.env)
+	if err != nil {
+		return err
+	}
+	s.keyring.Destroy()
+	s.keyring = keyring
+	return nil
+}
+
+func (s *session) record(command string) error {
+	return s.keeper.Record(
+		s.iv, s.v, s.keyring, s.opts.env, s.opts.role, command)
+}
+
+func (s *session) commit() error {
+	if err := s.keeper.Anchor(s.iv, s.v, s.keyring); err != nil {
+		return err
+	}
+	if err := s.keeper.Stamp(s.iv, s.v, s.keyring); err != nil {
+		return err
+	}
+	generations, err := s.keeper.Generations(s.v, s.keyring)
+	if err != nil {
+		return err
+	}
+	_, err = s.guard.Check(s.v, generations, s.opts.allowRollback)
+	if err != nil {
+		return err
+	}
+	return saveVault(s.opts.vault, s.v)
+}
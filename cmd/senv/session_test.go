@@ -0,0 +1,136 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/reshifr/secure-env/core/failure"
+	"github.com/reshifr/secure-env/core/passphrase"
+	"github.com/reshifr/secure-env/core/vault"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_App_provider(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrUnknownProvider error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+		ta.vars[passphrase.OrderEnv] = "env,carrier-pigeon"
+
+		code := ta.run(testPassphrase, "list")
+		assert.Equal(t, failure.ExitInvalid, code)
+		assert.Contains(t, ta.stderr.String(), "ErrUnknownProvider")
+	})
+	t.Run("ErrProviderUnavailable error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+		ta.vars[passphrase.OrderEnv] = passphrase.ProviderFD
+
+		code := ta.run(testPassphrase, "set", "A=1")
+		assert.Equal(t, failure.ExitIO, code)
+		assert.Contains(t, ta.stderr.String(), "ErrProviderUnavailable")
+	})
+	t.Run("Passphrase from fd", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		fd := ta.passphraseFD(t, testPassphrase, testPassphrase)
+
+		code := ta.run("", "init", "--passphrase-fd", fd)
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		code = ta.run("", "set", "--passphrase-fd", fd, "A=1")
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		code = ta.run(testPassphrase, "export")
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		assert.Equal(t, "A=\"1\"\n", ta.stdout.String())
+	})
+}
+
+func Test_App_watermarksPath(t *testing.T) {
+	t.Parallel()
+	t.Run("Override", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		expPath := ta.path("watermarks.json")
+
+		path := ta.app.watermarksPath()
+		assert.Equal(t, expPath, path)
+	})
+	t.Run("State home", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		delete(ta.vars, vault.WatermarksPathEnv)
+		ta.vars[StateHomeEnv] = ta.path("state")
+		expPath := filepath.Join(ta.path("state"), "senv", vault.WatermarksFile)
+
+		path := ta.app.watermarksPath()
+		assert.Equal(t, expPath, path)
+	})
+	t.Run("Home", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		delete(ta.vars, vault.WatermarksPathEnv)
+		ta.vars[HomeEnv] = ta.path("home")
+		expPath := filepath.Join(
+			ta.path("home"), ".local", "state", "senv", vault.WatermarksFile)
+
+		path := ta.app.watermarksPath()
+		assert.Equal(t, expPath, path)
+	})
+}
+
+func Test_App_guard(t *testing.T) {
+	t.Parallel()
+	rollback := func(t *testing.T, ta *testApp) {
+		ta.setup(t, []string{"init"}, []string{"set", "A=1"})
+		buf, err := os.ReadFile(ta.path(DefaultVaultPath))
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		ta.setup(t, []string{"set", "A=2"})
+		err = os.WriteFile(ta.path(DefaultVaultPath), buf, 0600)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+	}
+
+	t.Run("ErrMissingVaultID error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+		v, _ := loadVault(ta.path(DefaultVaultPath))
+		v.ID = ""
+		saveVault(ta.path(DefaultVaultPath), v)
+
+		code := ta.run(testPassphrase, "export")
+		assert.Equal(t, failure.ExitIntegrity, code)
+		assert.Contains(t, ta.stderr.String(), "ErrMissingVaultID")
+	})
+	t.Run("ErrRollback error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		rollback(t, ta)
+
+		code := ta.run(testPassphrase, "export")
+		assert.Equal(t, failure.ExitIntegrity, code)
+		assert.Contains(t, ta.stderr.String(), "ErrRollback")
+	})
+	t.Run("Allow rollback", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		rollback(t, ta)
+
+		code := ta.run(testPassphrase, "export", "--allow-rollback")
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		assert.Equal(t, "A=\"1\"\n", ta.stdout.String())
+		assert.True(t, strings.HasPrefix(ta.stderr.String(),
+			"senv: warning: The vault is older"))
+
+		code = ta.run(testPassphrase, "export")
+		assert.Equal(t, failure.ExitIntegrity, code)
+		assert.Contains(t, ta.stderr.String(), "ErrRollback")
+	})
+}
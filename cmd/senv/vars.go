@@ -0,0 +1,638 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	cimpl "github.com/reshifr/secure-env/core/crypto/impl"
+	"github.com/reshifr/secure-env/core/env"
+	eimpl "github.com/reshifr/secure-env/core/env/impl"
+	"github.com/reshifr/secure-env/core/failure"
+	"github.com/reshifr/secure-env/core/passphrase"
+	"github.com/reshifr/secure-env/core/run"
+	rimpl "github.com/reshifr/secure-env/core/run/impl"
+	simpl "github.com/reshifr/secure-env/core/sops/impl"
+	"github.com/reshifr/secure-env/core/vault"
+)
+
+type listFlag []string
+
+func (list *listFlag) String() string {
+	return strings.Join(*list, ",")
+}
+
+func (list *listFlag) Set(value string) error {
+	*list = append(*list, value)
+	return nil
+}
+
+func (app *App) interpolator(inheritEnv bool) eimpl.Interpolator {
+	fn := eimpl.FnInterpolator{}
+	if inheritEnv {
+		fn.LookupEnv = app.fn.LookupEnv
+	}
+	return eimpl.NewInterpolator(fn)
+}
+
+func (app *App) cmdInit(args []string) error {
+	opts := options{}
+	flags := app.flags("init", &opts)
+	parent := flags.String("parent", "", "environment to inherit from")
+	if err := app.parse(flags, args, 0, 0); err != nil {
+		return err
+	}
+	s, err := app.session(opts, true)
+	if err != nil {
+		return err
+	}
+	defer s.close()
+	if *parent == "" {
+		s.passphrase, err = s.prompt(
+			fmt.Sprintf("New passphrase for role %s:", opts.role))
+	} else {
+		_, err = s.rolePassphrase()
+	}
+	if err != nil {
+		return err
+	}
+	s.keyring, err = s.keeper.CreateEnv(
+		s.iv, s.v, opts.env, *parent, opts.role, s.passphrase)
+	if err != nil {
+		return err
+	}
+	return s.commit()
+}
+
+func (app *App) cmdSet(args []string) error {
+	opts := options{}
+	flags := app.flags("set", &opts)
+	file := flags.Bool("file", false, "store NAME=PATH as a file secret")
+	interpolate := flags.Bool("interpolate", false,
+		"expand ${VAR} references in the values when they are read")
+	if err := app.parse(flags, args, 1, -1); err != nil {
+		return err
+	}
+	s, err := app.session(opts, false)
+	if err != nil {
+		return err
+	}
+	defer s.close()
+	if err := s.unlock(); err != nil {
+		return err
+	}
+	for _, arg := range flags.Args() {
+		name, value, ok := strings.Cut(arg, "=")
+		switch {
+		case *file && ok:
+			err = s.setFile(name, value)
+		case *file:
+			err = ErrUsage
+		case ok:
+			err = s.keeper.Set(s.iv, s.v, s.keyring, opts.env, opts.role,
+				env.Var{Name: name, Value: value, Interpolate: *interpolate})
+		default:
+			err = s.setStdin(name, *interpolate)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return s.commit()
+}
+
+func (s *session) setFile(name string, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return failure.New(OpReadFile, ErrReadFileFailed, err)
+	}
+	defer file.Close()
+	return s.keeper.SetFile(
+		s.iv, s.v, s.keyring, s.opts.env, s.opts.role, name, file)
+}
+
+func (s *session) setStdin(name string, interpolate bool) error {
+	buf, err := readInput(s.app.stdin, "-")
+	if err != nil {
+		return err
+	}
+	value := strings.TrimSuffix(string(buf), "\n")
+	return s.keeper.Set(s.iv, s.v, s.keyring, s.opts.env, s.opts.role,
+		env.Var{
+			Name:        name,
+			Value:       strings.TrimSuffix(value, "\r"),
+			Interpolate: interpolate,
+		})
+}
+
+func loadAgeIdentities(paths []string) ([]*crypto.Secret, error) {
+	identities := []*crypto.Secret{}
+	for _, path := range paths {
+		buf, err := os.ReadFile(path)
+		if err != nil {
+			destroyAll(identities)
+			return nil, failure.New(OpReadFile, ErrReadFileFailed, err)
+		}
+		parsed, err := cimpl.ParseAgeIdentities(buf)
+		clear(buf)
+		if err != nil {
+			destroyAll(identities)
+			return nil, err
+		}
+		identities = append(identities, parsed...)
+	}
+	return identities, nil
+}
+
+func destroyAll(secrets []*crypto.Secret) {
+	for _, secret := range secrets {
+		secret.Destroy()
+	}
+}
+
+func (s *session) decryptAge(
+	identities []*crypto.Secret, buf []byte) ([]byte, error) {
+	age, err := cimpl.NewAge(s.rng, cimpl.AgeScryptWorkFactor)
+	if err != nil {
+		return nil, err
+	}
+	var secret *crypto.Secret
+	if len(identities) == 0 {
+		secret, err = s.prompt("Passphrase for the age file:")
+		if err != nil {
+			return nil, err
+		}
+		defer secret.Destroy()
+	}
+	r, err := age.Decrypt(identities, secret, bytes.NewReader(buf))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		clear(plaintext)
+		return nil, err
+	}
+	return plaintext, nil
+}
+
+func sopsFormat(format string, path string) string {
+	if format != "" {
+		return format
+	}
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml":
+		return simpl.FormatYAML
+	default:
+		return simpl.FormatDotenv
+	}
+}
+
+func (app *App) cmdImport(args []string) error {
+	opts := options{}
+	flags := app.flags("import", &opts)
+	format := flags.String("format", "", "input format")
+	ageFile := flags.Bool("age", false, "decrypt an age-encrypted file")
+	sopsFile := flags.Bool("sops", false, "decrypt a SOPS-encrypted file")
+	interpolate := flags.Bool("interpolate", false,
+		"expand ${VAR} references in the values when they are read")
+	ageIdentities := listFlag{}
+	flags.Var(&ageIdentities, "age-identity", "age identity file")
+	if err := app.parse(flags, args, 1, 1); err != nil {
+		return err
+	}
+	s, err := app.session(opts, false)
+	if err != nil {
+		return err
+	}
+	defer s.close()
+	if err := s.unlock(); err != nil {
+		return err
+	}
+	path := flags.Arg(0)
+	buf, err := readInput(app.stdin, path)
+	if err != nil {
+		return err
+	}
+	identities, err := loadAgeIdentities(ageIdentities)
+	if err != nil {
+		return err
+	}
+	defer destroyAll(identities)
+	var vars []env.Var
+	switch {
+	case *sopsFile:
+		vars, err = s.decodeSOPS(sopsFormat(*format, path), identities, buf)
+	case *ageFile:
+		vars, err = s.decodeAge(*format, identities, buf)
+	default:
+		vars, err = decode(*format, buf)
+	}
+	if err != nil {
+		return err
+	}
+	for i := range vars {
+		vars[i].Interpolate = *interpolate
+	}
+	report, err := s.keeper.Import(
+		s.iv, s.v, s.keyring, opts.env, opts.role, vars)
+	if err != nil {
+		return err
+	}
+	if err := s.commit(); err != nil {
+		return err
+	}
+	if len(report.Added) != 0 {
+		fmt.Fprintf(app.stdout,
+			"Added: %s\n", strings.Join(report.Added, ", "))
+	}
+	if len(report.Updated) != 0 {
+		fmt.Fprintf(app.stdout,
+			"Updated: %s\n", strings.Join(report.Updated, ", "))
+	}
+	return nil
+}
+
+func decode(format string, buf []byte) ([]env.Var, error) {
+	if format == "" {
+		format = eimpl.FormatDotenv
+	}
+	decoder, err := eimpl.NewFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	return decoder.Decode(buf)
+}
+
+func (s *session) decodeAge(format string,
+	identities []*crypto.Secret, buf []byte) ([]env.Var, error) {
+	plaintext, err := s.decryptAge(identities, buf)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(plaintext)
+	return decode(format, plaintext)
+}
+
+func (s *session) decodeSOPS(format string,
+	identities []*crypto.Secret, buf []byte) ([]env.Var, error) {
+	age, err := cimpl.NewAge(s.rng, cimpl.AgeScryptWorkFactor)
+	if err != nil {
+		return nil, err
+	}
+	decoder, err := simpl.NewSOPS(age, format)
+	if err != nil {
+		return nil, err
+	}
+	return decoder.Decode(buf, identities)
+}
+
+func (s *session) resolve(inheritEnv bool) ([]vault.ResolvedVar, error) {
+	return s.keeper.Resolve(
+		s.v, s.keyring, s.opts.env, s.app.interpolator(inheritEnv))
+}
+
+func values(resolved []vault.ResolvedVar) []env.Var {
+	vars := []env.Var{}
+	for _, variable := range resolved {
+		if variable.Kind != vault.EntryKindFile {
+			vars = append(vars, variable.Var)
+		}
+	}
+	return vars
+}
+
+func (s *session) encryptAge(
+	recipients []string, buf []byte) ([]byte, error) {
+	age, err := cimpl.NewAge(s.rng, cimpl.AgeScryptWorkFactor)
+	if err != nil {
+		return nil, err
+	}
+	out := &bytes.Buffer{}
+	var w io.WriteCloser
+	if len(recipients) == 0 {
+		secret, err := s.promptNew("New passphrase for the age file:")
+		if err != nil {
+			return nil, err
+		}
+		defer secret.Destroy()
+		w, err = age.EncryptPassphrase(secret, out)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		publicKeys := make([][]byte, len(recipients))
+		for i, recipient := range recipients {
+			publicKeys[i] = []byte(recipient)
+		}
+		w, err = age.Encrypt(publicKeys, out)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if _, err := w.Write(buf); err != nil {
+		w.Close()
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+func (app *App) cmdExport(args []string) error {
+	opts := options{}
+	flags := app.flags("export", &opts)
+	format := flags.String("format", eimpl.FormatDotenv, "output format")
+	out := flags.String("out", "", "write to this file instead of stdout")
+	inheritEnv := flags.Bool("inherit-env", false,
+		"let ${VAR} reference the process environment")
+	ageFile := flags.Bool("age", false, "encrypt the output with age")
+	recipients := listFlag{}
+	flags.Var(&recipients, "r", "age recipient")
+	flags.Var(&recipients, "recipient", "age recipient")
+	if err := app.parse(flags, args, 0, 0); err != nil {
+		return err
+	}
+	encoder, err := eimpl.NewFormat(*format)
+	if err != nil {
+		return err
+	}
+	s, err := app.session(opts, false)
+	if err != nil {
+		return err
+	}
+	defer s.close()
+	if err := s.unlock(); err != nil {
+		return err
+	}
+	resolved, err := s.resolve(*inheritEnv)
+	if err != nil {
+		return err
+	}
+	if err := s.record(vault.OpExport); err != nil {
+		return err
+	}
+	buf, err := encoder.Encode(values(resolved))
+	if err != nil {
+		return err
+	}
+	if *ageFile || len(recipients) != 0 {
+		plaintext := buf
+		buf, err = s.encryptAge(recipients, plaintext)
+		clear(plaintext)
+		if err != nil {
+			return err
+		}
+	}
+	if *out != "" {
+		return writeFile(*out, buf)
+	}
+	_, err = app.stdout.Write(buf)
+	return err
+}
+
+func (app *App) cmdList(args []string) error {
+	opts := options{}
+	flags := app.flags("list", &opts)
+	resolved := flags.Bool("resolved", false,
+		"list inherited variables and where each comes from")
+	if err := app.parse(flags, args, 0, 0); err != nil {
+		return err
+	}
+	s, err := app.session(opts, false)
+	if err != nil {
+		return err
+	}
+	defer s.close()
+	w := tabwriter.NewWriter(app.stdout, 0, 4, 2, ' ', 0)
+	if !*resolved {
+		e, err := s.v.Env(opts.env)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, "NAME\tKIND")
+		for _, entry := range e.Entries {
+			fmt.Fprintf(w, "%s\t%s\n", entry.Name, entryKind(entry.Kind))
+		}
+		return w.Flush()
+	}
+	if err := s.unlock(); err != nil {
+		return err
+	}
+	vars, err := s.resolve(false)
+	if err != nil {
+		return err
+	}
+	if err := s.record(vault.OpList); err != nil {
+		return err
+	}
+	fmt.Fprintln(w, "NAME\tKIND\tORIGIN")
+	for _, variable := range vars {
+		fmt.Fprintf(w, "%s\t%s\t%s\n",
+			variable.Name, entryKind(variable.Kind), variable.Origin)
+	}
+	return w.Flush()
+}
+
+func entryKind(kind string) string {
+	if kind == vault.EntryKindVar {
+		return "var"
+	}
+	return kind
+}
+
+func (app *App) cmdCheck(args []string) error {
+	opts := options{}
+	flags := app.flags("check", &opts)
+	inheritEnv := flags.Bool("inherit-env", false,
+		"let ${VAR} reference the process environment")
+	if err := app.parse(flags, args, 0, 0); err != nil {
+		return err
+	}
+	s, err := app.session(opts, false)
+	if err != nil {
+		return err
+	}
+	defer s.close()
+	if err := s.unlock(); err != nil {
+		return err
+	}
+	report, err := s.keeper.Check(
+		s.v, s.keyring, opts.env, app.interpolator(*inheritEnv))
+	if err != nil {
+		return err
+	}
+	if err := s.record(vault.OpCheck); err != nil {
+		return err
+	}
+	if len(report) == 0 {
+		return nil
+	}
+	fmt.Fprint(app.stdout, report.String())
+	return failure.Wrap(report[0].Err, failure.Error{
+		Op: vault.OpCheck, Env: opts.env, Var: report[0].Rule.Name})
+}
+
+func (app *App) cmdRun(args []string) error {
+	opts := options{}
+	flags := app.flags("run", &opts)
+	redact := flags.Bool("redact", false,
+		"mask secret values in the command's output")
+	inheritEnv := flags.Bool("inherit-env", false,
+		"let ${VAR} reference the process environment")
+	if err := app.parse(flags, args, 1, -1); err != nil {
+		return err
+	}
+	s, err := app.session(opts, false)
+	if err != nil {
+		return err
+	}
+	defer s.close()
+	if err := s.unlock(); err != nil {
+		return err
+	}
+	resolved, err := s.resolve(*inheritEnv)
+	if err != nil {
+		return err
+	}
+	if err := s.record(vault.OpRun); err != nil {
+		return err
+	}
+	signals := rimpl.Notify()
+	defer signals.Stop()
+	files, err := s.materialize(resolved)
+	if err != nil {
+		return err
+	}
+	vars := values(resolved)
+	// Passphrases a provider never read, as on an agent cache hit, must
+	// not reach the child either.
+	app.fn.Unsetenv(passphrase.PassphraseEnv)
+	app.fn.Unsetenv(passphrase.NewPassphraseEnv)
+	environ := app.fn.Environ()
+	for _, variable := range vars {
+		environ = append(environ, variable.Name+"="+variable.Value)
+	}
+	for _, variable := range resolved {
+		if variable.Kind == vault.EntryKindFile {
+			environ = append(environ,
+				variable.Name+"="+files.Path(variable.Name))
+		}
+	}
+	cmdArgs := flags.Args()
+	cmd := exec.Command(cmdArgs[0], cmdArgs[1:]...)
+	cmd.Env = environ
+	cmd.Stdin = app.stdin
+	cmd.Stdout = app.stdout
+	cmd.Stderr = app.stderr
+	cleanup := []func() error{}
+	if files != nil {
+		cleanup = append(cleanup, files.Remove)
+	}
+	if *redact {
+		matcher := rimpl.NewMatcher(vars)
+		stdout := matcher.Redact(app.stdout)
+		stderr := matcher.Redact(app.stderr)
+		cmd.Stdout, cmd.Stderr = stdout, stderr
+		cleanup = append(cleanup, stdout.Close, stderr.Close)
+	}
+	app.exit, err = rimpl.Run(cmd, signals, func() {
+		for _, fn := range cleanup {
+			fn()
+		}
+	})
+	return err
+}
+
+func (s *session) materialize(
+	resolved []vault.ResolvedVar) (*rimpl.Files, error) {
+	files := []run.File{}
+	for _, variable := range resolved {
+		if variable.Kind != vault.EntryKindFile {
+			continue
+		}
+		name, origin := variable.Name, variable.Origin
+		files = append(files, run.File{
+			Name: name,
+			Open: func() (io.ReadCloser, error) {
+				return s.keeper.OpenFile(s.v, s.keyring, origin, name)
+			},
+		})
+	}
+	if len(files) == 0 {
+		return nil, nil
+	}
+	return rimpl.Materialize(
+		rimpl.FnFiles{LookupEnv: s.app.fn.LookupEnv}, files)
+}
+
+func (app *App) cmdSchema(args []string) error {
+	schema := env.Schema{}
+	return app.settings("schema", args, &schema,
+		func(v *vault.Vault) any { return v.Schema },
+		func(v *vault.Vault) error {
+			if err := schema.Validate(); err != nil {
+				return err
+			}
+			v.Schema = schema
+			return nil
+		})
+}
+
+func (app *App) cmdPolicy(args []string) error {
+	policy := passphrase.Policy{}
+	return app.settings("policy", args, &policy,
+		func(v *vault.Vault) any { return v.PassphrasePolicy() },
+		func(v *vault.Vault) error {
+			if err := policy.Validate(); err != nil {
+				return err
+			}
+			v.Policy = &policy
+			return nil
+		})
+}
+
+func (app *App) settings(name string, args []string, value any,
+	get func(v *vault.Vault) any, set func(v *vault.Vault) error) error {
+	opts := options{}
+	flags := app.flags(name, &opts)
+	if err := app.parse(flags, args, 0, 1); err != nil {
+		return err
+	}
+	s, err := app.session(opts, false)
+	if err != nil {
+		return err
+	}
+	defer s.close()
+	if flags.NArg() == 0 {
+		buf, _ := json.MarshalIndent(get(s.v), "", "  ")
+		fmt.Fprintln(app.stdout, string(buf))
+		return nil
+	}
+	buf, err := readInput(app.stdin, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(buf, value); err != nil {
+		return failure.New(OpReadFile, ErrInvalidFile, err)
+	}
+	if err := s.unlock(); err != nil {
+		return err
+	}
+	if err := s.unlockOwner(); err != nil {
+		return err
+	}
+	if err := set(s.v); err != nil {
+		return err
+	}
+	return s.commit()
+}
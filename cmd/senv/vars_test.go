@@ -0,0 +1,653 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	cimpl "github.com/reshifr/secure-env/core/crypto/impl"
+	eimpl "github.com/reshifr/secure-env/core/env/impl"
+	"github.com/reshifr/secure-env/core/failure"
+	"github.com/reshifr/secure-env/core/passphrase"
+	"github.com/reshifr/secure-env/core/vault"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSchema = `{"Rules": [
+	{"Name": "PORT", "Type": "port", "Required": true,
+		"Description": "listen port"},
+	{"Name": "TOKEN", "Required": true}
+]}`
+
+const testAgeIdentity = "AGE-SECRET-KEY-1755YVEUH6KDX9PZ2U4J54LNZ2JHZSWRD8" +
+	"AR7QV88QVQMHVC46FJS9PMP6V"
+
+const (
+	sopsDotenvFixture = "../../core/sops/test/testdata/secrets.env"
+	sopsYAMLFixture   = "../../core/sops/test/testdata/secrets.yaml"
+)
+
+func ageRecipient(t *testing.T) string {
+	identities, err := cimpl.ParseAgeIdentities([]byte(testAgeIdentity))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer identities[0].Destroy()
+	recipient, err := cimpl.AgeRecipient(identities[0])
+	assert.NoError(t, err)
+	return string(recipient)
+}
+
+func (ta *testApp) exportAge(t *testing.T, name string) string {
+	path := ta.path(name)
+	ta.setup(t, []string{"init"}, []string{"set", "A=1", "B=2"},
+		[]string{"export", "-r", ageRecipient(t), "--out", path})
+	return path
+}
+
+func Test_App_cmdInit(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrEnvExists error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+
+		code := ta.run(testPassphrase, "init")
+		assert.Equal(t, failure.ExitExists, code)
+		assert.Contains(t, ta.stderr.String(), "ErrEnvExists")
+	})
+	t.Run("ErrEnvNotFound error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+
+		code := ta.run(testPassphrase,
+			"init", "--env", "prod", "--parent", "staging")
+		assert.Equal(t, failure.ExitNotFound, code)
+		assert.Contains(t, ta.stderr.String(), "ErrEnvNotFound")
+	})
+	t.Run("Parent env", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"}, []string{"set", "HOST=db", "PORT=1"},
+			[]string{"init", "--env", "prod", "--parent", "default"},
+			[]string{"set", "--env", "prod", "PORT=2"})
+		const expProd = "HOST=\"db\"\nPORT=\"2\"\n"
+		const expDefault = "HOST=\"db\"\nPORT=\"1\"\n"
+
+		code := ta.run(testPassphrase, "export", "--env", "prod")
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		assert.Equal(t, expProd, ta.stdout.String())
+		code = ta.run(testPassphrase, "export")
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		assert.Equal(t, expDefault, ta.stdout.String())
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+
+		code := ta.run(testPassphrase, "init")
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		info, err := os.Stat(ta.vars[VaultPathEnv])
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	})
+}
+
+func Test_App_cmdSet(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrAuthFailed error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+
+		code := ta.run("wrong-"+testPassphrase, "set", "A=1")
+		assert.Equal(t, failure.ExitAuth, code)
+		assert.Contains(t, ta.stderr.String(), "ErrAuthFailed")
+	})
+	t.Run("ErrInvalidVarName error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+
+		code := ta.run(testPassphrase, "set", "1A=1")
+		assert.Equal(t, failure.ExitInvalid, code)
+		assert.Contains(t, ta.stderr.String(), "ErrInvalidVarName")
+	})
+	t.Run("Value from stdin", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+		const expOut = "TOKEN=\"from stdin\"\n"
+
+		code := ta.runInput("from stdin\r\n", testPassphrase, "set", "TOKEN")
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		ta.setup(t, []string{"export"})
+		assert.Equal(t, expOut, ta.stdout.String())
+	})
+	t.Run("ErrUsage error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+
+		code := ta.run(testPassphrase, "set", "--file", "CREDS")
+		assert.Equal(t, failure.ExitUsage, code)
+		assert.Contains(t, ta.stderr.String(), "ErrUsage")
+	})
+	t.Run("ErrReadFileFailed error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+
+		code := ta.run(testPassphrase,
+			"set", "--file", "CREDS="+ta.path("missing.json"))
+		assert.Equal(t, failure.ExitIO, code)
+		assert.Contains(t, ta.stderr.String(), "ErrReadFileFailed")
+	})
+	t.Run("File secret", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		os.WriteFile(ta.path("creds.json"), []byte(`{"k":1}`), 0600)
+		ta.setup(t, []string{"init"}, []string{"set", "A=1"})
+
+		code := ta.run(testPassphrase,
+			"set", "--file", "CREDS="+ta.path("creds.json"))
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		ta.setup(t, []string{"export"})
+		assert.Equal(t, "A=\"1\"\n", ta.stdout.String())
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+		const expOut = "A=\"2\"\nB=\"x=y\"\n"
+
+		code := ta.run(testPassphrase, "set", "A=1", "B=x=y", "A=2")
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		ta.setup(t, []string{"export"})
+		assert.Equal(t, expOut, ta.stdout.String())
+	})
+}
+
+func Test_App_cmdImport(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrReadFileFailed error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+
+		code := ta.run(testPassphrase, "import", ta.path("missing.env"))
+		assert.Equal(t, failure.ExitIO, code)
+		assert.Contains(t, ta.stderr.String(), "ErrReadFileFailed")
+	})
+	t.Run("ErrUnknownFormat error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+
+		code := ta.runInput("A=1\n", testPassphrase,
+			"import", "--format", "toml", "-")
+		assert.Equal(t, failure.ExitInvalid, code)
+		assert.Contains(t, ta.stderr.String(), "ErrUnknownFormat")
+	})
+	t.Run("ErrInvalidSyntax error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+
+		code := ta.runInput("{", testPassphrase,
+			"import", "--format", eimpl.FormatJSON, "-")
+		assert.Equal(t, failure.ExitInvalid, code)
+		assert.Contains(t, ta.stderr.String(), "ErrInvalidSyntax")
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"}, []string{"set", "A=0"})
+		path := ta.path("app.env")
+		os.WriteFile(path, []byte("A=1\nB=2\n"), 0600)
+		const expOut = "Added: B\nUpdated: A\n"
+
+		code := ta.run(testPassphrase, "import", path)
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		assert.Equal(t, expOut, ta.stdout.String())
+	})
+	t.Run("Literal", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+		ta.setupInput(t, "PASS='pa$$word'\nURL='x${Y}'\n", "import", "-")
+		const expOut = "{\n  \"PASS\": \"pa$$word\",\n  \"URL\": \"x${Y}\"\n}\n"
+
+		code := ta.run(testPassphrase, "export", "--format", "json")
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		assert.Equal(t, expOut, ta.stdout.String())
+	})
+	t.Run("Interpolate", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"}, []string{"set", "HOST=db"})
+		ta.setupInput(t, "PASS=pa$$word\nURL=${HOST}:5432\n",
+			"import", "--interpolate", "-")
+		const expOut = "{\n  \"HOST\": \"db\",\n  \"PASS\": \"pa$word\",\n" +
+			"  \"URL\": \"db:5432\"\n}\n"
+
+		code := ta.run(testPassphrase, "export", "--format", "json")
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		assert.Equal(t, expOut, ta.stdout.String())
+	})
+	t.Run("Age", func(t *testing.T) {
+		t.Parallel()
+		t.Run("ErrNoAgeIdentity error", func(t *testing.T) {
+			t.Parallel()
+			ta := newTestApp(t)
+			path := ta.exportAge(t, "app.age")
+			fd := ta.passphraseFD(t, testPassphrase, testNewPassphrase)
+
+			code := ta.run("", "import", "--age", "--passphrase-fd", fd, path)
+			assert.Equal(t, failure.ExitAuth, code)
+			assert.Contains(t, ta.stderr.String(), "ErrNoAgeIdentity")
+		})
+		t.Run("Succeed", func(t *testing.T) {
+			t.Parallel()
+			ta := newTestApp(t)
+			path := ta.exportAge(t, "app.age")
+			identity := ta.path("identity.txt")
+			os.WriteFile(identity, []byte(testAgeIdentity+"\n"), 0600)
+			other := newTestApp(t)
+			other.setup(t, []string{"init"})
+
+			code := other.run(testPassphrase, "import",
+				"--age", "--age-identity", identity, path)
+			assert.Equal(t, failure.ExitOK, code, other.stderr.String())
+			assert.Equal(t, "Added: A, B\n", other.stdout.String())
+		})
+	})
+	t.Run("SOPS", func(t *testing.T) {
+		t.Parallel()
+		dotenv, _ := os.ReadFile(sopsDotenvFixture)
+		yaml, _ := os.ReadFile(sopsYAMLFixture)
+		tampered := bytes.Replace(
+			dotenv, []byte("db.local"), []byte("db.evil1"), 1)
+		tests := []struct {
+			name    string
+			file    string
+			buf     []byte
+			expCode int
+			expOut  string
+			expErr  string
+		}{
+			{"Dotenv", "secrets.env", dotenv, failure.ExitOK,
+				"Added: DB_HOST_unencrypted, DB_PASS, API_TOKEN\n", ""},
+			{"YAML", "secrets.yaml", yaml, failure.ExitOK,
+				"Added: DB_HOST_unencrypted, DB_PASS, DB_PORT, " +
+					"SMTP_PASSWORD\n", ""},
+			{"ErrSOPSMACMismatch error", "secrets.env", tampered,
+				failure.ExitIntegrity, "", "ErrSOPSMACMismatch"},
+		}
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				t.Parallel()
+				ta := newTestApp(t)
+				ta.setup(t, []string{"init"})
+				path := ta.path(test.file)
+				os.WriteFile(path, test.buf, 0600)
+				identity := ta.path("identity.txt")
+				os.WriteFile(identity, []byte(testAgeIdentity+"\n"), 0600)
+
+				code := ta.run(testPassphrase, "import",
+					"--sops", "--age-identity", identity, path)
+				assert.Equal(t, test.expCode, code, ta.stderr.String())
+				assert.Equal(t, test.expOut, ta.stdout.String())
+				assert.Contains(t, ta.stderr.String(), test.expErr)
+			})
+		}
+	})
+}
+
+func Test_App_cmdExport(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		format string
+		expOut string
+	}{
+		{eimpl.FormatDotenv,
+			"DB_USER=\"app\"\nGREETING=\"hello \\\"world\\\"\"\n"},
+		{eimpl.FormatShell,
+			"export DB_USER='app'\nexport GREETING='hello \"world\"'\n"},
+		{eimpl.FormatJSON, "{\n" +
+			"  \"DB_USER\": \"app\",\n" +
+			"  \"GREETING\": \"hello \\\"world\\\"\"\n" +
+			"}\n"},
+		{eimpl.FormatDocker, "DB_USER=app\nGREETING=hello \"world\"\n"},
+		{eimpl.FormatSystemd,
+			"DB_USER=\"app\"\nGREETING=\"hello \\\"world\\\"\"\n"},
+		{eimpl.FormatK8sSecret, "apiVersion: v1\n" +
+			"kind: Secret\n" +
+			"metadata:\n" +
+			"  name: \"senv\"\n" +
+			"type: Opaque\n" +
+			"data:\n" +
+			"  DB_USER: YXBw\n" +
+			"  GREETING: aGVsbG8gIndvcmxkIg==\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.format+" format", func(t *testing.T) {
+			t.Parallel()
+			ta := newTestApp(t)
+			ta.setup(t, []string{"init"},
+				[]string{"set", "DB_USER=app", "GREETING=hello \"world\""})
+			path := ta.path("out")
+
+			code := ta.run(testPassphrase,
+				"export", "--format", test.format, "--out", path)
+			assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+			buf, _ := os.ReadFile(path)
+			assert.Equal(t, test.expOut, string(buf))
+
+			other := newTestApp(t)
+			other.setup(t, []string{"init"},
+				[]string{"import", "--format", test.format, path},
+				[]string{"export", "--format", test.format})
+			assert.Equal(t, test.expOut, other.stdout.String())
+		})
+	}
+	t.Run("Age", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		path := ta.exportAge(t, "app.age")
+
+		buf, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		assert.True(t,
+			strings.HasPrefix(string(buf), "age-encryption.org/v1\n"))
+		assert.NotContains(t, string(buf), "A=\"1\"")
+	})
+	t.Run("Interpolation", func(t *testing.T) {
+		t.Parallel()
+		tests := []struct {
+			name    string
+			args    []string
+			expCode int
+			expOut  string
+			expErr  string
+		}{
+			{"Reference", []string{"URL=db://${USER}@${HOST}"},
+				failure.ExitOK, "db://app@db", ""},
+			{"Default", []string{"URL=${PORT:-5432}"},
+				failure.ExitOK, "5432", ""},
+			{"Escape", []string{"URL=$${USER}"},
+				failure.ExitOK, "${USER}", ""},
+			{"Process env", []string{"--inherit-env", "URL=${SHELL}"},
+				failure.ExitOK, "/bin/sh", ""},
+			{"ErrUndefinedVar error", []string{"URL=${SHELL}"},
+				failure.ExitInvalid, "", "ErrUndefinedVar"},
+			{"ErrInterpolationCycle error", []string{"URL=${URL}"},
+				failure.ExitInvalid, "", "ErrInterpolationCycle"},
+		}
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				t.Parallel()
+				ta := newTestApp(t)
+				ta.vars["SHELL"] = "/bin/sh"
+				value := test.args[len(test.args)-1]
+				ta.setup(t, []string{"init"},
+					[]string{"set", "USER=app", "HOST=db"},
+					[]string{"set", "--interpolate", value})
+				args := append([]string{"export", "--format", "json"},
+					test.args[:len(test.args)-1]...)
+
+				code := ta.run(testPassphrase, args...)
+				assert.Equal(t, test.expCode, code, ta.stderr.String())
+				assert.Contains(t, ta.stderr.String(), test.expErr)
+				if test.expCode == failure.ExitOK {
+					assert.Contains(t, ta.stdout.String(),
+						"\"URL\": \""+test.expOut+"\"")
+				}
+			})
+		}
+	})
+	t.Run("ErrUnknownFormat error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+
+		code := ta.run(testPassphrase, "export", "--format", "toml")
+		assert.Equal(t, failure.ExitInvalid, code)
+		assert.Contains(t, ta.stderr.String(), "ErrUnknownFormat")
+		assert.Empty(t, ta.stdout.String())
+	})
+}
+
+func Test_App_cmdList(t *testing.T) {
+	t.Parallel()
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		os.WriteFile(ta.path("creds.json"), []byte("{}"), 0600)
+		ta.setup(t, []string{"init"}, []string{"set", "B=2", "A=1"},
+			[]string{"set", "--file", "C=" + ta.path("creds.json")})
+		const expOut = "NAME  KIND\n" +
+			"B     var\n" +
+			"A     var\n" +
+			"C     file\n"
+
+		code := ta.run("", "list")
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		assert.Equal(t, expOut, ta.stdout.String())
+	})
+	t.Run("ErrEnvNotFound error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+
+		code := ta.run("", "list", "--env", "prod")
+		assert.Equal(t, failure.ExitNotFound, code)
+		assert.Contains(t, ta.stderr.String(), "ErrEnvNotFound")
+	})
+	t.Run("Resolved", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"}, []string{"set", "HOST=db", "PORT=1"},
+			[]string{"init", "--env", "prod", "--parent", "default"},
+			[]string{"set", "--env", "prod", "PORT=2", "TLS=on"})
+		const expOut = "NAME  KIND  ORIGIN\n" +
+			"HOST  var   default\n" +
+			"PORT  var   prod\n" +
+			"TLS   var   prod\n"
+
+		code := ta.run(testPassphrase, "list", "--env", "prod", "--resolved")
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		assert.Equal(t, expOut, ta.stdout.String())
+	})
+}
+
+func Test_App_cmdCheck(t *testing.T) {
+	t.Parallel()
+	t.Run("Violations", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+		ta.setupInput(t, testSchema, "schema", "-")
+		ta.setup(t, []string{"set", "P=abc"},
+			[]string{"set", "--interpolate", "PORT=${P}"})
+		const expOut = "PORT: ErrInvalidPort: " +
+			"the value is not a port number. (listen port)\n" +
+			"TOKEN: ErrRequiredVar: the required variable is missing.\n"
+
+		code := ta.run(testPassphrase, "check")
+		assert.Equal(t, failure.ExitInvalid, code)
+		assert.Equal(t, expOut, ta.stdout.String())
+		assert.Contains(t, ta.stderr.String(), "var=PORT")
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+		ta.setupInput(t, testSchema, "schema", "-")
+		ta.setup(t, []string{"set", "PORT=8080", "TOKEN=t"})
+
+		code := ta.run(testPassphrase, "check")
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		assert.Empty(t, ta.stdout.String())
+	})
+}
+
+func Test_App_cmdSchema(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrInvalidFile error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+
+		code := ta.runInput("{", testPassphrase, "schema", "-")
+		assert.Equal(t, failure.ExitInvalid, code)
+		assert.Contains(t, ta.stderr.String(), "ErrInvalidFile")
+	})
+	t.Run("ErrInvalidSchema error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+		const schema = `{"Rules": [{"Name": "ID", "Type": "uuid"}]}`
+
+		code := ta.runInput(schema, testPassphrase, "schema", "-")
+		assert.Equal(t, failure.ExitInvalid, code)
+		assert.Contains(t, ta.stderr.String(), "ErrInvalidSchema")
+	})
+	t.Run("Enforced on set", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+		ta.setupInput(t, testSchema, "schema", "-")
+
+		code := ta.run(testPassphrase, "set", "PORT=http")
+		assert.Equal(t, failure.ExitInvalid, code)
+		assert.Contains(t, ta.stderr.String(), "ErrInvalidPort")
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+		path := ta.path("schema.json")
+		os.WriteFile(path, []byte(testSchema), 0600)
+
+		code := ta.run(testPassphrase, "schema", path)
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		ta.setup(t, []string{"schema"})
+		assert.Contains(t, ta.stdout.String(), `"Description": "listen port"`)
+	})
+}
+
+func Test_App_cmdPolicy(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrPassphraseTooShort error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+
+		code := ta.run("hunter2", "init")
+		assert.Equal(t, failure.ExitPolicy, code)
+		assert.Contains(t, ta.stderr.String(), "ErrPassphraseTooShort")
+	})
+	t.Run("ErrInvalidFile error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+
+		code := ta.runInput("[", testPassphrase, "policy", "-")
+		assert.Equal(t, failure.ExitInvalid, code)
+		assert.Contains(t, ta.stderr.String(), "ErrInvalidFile")
+	})
+	t.Run("ErrInvalidPolicy error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+
+		code := ta.runInput(`{"MinLen": -1}`, testPassphrase, "policy", "-")
+		assert.Equal(t, failure.ExitInvalid, code)
+		assert.Contains(t, ta.stderr.String(), "ErrInvalidPolicy")
+		code = ta.runInput(`{"MinScore": 5}`, testPassphrase, "policy", "-")
+		assert.Equal(t, failure.ExitInvalid, code)
+		assert.Contains(t, ta.stderr.String(), "ErrInvalidPolicy")
+	})
+	t.Run("ErrNotOwner error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"},
+			[]string{"init", "--env", "dev", "--parent", DefaultEnv})
+		fd := ta.passphraseFD(t, testPassphrase, testNewPassphrase)
+		code := ta.run("", "role", "add",
+			"--env", "dev", "--passphrase-fd", fd, "devteam")
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+
+		code = ta.runInput(`{"MinLen": 0}`, testNewPassphrase,
+			"policy", "--env", "dev", "--role", "devteam", "-")
+		assert.Equal(t, failure.ExitAuth, code)
+		assert.Contains(t, ta.stderr.String(), "ErrNotOwner")
+		code = ta.runInput(`{"Rules": []}`, testNewPassphrase,
+			"schema", "--env", "dev", "--role", "devteam", "-")
+		assert.Equal(t, failure.ExitAuth, code)
+		assert.Contains(t, ta.stderr.String(), "ErrNotOwner")
+	})
+	t.Run("Reordered slots", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+		ta.addRole(t, "devteam", testNewPassphrase)
+		ta.editVault(t, func(v *vault.Vault) {
+			slots := v.Envs[0].Slots
+			slots[0], slots[1] = slots[1], slots[0]
+		})
+
+		code := ta.runInput(`{"MinLen": 0}`, testNewPassphrase,
+			"policy", "--role", "devteam", "-")
+		assert.Equal(t, failure.ExitAuth, code)
+		assert.Contains(t, ta.stderr.String(), "ErrAuthFailed")
+	})
+	t.Run("Edited policy", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+		ta.editVault(t, func(v *vault.Vault) {
+			v.Policy = &passphrase.Policy{}
+		})
+
+		code := ta.run(testPassphrase, "export")
+		assert.Equal(t, failure.ExitAuth, code)
+		assert.Contains(t, ta.stderr.String(), "ErrAuthFailed")
+	})
+	t.Run("Every root env", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"},
+			[]string{"init", "--env", "staging"})
+		ta.setupInput(t, `{"MinLen": 8}`, "policy", "-")
+
+		code := ta.run(testPassphrase, "export", "--env", "staging")
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+	})
+	t.Run("Enforced on init", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+		ta.setupInput(t, `{"MinLen": 40}`, "policy", "-")
+
+		code := ta.run(testPassphrase, "init", "--env", "prod")
+		assert.Equal(t, failure.ExitPolicy, code)
+		assert.Contains(t, ta.stderr.String(), "ErrPassphraseTooShort")
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+		const expOut = "{\n  \"MinLen\": 8,\n  \"MinScore\": 0,\n" +
+			"  \"RejectReuse\": false\n}\n"
+
+		code := ta.runInput(`{"MinLen": 8}`, testPassphrase, "policy", "-")
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		ta.setup(t, []string{"policy"})
+		assert.Equal(t, expOut, ta.stdout.String())
+		code = ta.run("hunter22", "init", "--env", "prod")
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+	})
+}
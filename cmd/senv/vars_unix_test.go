@@ -0,0 +1,75 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/reshifr/secure-env/core/failure"
+	"github.com/reshifr/secure-env/core/passphrase"
+	"github.com/reshifr/secure-env/core/run"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_App_cmdRun(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrStartFailed error", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+
+		code := ta.run(testPassphrase, "run", ta.path("missing"))
+		assert.Equal(t, run.ExitStartFailed, code)
+		assert.Contains(t, ta.stderr.String(), "ErrStartFailed")
+	})
+	t.Run("Exit code", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+
+		code := ta.run(testPassphrase, "run", "sh", "-c", "exit 3")
+		assert.Equal(t, 3, code)
+		assert.Empty(t, ta.stderr.String())
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		os.WriteFile(ta.path("creds.json"), []byte(`{"k":1}`), 0600)
+		ta.setup(t, []string{"init"}, []string{"set", "A=1"},
+			[]string{"set", "--file", "CREDS=" + ta.path("creds.json")})
+		const expOut = "1 {\"k\":1}\n"
+
+		code := ta.run(testPassphrase, "run", "--",
+			"sh", "-c", `printf '%s %s\n' "$A" "$(cat "$CREDS")"`)
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		assert.Equal(t, expOut, ta.stdout.String())
+	})
+	t.Run("Passphrase env", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"})
+		ta.vars[passphrase.NewPassphraseEnv] = testPassphrase
+		const expOut = "unset unset\n"
+
+		code := ta.run(testPassphrase, "run", "--", "sh", "-c",
+			`printf '%s %s\n' "${SENV_PASSPHRASE-unset}" `+
+				`"${SENV_NEW_PASSPHRASE-unset}"`)
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		assert.Equal(t, expOut, ta.stdout.String())
+	})
+	t.Run("Redact", func(t *testing.T) {
+		t.Parallel()
+		ta := newTestApp(t)
+		ta.setup(t, []string{"init"}, []string{"set", "TOKEN=s3cr3t"})
+		const expOut = "token=***TOKEN*** b64=***TOKEN***\n"
+		const expErr = "***TOKEN***\n"
+
+		code := ta.run(testPassphrase, "run", "--redact", "--", "sh", "-c",
+			`echo "token=$TOKEN b64=$(printf %s "$TOKEN" | base64)"; `+
+				`echo "$TOKEN" >&2`)
+		assert.Equal(t, failure.ExitOK, code, ta.stderr.String())
+		assert.Equal(t, expOut, ta.stdout.String())
+		assert.Equal(t, expErr, ta.stderr.String())
+	})
+}
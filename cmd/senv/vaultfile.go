@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/reshifr/secure-env/core/failure"
+	"github.com/reshifr/secure-env/core/vault"
+)
+
+const (
+	VaultTempPattern = ".senv-*"
+)
+
+func loadVault(path string) (*vault.Vault, error) {
+	buf, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, failure.New(OpReadVault, ErrVaultNotFound, err)
+	}
+	if err != nil {
+		return nil, failure.New(OpReadVault, ErrReadFileFailed, err)
+	}
+	v := &vault.Vault{}
+	if err := json.Unmarshal(buf, v); err != nil {
+		return nil, failure.New(OpReadVault, ErrInvalidVault, err)
+	}
+	return v, nil
+}
+
+func saveVault(path string, v *vault.Vault) error {
+	buf, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return failure.New(OpWriteFile, ErrInvalidVault, err)
+	}
+	return writeFile(path, append(buf, '\n'))
+}
+
+func writeFile(path string, buf []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), VaultTempPattern)
+	if err != nil {
+		return failure.New(OpWriteFile, ErrWriteFileFailed, err)
+	}
+	defer os.Remove(tmp.Name())
+	err = tmp.Chmod(0600)
+	if err == nil {
+		_, err = tmp.Write(buf)
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		return failure.New(OpWriteFile, ErrWriteFileFailed, err)
+	}
+	return nil
+}
+
+func readKey(path string) ([]byte, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, failure.New(OpReadFile, ErrReadFileFailed, err)
+	}
+	defer clear(buf)
+	key := make([]byte, base64.StdEncoding.DecodedLen(len(buf)))
+	n, err := base64.StdEncoding.Decode(key, buf)
+	if err != nil {
+		clear(key)
+		return nil, failure.New(OpReadFile, ErrInvalidFile, err)
+	}
+	return key[:n], nil
+}
+
+func loadKey(path string) (*crypto.Secret, error) {
+	key, err := readKey(path)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(key)
+	return crypto.NewSecretFrom(key)
+}
+
+func writeKey(path string, key []byte) error {
+	buf := make([]byte, base64.StdEncoding.EncodedLen(len(key))+1)
+	defer clear(buf)
+	base64.StdEncoding.Encode(buf, key)
+	buf[len(buf)-1] = '\n'
+	return writeFile(path, buf)
+}
+
+func readInput(stdin io.Reader, path string) ([]byte, error) {
+	var buf []byte
+	var err error
+	if path == "-" {
+		buf, err = io.ReadAll(stdin)
+	} else {
+		buf, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, failure.New(OpReadFile, ErrReadFileFailed, err)
+	}
+	return buf, nil
+}
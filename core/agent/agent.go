@@ -0,0 +1,83 @@
+package agent
+
+import (
+	"time"
+
+	"github.com/reshifr/secure-env/core/failure"
+)
+
+const (
+	AuthSockEnv = "SENV_AUTH_SOCK"
+)
+
+type AgentError int
+
+const (
+	ErrKeyNotFound AgentError = iota + 1
+	ErrInvalidRequest
+	ErrPeerRejected
+	ErrLockMemoryFailed
+	ErrAgentUnavailable
+)
+
+func (err AgentError) Error() string {
+	switch err {
+	case ErrKeyNotFound:
+		return "ErrKeyNotFound: the agent does not hold the key."
+	case ErrInvalidRequest:
+		return "ErrInvalidRequest: the agent cannot process the request."
+	case ErrPeerRejected:
+		return "ErrPeerRejected: the peer is not allowed to use the agent."
+	case ErrLockMemoryFailed:
+		return "ErrLockMemoryFailed: failed to lock the key in memory."
+	case ErrAgentUnavailable:
+		return "ErrAgentUnavailable: the agent cannot be reached."
+	default:
+		return "Error: unknown."
+	}
+}
+
+func (err AgentError) Kind() failure.Kind {
+	switch err {
+	case ErrKeyNotFound:
+		return failure.KindNotFound
+	case ErrInvalidRequest:
+		return failure.KindInvalid
+	case ErrPeerRejected:
+		return failure.KindAuth
+	case ErrAgentUnavailable:
+		return failure.KindIO
+	default:
+		return failure.KindInternal
+	}
+}
+
+type Agent interface {
+	Add(id string, key []byte, idleTTL time.Duration, ttl time.Duration) (
+		err error)
+	Get(id string) (key []byte, err error)
+	Remove(id string) (err error)
+	Lock() (err error)
+}
+
+type Op string
+
+const (
+	OpAdd    Op = "add"
+	OpGet    Op = "get"
+	OpRemove Op = "remove"
+	OpLock   Op = "lock"
+)
+
+type Request struct {
+	Op      Op
+	ID      string        `json:",omitempty"`
+	Key     []byte        `json:",omitempty"`
+	IdleTTL time.Duration `json:",omitempty"`
+	TTL     time.Duration `json:",omitempty"`
+}
+
+type Response struct {
+	Key []byte     `json:",omitempty"`
+	Err AgentError `json:",omitempty"`
+}
@@ -0,0 +1,114 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/reshifr/secure-env/core/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AgentError_Error(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrKeyNotFound value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrKeyNotFound
+		const expMsg = "ErrKeyNotFound: the agent does not hold the key."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrInvalidRequest value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrInvalidRequest
+		const expMsg = "ErrInvalidRequest: " +
+			"the agent cannot process the request."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrPeerRejected value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrPeerRejected
+		const expMsg = "ErrPeerRejected: " +
+			"the peer is not allowed to use the agent."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrLockMemoryFailed value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrLockMemoryFailed
+		const expMsg = "ErrLockMemoryFailed: failed to lock the key in memory."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrAgentUnavailable value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrAgentUnavailable
+		const expMsg = "ErrAgentUnavailable: the agent cannot be reached."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("Unknown value", func(t *testing.T) {
+		t.Parallel()
+		const err = AgentError(284751)
+		const expMsg = "Error: unknown."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+}
+
+func Test_AgentError_Kind(t *testing.T) {
+	t.Parallel()
+	t.Run("KindNotFound value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrKeyNotFound
+		const expKind = failure.KindNotFound
+
+		kind := err.Kind()
+		assert.Equal(t, expKind, kind)
+	})
+	t.Run("KindInvalid value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrInvalidRequest
+		const expKind = failure.KindInvalid
+
+		kind := err.Kind()
+		assert.Equal(t, expKind, kind)
+	})
+	t.Run("KindAuth value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrPeerRejected
+		const expKind = failure.KindAuth
+
+		kind := err.Kind()
+		assert.Equal(t, expKind, kind)
+	})
+	t.Run("KindIO value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrAgentUnavailable
+		const expKind = failure.KindIO
+
+		kind := err.Kind()
+		assert.Equal(t, expKind, kind)
+	})
+	t.Run("KindInternal value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrLockMemoryFailed
+		const expKind = failure.KindInternal
+
+		kind := err.Kind()
+		assert.Equal(t, expKind, kind)
+	})
+	t.Run("Unknown value", func(t *testing.T) {
+		t.Parallel()
+		const err = AgentError(613724)
+		const expKind = failure.KindInternal
+
+		kind := err.Kind()
+		assert.Equal(t, expKind, kind)
+	})
+}
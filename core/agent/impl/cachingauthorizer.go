@@ -0,0 +1,73 @@
+package agent_impl
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/reshifr/secure-env/core/agent"
+	"github.com/reshifr/secure-env/core/crypto"
+)
+
+type CachingAuthorizer[
+	Authorizer crypto.Authorizer,
+	Agent agent.Agent] struct {
+	authorizer Authorizer
+	agent      Agent
+}
+
+func NewCachingAuthorizer[
+	Authorizer crypto.Authorizer,
+	Agent agent.Agent](
+	authorizer Authorizer,
+	a Agent) CachingAuthorizer[Authorizer, Agent] {
+	return CachingAuthorizer[Authorizer, Agent]{
+		authorizer: authorizer,
+		agent:      a,
+	}
+}
+
+func BlockID(block []byte) string {
+	digest := sha256.Sum256(block)
+	return hex.EncodeToString(digest[:])
+}
+
+func (authorizer CachingAuthorizer[Authorizer, Agent]) Kind() string {
+	return authorizer.authorizer.Kind()
+}
+
+func (authorizer CachingAuthorizer[Authorizer, Agent]) KDFParams() crypto.KDFParams {
+	return authorizer.authorizer.KDFParams()
+}
+
+func (authorizer CachingAuthorizer[Authorizer, Agent]) Make(
+	iv crypto.IV,
+	passphrase *crypto.Secret,
+	keyLen uint32) (*crypto.Secret, []byte, error) {
+	return authorizer.authorizer.Make(iv, passphrase, keyLen)
+}
+
+func (authorizer CachingAuthorizer[Authorizer, Agent]) Open(
+	params crypto.KDFParams,
+	passphrase *crypto.Secret,
+	block []byte) (*crypto.Secret, error) {
+	id := BlockID(block)
+	if cachedKey, err := authorizer.agent.Get(id); err == nil {
+		return crypto.NewSecretFrom(cachedKey)
+	}
+	accessKey, err := authorizer.authorizer.Open(params, passphrase, block)
+	if err != nil {
+		return nil, err
+	}
+	authorizer.agent.Add(id, accessKey.Bytes(), 0, 0)
+	return accessKey, nil
+}
+
+func (authorizer CachingAuthorizer[Authorizer, Agent]) Inherit(
+	iv crypto.IV,
+	params crypto.KDFParams,
+	passphrase *crypto.Secret,
+	childPassphrase *crypto.Secret,
+	block []byte) (*crypto.Secret, []byte, error) {
+	return authorizer.authorizer.Inherit(
+		iv, params, passphrase, childPassphrase, block)
+}
@@ -0,0 +1,151 @@
+package agent_impl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/reshifr/secure-env/core/agent"
+	amock "github.com/reshifr/secure-env/core/agent/mock"
+	"github.com/reshifr/secure-env/core/crypto"
+	cmock "github.com/reshifr/secure-env/core/crypto/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewCachingAuthorizer(t *testing.T) {
+	t.Parallel()
+	inner := cmock.NewAuthorizer(t)
+	a := amock.NewAgent(t)
+	expAuthorizer := CachingAuthorizer[*cmock.Authorizer, *amock.Agent]{
+		authorizer: inner,
+		agent:      a,
+	}
+
+	authorizer := NewCachingAuthorizer(inner, a)
+	assert.Equal(t, expAuthorizer, authorizer)
+}
+
+func Test_BlockID(t *testing.T) {
+	t.Parallel()
+	const expID = "6e340b9cffb37a989ca544e6bb780a2c" +
+		"78901d3fb33738768511a30617afa01d"
+
+	id := BlockID([]byte{0x00})
+	assert.Equal(t, expID, id)
+}
+
+func Test_CachingAuthorizer_Kind(t *testing.T) {
+	t.Parallel()
+	inner := cmock.NewAuthorizer(t)
+	a := amock.NewAgent(t)
+	const expKind = "passphrase"
+	inner.EXPECT().Kind().Return(expKind).Once()
+
+	authorizer := NewCachingAuthorizer(inner, a)
+	kind := authorizer.Kind()
+	assert.Equal(t, expKind, kind)
+}
+
+func Test_CachingAuthorizer_KDFParams(t *testing.T) {
+	t.Parallel()
+	inner := cmock.NewAuthorizer(t)
+	a := amock.NewAgent(t)
+	expParams := crypto.KDFParams{Algorithm: "argon2i", Time: 3}
+	inner.EXPECT().KDFParams().Return(expParams).Once()
+
+	authorizer := NewCachingAuthorizer(inner, a)
+	params := authorizer.KDFParams()
+	assert.Equal(t, expParams, params)
+}
+
+func Test_CachingAuthorizer_Make(t *testing.T) {
+	t.Parallel()
+	iv := cmock.NewIV(t)
+	inner := cmock.NewAuthorizer(t)
+	a := amock.NewAgent(t)
+	passphrase, _ := crypto.NewSecretFrom([]byte("+DF7Rc-X/MOYjkNj"))
+	expAccessKey, _ := crypto.NewSecretFrom([]byte{0x11})
+	expBlock := []byte{0x21}
+	inner.EXPECT().Make(iv, passphrase, uint32(32)).
+		Return(expAccessKey, expBlock, nil).Once()
+
+	authorizer := NewCachingAuthorizer(inner, a)
+	accessKey, block, err := authorizer.Make(iv, passphrase, 32)
+	assert.Equal(t, expAccessKey, accessKey)
+	assert.Equal(t, expBlock, block)
+	assert.ErrorIs(t, err, nil)
+}
+
+func Test_CachingAuthorizer_Open(t *testing.T) {
+	t.Parallel()
+	params := crypto.KDFParams{Algorithm: "argon2i", Time: 3}
+	passphrase, _ := crypto.NewSecretFrom([]byte("+DF7Rc-X/MOYjkNj"))
+	block := []byte{0x00}
+	id := BlockID(block)
+
+	t.Run("ErrAuthFailed error", func(t *testing.T) {
+		t.Parallel()
+		inner := cmock.NewAuthorizer(t)
+		a := amock.NewAgent(t)
+		a.EXPECT().Get(id).Return(nil, agent.ErrKeyNotFound).Once()
+		inner.EXPECT().Open(params, passphrase, block).
+			Return(nil, crypto.ErrAuthFailed).Once()
+
+		var expAccessKey *crypto.Secret = nil
+		const expErr = crypto.ErrAuthFailed
+
+		authorizer := NewCachingAuthorizer(inner, a)
+		accessKey, err := authorizer.Open(params, passphrase, block)
+		assert.Equal(t, expAccessKey, accessKey)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Cache hit", func(t *testing.T) {
+		t.Parallel()
+		inner := cmock.NewAuthorizer(t)
+		a := amock.NewAgent(t)
+		expAccessKey := []byte{0x11}
+		a.EXPECT().Get(id).Return([]byte{0x11}, nil).Once()
+
+		authorizer := NewCachingAuthorizer(inner, a)
+		accessKey, err := authorizer.Open(params, nil, block)
+		assert.Equal(t, expAccessKey, accessKey.Bytes())
+		assert.ErrorIs(t, err, nil)
+	})
+	t.Run("Cache miss", func(t *testing.T) {
+		t.Parallel()
+		inner := cmock.NewAuthorizer(t)
+		a := amock.NewAgent(t)
+		expAccessKey, _ := crypto.NewSecretFrom([]byte{0x11})
+		a.EXPECT().Get(id).Return(nil, agent.ErrAgentUnavailable).Once()
+		inner.EXPECT().Open(params, passphrase, block).
+			Return(expAccessKey, nil).Once()
+		a.EXPECT().Add(id, []byte{0x11}, time.Duration(0), time.Duration(0)).
+			Return(agent.ErrAgentUnavailable).Once()
+
+		authorizer := NewCachingAuthorizer(inner, a)
+		accessKey, err := authorizer.Open(params, passphrase, block)
+		assert.Equal(t, expAccessKey, accessKey)
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_CachingAuthorizer_Inherit(t *testing.T) {
+	t.Parallel()
+	iv := cmock.NewIV(t)
+	inner := cmock.NewAuthorizer(t)
+	a := amock.NewAgent(t)
+	params := crypto.KDFParams{Algorithm: "argon2i", Time: 3}
+	passphrase, _ := crypto.NewSecretFrom([]byte("+DF7Rc-X/MOYjkNj"))
+	childPassphrase, _ := crypto.NewSecretFrom([]byte("q7!Lw2#zR9@pXe4v"))
+	block := []byte{0x21}
+	expAccessKey, _ := crypto.NewSecretFrom([]byte{0x11})
+	expChildBlock := []byte{0x22}
+	inner.EXPECT().Inherit(iv, params, passphrase, childPassphrase, block).
+		Return(expAccessKey, expChildBlock, nil).Once()
+
+	authorizer := NewCachingAuthorizer(inner, a)
+	accessKey, childBlock, err := authorizer.Inherit(
+		iv, params, passphrase, childPassphrase, block)
+	assert.Equal(t, expAccessKey, accessKey)
+	assert.Equal(t, expChildBlock, childBlock)
+	assert.ErrorIs(t, err, nil)
+}
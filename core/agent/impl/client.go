@@ -0,0 +1,73 @@
+package agent_impl
+
+import (
+	"encoding/json"
+	"net"
+	"os"
+	"time"
+
+	"github.com/reshifr/secure-env/core/agent"
+)
+
+type Client struct {
+	path string
+	uid  int
+}
+
+func NewClient(path string) Client {
+	return Client{path: path, uid: os.Getuid()}
+}
+
+func (client Client) roundTrip(req agent.Request) (agent.Response, error) {
+	conn, err := net.DialUnix("unix", nil, &net.UnixAddr{Name: client.path})
+	if err != nil {
+		return agent.Response{}, agent.ErrAgentUnavailable
+	}
+	defer conn.Close()
+	// Whoever owns the socket receives every key sent with Add, so refuse
+	// to talk to a listener that is not running as the current user.
+	if uid, err := peerUID(conn); err != nil || uid != client.uid {
+		return agent.Response{}, agent.ErrPeerRejected
+	}
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return agent.Response{}, agent.ErrAgentUnavailable
+	}
+	res := agent.Response{}
+	if err := json.NewDecoder(conn).Decode(&res); err != nil {
+		return agent.Response{}, agent.ErrAgentUnavailable
+	}
+	if res.Err != 0 {
+		return agent.Response{}, res.Err
+	}
+	return res, nil
+}
+
+func (client Client) Add(id string,
+	key []byte, idleTTL time.Duration, ttl time.Duration) error {
+	_, err := client.roundTrip(agent.Request{
+		Op:      agent.OpAdd,
+		ID:      id,
+		Key:     key,
+		IdleTTL: idleTTL,
+		TTL:     ttl,
+	})
+	return err
+}
+
+func (client Client) Get(id string) ([]byte, error) {
+	res, err := client.roundTrip(agent.Request{Op: agent.OpGet, ID: id})
+	if err != nil {
+		return nil, err
+	}
+	return res.Key, nil
+}
+
+func (client Client) Remove(id string) error {
+	_, err := client.roundTrip(agent.Request{Op: agent.OpRemove, ID: id})
+	return err
+}
+
+func (client Client) Lock() error {
+	_, err := client.roundTrip(agent.Request{Op: agent.OpLock})
+	return err
+}
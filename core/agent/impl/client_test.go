@@ -0,0 +1,51 @@
+package agent_impl
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/reshifr/secure-env/core/agent"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewClient(t *testing.T) {
+	t.Parallel()
+	const path = "/tmp/senv.sock"
+	expClient := Client{path: path, uid: os.Getuid()}
+
+	client := NewClient(path)
+	assert.Equal(t, expClient, client)
+}
+
+func Test_Client_Get(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrAgentUnavailable error", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "missing.sock")
+		var expKey []byte = nil
+		const expErr = agent.ErrAgentUnavailable
+
+		client := NewClient(path)
+		key, err := client.Get("id")
+		assert.Equal(t, expKey, key)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrPeerRejected error", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "agent.sock")
+		listener, err := net.ListenUnix("unix", &net.UnixAddr{Name: path})
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		t.Cleanup(func() { listener.Close() })
+		var expKey []byte = nil
+		const expErr = agent.ErrPeerRejected
+
+		client := Client{path: path, uid: os.Getuid() + 1}
+		key, err := client.Get("id")
+		assert.Equal(t, expKey, key)
+		assert.ErrorIs(t, err, expErr)
+	})
+}
@@ -0,0 +1,133 @@
+package agent_impl
+
+import (
+	"sync"
+	"time"
+
+	"github.com/reshifr/secure-env/core/agent"
+	"github.com/reshifr/secure-env/core/crypto"
+)
+
+const (
+	KeystoreSweepInterval = time.Second
+)
+
+type Keystore struct {
+	mu      sync.Mutex
+	fn      FnKeystore
+	entries map[string]*keystoreEntry
+}
+
+type FnKeystore struct {
+	Now func() time.Time
+}
+
+type keystoreEntry struct {
+	key      *crypto.Secret
+	idleTTL  time.Duration
+	lastUsed time.Time
+	expires  time.Time
+}
+
+func NewKeystore(fn FnKeystore) *Keystore {
+	return &Keystore{fn: fn, entries: map[string]*keystoreEntry{}}
+}
+
+func (entry *keystoreEntry) expired(now time.Time) bool {
+	if !entry.expires.IsZero() && !now.Before(entry.expires) {
+		return true
+	}
+	return entry.idleTTL > 0 && now.Sub(entry.lastUsed) >= entry.idleTTL
+}
+
+func (entry *keystoreEntry) wipe() {
+	entry.key.Destroy()
+}
+
+func (store *Keystore) Add(id string,
+	key []byte, idleTTL time.Duration, ttl time.Duration) error {
+	locked, err := crypto.NewSecret(len(key))
+	if err != nil {
+		return agent.ErrLockMemoryFailed
+	}
+	copy(locked.Bytes(), key)
+	now := store.fn.Now()
+	entry := &keystoreEntry{key: locked, idleTTL: idleTTL, lastUsed: now}
+	if ttl > 0 {
+		entry.expires = now.Add(ttl)
+	}
+	store.mu.Lock()
+	if old, ok := store.entries[id]; ok {
+		old.wipe()
+	}
+	store.entries[id] = entry
+	store.mu.Unlock()
+	return nil
+}
+
+func (store *Keystore) Get(id string) ([]byte, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	entry, ok := store.entries[id]
+	if !ok {
+		return nil, agent.ErrKeyNotFound
+	}
+	now := store.fn.Now()
+	if entry.expired(now) {
+		entry.wipe()
+		delete(store.entries, id)
+		return nil, agent.ErrKeyNotFound
+	}
+	entry.lastUsed = now
+	key := make([]byte, entry.key.Len())
+	copy(key, entry.key.Bytes())
+	return key, nil
+}
+
+func (store *Keystore) Remove(id string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	entry, ok := store.entries[id]
+	if !ok {
+		return agent.ErrKeyNotFound
+	}
+	entry.wipe()
+	delete(store.entries, id)
+	return nil
+}
+
+func (store *Keystore) Lock() error {
+	store.mu.Lock()
+	for id, entry := range store.entries {
+		entry.wipe()
+		delete(store.entries, id)
+	}
+	store.mu.Unlock()
+	return nil
+}
+
+func (store *Keystore) Sweep() {
+	store.mu.Lock()
+	now := store.fn.Now()
+	for id, entry := range store.entries {
+		if entry.expired(now) {
+			entry.wipe()
+			delete(store.entries, id)
+		}
+	}
+	store.mu.Unlock()
+}
+
+func (store *Keystore) Run(done <-chan struct{}) {
+	ticker := time.NewTicker(KeystoreSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			store.Lock()
+			return
+		case <-ticker.C:
+			store.Sweep()
+		}
+	}
+}
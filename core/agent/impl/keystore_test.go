@@ -0,0 +1,158 @@
+package agent_impl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/reshifr/secure-env/core/agent"
+	"github.com/stretchr/testify/assert"
+)
+
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func Test_NewKeystore(t *testing.T) {
+	t.Parallel()
+	store := NewKeystore(FnKeystore{})
+	assert.NotNil(t, store.entries)
+	assert.Empty(t, store.entries)
+}
+
+func Test_Keystore_Get(t *testing.T) {
+	t.Parallel()
+	key := []byte{0x11, 0x22, 0x33}
+
+	t.Run("ErrKeyNotFound error", func(t *testing.T) {
+		t.Parallel()
+		store := NewKeystore(FnKeystore{Now: time.Now})
+		var expKey []byte = nil
+		const expErr = agent.ErrKeyNotFound
+
+		k, err := store.Get("id")
+		assert.Equal(t, expKey, k)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Idle expiry", func(t *testing.T) {
+		t.Parallel()
+		c := &clock{now: time.Unix(1000, 0)}
+		store := NewKeystore(FnKeystore{Now: c.Now})
+		store.Add("id", key, time.Minute, time.Hour)
+
+		c.now = c.now.Add(50 * time.Second)
+		k, err := store.Get("id")
+		assert.Equal(t, key, k)
+		assert.ErrorIs(t, err, nil)
+
+		c.now = c.now.Add(50 * time.Second)
+		k, err = store.Get("id")
+		assert.Equal(t, key, k)
+		assert.ErrorIs(t, err, nil)
+
+		c.now = c.now.Add(time.Minute)
+		k, err = store.Get("id")
+		assert.Equal(t, []byte(nil), k)
+		assert.ErrorIs(t, err, agent.ErrKeyNotFound)
+		assert.Empty(t, store.entries)
+	})
+	t.Run("Absolute expiry", func(t *testing.T) {
+		t.Parallel()
+		c := &clock{now: time.Unix(1000, 0)}
+		store := NewKeystore(FnKeystore{Now: c.Now})
+		store.Add("id", key, time.Minute, 90*time.Second)
+
+		c.now = c.now.Add(50 * time.Second)
+		store.Get("id")
+		c.now = c.now.Add(50 * time.Second)
+		k, err := store.Get("id")
+		assert.Equal(t, []byte(nil), k)
+		assert.ErrorIs(t, err, agent.ErrKeyNotFound)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		store := NewKeystore(FnKeystore{Now: time.Now})
+		store.Add("id", key, 0, 0)
+
+		k, err := store.Get("id")
+		assert.Equal(t, key, k)
+		assert.ErrorIs(t, err, nil)
+		k[0] = 0xff
+		assert.Equal(t, key, store.entries["id"].key.Bytes())
+	})
+}
+
+func Test_Keystore_Add(t *testing.T) {
+	t.Parallel()
+	store := NewKeystore(FnKeystore{Now: time.Now})
+	key := []byte{0x11, 0x22}
+	store.Add("id", []byte{0x99}, 0, 0)
+	old := store.entries["id"].key
+
+	err := store.Add("id", key, 0, 0)
+	assert.ErrorIs(t, err, nil)
+	assert.Equal(t, key, store.entries["id"].key.Bytes())
+	assert.Zero(t, old.Len())
+	key[0] = 0xff
+	assert.Equal(t, []byte{0x11, 0x22}, store.entries["id"].key.Bytes())
+}
+
+func Test_Keystore_Remove(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrKeyNotFound error", func(t *testing.T) {
+		t.Parallel()
+		store := NewKeystore(FnKeystore{Now: time.Now})
+		const expErr = agent.ErrKeyNotFound
+
+		err := store.Remove("id")
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		store := NewKeystore(FnKeystore{Now: time.Now})
+		store.Add("id", []byte{0x11}, 0, 0)
+		locked := store.entries["id"].key
+
+		err := store.Remove("id")
+		assert.ErrorIs(t, err, nil)
+		assert.Empty(t, store.entries)
+		assert.Zero(t, locked.Len())
+	})
+}
+
+func Test_Keystore_Lock(t *testing.T) {
+	t.Parallel()
+	store := NewKeystore(FnKeystore{Now: time.Now})
+	store.Add("a", []byte{0x11}, 0, 0)
+	store.Add("b", []byte{0x22}, 0, 0)
+	lockedA := store.entries["a"].key
+	lockedB := store.entries["b"].key
+
+	err := store.Lock()
+	assert.ErrorIs(t, err, nil)
+	assert.Empty(t, store.entries)
+	assert.Zero(t, lockedA.Len())
+	assert.Zero(t, lockedB.Len())
+}
+
+func Test_Keystore_Sweep(t *testing.T) {
+	t.Parallel()
+	c := &clock{now: time.Unix(1000, 0)}
+	store := NewKeystore(FnKeystore{Now: c.Now})
+	store.Add("idle", []byte{0x11}, time.Minute, 0)
+	store.Add("absolute", []byte{0x22}, 0, time.Hour)
+	locked := store.entries["idle"].key
+
+	c.now = c.now.Add(2 * time.Minute)
+	store.Sweep()
+	assert.Zero(t, locked.Len())
+	assert.NotContains(t, store.entries, "idle")
+	assert.Contains(t, store.entries, "absolute")
+
+	c.now = c.now.Add(time.Hour)
+	store.Sweep()
+	assert.Empty(t, store.entries)
+}
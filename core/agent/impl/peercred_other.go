@@ -0,0 +1,13 @@
+//go:build !linux && !darwin
+
+package agent_impl
+
+import (
+	"net"
+
+	"github.com/reshifr/secure-env/core/agent"
+)
+
+func peerUID(*net.UnixConn) (int, error) {
+	return -1, agent.ErrPeerRejected
+}
@@ -0,0 +1,114 @@
+package agent_impl
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"time"
+
+	"github.com/reshifr/secure-env/core/agent"
+)
+
+const (
+	ServerIdleTTL = 15 * time.Minute
+	ServerTTL     = 8 * time.Hour
+)
+
+type Server[Agent agent.Agent] struct {
+	agent   Agent
+	uid     int
+	idleTTL time.Duration
+	ttl     time.Duration
+}
+
+func NewServer[Agent agent.Agent](a Agent,
+	idleTTL time.Duration, ttl time.Duration) Server[Agent] {
+	return Server[Agent]{
+		agent:   a,
+		uid:     os.Getuid(),
+		idleTTL: idleTTL,
+		ttl:     ttl,
+	}
+}
+
+func limitTTL(ttl time.Duration, max time.Duration) time.Duration {
+	if max > 0 && (ttl <= 0 || ttl > max) {
+		return max
+	}
+	return ttl
+}
+
+func Listen(path string) (*net.UnixListener, error) {
+	mask := umask(0177)
+	listener, err := net.ListenUnix("unix", &net.UnixAddr{Name: path})
+	umask(mask)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
+
+func (server Server[Agent]) Serve(listener *net.UnixListener) error {
+	for {
+		conn, err := listener.AcceptUnix()
+		if errors.Is(err, net.ErrClosed) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		go server.handle(conn)
+	}
+}
+
+func (server Server[Agent]) handle(conn *net.UnixConn) {
+	defer conn.Close()
+	enc := json.NewEncoder(conn)
+	if uid, err := peerUID(conn); err != nil || uid != server.uid {
+		enc.Encode(agent.Response{Err: agent.ErrPeerRejected})
+		return
+	}
+	dec := json.NewDecoder(conn)
+	for {
+		req := agent.Request{}
+		if err := dec.Decode(&req); err != nil {
+			return
+		}
+		if err := enc.Encode(server.dispatch(req)); err != nil {
+			return
+		}
+	}
+}
+
+func (server Server[Agent]) dispatch(req agent.Request) agent.Response {
+	var err error
+	res := agent.Response{}
+	switch req.Op {
+	case agent.OpAdd:
+		err = server.agent.Add(req.ID, req.Key,
+			limitTTL(req.IdleTTL, server.idleTTL),
+			limitTTL(req.TTL, server.ttl))
+		clear(req.Key)
+	case agent.OpGet:
+		res.Key, err = server.agent.Get(req.ID)
+	case agent.OpRemove:
+		err = server.agent.Remove(req.ID)
+	case agent.OpLock:
+		err = server.agent.Lock()
+	default:
+		err = agent.ErrInvalidRequest
+	}
+	if err != nil {
+		var agentErr agent.AgentError
+		if !errors.As(err, &agentErr) {
+			agentErr = agent.ErrInvalidRequest
+		}
+		res.Err = agentErr
+	}
+	return res
+}
@@ -0,0 +1,150 @@
+package agent_impl
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/reshifr/secure-env/core/agent"
+	amock "github.com/reshifr/secure-env/core/agent/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewServer(t *testing.T) {
+	t.Parallel()
+	a := amock.NewAgent(t)
+	expServer := Server[*amock.Agent]{
+		agent:   a,
+		uid:     os.Getuid(),
+		idleTTL: time.Minute,
+		ttl:     time.Hour,
+	}
+
+	server := NewServer(a, time.Minute, time.Hour)
+	assert.Equal(t, expServer, server)
+}
+
+func Test_Server_dispatch(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrInvalidRequest error", func(t *testing.T) {
+		t.Parallel()
+		a := amock.NewAgent(t)
+		req := agent.Request{Op: "sign"}
+		expRes := agent.Response{Err: agent.ErrInvalidRequest}
+
+		server := NewServer(a, time.Minute, time.Hour)
+		res := server.dispatch(req)
+		assert.Equal(t, expRes, res)
+	})
+	t.Run("Unknown error", func(t *testing.T) {
+		t.Parallel()
+		a := amock.NewAgent(t)
+		a.EXPECT().Lock().Return(errors.New("")).Once()
+
+		req := agent.Request{Op: agent.OpLock}
+		expRes := agent.Response{Err: agent.ErrInvalidRequest}
+
+		server := NewServer(a, time.Minute, time.Hour)
+		res := server.dispatch(req)
+		assert.Equal(t, expRes, res)
+	})
+	t.Run("Add request", func(t *testing.T) {
+		t.Parallel()
+		a := amock.NewAgent(t)
+		key := []byte{0x11}
+		a.EXPECT().Add("id", key, time.Minute, time.Hour).
+			Return(nil).Once()
+
+		req := agent.Request{
+			Op:      agent.OpAdd,
+			ID:      "id",
+			Key:     key,
+			IdleTTL: time.Minute,
+			TTL:     time.Hour,
+		}
+		expRes := agent.Response{}
+
+		server := NewServer(a, time.Minute, time.Hour)
+		res := server.dispatch(req)
+		assert.Equal(t, expRes, res)
+		assert.Equal(t, []byte{0x00}, key)
+	})
+	t.Run("Add request with server TTLs", func(t *testing.T) {
+		t.Parallel()
+		a := amock.NewAgent(t)
+		key := []byte{0x11}
+		a.EXPECT().Add("id", key, time.Minute, time.Hour).
+			Return(nil).Once()
+
+		req := agent.Request{
+			Op:  agent.OpAdd,
+			ID:  "id",
+			Key: key,
+			TTL: 2 * time.Hour,
+		}
+		expRes := agent.Response{}
+
+		server := NewServer(a, time.Minute, time.Hour)
+		res := server.dispatch(req)
+		assert.Equal(t, expRes, res)
+	})
+	t.Run("Add request without server TTLs", func(t *testing.T) {
+		t.Parallel()
+		a := amock.NewAgent(t)
+		key := []byte{0x11}
+		a.EXPECT().Add("id", key, time.Duration(0), 2*time.Hour).
+			Return(nil).Once()
+
+		req := agent.Request{
+			Op:  agent.OpAdd,
+			ID:  "id",
+			Key: key,
+			TTL: 2 * time.Hour,
+		}
+		expRes := agent.Response{}
+
+		server := NewServer(a, 0, 0)
+		res := server.dispatch(req)
+		assert.Equal(t, expRes, res)
+	})
+	t.Run("Get request", func(t *testing.T) {
+		t.Parallel()
+		a := amock.NewAgent(t)
+		a.EXPECT().Get("id").Return(nil, agent.ErrKeyNotFound).Once()
+		a.EXPECT().Get("id").Return([]byte{0x11}, nil).Once()
+
+		req := agent.Request{Op: agent.OpGet, ID: "id"}
+		expRes := agent.Response{Key: []byte{0x11}}
+
+		server := NewServer(a, time.Minute, time.Hour)
+		res := server.dispatch(req)
+		assert.Equal(t, agent.Response{Err: agent.ErrKeyNotFound}, res)
+		res = server.dispatch(req)
+		assert.Equal(t, expRes, res)
+	})
+	t.Run("Remove request", func(t *testing.T) {
+		t.Parallel()
+		a := amock.NewAgent(t)
+		a.EXPECT().Remove("id").Return(nil).Once()
+
+		req := agent.Request{Op: agent.OpRemove, ID: "id"}
+		expRes := agent.Response{}
+
+		server := NewServer(a, time.Minute, time.Hour)
+		res := server.dispatch(req)
+		assert.Equal(t, expRes, res)
+	})
+	t.Run("Lock request", func(t *testing.T) {
+		t.Parallel()
+		a := amock.NewAgent(t)
+		a.EXPECT().Lock().Return(nil).Once()
+
+		req := agent.Request{Op: agent.OpLock}
+		expRes := agent.Response{}
+
+		server := NewServer(a, time.Minute, time.Hour)
+		res := server.dispatch(req)
+		assert.Equal(t, expRes, res)
+	})
+}
@@ -0,0 +1,7 @@
+//go:build !unix
+
+package agent_impl
+
+func umask(int) int {
+	return 0
+}
@@ -0,0 +1,11 @@
+//go:build unix
+
+package agent_impl
+
+import (
+	"syscall"
+)
+
+func umask(mask int) int {
+	return syscall.Umask(mask)
+}
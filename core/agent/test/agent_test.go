@@ -0,0 +1,47 @@
+package agent_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/reshifr/secure-env/core/agent"
+	aimpl "github.com/reshifr/secure-env/core/agent/impl"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Agent_Client(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "agent.sock")
+	listener, err := aimpl.Listen(path)
+	assert.ErrorIs(t, err, nil)
+	t.Cleanup(func() { listener.Close() })
+
+	store := aimpl.NewKeystore(aimpl.FnKeystore{Now: time.Now})
+	server := aimpl.NewServer(store, time.Minute, time.Hour)
+	go server.Serve(listener)
+	client := aimpl.NewClient(path)
+
+	info, err := os.Stat(path)
+	assert.ErrorIs(t, err, nil)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	key := []byte{0x11, 0x22, 0x33}
+	err = client.Add("id", key, time.Minute, time.Hour)
+	assert.ErrorIs(t, err, nil)
+
+	k, err := client.Get("id")
+	assert.Equal(t, key, k)
+	assert.ErrorIs(t, err, nil)
+
+	err = client.Remove("other")
+	assert.ErrorIs(t, err, agent.ErrKeyNotFound)
+
+	err = client.Lock()
+	assert.ErrorIs(t, err, nil)
+
+	k, err = client.Get("id")
+	assert.Equal(t, []byte(nil), k)
+	assert.ErrorIs(t, err, agent.ErrKeyNotFound)
+}
@@ -0,0 +1,127 @@
+package audit
+
+import (
+	"time"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/reshifr/secure-env/core/failure"
+)
+
+const (
+	LogPathEnv   = "SENV_AUDIT_LOG"
+	LogSuffix    = ".audit"
+	KeySalt      = "audit"
+	HeadLabel    = "audit-head"
+	RetiredLabel = "audit-retired"
+	KeyLen       = 32
+	ResultOK     = "ok"
+	ResultDenied = "denied"
+)
+
+const (
+	OpAppend = "append"
+	OpHead   = "head"
+	OpVerify = "verify"
+)
+
+type AuditError int
+
+const (
+	ErrInvalidRecord AuditError = iota + 1
+	ErrChainBroken
+	ErrMACMismatch
+	ErrUnverifiedRecord
+	ErrOpenLogFailed
+	ErrWriteLogFailed
+	ErrLogTruncated
+	ErrRetiredKey
+)
+
+func (err AuditError) Error() string {
+	switch err {
+	case ErrInvalidRecord:
+		return "ErrInvalidRecord: the audit record is malformed."
+	case ErrChainBroken:
+		return "ErrChainBroken: " +
+			"an audit record was deleted, reordered or edited."
+	case ErrMACMismatch:
+		return "ErrMACMismatch: an audit record failed authentication."
+	case ErrUnverifiedRecord:
+		return "ErrUnverifiedRecord: " +
+			"the vault holds no audit key for the record's epoch."
+	case ErrOpenLogFailed:
+		return "ErrOpenLogFailed: failed to open the audit log."
+	case ErrWriteLogFailed:
+		return "ErrWriteLogFailed: failed to write the audit log."
+	case ErrLogTruncated:
+		return "ErrLogTruncated: " +
+			"the audit log ends before the head sealed in the vault."
+	case ErrRetiredKey:
+		return "ErrRetiredKey: " +
+			"an audit record uses a key retired by an earlier rotation."
+	default:
+		return "Error: unknown."
+	}
+}
+
+func (err AuditError) Kind() failure.Kind {
+	switch err {
+	case ErrOpenLogFailed, ErrWriteLogFailed:
+		return failure.KindIO
+	case ErrInvalidRecord, ErrChainBroken, ErrMACMismatch,
+		ErrUnverifiedRecord, ErrLogTruncated, ErrRetiredKey:
+		return failure.KindIntegrity
+	default:
+		return failure.KindInternal
+	}
+}
+
+type Record struct {
+	Seq     uint64
+	Time    time.Time
+	Role    string
+	Command string
+	Env     string
+	Vars    []string `json:",omitempty"`
+	Result  string
+	Epoch   uint64 `json:",omitempty"`
+	Prev    []byte `json:",omitempty"`
+	MAC     []byte `json:",omitempty"`
+}
+
+type Head struct {
+	Records uint64
+	Hash    []byte `json:",omitempty"`
+}
+
+type Summary struct {
+	Records  int
+	Verified int
+	Skipped  int
+}
+
+// Keys holds the audit keys of each environment, indexed by the Epoch of
+// the records they authenticate.
+type Keys map[string][]*crypto.Secret
+
+func (keys Keys) Destroy() {
+	for name, epochs := range keys {
+		for _, key := range epochs {
+			key.Destroy()
+		}
+		delete(keys, name)
+	}
+}
+
+type Log interface {
+	Append(key *crypto.Secret, record Record) (err error)
+	Head() (head Head, err error)
+	Verify(keys Keys, heads []Head) (summary Summary, err error)
+}
+
+func LogPath(vaultPath string, override string) string {
+	if override != "" {
+		return override
+	}
+	return vaultPath + LogSuffix
+}
@@ -0,0 +1,148 @@
+package audit
+
+import (
+	"testing"
+
+	"github.com/reshifr/secure-env/core/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AuditError_Error(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrInvalidRecord value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrInvalidRecord
+		const expMsg = "ErrInvalidRecord: the audit record is malformed."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrChainBroken value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrChainBroken
+		const expMsg = "ErrChainBroken: " +
+			"an audit record was deleted, reordered or edited."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrMACMismatch value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrMACMismatch
+		const expMsg = "ErrMACMismatch: an audit record failed authentication."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrUnverifiedRecord value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrUnverifiedRecord
+		const expMsg = "ErrUnverifiedRecord: " +
+			"the vault holds no audit key for the record's epoch."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrOpenLogFailed value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrOpenLogFailed
+		const expMsg = "ErrOpenLogFailed: failed to open the audit log."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrWriteLogFailed value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrWriteLogFailed
+		const expMsg = "ErrWriteLogFailed: failed to write the audit log."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrLogTruncated value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrLogTruncated
+		const expMsg = "ErrLogTruncated: " +
+			"the audit log ends before the head sealed in the vault."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrRetiredKey value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrRetiredKey
+		const expMsg = "ErrRetiredKey: " +
+			"an audit record uses a key retired by an earlier rotation."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("Unknown value", func(t *testing.T) {
+		t.Parallel()
+		const err = AuditError(613724)
+		const expMsg = "Error: unknown."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+}
+
+func Test_LogPath(t *testing.T) {
+	t.Parallel()
+	t.Run("Default path", func(t *testing.T) {
+		t.Parallel()
+		const expPath = "/srv/app/secrets.senv.audit"
+
+		path := LogPath("/srv/app/secrets.senv", "")
+		assert.Equal(t, expPath, path)
+	})
+	t.Run("Override path", func(t *testing.T) {
+		t.Parallel()
+		const expPath = "/var/log/senv.audit"
+
+		path := LogPath("/srv/app/secrets.senv", "/var/log/senv.audit")
+		assert.Equal(t, expPath, path)
+	})
+}
+
+func Test_AuditError_Kind(t *testing.T) {
+	t.Parallel()
+	t.Run("KindIO value", func(t *testing.T) {
+		t.Parallel()
+		errs := []AuditError{
+			ErrOpenLogFailed,
+			ErrWriteLogFailed,
+		}
+		const expKind = failure.KindIO
+
+		for _, err := range errs {
+			kind := err.Kind()
+			assert.Equal(t, expKind, kind, err.Error())
+		}
+	})
+	t.Run("KindIntegrity value", func(t *testing.T) {
+		t.Parallel()
+		errs := []AuditError{
+			ErrInvalidRecord,
+			ErrChainBroken,
+			ErrMACMismatch,
+			ErrUnverifiedRecord,
+			ErrLogTruncated,
+			ErrRetiredKey,
+		}
+		const expKind = failure.KindIntegrity
+
+		for _, err := range errs {
+			kind := err.Kind()
+			assert.Equal(t, expKind, kind, err.Error())
+		}
+	})
+	t.Run("Unknown value", func(t *testing.T) {
+		t.Parallel()
+		const err = AuditError(613724)
+		const expKind = failure.KindInternal
+
+		kind := err.Kind()
+		assert.Equal(t, expKind, kind)
+	})
+}
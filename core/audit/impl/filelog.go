@@ -0,0 +1,187 @@
+package audit_impl
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+
+	"github.com/reshifr/secure-env/core/audit"
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/reshifr/secure-env/core/failure"
+)
+
+const (
+	FileLogMaxRecordLen = 1 << 20
+)
+
+type FileLog struct {
+	path string
+}
+
+func NewFileLog(path string) FileLog {
+	return FileLog{path: path}
+}
+
+func digest(record audit.Record) []byte {
+	buf, _ := json.Marshal(record)
+	sum := sha256.Sum256(buf)
+	return sum[:]
+}
+
+func sign(key *crypto.Secret, record audit.Record) []byte {
+	record.MAC = nil
+	buf, _ := json.Marshal(record)
+	mac := hmac.New(sha256.New, key.Bytes())
+	mac.Write(buf)
+	return mac.Sum(nil)
+}
+
+func scan(r io.Reader, fn func(record audit.Record) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, FileLogMaxRecordLen)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		record := audit.Record{}
+		if err := json.Unmarshal(line, &record); err != nil {
+			return audit.ErrInvalidRecord
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+	if scanner.Err() != nil {
+		return audit.ErrInvalidRecord
+	}
+	return nil
+}
+
+func tail(r io.Reader) (*audit.Record, error) {
+	var last *audit.Record
+	err := scan(r, func(record audit.Record) error {
+		last = &record
+		return nil
+	})
+	return last, err
+}
+
+func (log FileLog) Append(key *crypto.Secret, record audit.Record) error {
+	if record.Env == "" || (key == nil && record.Result == audit.ResultOK) {
+		return failure.New(audit.OpAppend, audit.ErrInvalidRecord, nil)
+	}
+	file, err := os.OpenFile(log.path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return failure.New(audit.OpAppend, audit.ErrOpenLogFailed, err)
+	}
+	defer file.Close()
+	if err := lock(file); err != nil {
+		return failure.New(audit.OpAppend, audit.ErrOpenLogFailed, err)
+	}
+	defer unlock(file)
+	last, err := tail(file)
+	if err != nil {
+		return err
+	}
+	record.Seq = 0
+	record.Prev = nil
+	if last != nil {
+		record.Seq = last.Seq + 1
+		record.Prev = digest(*last)
+	}
+	record.MAC = nil
+	if key != nil {
+		record.MAC = sign(key, record)
+	}
+	buf, _ := json.Marshal(record)
+	_, err = file.Seek(0, io.SeekEnd)
+	if err == nil {
+		_, err = file.Write(append(buf, '\n'))
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	if err != nil {
+		return failure.New(audit.OpAppend, audit.ErrWriteLogFailed, err)
+	}
+	return nil
+}
+
+func (log FileLog) Head() (audit.Head, error) {
+	file, err := os.Open(log.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return audit.Head{}, nil
+	}
+	if err != nil {
+		return audit.Head{},
+			failure.New(audit.OpHead, audit.ErrOpenLogFailed, err)
+	}
+	defer file.Close()
+	last, err := tail(file)
+	if err != nil || last == nil {
+		return audit.Head{}, err
+	}
+	return audit.Head{Records: last.Seq + 1, Hash: digest(*last)}, nil
+}
+
+func (log FileLog) Verify(
+	keys audit.Keys, heads []audit.Head) (audit.Summary, error) {
+	file, err := os.Open(log.path)
+	if err != nil {
+		return audit.Summary{},
+			failure.New(audit.OpVerify, audit.ErrOpenLogFailed, err)
+	}
+	defer file.Close()
+	summary := audit.Summary{}
+	digests := [][]byte{}
+	epochs := map[string]uint64{}
+	var prev []byte
+	err = scan(file, func(record audit.Record) error {
+		if record.Seq != uint64(summary.Records) ||
+			!bytes.Equal(record.Prev, prev) {
+			return audit.ErrChainBroken
+		}
+		if len(record.MAC) != 0 || record.Result == audit.ResultOK {
+			envKeys, ok := keys[record.Env]
+			switch {
+			case !ok:
+				summary.Skipped++
+			case record.Epoch < epochs[record.Env]:
+				return audit.ErrRetiredKey
+			case record.Epoch >= uint64(len(envKeys)):
+				return audit.ErrUnverifiedRecord
+			case !hmac.Equal(record.MAC, sign(envKeys[record.Epoch], record)):
+				return audit.ErrMACMismatch
+			default:
+				epochs[record.Env] = record.Epoch
+				summary.Verified++
+			}
+		}
+		summary.Records++
+		prev = digest(record)
+		digests = append(digests, prev)
+		return nil
+	})
+	if err != nil {
+		return summary, err
+	}
+	for _, head := range heads {
+		if head.Records == 0 {
+			continue
+		}
+		if head.Records > uint64(len(digests)) {
+			return summary, audit.ErrLogTruncated
+		}
+		if !bytes.Equal(digests[head.Records-1], head.Hash) {
+			return summary, audit.ErrChainBroken
+		}
+	}
+	return summary, nil
+}
@@ -0,0 +1,272 @@
+package audit_impl
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/reshifr/secure-env/core/audit"
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSecret(b ...byte) *crypto.Secret {
+	secret, _ := crypto.NewSecretFrom(b)
+	return secret
+}
+
+func newLog(t *testing.T, key *crypto.Secret, n int) FileLog {
+	log := NewFileLog(filepath.Join(t.TempDir(), "vault.audit"))
+	for i := 0; i < n; i++ {
+		log.Append(key, audit.Record{
+			Time:    time.Unix(int64(1000+i), 0),
+			Role:    "admin",
+			Command: "set",
+			Env:     "prod",
+			Vars:    []string{"DB_PASS"},
+			Result:  audit.ResultOK,
+		})
+	}
+	return log
+}
+
+func readLines(log FileLog) []string {
+	buf, _ := os.ReadFile(log.path)
+	return strings.SplitAfter(strings.TrimSuffix(string(buf), "\n"), "\n")
+}
+
+func writeLines(log FileLog, lines []string) {
+	os.WriteFile(log.path, []byte(strings.Join(lines, "")+"\n"), 0600)
+}
+
+func Test_NewFileLog(t *testing.T) {
+	t.Parallel()
+	expLog := FileLog{path: "vault.audit"}
+
+	log := NewFileLog("vault.audit")
+	assert.Equal(t, expLog, log)
+}
+
+func Test_FileLog_Append(t *testing.T) {
+	t.Parallel()
+	key := newSecret(0x31)
+	t.Run("ErrOpenLogFailed error", func(t *testing.T) {
+		t.Parallel()
+		log := NewFileLog(filepath.Join(t.TempDir(), "missing", "vault.audit"))
+		const expErr = audit.ErrOpenLogFailed
+
+		err := log.Append(key, audit.Record{Env: "prod"})
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrInvalidRecord error", func(t *testing.T) {
+		t.Parallel()
+		log := newLog(t, key, 0)
+		os.WriteFile(log.path, []byte("{\n"), 0600)
+		const expErr = audit.ErrInvalidRecord
+
+		err := log.Append(key, audit.Record{Env: "prod"})
+		assert.ErrorIs(t, err, expErr)
+		err = log.Append(key, audit.Record{Role: "admin"})
+		assert.ErrorIs(t, err, expErr)
+		err = log.Append(nil, audit.Record{Env: "prod", Result: "ok"})
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		log := newLog(t, key, 0)
+
+		err := log.Append(key, audit.Record{Seq: 7, Env: "prod"})
+		assert.ErrorIs(t, err, nil)
+		err = log.Append(key, audit.Record{Seq: 7, Env: "prod"})
+		assert.ErrorIs(t, err, nil)
+
+		info, _ := os.Stat(log.path)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+		lines := readLines(log)
+		assert.Len(t, lines, 2)
+		assert.Contains(t, lines[0], `"Seq":0`)
+		assert.NotContains(t, lines[0], `"Prev"`)
+		assert.Contains(t, lines[1], `"Seq":1`)
+		assert.Contains(t, lines[1], `"Prev"`)
+	})
+	t.Run("Denied record", func(t *testing.T) {
+		t.Parallel()
+		log := newLog(t, key, 0)
+
+		err := log.Append(nil, audit.Record{Env: "prod", Result: "denied"})
+		assert.ErrorIs(t, err, nil)
+
+		lines := readLines(log)
+		assert.Len(t, lines, 1)
+		assert.NotContains(t, lines[0], `"MAC"`)
+	})
+}
+
+func Test_FileLog_Head(t *testing.T) {
+	t.Parallel()
+	key := newSecret(0x31)
+	t.Run("ErrOpenLogFailed error", func(t *testing.T) {
+		t.Parallel()
+		parent := filepath.Join(t.TempDir(), "vault")
+		os.WriteFile(parent, nil, 0600)
+		log := NewFileLog(filepath.Join(parent, "vault.audit"))
+		expHead := audit.Head{}
+		const expErr = audit.ErrOpenLogFailed
+
+		head, err := log.Head()
+		assert.Equal(t, expHead, head)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Missing log", func(t *testing.T) {
+		t.Parallel()
+		log := newLog(t, key, 0)
+		expHead := audit.Head{}
+
+		head, err := log.Head()
+		assert.Equal(t, expHead, head)
+		assert.ErrorIs(t, err, nil)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		log := newLog(t, key, 3)
+
+		head, err := log.Head()
+		assert.Equal(t, uint64(3), head.Records)
+		assert.Len(t, head.Hash, 32)
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_FileLog_Verify(t *testing.T) {
+	t.Parallel()
+	key := newSecret(0x31)
+	keys := audit.Keys{"prod": {key}}
+
+	t.Run("ErrOpenLogFailed error", func(t *testing.T) {
+		t.Parallel()
+		log := NewFileLog(filepath.Join(t.TempDir(), "vault.audit"))
+		expSummary := audit.Summary{}
+		const expErr = audit.ErrOpenLogFailed
+
+		summary, err := log.Verify(keys, nil)
+		assert.Equal(t, expSummary, summary)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrInvalidRecord error", func(t *testing.T) {
+		t.Parallel()
+		log := newLog(t, key, 2)
+		lines := readLines(log)
+		writeLines(log, []string{lines[0], "not json\n"})
+		expSummary := audit.Summary{Records: 1, Verified: 1}
+		const expErr = audit.ErrInvalidRecord
+
+		summary, err := log.Verify(keys, nil)
+		assert.Equal(t, expSummary, summary)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrChainBroken error", func(t *testing.T) {
+		t.Parallel()
+		log := newLog(t, key, 3)
+		lines := readLines(log)
+		writeLines(log, []string{lines[0], lines[2]})
+		expSummary := audit.Summary{Records: 1, Verified: 1}
+		const expErr = audit.ErrChainBroken
+
+		summary, err := log.Verify(keys, nil)
+		assert.Equal(t, expSummary, summary)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrMACMismatch error", func(t *testing.T) {
+		t.Parallel()
+		log := newLog(t, key, 2)
+		lines := readLines(log)
+		lines[1] = strings.Replace(lines[1], `"set"`, `"get"`, 1)
+		writeLines(log, lines)
+		expSummary := audit.Summary{Records: 1, Verified: 1}
+		const expErr = audit.ErrMACMismatch
+
+		summary, err := log.Verify(keys, nil)
+		assert.Equal(t, expSummary, summary)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrUnverifiedRecord error", func(t *testing.T) {
+		t.Parallel()
+		log := newLog(t, key, 2)
+		log.Append(newSecret(0x32), audit.Record{Env: "prod", Epoch: 1})
+		expSummary := audit.Summary{Records: 2, Verified: 2}
+		const expErr = audit.ErrUnverifiedRecord
+
+		summary, err := log.Verify(keys, nil)
+		assert.Equal(t, expSummary, summary)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrRetiredKey error", func(t *testing.T) {
+		t.Parallel()
+		newKey := newSecret(0x32)
+		log := newLog(t, key, 1)
+		log.Append(newKey, audit.Record{
+			Env:     "prod",
+			Command: "rotate",
+			Result:  audit.ResultOK,
+			Epoch:   1,
+		})
+		log.Append(key, audit.Record{Env: "prod", Result: audit.ResultOK})
+		expSummary := audit.Summary{Records: 2, Verified: 2}
+		const expErr = audit.ErrRetiredKey
+
+		summary, err := log.Verify(audit.Keys{"prod": {key, newKey}}, nil)
+		assert.Equal(t, expSummary, summary)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrLogTruncated error", func(t *testing.T) {
+		t.Parallel()
+		log := newLog(t, key, 3)
+		head, _ := log.Head()
+		lines := readLines(log)
+		writeLines(log, lines[:2])
+		expSummary := audit.Summary{Records: 2, Verified: 2}
+		const expErr = audit.ErrLogTruncated
+
+		summary, err := log.Verify(keys, []audit.Head{head})
+		assert.Equal(t, expSummary, summary)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Head ErrChainBroken error", func(t *testing.T) {
+		t.Parallel()
+		log := newLog(t, key, 2)
+		head, _ := log.Head()
+		lines := readLines(log)
+		writeLines(log, lines[:1])
+		log.Append(key, audit.Record{Env: "prod", Result: audit.ResultOK})
+		expSummary := audit.Summary{Records: 2, Verified: 2}
+		const expErr = audit.ErrChainBroken
+
+		summary, err := log.Verify(keys, []audit.Head{head})
+		assert.Equal(t, expSummary, summary)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		log := newLog(t, key, 2)
+		head, _ := log.Head()
+		log.Append(nil, audit.Record{Env: "prod", Result: "denied"})
+		expSummary := audit.Summary{Records: 3, Verified: 2}
+
+		summary, err := log.Verify(keys, []audit.Head{{}, head})
+		assert.Equal(t, expSummary, summary)
+		assert.ErrorIs(t, err, nil)
+	})
+	t.Run("Skipped record", func(t *testing.T) {
+		t.Parallel()
+		log := newLog(t, key, 2)
+		log.Append(newSecret(0x32), audit.Record{Env: "dev"})
+		log.Append(key, audit.Record{Env: "prod", Result: audit.ResultOK})
+		expSummary := audit.Summary{Records: 4, Verified: 3, Skipped: 1}
+
+		summary, err := log.Verify(keys, nil)
+		assert.Equal(t, expSummary, summary)
+		assert.ErrorIs(t, err, nil)
+	})
+}
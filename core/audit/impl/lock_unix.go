@@ -0,0 +1,17 @@
+//go:build unix
+
+package audit_impl
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+func lock(file *os.File) error {
+	return unix.Flock(int(file.Fd()), unix.LOCK_EX)
+}
+
+func unlock(file *os.File) error {
+	return unix.Flock(int(file.Fd()), unix.LOCK_UN)
+}
@@ -0,0 +1,102 @@
+package audit_test
+
+import (
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/reshifr/secure-env/core/audit"
+	aimpl "github.com/reshifr/secure-env/core/audit/impl"
+	"github.com/reshifr/secure-env/core/crypto"
+	cimpl "github.com/reshifr/secure-env/core/crypto/impl"
+	"github.com/reshifr/secure-env/core/env"
+	"github.com/reshifr/secure-env/core/vault"
+	vimpl "github.com/reshifr/secure-env/core/vault/impl"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_FileLog_Verify(t *testing.T) {
+	t.Parallel()
+	rng := cimpl.NewStdRNG(cimpl.FnStdRNG{Read: rand.Read})
+	cipher := cimpl.ChaChaPoly{}
+	stream, _ := cimpl.NewStream(cipher, cimpl.StreamChunkLen)
+	kdf := cimpl.NewHKDF([]byte("secure-env"))
+	authorizer := cimpl.NewRoleAuthorizer(cimpl.Argon{}, rng, cipher)
+	path := audit.LogPath(filepath.Join(t.TempDir(), "secrets.senv"), "")
+	log := aimpl.NewFileLog(path)
+	keeper := vimpl.NewKeeper(
+		vimpl.FnKeeper{Now: time.Now, Audit: log.Append, Head: log.Head},
+		authorizer, cipher, stream, kdf)
+	rawIV, _ := rng.Block(cimpl.IV96Len)
+	iv, _ := cimpl.LoadIV96(rawIV)
+
+	passphrase, _ := crypto.NewSecretFrom([]byte("+DF7Rc-X/MOYjkNj"))
+	newPassphrase, _ := crypto.NewSecretFrom([]byte("q7!Lw2#zR9@pXe4v"))
+	v := &vault.Vault{}
+	keyring, err := keeper.CreateEnv(iv, v, "prod", "", "admin", passphrase)
+	assert.ErrorIs(t, err, nil)
+	variable := env.Var{Name: "DB_PASS", Value: "s3cr3t"}
+	err = keeper.Set(iv, v, keyring, "prod", "admin", variable)
+	assert.ErrorIs(t, err, nil)
+	_, err = keeper.Import(iv, v, keyring, "prod", "admin",
+		[]env.Var{{Name: "DB_USER", Value: "app"}})
+	assert.ErrorIs(t, err, nil)
+	err = keeper.AddRole(iv, v, "prod", "admin", passphrase, "dev", newPassphrase)
+	assert.ErrorIs(t, err, nil)
+	err = keeper.Anchor(iv, v, keyring)
+	assert.ErrorIs(t, err, nil)
+	oldKeys, err := keeper.AuditKeys(v, keyring)
+	assert.ErrorIs(t, err, nil)
+	_, err = keeper.Rotate(iv, v, "prod", "admin", passphrase)
+	assert.ErrorIs(t, err, nil)
+	err = keeper.Passwd(iv, v, "admin", passphrase, newPassphrase)
+	assert.ErrorIs(t, err, nil)
+	_, err = keeper.Open(v, "prod", "admin", passphrase)
+	assert.ErrorIs(t, err, crypto.ErrAuthFailed)
+
+	buf, _ := os.ReadFile(path)
+	assert.NotContains(t, string(buf), variable.Value)
+
+	reopened, _ := keeper.Open(v, "prod", "admin", newPassphrase)
+	heads, err := keeper.AuditHeads(v, reopened)
+	assert.Len(t, heads, 1)
+	assert.Equal(t, uint64(4), heads[0].Records)
+	assert.ErrorIs(t, err, nil)
+	err = keeper.Anchor(iv, v, reopened)
+	assert.ErrorIs(t, err, nil)
+	heads, err = keeper.AuditHeads(v, reopened)
+	assert.Len(t, heads, 1)
+	assert.Equal(t, uint64(7), heads[0].Records)
+	assert.ErrorIs(t, err, nil)
+	keys, err := keeper.AuditKeys(v, reopened)
+	assert.ErrorIs(t, err, nil)
+	summary, err := log.Verify(keys, heads)
+	assert.Equal(t, audit.Summary{Records: 7, Verified: 6}, summary)
+	assert.ErrorIs(t, err, nil)
+
+	summary, err = log.Verify(audit.Keys{}, heads)
+	assert.Equal(t, audit.Summary{Records: 7, Skipped: 6}, summary)
+	assert.ErrorIs(t, err, nil)
+
+	err = log.Append(oldKeys["prod"][0], audit.Record{
+		Role:    "dev",
+		Command: vault.OpExport,
+		Env:     "prod",
+		Result:  audit.ResultOK,
+	})
+	assert.ErrorIs(t, err, nil)
+	_, err = log.Verify(keys, heads)
+	assert.ErrorIs(t, err, audit.ErrRetiredKey)
+
+	lines := strings.SplitAfter(string(buf), "\n")
+	os.WriteFile(path, []byte(strings.Join(lines[:5], "")), 0600)
+	_, err = log.Verify(keys, heads)
+	assert.ErrorIs(t, err, audit.ErrLogTruncated)
+
+	os.WriteFile(path, []byte(lines[0]+lines[2]), 0600)
+	_, err = log.Verify(keys, heads)
+	assert.ErrorIs(t, err, audit.ErrChainBroken)
+}
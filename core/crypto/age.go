@@ -0,0 +1,53 @@
+package crypto
+
+import (
+	"io"
+
+	"github.com/reshifr/secure-env/core/failure"
+)
+
+type AgeError int
+
+const (
+	ErrInvalidAgeHeader AgeError = iota + 1
+	ErrAgeHeaderMAC
+	ErrNoAgeIdentity
+	ErrAgeWorkFactor
+)
+
+func (err AgeError) Error() string {
+	switch err {
+	case ErrInvalidAgeHeader:
+		return "ErrInvalidAgeHeader: the age header is malformed."
+	case ErrAgeHeaderMAC:
+		return "ErrAgeHeaderMAC: the age header failed authentication."
+	case ErrNoAgeIdentity:
+		return "ErrNoAgeIdentity: no identity can decrypt the age file."
+	case ErrAgeWorkFactor:
+		return "ErrAgeWorkFactor: the scrypt work factor is out of range."
+	default:
+		return "Error: unknown."
+	}
+}
+
+func (err AgeError) Kind() failure.Kind {
+	switch err {
+	case ErrInvalidAgeHeader, ErrAgeWorkFactor:
+		return failure.KindInvalid
+	case ErrAgeHeaderMAC:
+		return failure.KindIntegrity
+	case ErrNoAgeIdentity:
+		return failure.KindAuth
+	default:
+		return failure.KindInternal
+	}
+}
+
+type Age interface {
+	Encrypt(recipients [][]byte, w io.Writer) (
+		stream io.WriteCloser, err error)
+	EncryptPassphrase(passphrase *Secret, w io.Writer) (
+		stream io.WriteCloser, err error)
+	Decrypt(identities []*Secret, passphrase *Secret, r io.Reader) (
+		stream io.ReadCloser, err error)
+}
@@ -0,0 +1,96 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/reshifr/secure-env/core/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AgeError_Error(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrInvalidAgeHeader value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrInvalidAgeHeader
+		const expMsg = "ErrInvalidAgeHeader: the age header is malformed."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrAgeHeaderMAC value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrAgeHeaderMAC
+		const expMsg = "ErrAgeHeaderMAC: " +
+			"the age header failed authentication."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrNoAgeIdentity value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrNoAgeIdentity
+		const expMsg = "ErrNoAgeIdentity: " +
+			"no identity can decrypt the age file."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrAgeWorkFactor value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrAgeWorkFactor
+		const expMsg = "ErrAgeWorkFactor: " +
+			"the scrypt work factor is out of range."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("Unknown value", func(t *testing.T) {
+		t.Parallel()
+		const err = AgeError(957361)
+		const expMsg = "Error: unknown."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+}
+
+func Test_AgeError_Kind(t *testing.T) {
+	t.Parallel()
+	t.Run("KindInvalid value", func(t *testing.T) {
+		t.Parallel()
+		errs := []AgeError{
+			ErrInvalidAgeHeader,
+			ErrAgeWorkFactor,
+		}
+		const expKind = failure.KindInvalid
+
+		for _, err := range errs {
+			kind := err.Kind()
+			assert.Equal(t, expKind, kind, err.Error())
+		}
+	})
+	t.Run("KindIntegrity value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrAgeHeaderMAC
+		const expKind = failure.KindIntegrity
+
+		kind := err.Kind()
+		assert.Equal(t, expKind, kind)
+	})
+	t.Run("KindAuth value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrNoAgeIdentity
+		const expKind = failure.KindAuth
+
+		kind := err.Kind()
+		assert.Equal(t, expKind, kind)
+	})
+	t.Run("Unknown value", func(t *testing.T) {
+		t.Parallel()
+		const err = AgeError(613724)
+		const expKind = failure.KindInternal
+
+		kind := err.Kind()
+		assert.Equal(t, expKind, kind)
+	})
+}
@@ -1,16 +1,44 @@
 package crypto
 
+import (
+	"github.com/reshifr/secure-env/core/failure"
+)
+
 type AuthorizerError int
 
 const (
 	ErrInvalidBlockLen AuthorizerError = iota + 1
+	ErrInvalidKDFParams
 )
 
 func (err AuthorizerError) Error() string {
 	switch err {
 	case ErrInvalidBlockLen:
-		return "ErrInvalidBlockLen: ... ."
+		return "ErrInvalidBlockLen: the authorization block is too short."
+	case ErrInvalidKDFParams:
+		return "ErrInvalidKDFParams: the slot's KDF parameters are not usable."
 	default:
 		return "Error: unknown."
 	}
 }
+
+func (err AuthorizerError) Kind() failure.Kind {
+	switch err {
+	case ErrInvalidBlockLen, ErrInvalidKDFParams:
+		return failure.KindInvalid
+	default:
+		return failure.KindInternal
+	}
+}
+
+type Authorizer interface {
+	Kind() (kind string)
+	KDFParams() (params KDFParams)
+	Make(iv IV, passphrase *Secret, keyLen uint32) (
+		accessKey *Secret, block []byte, err error)
+	Open(params KDFParams, passphrase *Secret, block []byte) (
+		accessKey *Secret, err error)
+	Inherit(iv IV, params KDFParams, passphrase *Secret,
+		childPassphrase *Secret, block []byte) (
+		accessKey *Secret, childBlock []byte, err error)
+}
@@ -0,0 +1,60 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/reshifr/secure-env/core/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AuthorizerError_Error(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrInvalidBlockLen value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrInvalidBlockLen
+		const expMsg = "ErrInvalidBlockLen: " +
+			"the authorization block is too short."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrInvalidKDFParams value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrInvalidKDFParams
+		const expMsg = "ErrInvalidKDFParams: " +
+			"the slot's KDF parameters are not usable."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("Unknown value", func(t *testing.T) {
+		t.Parallel()
+		const err = AuthorizerError(957361)
+		const expMsg = "Error: unknown."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+}
+
+func Test_AuthorizerError_Kind(t *testing.T) {
+	t.Parallel()
+	t.Run("KindInvalid value", func(t *testing.T) {
+		t.Parallel()
+		errs := []AuthorizerError{ErrInvalidBlockLen, ErrInvalidKDFParams}
+		const expKind = failure.KindInvalid
+
+		for _, err := range errs {
+			kind := err.Kind()
+			assert.Equal(t, expKind, kind)
+		}
+	})
+	t.Run("Unknown value", func(t *testing.T) {
+		t.Parallel()
+		const err = AuthorizerError(613724)
+		const expKind = failure.KindInternal
+
+		kind := err.Kind()
+		assert.Equal(t, expKind, kind)
+	})
+}
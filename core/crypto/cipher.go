@@ -1,5 +1,14 @@
 package crypto
 
+import (
+	"github.com/reshifr/secure-env/core/failure"
+)
+
+const (
+	OpSeal = "seal"
+	OpOpen = "open"
+)
+
 type CipherError int
 
 const (
@@ -21,6 +30,15 @@ func (err CipherError) Error() string {
 	}
 }
 
+func (err CipherError) Kind() failure.Kind {
+	switch err {
+	case ErrInvalidIVLen, ErrInvalidKeyLen, ErrInvalidBufLayout:
+		return failure.KindInvalid
+	default:
+		return failure.KindInternal
+	}
+}
+
 type AEError int
 
 const (
@@ -36,6 +54,15 @@ func (err AEError) Error() string {
 	}
 }
 
+func (err AEError) Kind() failure.Kind {
+	switch err {
+	case ErrAuthFailed:
+		return failure.KindAuth
+	default:
+		return failure.KindInternal
+	}
+}
+
 type IV interface {
 	Len() (ivLen uint32)
 	Invoke() (invokedRawIV []byte)
@@ -43,6 +70,7 @@ type IV interface {
 
 type AE interface {
 	KeyLen() (keyLen uint32)
-	Seal(iv IV, key []byte, plaintext []byte) (buf []byte, err error)
-	Open(key []byte, buf []byte) (ciphertext []byte, err error)
+	Seal(iv IV, key *Secret, plaintext []byte, ad []byte) (
+		buf []byte, err error)
+	Open(key *Secret, buf []byte, ad []byte) (plaintext *Secret, err error)
 }
@@ -3,6 +3,7 @@ package crypto
 import (
 	"testing"
 
+	"github.com/reshifr/secure-env/core/failure"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -69,3 +70,49 @@ func Test_AEError_Error(t *testing.T) {
 		assert.Equal(t, expMsg, msg)
 	})
 }
+
+func Test_CipherError_Kind(t *testing.T) {
+	t.Parallel()
+	t.Run("KindInvalid value", func(t *testing.T) {
+		t.Parallel()
+		errs := []CipherError{
+			ErrInvalidIVLen,
+			ErrInvalidKeyLen,
+			ErrInvalidBufLayout,
+		}
+		const expKind = failure.KindInvalid
+
+		for _, err := range errs {
+			kind := err.Kind()
+			assert.Equal(t, expKind, kind, err.Error())
+		}
+	})
+	t.Run("Unknown value", func(t *testing.T) {
+		t.Parallel()
+		const err = CipherError(613724)
+		const expKind = failure.KindInternal
+
+		kind := err.Kind()
+		assert.Equal(t, expKind, kind)
+	})
+}
+
+func Test_AEError_Kind(t *testing.T) {
+	t.Parallel()
+	t.Run("KindAuth value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrAuthFailed
+		const expKind = failure.KindAuth
+
+		kind := err.Kind()
+		assert.Equal(t, expKind, kind)
+	})
+	t.Run("Unknown value", func(t *testing.T) {
+		t.Parallel()
+		const err = AEError(613724)
+		const expKind = failure.KindInternal
+
+		kind := err.Kind()
+		assert.Equal(t, expKind, kind)
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/cipher"
 
 	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/reshifr/secure-env/core/failure"
 )
 
 const (
@@ -18,37 +19,54 @@ func (AESGCM) KeyLen() uint32 {
 	return AESGCMKeyLen
 }
 
+func (AESGCM) AEAD(key []byte) (cipher.AEAD, error) {
+	aes, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(aes)
+}
+
 func (AESGCM) Seal(iv crypto.IV,
-	key []byte, plaintext []byte) ([]byte, error) {
+	key *crypto.Secret, plaintext []byte, ad []byte) ([]byte, error) {
 	if iv.Len() != AESGCMIVLen {
 		return nil, crypto.ErrInvalidIVLen
 	}
-	aes, err := aes.NewCipher(key)
+	aes, err := aes.NewCipher(key.Bytes())
 	if err != nil {
-		return nil, crypto.ErrInvalidKeyLen
+		return nil, failure.New(crypto.OpSeal, crypto.ErrInvalidKeyLen, err)
 	}
 	rawIV := iv.Invoke()
 	aesgcm, _ := cipher.NewGCM(aes)
-	ciphertext := aesgcm.Seal(nil, rawIV, plaintext, nil)
+	ciphertext := aesgcm.Seal(nil, rawIV, plaintext, ad)
 	buf := make([]byte, AESGCMIVLen+len(ciphertext))
 	copy(buf, rawIV)
 	copy(buf[AESGCMIVLen:], ciphertext)
 	return buf, nil
 }
 
-func (AESGCM) Open(key []byte, buf []byte) ([]byte, error) {
+func (AESGCM) Open(key *crypto.Secret,
+	buf []byte, ad []byte) (*crypto.Secret, error) {
 	if len(buf) < AESGCMIVLen {
 		return nil, crypto.ErrInvalidBufLayout
 	}
-	aes, err := aes.NewCipher(key)
+	aes, err := aes.NewCipher(key.Bytes())
 	if err != nil {
-		return nil, crypto.ErrInvalidKeyLen
+		return nil, failure.New(crypto.OpOpen, crypto.ErrInvalidKeyLen, err)
 	}
 	rawIV := buf[:AESGCMIVLen]
 	ciphertext := buf[AESGCMIVLen:]
 	aesgcm, _ := cipher.NewGCM(aes)
-	plaintext, err := aesgcm.Open(nil, rawIV, ciphertext, nil)
+	if len(ciphertext) < aesgcm.Overhead() {
+		return nil, crypto.ErrAuthFailed
+	}
+	plaintext, err := crypto.NewSecret(len(ciphertext) - aesgcm.Overhead())
+	if err != nil {
+		return nil, err
+	}
+	_, err = aesgcm.Open(plaintext.Bytes()[:0], rawIV, ciphertext, ad)
 	if err != nil {
+		plaintext.Destroy()
 		return nil, crypto.ErrAuthFailed
 	}
 	return plaintext, nil
@@ -31,7 +31,7 @@ func Test_AESGCM_Seal(t *testing.T) {
 		var expBuf []byte = nil
 		const expErr = crypto.ErrInvalidIVLen
 
-		buf, err := cipher.Seal(iv, nil, nil)
+		buf, err := cipher.Seal(iv, nil, nil, nil)
 		assert.Equal(t, expBuf, buf)
 		assert.ErrorIs(t, err, expErr)
 	})
@@ -40,11 +40,11 @@ func Test_AESGCM_Seal(t *testing.T) {
 		iv := cmock.NewIV(t)
 		iv.EXPECT().Len().Return(AESGCMIVLen).Once()
 
-		key := bytes.Repeat([]byte{0x11}, 8)
+		key, _ := crypto.NewSecretFrom(bytes.Repeat([]byte{0x11}, 8))
 		var expBuf []byte = nil
 		const expErr = crypto.ErrInvalidKeyLen
 
-		buf, err := cipher.Seal(iv, key, nil)
+		buf, err := cipher.Seal(iv, key, nil, nil)
 		assert.Equal(t, expBuf, buf)
 		assert.ErrorIs(t, err, expErr)
 	})
@@ -53,9 +53,10 @@ func Test_AESGCM_Seal(t *testing.T) {
 		iv := cmock.NewIV(t)
 		iv.EXPECT().Len().Return(AESGCMIVLen).Once()
 
-		key, _ := hex.DecodeString(
+		rawKey, _ := hex.DecodeString(
 			"c4fcdf96ba5fb52c72ad024d8b7eaeef" +
 				"b63e909b63ed92cf0fbf31fc71c6d704")
+		key, _ := crypto.NewSecretFrom(rawKey)
 
 		rawIV, _ := hex.DecodeString("111111112222222222222222")
 		iv.EXPECT().Invoke().Return(rawIV).Once()
@@ -66,7 +67,7 @@ func Test_AESGCM_Seal(t *testing.T) {
 				"85ec6cdbb2fc0c6a1cde5e343f")
 		expBuf := append(rawIV, ciphertext...)
 
-		buf, err := cipher.Seal(iv, key, plaintext)
+		buf, err := cipher.Seal(iv, key, plaintext, nil)
 		assert.Equal(t, buf, expBuf)
 		assert.ErrorIs(t, err, nil)
 	})
@@ -79,21 +80,21 @@ func Test_AESGCM_Open(t *testing.T) {
 	t.Run("ErrInvalidBufLayout error", func(t *testing.T) {
 		t.Parallel()
 		buf := bytes.Repeat([]byte{0x22}, 8)
-		var expPlaintext []byte = nil
+		var expPlaintext *crypto.Secret = nil
 		const expErr = crypto.ErrInvalidBufLayout
 
-		plaintext, err := cipher.Open(nil, buf)
+		plaintext, err := cipher.Open(nil, buf, nil)
 		assert.Equal(t, expPlaintext, plaintext)
 		assert.ErrorIs(t, err, expErr)
 	})
 	t.Run("ErrInvalidKeyLen error", func(t *testing.T) {
 		t.Parallel()
-		key := bytes.Repeat([]byte{0x11}, 8)
+		key, _ := crypto.NewSecretFrom(bytes.Repeat([]byte{0x11}, 8))
 		buf := bytes.Repeat([]byte{0x22}, IV96Len)
-		var expPlaintext []byte = nil
+		var expPlaintext *crypto.Secret = nil
 		const expErr = crypto.ErrInvalidKeyLen
 
-		plaintext, err := cipher.Open(key, buf)
+		plaintext, err := cipher.Open(key, buf, nil)
 		assert.Equal(t, expPlaintext, plaintext)
 		assert.ErrorIs(t, err, expErr)
 	})
@@ -106,25 +107,40 @@ func Test_AESGCM_Open(t *testing.T) {
 
 	t.Run("ErrAuthFailed error", func(t *testing.T) {
 		t.Parallel()
-		key, _ := hex.DecodeString(
+		rawKey, _ := hex.DecodeString(
 			"27fb29cdfff36b420da50b61dc15380d" +
 				"626bc422352488e10a272144186566b8")
-		var expPlaintext []byte = nil
+		key, _ := crypto.NewSecretFrom(rawKey)
+		var expPlaintext *crypto.Secret = nil
 		const expErr = crypto.ErrAuthFailed
 
-		plaintext, err := cipher.Open(key, buf)
+		plaintext, err := cipher.Open(key, buf, nil)
+		assert.Equal(t, expPlaintext, plaintext)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Associated data mismatch", func(t *testing.T) {
+		t.Parallel()
+		rawKey, _ := hex.DecodeString(
+			"c4fcdf96ba5fb52c72ad024d8b7eaeef" +
+				"b63e909b63ed92cf0fbf31fc71c6d704")
+		key, _ := crypto.NewSecretFrom(rawKey)
+		var expPlaintext *crypto.Secret = nil
+		const expErr = crypto.ErrAuthFailed
+
+		plaintext, err := cipher.Open(key, buf, []byte("prod\x00KEY"))
 		assert.Equal(t, expPlaintext, plaintext)
 		assert.ErrorIs(t, err, expErr)
 	})
 	t.Run("Succeed", func(t *testing.T) {
 		t.Parallel()
-		key, _ := hex.DecodeString(
+		rawKey, _ := hex.DecodeString(
 			"c4fcdf96ba5fb52c72ad024d8b7eaeef" +
 				"b63e909b63ed92cf0fbf31fc71c6d704")
+		key, _ := crypto.NewSecretFrom(rawKey)
 		expPlaintext := []byte("Hello, World!")
 
-		plaintext, err := cipher.Open(key, buf)
-		assert.Equal(t, expPlaintext, plaintext)
+		plaintext, err := cipher.Open(key, buf, nil)
+		assert.Equal(t, expPlaintext, plaintext.Bytes())
 		assert.ErrorIs(t, err, nil)
 	})
 }
@@ -0,0 +1,526 @@
+package crypto_impl
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ecdh"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/reshifr/secure-env/core/failure"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	AgeVersion             = "age-encryption.org/v1"
+	AgeRecipientHRP        = "age"
+	AgeIdentityHRP         = "AGE-SECRET-KEY-"
+	AgeX25519Type          = "X25519"
+	AgeX25519Info          = "age-encryption.org/v1/X25519"
+	AgeScryptType          = "scrypt"
+	AgeScryptInfo          = "age-encryption.org/v1/scrypt"
+	AgeScryptSaltLen       = 16
+	AgeScryptWorkFactor    = 18
+	AgeScryptMaxWorkFactor = 22
+	AgeFileKeyLen          = 16
+	AgeNonceLen            = 16
+	AgeKeyLen              = 32
+	AgeBodyColumns         = 64
+	AgeHeaderInfo          = "header"
+	AgePayloadInfo         = "payload"
+)
+
+var (
+	ageBase64 = base64.RawStdEncoding.Strict()
+)
+
+type Age[RNG crypto.RNG] struct {
+	rng        RNG
+	stream     Stream[ChaChaPoly]
+	workFactor int
+}
+
+type ageStanza struct {
+	kind string
+	args []string
+	body []byte
+}
+
+type ageHeader struct {
+	stanzas []ageStanza
+	mac     []byte
+	raw     []byte
+}
+
+func NewAge[RNG crypto.RNG](rng RNG, workFactor int) (Age[RNG], error) {
+	if workFactor < 1 || workFactor > AgeScryptMaxWorkFactor {
+		return Age[RNG]{}, crypto.ErrAgeWorkFactor
+	}
+	stream, _ := NewStream(ChaChaPoly{}, StreamChunkLen)
+	return Age[RNG]{rng: rng, stream: stream, workFactor: workFactor}, nil
+}
+
+func ParseAgeRecipient(recipient []byte) ([]byte, error) {
+	hrp, publicKey, err := bech32Decode(string(recipient))
+	if err != nil || hrp != AgeRecipientHRP ||
+		len(publicKey) != HybridX25519Len {
+		return nil, crypto.ErrInvalidRecipient
+	}
+	return publicKey, nil
+}
+
+func EncodeAgeRecipient(publicKey []byte) ([]byte, error) {
+	if len(publicKey) != HybridX25519Len {
+		return nil, crypto.ErrInvalidRecipient
+	}
+	recipient, _ := bech32Encode(AgeRecipientHRP, publicKey)
+	return []byte(recipient), nil
+}
+
+func AgeRecipient(identity *crypto.Secret) ([]byte, error) {
+	privateKey, err := ecdh.X25519().NewPrivateKey(identity.Bytes())
+	if err != nil {
+		return nil, crypto.ErrInvalidIdentity
+	}
+	return EncodeAgeRecipient(privateKey.PublicKey().Bytes())
+}
+
+func ParseAgeIdentities(buf []byte) ([]*crypto.Secret, error) {
+	identities := []*crypto.Secret{}
+	for _, line := range strings.Split(string(buf), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		hrp, scalar, err := bech32Decode(line)
+		if err != nil || hrp != strings.ToLower(AgeIdentityHRP) ||
+			len(scalar) != HybridX25519Len {
+			for _, identity := range identities {
+				identity.Destroy()
+			}
+			return nil, crypto.ErrInvalidIdentity
+		}
+		identity, err := crypto.NewSecretFrom(scalar)
+		clear(scalar)
+		if err != nil {
+			return nil, err
+		}
+		identities = append(identities, identity)
+	}
+	if len(identities) == 0 {
+		return nil, crypto.ErrInvalidIdentity
+	}
+	return identities, nil
+}
+
+func ageWrap(key *crypto.Secret, fileKey *crypto.Secret) ([]byte, error) {
+	aead, err := ChaChaPoly{}.AEAD(key.Bytes())
+	if err != nil {
+		return nil, failure.New(crypto.OpSeal, crypto.ErrInvalidKeyLen, err)
+	}
+	nonce := make([]byte, aead.NonceSize())
+	return aead.Seal(nil, nonce, fileKey.Bytes(), nil), nil
+}
+
+func ageUnwrap(key *crypto.Secret, body []byte) (*crypto.Secret, error) {
+	aead, err := ChaChaPoly{}.AEAD(key.Bytes())
+	if err != nil {
+		return nil, failure.New(crypto.OpOpen, crypto.ErrInvalidKeyLen, err)
+	}
+	if len(body) != AgeFileKeyLen+aead.Overhead() {
+		return nil, crypto.ErrInvalidAgeHeader
+	}
+	fileKey, err := crypto.NewSecret(AgeFileKeyLen)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := aead.Open(fileKey.Bytes()[:0], nonce, body, nil); err != nil {
+		fileKey.Destroy()
+		return nil, crypto.ErrAuthFailed
+	}
+	return fileKey, nil
+}
+
+func ageX25519Key(shared []byte,
+	share []byte, publicKey []byte) (*crypto.Secret, error) {
+	ikm, err := crypto.NewSecretFrom(shared)
+	if err != nil {
+		return nil, err
+	}
+	defer ikm.Destroy()
+	salt := make([]byte, 0, 2*HybridX25519Len)
+	salt = append(salt, share...)
+	salt = append(salt, publicKey...)
+	return NewHKDF([]byte(AgeX25519Info)).Key(ikm, salt, AgeKeyLen)
+}
+
+func ageScryptKey(passphrase *crypto.Secret,
+	salt []byte, workFactor int) (*crypto.Secret, error) {
+	label := append([]byte(AgeScryptInfo), salt...)
+	key, err := scrypt.Key(
+		passphrase.Bytes(), label, 1<<workFactor, 8, 1, AgeKeyLen)
+	if err != nil {
+		return nil, failure.New(crypto.OpSeal, crypto.ErrAgeWorkFactor, err)
+	}
+	defer clear(key)
+	return crypto.NewSecretFrom(key)
+}
+
+func ageMAC(fileKey *crypto.Secret, raw []byte) ([]byte, error) {
+	key, err := NewHKDF([]byte(AgeHeaderInfo)).Key(fileKey, nil, AgeKeyLen)
+	if err != nil {
+		return nil, err
+	}
+	defer key.Destroy()
+	mac := hmac.New(sha256.New, key.Bytes())
+	mac.Write(raw)
+	return mac.Sum(nil), nil
+}
+
+func (stanza ageStanza) marshal(buf *bytes.Buffer) {
+	buf.WriteString("-> " + stanza.kind)
+	for _, arg := range stanza.args {
+		buf.WriteString(" " + arg)
+	}
+	buf.WriteByte('\n')
+	body := ageBase64.EncodeToString(stanza.body)
+	for len(body) >= AgeBodyColumns {
+		buf.WriteString(body[:AgeBodyColumns] + "\n")
+		body = body[AgeBodyColumns:]
+	}
+	buf.WriteString(body + "\n")
+}
+
+func ageInvalidChar(r rune) bool {
+	return r < '!' || r > '~'
+}
+
+func ageLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadSlice('\n')
+	if err != nil {
+		return "", failure.New(crypto.OpOpen, crypto.ErrInvalidAgeHeader, err)
+	}
+	return string(line[:len(line)-1]), nil
+}
+
+func parseAgeHeader(r *bufio.Reader) (ageHeader, error) {
+	header := ageHeader{}
+	raw := &bytes.Buffer{}
+	line, err := ageLine(r)
+	if err != nil {
+		return ageHeader{}, err
+	}
+	if line != AgeVersion {
+		return ageHeader{}, crypto.ErrInvalidAgeHeader
+	}
+	raw.WriteString(line + "\n")
+	for {
+		line, err := ageLine(r)
+		if err != nil {
+			return ageHeader{}, err
+		}
+		if mac, ok := strings.CutPrefix(line, "--- "); ok {
+			if len(header.stanzas) == 0 {
+				return ageHeader{}, crypto.ErrInvalidAgeHeader
+			}
+			header.mac, err = ageBase64.DecodeString(mac)
+			if err != nil || len(header.mac) != sha256.Size {
+				return ageHeader{}, crypto.ErrInvalidAgeHeader
+			}
+			raw.WriteString("---")
+			header.raw = raw.Bytes()
+			return header, nil
+		}
+		fields, ok := strings.CutPrefix(line, "-> ")
+		if !ok {
+			return ageHeader{}, crypto.ErrInvalidAgeHeader
+		}
+		args := strings.Split(fields, " ")
+		for _, arg := range args {
+			if arg == "" || strings.ContainsFunc(arg, ageInvalidChar) {
+				return ageHeader{}, crypto.ErrInvalidAgeHeader
+			}
+		}
+		raw.WriteString(line + "\n")
+		stanza := ageStanza{kind: args[0], args: args[1:]}
+		for {
+			line, err := ageLine(r)
+			if err != nil {
+				return ageHeader{}, err
+			}
+			if len(line) > AgeBodyColumns {
+				return ageHeader{}, crypto.ErrInvalidAgeHeader
+			}
+			chunk, err := ageBase64.DecodeString(line)
+			if err != nil {
+				return ageHeader{}, crypto.ErrInvalidAgeHeader
+			}
+			raw.WriteString(line + "\n")
+			stanza.body = append(stanza.body, chunk...)
+			if len(line) < AgeBodyColumns {
+				break
+			}
+		}
+		header.stanzas = append(header.stanzas, stanza)
+	}
+}
+
+func (age Age[RNG]) seal(stanzas []ageStanza,
+	fileKey *crypto.Secret, w io.Writer) (io.WriteCloser, error) {
+	raw := &bytes.Buffer{}
+	raw.WriteString(AgeVersion + "\n")
+	for _, stanza := range stanzas {
+		stanza.marshal(raw)
+	}
+	raw.WriteString("---")
+	mac, err := ageMAC(fileKey, raw.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	raw.WriteString(" " + ageBase64.EncodeToString(mac) + "\n")
+	nonce, err := age.rng.Block(AgeNonceLen)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(raw.Bytes()); err != nil {
+		return nil, err
+	}
+	writer, err := age.stream.newWriter(
+		fileKey, nonce, []byte(AgePayloadInfo), nil, w)
+	if err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+func (age Age[RNG]) fileKey() (*crypto.Secret, error) {
+	fileKey, err := crypto.NewSecret(AgeFileKeyLen)
+	if err != nil {
+		return nil, err
+	}
+	if err := age.rng.Read(fileKey.Bytes()); err != nil {
+		fileKey.Destroy()
+		return nil, err
+	}
+	return fileKey, nil
+}
+
+func (age Age[RNG]) x25519Stanza(recipient []byte,
+	fileKey *crypto.Secret) (ageStanza, error) {
+	rawPublicKey, err := ParseAgeRecipient(recipient)
+	if err != nil {
+		return ageStanza{}, err
+	}
+	publicKey, err := ecdh.X25519().NewPublicKey(rawPublicKey)
+	if err != nil {
+		return ageStanza{}, crypto.ErrInvalidRecipient
+	}
+	seed, err := crypto.NewSecret(HybridX25519Len)
+	if err != nil {
+		return ageStanza{}, err
+	}
+	defer seed.Destroy()
+	if err := age.rng.Read(seed.Bytes()); err != nil {
+		return ageStanza{}, err
+	}
+	ephemeral, err := ecdh.X25519().NewPrivateKey(seed.Bytes())
+	if err != nil {
+		return ageStanza{}, err
+	}
+	shared, err := ephemeral.ECDH(publicKey)
+	if err != nil {
+		return ageStanza{}, crypto.ErrInvalidRecipient
+	}
+	defer clear(shared)
+	share := ephemeral.PublicKey().Bytes()
+	key, err := ageX25519Key(shared, share, rawPublicKey)
+	if err != nil {
+		return ageStanza{}, err
+	}
+	defer key.Destroy()
+	body, err := ageWrap(key, fileKey)
+	if err != nil {
+		return ageStanza{}, err
+	}
+	return ageStanza{
+		kind: AgeX25519Type,
+		args: []string{ageBase64.EncodeToString(share)},
+		body: body,
+	}, nil
+}
+
+func (age Age[RNG]) Encrypt(
+	recipients [][]byte, w io.Writer) (io.WriteCloser, error) {
+	if len(recipients) == 0 {
+		return nil, crypto.ErrInvalidRecipient
+	}
+	fileKey, err := age.fileKey()
+	if err != nil {
+		return nil, err
+	}
+	defer fileKey.Destroy()
+	stanzas := make([]ageStanza, 0, len(recipients))
+	for _, recipient := range recipients {
+		stanza, err := age.x25519Stanza(recipient, fileKey)
+		if err != nil {
+			return nil, err
+		}
+		stanzas = append(stanzas, stanza)
+	}
+	return age.seal(stanzas, fileKey, w)
+}
+
+func (age Age[RNG]) EncryptPassphrase(
+	passphrase *crypto.Secret, w io.Writer) (io.WriteCloser, error) {
+	fileKey, err := age.fileKey()
+	if err != nil {
+		return nil, err
+	}
+	defer fileKey.Destroy()
+	salt, err := age.rng.Block(AgeScryptSaltLen)
+	if err != nil {
+		return nil, err
+	}
+	key, err := ageScryptKey(passphrase, salt, age.workFactor)
+	if err != nil {
+		return nil, err
+	}
+	defer key.Destroy()
+	body, err := ageWrap(key, fileKey)
+	if err != nil {
+		return nil, err
+	}
+	stanza := ageStanza{
+		kind: AgeScryptType,
+		args: []string{
+			ageBase64.EncodeToString(salt),
+			strconv.Itoa(age.workFactor),
+		},
+		body: body,
+	}
+	return age.seal([]ageStanza{stanza}, fileKey, w)
+}
+
+func ageX25519Unwrap(identity *crypto.Secret,
+	stanza ageStanza) (*crypto.Secret, error) {
+	if len(stanza.args) != 1 {
+		return nil, crypto.ErrInvalidAgeHeader
+	}
+	share, err := ageBase64.DecodeString(stanza.args[0])
+	if err != nil || len(share) != HybridX25519Len {
+		return nil, crypto.ErrInvalidAgeHeader
+	}
+	ephemeral, err := ecdh.X25519().NewPublicKey(share)
+	if err != nil {
+		return nil, crypto.ErrInvalidAgeHeader
+	}
+	privateKey, err := ecdh.X25519().NewPrivateKey(identity.Bytes())
+	if err != nil {
+		return nil, crypto.ErrInvalidIdentity
+	}
+	shared, err := privateKey.ECDH(ephemeral)
+	if err != nil {
+		return nil, crypto.ErrInvalidAgeHeader
+	}
+	defer clear(shared)
+	key, err := ageX25519Key(shared, share, privateKey.PublicKey().Bytes())
+	if err != nil {
+		return nil, err
+	}
+	defer key.Destroy()
+	return ageUnwrap(key, stanza.body)
+}
+
+func ageScryptUnwrap(passphrase *crypto.Secret,
+	stanza ageStanza) (*crypto.Secret, error) {
+	if len(stanza.args) != 2 {
+		return nil, crypto.ErrInvalidAgeHeader
+	}
+	salt, err := ageBase64.DecodeString(stanza.args[0])
+	if err != nil || len(salt) != AgeScryptSaltLen {
+		return nil, crypto.ErrInvalidAgeHeader
+	}
+	workFactor, err := strconv.Atoi(stanza.args[1])
+	if err != nil || strconv.Itoa(workFactor) != stanza.args[1] {
+		return nil, crypto.ErrInvalidAgeHeader
+	}
+	if workFactor < 1 || workFactor > AgeScryptMaxWorkFactor {
+		return nil, crypto.ErrAgeWorkFactor
+	}
+	key, err := ageScryptKey(passphrase, salt, workFactor)
+	if err != nil {
+		return nil, err
+	}
+	defer key.Destroy()
+	return ageUnwrap(key, stanza.body)
+}
+
+func ageFileKey(header ageHeader, identities []*crypto.Secret,
+	passphrase *crypto.Secret) (*crypto.Secret, error) {
+	for _, stanza := range header.stanzas {
+		if stanza.kind != AgeScryptType {
+			continue
+		}
+		if len(header.stanzas) != 1 {
+			return nil, crypto.ErrInvalidAgeHeader
+		}
+		if passphrase == nil {
+			return nil, crypto.ErrNoAgeIdentity
+		}
+		fileKey, err := ageScryptUnwrap(passphrase, stanza)
+		if err == crypto.ErrAuthFailed {
+			return nil, crypto.ErrNoAgeIdentity
+		}
+		return fileKey, err
+	}
+	for _, stanza := range header.stanzas {
+		if stanza.kind != AgeX25519Type {
+			continue
+		}
+		for _, identity := range identities {
+			fileKey, err := ageX25519Unwrap(identity, stanza)
+			if err == crypto.ErrAuthFailed {
+				continue
+			}
+			return fileKey, err
+		}
+	}
+	return nil, crypto.ErrNoAgeIdentity
+}
+
+func (age Age[RNG]) Decrypt(identities []*crypto.Secret,
+	passphrase *crypto.Secret, r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	header, err := parseAgeHeader(br)
+	if err != nil {
+		return nil, err
+	}
+	fileKey, err := ageFileKey(header, identities, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer fileKey.Destroy()
+	mac, err := ageMAC(fileKey, header.raw)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(mac, header.mac) {
+		return nil, crypto.ErrAgeHeaderMAC
+	}
+	nonce := make([]byte, AgeNonceLen)
+	if _, err := io.ReadFull(br, nonce); err != nil {
+		return nil, failure.New(crypto.OpOpen, crypto.ErrStreamTruncated, err)
+	}
+	reader, err := age.stream.newReader(
+		fileKey, nonce, []byte(AgePayloadInfo), nil, br)
+	if err != nil {
+		return nil, err
+	}
+	return reader, nil
+}
@@ -0,0 +1,518 @@
+package crypto_impl
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	ageTestkitDir = "testdata/age"
+	ageWorkFactor = 10
+	ageIdentity   = "AGE-SECRET-KEY-1GFPYYSJZGFPYYSJZGFPYYSJZGFPYYSJZ" +
+		"GFPYYSJZGFPYYSJZGFPQ4EGAEX"
+	ageRecipient = "age1zvkyg2lqzraa2lnjvqej32nkuu0ues2s82hzrye869x" +
+		"eexvn73equnujwj"
+)
+
+func newAge() Age[StdRNG] {
+	age, _ := NewAge(NewStdRNG(FnStdRNG{Read: rand.Read}), ageWorkFactor)
+	return age
+}
+
+func newAgeIdentity() *crypto.Secret {
+	rng := NewStdRNG(FnStdRNG{Read: rand.Read})
+	rawIdentity, _ := rng.Block(HybridX25519Len)
+	identity, _ := crypto.NewSecretFrom(rawIdentity)
+	return identity
+}
+
+func ageSeal(t *testing.T,
+	w io.WriteCloser, err error, plaintext []byte) {
+	assert.ErrorIs(t, err, nil)
+	w.Write(plaintext)
+	assert.ErrorIs(t, w.Close(), nil)
+}
+
+func ageOpen(age Age[StdRNG], identities []*crypto.Secret,
+	passphrase *crypto.Secret, buf []byte) ([]byte, error) {
+	r, err := age.Decrypt(identities, passphrase, bytes.NewReader(buf))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+type ageVector struct {
+	expect     string
+	payload    string
+	identities []string
+	passphrase string
+	file       []byte
+}
+
+func ageTestkit(t *testing.T, path string) ageVector {
+	buf, err := os.ReadFile(path)
+	assert.ErrorIs(t, err, nil)
+	meta, file, _ := bytes.Cut(buf, []byte("\n\n"))
+	vector := ageVector{file: file}
+	for _, line := range strings.Split(string(meta), "\n") {
+		key, value, _ := strings.Cut(line, ": ")
+		switch key {
+		case "expect":
+			vector.expect = value
+		case "payload":
+			vector.payload = value
+		case "identity":
+			vector.identities = append(vector.identities, value)
+		case "passphrase":
+			vector.passphrase = value
+		case "compressed":
+			r, err := zlib.NewReader(bytes.NewReader(file))
+			assert.ErrorIs(t, err, nil)
+			vector.file, err = io.ReadAll(r)
+			assert.ErrorIs(t, err, nil)
+		}
+	}
+	return vector
+}
+
+func Test_NewAge(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrAgeWorkFactor error", func(t *testing.T) {
+		t.Parallel()
+		rng := NewStdRNG(FnStdRNG{})
+		expAge := Age[StdRNG]{}
+		const expErr = crypto.ErrAgeWorkFactor
+
+		age, err := NewAge(rng, AgeScryptMaxWorkFactor+1)
+		assert.Equal(t, expAge, age)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		rng := NewStdRNG(FnStdRNG{})
+		expAge := Age[StdRNG]{
+			rng:        rng,
+			stream:     Stream[ChaChaPoly]{chunkLen: StreamChunkLen},
+			workFactor: AgeScryptWorkFactor,
+		}
+
+		age, err := NewAge(rng, AgeScryptWorkFactor)
+		assert.Equal(t, expAge, age)
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_ParseAgeRecipient(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrInvalidRecipient error", func(t *testing.T) {
+		t.Parallel()
+		var expPublicKey []byte = nil
+		const expErr = crypto.ErrInvalidRecipient
+
+		publicKey, err := ParseAgeRecipient([]byte("age1invalid"))
+		assert.Equal(t, expPublicKey, publicKey)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrInvalidRecipient value", func(t *testing.T) {
+		t.Parallel()
+		recipient, _ := bech32Encode("tag", make([]byte, HybridX25519Len))
+		var expPublicKey []byte = nil
+		const expErr = crypto.ErrInvalidRecipient
+
+		publicKey, err := ParseAgeRecipient([]byte(recipient))
+		assert.Equal(t, expPublicKey, publicKey)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+
+		publicKey, err := ParseAgeRecipient([]byte(ageRecipient))
+		assert.Len(t, publicKey, HybridX25519Len)
+		assert.ErrorIs(t, err, nil)
+		recipient, err := EncodeAgeRecipient(publicKey)
+		assert.Equal(t, ageRecipient, string(recipient))
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_EncodeAgeRecipient(t *testing.T) {
+	t.Parallel()
+	var expRecipient []byte = nil
+	const expErr = crypto.ErrInvalidRecipient
+
+	recipient, err := EncodeAgeRecipient([]byte{0x01})
+	assert.Equal(t, expRecipient, recipient)
+	assert.ErrorIs(t, err, expErr)
+}
+
+func Test_AgeRecipient(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrInvalidIdentity error", func(t *testing.T) {
+		t.Parallel()
+		identity, _ := crypto.NewSecretFrom([]byte{0x01})
+		var expRecipient []byte = nil
+		const expErr = crypto.ErrInvalidIdentity
+
+		recipient, err := AgeRecipient(identity)
+		assert.Equal(t, expRecipient, recipient)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		identity, _ := crypto.NewSecretFrom(
+			bytes.Repeat([]byte{0x42}, HybridX25519Len))
+
+		recipient, err := AgeRecipient(identity)
+		assert.Equal(t, ageRecipient, string(recipient))
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_ParseAgeIdentities(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrInvalidIdentity error", func(t *testing.T) {
+		t.Parallel()
+		var expIdentities []*crypto.Secret = nil
+		const expErr = crypto.ErrInvalidIdentity
+
+		identities, err := ParseAgeIdentities([]byte("# empty\n\n"))
+		assert.Equal(t, expIdentities, identities)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrInvalidIdentity value", func(t *testing.T) {
+		t.Parallel()
+		buf := []byte(ageIdentity + "\n" + ageRecipient + "\n")
+		var expIdentities []*crypto.Secret = nil
+		const expErr = crypto.ErrInvalidIdentity
+
+		identities, err := ParseAgeIdentities(buf)
+		assert.Equal(t, expIdentities, identities)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		buf := []byte("# created: 2024-01-01T00:00:00Z\n" +
+			"# public key: " + ageRecipient + "\n" +
+			ageIdentity + "\n\n" +
+			strings.ToLower(ageIdentity) + "\r\n")
+		expIdentity := bytes.Repeat([]byte{0x42}, HybridX25519Len)
+
+		identities, err := ParseAgeIdentities(buf)
+		assert.Len(t, identities, 2)
+		for _, identity := range identities {
+			assert.Equal(t, expIdentity, identity.Bytes())
+		}
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_Age_Encrypt(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrInvalidRecipient error", func(t *testing.T) {
+		t.Parallel()
+		var expWriter io.WriteCloser = nil
+		const expErr = crypto.ErrInvalidRecipient
+
+		w, err := newAge().Encrypt(nil, io.Discard)
+		assert.Equal(t, expWriter, w)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrInvalidRecipient value", func(t *testing.T) {
+		t.Parallel()
+		recipients := [][]byte{[]byte(ageRecipient), []byte("age1")}
+		var expWriter io.WriteCloser = nil
+		const expErr = crypto.ErrInvalidRecipient
+
+		w, err := newAge().Encrypt(recipients, io.Discard)
+		assert.Equal(t, expWriter, w)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrReadEntropyFailed error", func(t *testing.T) {
+		t.Parallel()
+		rng := NewStdRNG(FnStdRNG{
+			Read: func([]byte) (int, error) {
+				return 0, errors.New("")
+			},
+		})
+		age, _ := NewAge(rng, ageWorkFactor)
+		var expWriter io.WriteCloser = nil
+		const expErr = crypto.ErrReadEntropyFailed
+
+		w, err := age.Encrypt([][]byte{[]byte(ageRecipient)}, io.Discard)
+		assert.Equal(t, expWriter, w)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		age := newAge()
+		identity := newAgeIdentity()
+		recipient, _ := AgeRecipient(identity)
+		recipients := [][]byte{[]byte(ageRecipient), recipient}
+		plaintext := bytes.Repeat([]byte("API_KEY=1f3b6a90\n"), 8192)
+		buf := &bytes.Buffer{}
+
+		w, err := age.Encrypt(recipients, buf)
+		ageSeal(t, w, err, plaintext)
+		assert.True(t, strings.HasPrefix(buf.String(),
+			AgeVersion+"\n-> "+AgeX25519Type+" "))
+		opened, err := ageOpen(
+			age, []*crypto.Secret{identity}, nil, buf.Bytes())
+		assert.Equal(t, plaintext, opened)
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_Age_EncryptPassphrase(t *testing.T) {
+	t.Parallel()
+	passphrase, _ := crypto.NewSecretFrom([]byte("correct horse"))
+
+	t.Run("ErrReadEntropyFailed error", func(t *testing.T) {
+		t.Parallel()
+		rng := NewStdRNG(FnStdRNG{
+			Read: func([]byte) (int, error) {
+				return 0, errors.New("")
+			},
+		})
+		age, _ := NewAge(rng, ageWorkFactor)
+		var expWriter io.WriteCloser = nil
+		const expErr = crypto.ErrReadEntropyFailed
+
+		w, err := age.EncryptPassphrase(passphrase, io.Discard)
+		assert.Equal(t, expWriter, w)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		age := newAge()
+		plaintext := []byte("API_KEY=1f3b6a90\n")
+		buf := &bytes.Buffer{}
+
+		w, err := age.EncryptPassphrase(passphrase, buf)
+		ageSeal(t, w, err, plaintext)
+		assert.True(t, strings.HasPrefix(buf.String(),
+			AgeVersion+"\n-> "+AgeScryptType+" "))
+		opened, err := ageOpen(age, nil, passphrase, buf.Bytes())
+		assert.Equal(t, plaintext, opened)
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_Age_Decrypt(t *testing.T) {
+	t.Parallel()
+	age := newAge()
+	identity := newAgeIdentity()
+	recipient, _ := AgeRecipient(identity)
+	identities := []*crypto.Secret{identity}
+	passphrase, _ := crypto.NewSecretFrom([]byte("correct horse"))
+	plaintext := []byte("API_KEY=1f3b6a90\n")
+	sealed := &bytes.Buffer{}
+	w, err := age.Encrypt([][]byte{recipient}, sealed)
+	ageSeal(t, w, err, plaintext)
+	scryptSealed := &bytes.Buffer{}
+	w, err = age.EncryptPassphrase(passphrase, scryptSealed)
+	ageSeal(t, w, err, plaintext)
+	header := sealed.Bytes()[:bytes.Index(sealed.Bytes(), []byte("\n---"))+1]
+	scryptHeader := scryptSealed.Bytes()[:bytes.Index(
+		scryptSealed.Bytes(), []byte("\n---"))+1]
+
+	t.Run("ErrInvalidAgeHeader error", func(t *testing.T) {
+		t.Parallel()
+		buf := []byte("age-encryption.org/v2\n")
+		var expOpened []byte = nil
+		const expErr = crypto.ErrInvalidAgeHeader
+
+		opened, err := ageOpen(age, identities, nil, buf)
+		assert.Equal(t, expOpened, opened)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrInvalidAgeHeader value", func(t *testing.T) {
+		t.Parallel()
+		invalid := [][]byte{
+			[]byte(AgeVersion + "\n--- AAAA\n"),
+			[]byte(AgeVersion + "\n-> X25519\n"),
+			[]byte(AgeVersion + "\n->  X25519\n\n--- AAAA\n"),
+			[]byte(AgeVersion + "\n-> X25519 AAAA\n!!!!\n--- AAAA\n"),
+			[]byte(AgeVersion + "\n-> X25519 AAAA\n" +
+				strings.Repeat("A", AgeBodyColumns+4) + "\n--- AAAA\n"),
+			[]byte(AgeVersion + "\n-> X25519 AAAA\n\n--- AAAA\n"),
+			append(append([]byte{}, header...), scryptHeader[len(
+				AgeVersion)+1:]...),
+		}
+		var expOpened []byte = nil
+		const expErr = crypto.ErrInvalidAgeHeader
+
+		for _, buf := range invalid {
+			opened, err := ageOpen(age, identities, passphrase, buf)
+			assert.Equal(t, expOpened, opened, string(buf))
+			assert.ErrorIs(t, err, expErr, string(buf))
+		}
+	})
+	t.Run("ErrNoAgeIdentity error", func(t *testing.T) {
+		t.Parallel()
+		var expOpened []byte = nil
+		const expErr = crypto.ErrNoAgeIdentity
+
+		opened, err := ageOpen(age,
+			[]*crypto.Secret{newAgeIdentity()}, passphrase, sealed.Bytes())
+		assert.Equal(t, expOpened, opened)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrNoAgeIdentity value", func(t *testing.T) {
+		t.Parallel()
+		other, _ := crypto.NewSecretFrom([]byte("wrong horse"))
+		var expOpened []byte = nil
+		const expErr = crypto.ErrNoAgeIdentity
+
+		opened, err := ageOpen(age, identities, nil, scryptSealed.Bytes())
+		assert.Equal(t, expOpened, opened)
+		assert.ErrorIs(t, err, expErr)
+		opened, err = ageOpen(age, nil, other, scryptSealed.Bytes())
+		assert.Equal(t, expOpened, opened)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrAgeWorkFactor error", func(t *testing.T) {
+		t.Parallel()
+		fileKey, _ := age.fileKey()
+		stanza := ageStanza{
+			kind: AgeScryptType,
+			args: []string{"AAAAAAAAAAAAAAAAAAAAAA", "30"},
+			body: make([]byte, 32),
+		}
+		buf := &bytes.Buffer{}
+		w, err := age.seal([]ageStanza{stanza}, fileKey, buf)
+		ageSeal(t, w, err, plaintext)
+		var expOpened []byte = nil
+		const expErr = crypto.ErrAgeWorkFactor
+
+		opened, err := ageOpen(age, nil, passphrase, buf.Bytes())
+		assert.Equal(t, expOpened, opened)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrAgeHeaderMAC error", func(t *testing.T) {
+		t.Parallel()
+		tampered := append([]byte{}, sealed.Bytes()...)
+		start := len(header) + len("--- ")
+		end := start + bytes.IndexByte(tampered[start:], '\n')
+		mac, _ := ageBase64.DecodeString(string(tampered[start:end]))
+		mac[0] ^= 0x01
+		copy(tampered[start:end], ageBase64.EncodeToString(mac))
+		var expOpened []byte = nil
+		const expErr = crypto.ErrAgeHeaderMAC
+
+		opened, err := ageOpen(age, identities, nil, tampered)
+		assert.Equal(t, expOpened, opened)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrStreamTruncated error", func(t *testing.T) {
+		t.Parallel()
+		end := bytes.IndexByte(sealed.Bytes()[len(header):], '\n')
+		truncated := sealed.Bytes()[:len(header)+end+1]
+		var expOpened []byte = nil
+		const expErr = crypto.ErrStreamTruncated
+
+		opened, err := ageOpen(age, identities, nil, truncated)
+		assert.Equal(t, expOpened, opened)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrAuthFailed error", func(t *testing.T) {
+		t.Parallel()
+		tampered := append([]byte{}, sealed.Bytes()...)
+		tampered[len(tampered)-1] ^= 0x01
+		const expErr = crypto.ErrAuthFailed
+
+		_, err := ageOpen(age, identities, nil, tampered)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Unknown stanza value", func(t *testing.T) {
+		t.Parallel()
+		fileKey, _ := age.fileKey()
+		stanza, _ := age.x25519Stanza(recipient, fileKey)
+		unknown := ageStanza{
+			kind: "ssh-ed25519",
+			args: []string{"Xyz", "AAAA"},
+			body: bytes.Repeat([]byte{0x01}, 2*AgeBodyColumns),
+		}
+		buf := &bytes.Buffer{}
+		w, err := age.seal([]ageStanza{unknown, stanza}, fileKey, buf)
+		ageSeal(t, w, err, plaintext)
+
+		opened, err := ageOpen(age, identities, nil, buf.Bytes())
+		assert.Equal(t, plaintext, opened)
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func ageTestkitCase(t *testing.T, age Age[StdRNG], path string) {
+	vector := ageTestkit(t, path)
+	name := filepath.Base(path)
+	identities := []*crypto.Secret{}
+	for _, identity := range vector.identities {
+		parsed, err := ParseAgeIdentities([]byte(identity))
+		assert.ErrorIs(t, err, nil, name)
+		identities = append(identities, parsed...)
+	}
+	defer func() {
+		for _, identity := range identities {
+			identity.Destroy()
+		}
+	}()
+	var passphrase *crypto.Secret = nil
+	if vector.passphrase != "" {
+		passphrase, _ = crypto.NewSecretFrom([]byte(vector.passphrase))
+		defer passphrase.Destroy()
+	}
+	r, err := age.Decrypt(
+		identities, passphrase, bytes.NewReader(vector.file))
+	if err == nil {
+		defer r.Close()
+	}
+	switch vector.expect {
+	case "success":
+		if !assert.ErrorIs(t, err, nil, name) {
+			return
+		}
+		opened, err := io.ReadAll(r)
+		assert.ErrorIs(t, err, nil, name)
+		digest := sha256.Sum256(opened)
+		payload := hex.EncodeToString(digest[:])
+		assert.Equal(t, vector.payload, payload, name)
+	case "payload failure":
+		if !assert.ErrorIs(t, err, nil, name) {
+			return
+		}
+		_, err = io.ReadAll(r)
+		assert.NotNil(t, err, name)
+	case "no match":
+		assert.ErrorIs(t, err, crypto.ErrNoAgeIdentity, name)
+	case "HMAC failure":
+		assert.ErrorIs(t, err, crypto.ErrAgeHeaderMAC, name)
+	default:
+		assert.NotNil(t, err, name)
+		assert.NotErrorIs(t, err, crypto.ErrAgeHeaderMAC, name)
+	}
+}
+
+func Test_Age_Testkit(t *testing.T) {
+	t.Parallel()
+	age := newAge()
+	paths, _ := filepath.Glob(filepath.Join(ageTestkitDir, "*"))
+	assert.NotEmpty(t, paths)
+
+	for _, path := range paths {
+		ageTestkitCase(t, age, path)
+	}
+}
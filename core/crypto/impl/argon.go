@@ -1,25 +1,52 @@
 package crypto_impl
 
 import (
+	"github.com/reshifr/secure-env/core/crypto"
 	"golang.org/x/crypto/argon2"
 )
 
 const (
-	ArgonTime    = 7
-	ArgonMemory  = 65537
-	ArgonThreads = 7
+	ArgonTime      = 7
+	ArgonMemory    = 65537
+	ArgonThreads   = 7
+	ArgonAlgorithm = "argon2i"
 )
 
-type Argon struct{}
+type Argon struct {
+	params crypto.KDFParams
+}
+
+func NewArgon(params crypto.KDFParams) Argon {
+	params.Algorithm = ArgonAlgorithm
+	return Argon{params: params}
+}
 
-func (Argon) Key(passphrase []byte, salt []byte, keyLen uint32) []byte {
+func (kdf Argon) Params() crypto.KDFParams {
+	if kdf.params.Algorithm == ArgonAlgorithm {
+		return kdf.params
+	}
+	return crypto.KDFParams{
+		Algorithm: ArgonAlgorithm,
+		Time:      ArgonTime,
+		Memory:    ArgonMemory,
+		Threads:   ArgonThreads,
+	}
+}
+
+func argonKey(passphrase *crypto.Secret, salt []byte,
+	params crypto.KDFParams, keyLen uint32) (*crypto.Secret, error) {
 	key := argon2.Key(
-		passphrase,
+		passphrase.Bytes(),
 		salt,
-		ArgonTime,
-		ArgonMemory,
-		ArgonThreads,
+		params.Time,
+		params.Memory,
+		params.Threads,
 		keyLen,
 	)
-	return key
+	return crypto.NewSecretFrom(key)
+}
+
+func (kdf Argon) Key(passphrase *crypto.Secret,
+	salt []byte, keyLen uint32) (*crypto.Secret, error) {
+	return argonKey(passphrase, salt, kdf.Params(), keyLen)
 }
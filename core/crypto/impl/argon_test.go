@@ -4,9 +4,38 @@ import (
 	"encoding/hex"
 	"testing"
 
+	"github.com/reshifr/secure-env/core/crypto"
 	"github.com/stretchr/testify/assert"
 )
 
+func Test_Argon_Params(t *testing.T) {
+	t.Parallel()
+	kdf := Argon{}
+	expParams := crypto.KDFParams{
+		Algorithm: "argon2i",
+		Time:      ArgonTime,
+		Memory:    ArgonMemory,
+		Threads:   ArgonThreads,
+	}
+
+	params := kdf.Params()
+	assert.Equal(t, expParams, params)
+}
+
+func Test_NewArgon(t *testing.T) {
+	t.Parallel()
+	kdf := NewArgon(crypto.KDFParams{Time: 2, Memory: 256, Threads: 1})
+	expParams := crypto.KDFParams{
+		Algorithm: ArgonAlgorithm,
+		Time:      2,
+		Memory:    256,
+		Threads:   1,
+	}
+
+	params := kdf.Params()
+	assert.Equal(t, expParams, params)
+}
+
 func Test_Argon_Key(t *testing.T) {
 	t.Parallel()
 	kdf := Argon{}
@@ -15,16 +44,18 @@ func Test_Argon_Key(t *testing.T) {
 		t.Parallel()
 		expKey, _ := hex.DecodeString("5ec0f1251a896d18d4675829f916639f")
 
-		key := kdf.Key(nil, nil, 16)
-		assert.Equal(t, expKey, key)
+		key, err := kdf.Key(nil, nil, 16)
+		assert.Equal(t, expKey, key.Bytes())
+		assert.ErrorIs(t, err, nil)
 	})
 	t.Run("Filled input", func(t *testing.T) {
 		t.Parallel()
-		passphrase := []byte("+DF7Rc-X/MOYjkNj")
+		passphrase, _ := crypto.NewSecretFrom([]byte("+DF7Rc-X/MOYjkNj"))
 		salt, _ := hex.DecodeString("fe05fd6e139ceeb6b732fe6ea913aace")
 		expKey, _ := hex.DecodeString("4069e3739afe0c508914454de5bfe255")
 
-		key := kdf.Key(passphrase, salt, 16)
-		assert.Equal(t, expKey, key)
+		key, err := kdf.Key(passphrase, salt, 16)
+		assert.Equal(t, expKey, key.Bytes())
+		assert.ErrorIs(t, err, nil)
 	})
 }
@@ -0,0 +1,125 @@
+package crypto_impl
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+)
+
+var (
+	errBech32 = errors.New("invalid bech32 string")
+)
+
+func bech32Polymod(values []byte) uint32 {
+	generator := [5]uint32{
+		0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := range generator {
+			if (top>>i)&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+func bech32HRPExpand(hrp string) []byte {
+	values := make([]byte, 0, 2*len(hrp)+1)
+	for i := 0; i < len(hrp); i++ {
+		values = append(values, hrp[i]>>5)
+	}
+	values = append(values, 0)
+	for i := 0; i < len(hrp); i++ {
+		values = append(values, hrp[i]&31)
+	}
+	return values
+}
+
+func bech32ConvertBits(data []byte,
+	from uint, to uint, pad bool) ([]byte, error) {
+	var acc uint32
+	var bits uint
+	maxv := uint32(1)<<to - 1
+	out := make([]byte, 0, len(data)*int(from)/int(to)+1)
+	for _, b := range data {
+		if uint32(b)>>from != 0 {
+			return nil, errBech32
+		}
+		acc = acc<<from | uint32(b)
+		bits += from
+		for bits >= to {
+			bits -= to
+			out = append(out, byte(acc>>bits&maxv))
+		}
+	}
+	if pad {
+		if bits > 0 {
+			out = append(out, byte(acc<<(to-bits)&maxv))
+		}
+	} else if bits >= from || acc<<(to-bits)&maxv != 0 {
+		return nil, errBech32
+	}
+	return out, nil
+}
+
+func bech32Encode(hrp string, data []byte) (string, error) {
+	values, err := bech32ConvertBits(data, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+	lower := strings.ToLower(hrp)
+	polymod := bech32Polymod(append(append(
+		bech32HRPExpand(lower), values...), 0, 0, 0, 0, 0, 0)) ^ 1
+	var sb strings.Builder
+	sb.WriteString(lower)
+	sb.WriteByte('1')
+	for _, v := range values {
+		sb.WriteByte(bech32Charset[v])
+	}
+	for i := 0; i < 6; i++ {
+		sb.WriteByte(bech32Charset[polymod>>(5*(5-i))&31])
+	}
+	if hrp != lower {
+		return strings.ToUpper(sb.String()), nil
+	}
+	return sb.String(), nil
+}
+
+func bech32Decode(s string) (string, []byte, error) {
+	lower := strings.ToLower(s)
+	if lower != s && strings.ToUpper(s) != s {
+		return "", nil, errBech32
+	}
+	pos := strings.LastIndexByte(lower, '1')
+	if pos < 1 || pos+7 > len(lower) {
+		return "", nil, errBech32
+	}
+	hrp := lower[:pos]
+	for i := 0; i < len(hrp); i++ {
+		if hrp[i] < 33 || hrp[i] > 126 {
+			return "", nil, errBech32
+		}
+	}
+	values := make([]byte, 0, len(lower)-pos-1)
+	for i := pos + 1; i < len(lower); i++ {
+		v := strings.IndexByte(bech32Charset, lower[i])
+		if v < 0 {
+			return "", nil, errBech32
+		}
+		values = append(values, byte(v))
+	}
+	if bech32Polymod(append(bech32HRPExpand(hrp), values...)) != 1 {
+		return "", nil, errBech32
+	}
+	data, err := bech32ConvertBits(values[:len(values)-6], 5, 8, false)
+	if err != nil {
+		return "", nil, err
+	}
+	return hrp, data, nil
+}
@@ -0,0 +1,70 @@
+package crypto_impl
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_bech32Decode(t *testing.T) {
+	t.Parallel()
+	t.Run("errBech32 error", func(t *testing.T) {
+		t.Parallel()
+		invalid := []string{
+			"pzry9x0s0muk",
+			"1pzry9x0s0muk",
+			"x1b4n0q5v",
+			"li1dgmt3",
+			"A1G7SGD8",
+			"a12UEL5L",
+			"A12uEL5L",
+			"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxx",
+		}
+		for _, s := range invalid {
+			hrp, data, err := bech32Decode(s)
+			assert.Empty(t, hrp, s)
+			assert.Nil(t, data, s)
+			assert.ErrorIs(t, err, errBech32, s)
+		}
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		valid := []string{
+			"A12UEL5L",
+			"a12uel5l",
+			"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw",
+			"split1checkupstagehandshakeupstreamerranterredcaperred2y9e3w",
+			"?1ezyfcl",
+		}
+		for _, s := range valid {
+			_, _, err := bech32Decode(s)
+			assert.ErrorIs(t, err, nil, s)
+		}
+	})
+}
+
+func Test_bech32Encode(t *testing.T) {
+	t.Parallel()
+	t.Run("Lower value", func(t *testing.T) {
+		t.Parallel()
+		data := bytes.Repeat([]byte{0x42}, 32)
+
+		s, err := bech32Encode("age", data)
+		assert.ErrorIs(t, err, nil)
+		hrp, decoded, err := bech32Decode(s)
+		assert.Equal(t, "age", hrp)
+		assert.Equal(t, data, decoded)
+		assert.ErrorIs(t, err, nil)
+	})
+	t.Run("Upper value", func(t *testing.T) {
+		t.Parallel()
+		data := bytes.Repeat([]byte{0x42}, 32)
+		const expS = "AGE-SECRET-KEY-1GFPYYSJZGFPYYSJZGFPYYSJZ" +
+			"GFPYYSJZGFPYYSJZGFPYYSJZGFPQ4EGAEX"
+
+		s, err := bech32Encode("AGE-SECRET-KEY-", data)
+		assert.Equal(t, expS, s)
+		assert.ErrorIs(t, err, nil)
+	})
+}
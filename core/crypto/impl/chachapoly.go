@@ -1,7 +1,10 @@
 package crypto_impl
 
 import (
+	"crypto/cipher"
+
 	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/reshifr/secure-env/core/failure"
 	"golang.org/x/crypto/chacha20poly1305"
 )
 
@@ -16,35 +19,48 @@ func (ChaChaPoly) KeyLen() uint32 {
 	return ChaChaPolyKeyLen
 }
 
+func (ChaChaPoly) AEAD(key []byte) (cipher.AEAD, error) {
+	return chacha20poly1305.New(key)
+}
+
 func (ChaChaPoly) Seal(iv crypto.IV,
-	key []byte, plaintext []byte) ([]byte, error) {
+	key *crypto.Secret, plaintext []byte, ad []byte) ([]byte, error) {
 	if iv.Len() != ChaChaPolyIVLen {
 		return nil, crypto.ErrInvalidIVLen
 	}
-	chacha, err := chacha20poly1305.New(key)
+	chacha, err := chacha20poly1305.New(key.Bytes())
 	if err != nil {
-		return nil, crypto.ErrInvalidKeyLen
+		return nil, failure.New(crypto.OpSeal, crypto.ErrInvalidKeyLen, err)
 	}
 	rawIV := iv.Invoke()
-	ciphertext := chacha.Seal(nil, rawIV, plaintext, nil)
+	ciphertext := chacha.Seal(nil, rawIV, plaintext, ad)
 	buf := make([]byte, ChaChaPolyIVLen+len(ciphertext))
 	copy(buf, rawIV)
 	copy(buf[ChaChaPolyIVLen:], ciphertext)
 	return buf, nil
 }
 
-func (ChaChaPoly) Open(key []byte, buf []byte) ([]byte, error) {
+func (ChaChaPoly) Open(key *crypto.Secret,
+	buf []byte, ad []byte) (*crypto.Secret, error) {
 	if len(buf) < ChaChaPolyIVLen {
 		return nil, crypto.ErrInvalidBufLayout
 	}
-	chacha, err := chacha20poly1305.New(key)
+	chacha, err := chacha20poly1305.New(key.Bytes())
 	if err != nil {
-		return nil, crypto.ErrInvalidKeyLen
+		return nil, failure.New(crypto.OpOpen, crypto.ErrInvalidKeyLen, err)
 	}
 	rawIV := buf[:ChaChaPolyIVLen]
 	ciphertext := buf[ChaChaPolyIVLen:]
-	plaintext, err := chacha.Open(nil, rawIV, ciphertext, nil)
+	if len(ciphertext) < chacha.Overhead() {
+		return nil, crypto.ErrAuthFailed
+	}
+	plaintext, err := crypto.NewSecret(len(ciphertext) - chacha.Overhead())
+	if err != nil {
+		return nil, err
+	}
+	_, err = chacha.Open(plaintext.Bytes()[:0], rawIV, ciphertext, ad)
 	if err != nil {
+		plaintext.Destroy()
 		return nil, crypto.ErrAuthFailed
 	}
 	return plaintext, nil
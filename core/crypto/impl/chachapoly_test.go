@@ -31,7 +31,7 @@ func Test_ChaChaPoly_Seal(t *testing.T) {
 		var expBuf []byte = nil
 		const expErr = crypto.ErrInvalidIVLen
 
-		buf, err := cipher.Seal(iv, nil, nil)
+		buf, err := cipher.Seal(iv, nil, nil, nil)
 		assert.Equal(t, expBuf, buf)
 		assert.ErrorIs(t, err, expErr)
 	})
@@ -40,11 +40,11 @@ func Test_ChaChaPoly_Seal(t *testing.T) {
 		iv := cmock.NewIV(t)
 		iv.EXPECT().Len().Return(ChaChaPolyIVLen).Once()
 
-		key := bytes.Repeat([]byte{0x11}, 8)
+		key, _ := crypto.NewSecretFrom(bytes.Repeat([]byte{0x11}, 8))
 		var expBuf []byte = nil
 		const expErr = crypto.ErrInvalidKeyLen
 
-		buf, err := cipher.Seal(iv, key, nil)
+		buf, err := cipher.Seal(iv, key, nil, nil)
 		assert.Equal(t, expBuf, buf)
 		assert.ErrorIs(t, err, expErr)
 	})
@@ -53,9 +53,10 @@ func Test_ChaChaPoly_Seal(t *testing.T) {
 		iv := cmock.NewIV(t)
 		iv.EXPECT().Len().Return(ChaChaPolyIVLen).Once()
 
-		key, _ := hex.DecodeString(
+		rawKey, _ := hex.DecodeString(
 			"f1507d5e3f9e2fc69dce797acc3cf95c" +
 				"a5636a597c9a07becb81023bae55d00d")
+		key, _ := crypto.NewSecretFrom(rawKey)
 
 		rawIV, _ := hex.DecodeString("111111112222222222222222")
 		iv.EXPECT().Invoke().Return(rawIV).Once()
@@ -66,7 +67,7 @@ func Test_ChaChaPoly_Seal(t *testing.T) {
 				"f130ab55c66bdf03b0fcc8b70b")
 		expBuf := append(rawIV, ciphertext...)
 
-		buf, err := cipher.Seal(iv, key, plaintext)
+		buf, err := cipher.Seal(iv, key, plaintext, nil)
 		assert.Equal(t, buf, expBuf)
 		assert.ErrorIs(t, err, nil)
 	})
@@ -79,21 +80,21 @@ func Test_ChaChaPoly_Open(t *testing.T) {
 	t.Run("ErrInvalidBufLayout error", func(t *testing.T) {
 		t.Parallel()
 		buf := bytes.Repeat([]byte{0x22}, 8)
-		var expPlaintext []byte = nil
+		var expPlaintext *crypto.Secret = nil
 		const expErr = crypto.ErrInvalidBufLayout
 
-		plaintext, err := cipher.Open(nil, buf)
+		plaintext, err := cipher.Open(nil, buf, nil)
 		assert.Equal(t, expPlaintext, plaintext)
 		assert.ErrorIs(t, err, expErr)
 	})
 	t.Run("ErrInvalidKeyLen error", func(t *testing.T) {
 		t.Parallel()
-		key := bytes.Repeat([]byte{0x11}, 8)
+		key, _ := crypto.NewSecretFrom(bytes.Repeat([]byte{0x11}, 8))
 		buf := bytes.Repeat([]byte{0x22}, IV96Len)
-		var expPlaintext []byte = nil
+		var expPlaintext *crypto.Secret = nil
 		const expErr = crypto.ErrInvalidKeyLen
 
-		plaintext, err := cipher.Open(key, buf)
+		plaintext, err := cipher.Open(key, buf, nil)
 		assert.Equal(t, expPlaintext, plaintext)
 		assert.ErrorIs(t, err, expErr)
 	})
@@ -106,25 +107,40 @@ func Test_ChaChaPoly_Open(t *testing.T) {
 
 	t.Run("ErrAuthFailed error", func(t *testing.T) {
 		t.Parallel()
-		key, _ := hex.DecodeString(
+		rawKey, _ := hex.DecodeString(
 			"e4a1869b8db702549b4d0d69d5c0482c" +
 				"1a82a2e8fa7191c2ea7aaa2dbd2631b9")
-		var expPlaintext []byte = nil
+		key, _ := crypto.NewSecretFrom(rawKey)
+		var expPlaintext *crypto.Secret = nil
 		const expErr = crypto.ErrAuthFailed
 
-		plaintext, err := cipher.Open(key, buf)
+		plaintext, err := cipher.Open(key, buf, nil)
+		assert.Equal(t, expPlaintext, plaintext)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Associated data mismatch", func(t *testing.T) {
+		t.Parallel()
+		rawKey, _ := hex.DecodeString(
+			"c4fcdf96ba5fb52c72ad024d8b7eaeef" +
+				"b63e909b63ed92cf0fbf31fc71c6d704")
+		key, _ := crypto.NewSecretFrom(rawKey)
+		var expPlaintext *crypto.Secret = nil
+		const expErr = crypto.ErrAuthFailed
+
+		plaintext, err := cipher.Open(key, buf, []byte("prod\x00KEY"))
 		assert.Equal(t, expPlaintext, plaintext)
 		assert.ErrorIs(t, err, expErr)
 	})
 	t.Run("Succeed", func(t *testing.T) {
 		t.Parallel()
-		key, _ := hex.DecodeString(
+		rawKey, _ := hex.DecodeString(
 			"f1507d5e3f9e2fc69dce797acc3cf95c" +
 				"a5636a597c9a07becb81023bae55d00d")
+		key, _ := crypto.NewSecretFrom(rawKey)
 		expPlaintext := []byte("Hello, World!")
 
-		plaintext, err := cipher.Open(key, buf)
-		assert.Equal(t, expPlaintext, plaintext)
+		plaintext, err := cipher.Open(key, buf, nil)
+		assert.Equal(t, expPlaintext, plaintext.Bytes())
 		assert.ErrorIs(t, err, nil)
 	})
 }
@@ -0,0 +1,69 @@
+package crypto_impl
+
+import (
+	"crypto/ed25519"
+
+	"github.com/reshifr/secure-env/core/crypto"
+)
+
+const (
+	Ed25519SeedLen      = ed25519.SeedSize
+	Ed25519PublicKeyLen = ed25519.PublicKeySize
+)
+
+type Ed25519 struct {
+	seed      *crypto.Secret
+	publicKey []byte
+}
+
+type Ed25519Verifier struct{}
+
+func NewEd25519[RNG crypto.RNG](rng RNG) (Ed25519, error) {
+	seed, err := crypto.NewSecret(Ed25519SeedLen)
+	if err != nil {
+		return Ed25519{}, err
+	}
+	if err := rng.Read(seed.Bytes()); err != nil {
+		seed.Destroy()
+		return Ed25519{}, err
+	}
+	return LoadEd25519(seed)
+}
+
+func LoadEd25519(seed *crypto.Secret) (Ed25519, error) {
+	if seed.Len() != Ed25519SeedLen {
+		return Ed25519{}, crypto.ErrInvalidSeedLen
+	}
+	privateKey := ed25519.NewKeyFromSeed(seed.Bytes())
+	defer clear(privateKey)
+	publicKey := make([]byte, Ed25519PublicKeyLen)
+	copy(publicKey, privateKey.Public().(ed25519.PublicKey))
+	return Ed25519{seed: seed, publicKey: publicKey}, nil
+}
+
+func (signer Ed25519) Seed() *crypto.Secret {
+	return signer.seed
+}
+
+func (signer Ed25519) PublicKey() []byte {
+	return signer.publicKey
+}
+
+func (signer Ed25519) Sign(msg []byte) ([]byte, error) {
+	if signer.seed.Len() != Ed25519SeedLen {
+		return nil, crypto.ErrInvalidSeedLen
+	}
+	privateKey := ed25519.NewKeyFromSeed(signer.seed.Bytes())
+	defer clear(privateKey)
+	return ed25519.Sign(privateKey, msg), nil
+}
+
+func (Ed25519Verifier) Verify(publicKey []byte, msg []byte, sig []byte) error {
+	if len(publicKey) != Ed25519PublicKeyLen {
+		return crypto.ErrInvalidPublicKeyLen
+	}
+	if !ed25519.Verify(publicKey, msg, sig) {
+		return crypto.ErrInvalidSignature
+	}
+	return nil
+}
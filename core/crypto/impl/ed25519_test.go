@@ -0,0 +1,130 @@
+package crypto_impl
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	ed25519Seed = "9d61b19deffd5a60ba844af492ec2cc4" +
+		"4449c5697b326919703bac031cae7f60"
+	ed25519PublicKey = "d75a980182b10ab7d54bfed3c964073a" +
+		"0ee172f3daa62325af021a68f707511a"
+	ed25519Sig = "e5564300c360ac729086e2cc806e828a" +
+		"84877f1eb8e5d974d873e06522490155" +
+		"5fb8821590a33bacc61e39701cf9b46b" +
+		"d25bf5f0595bbe24655141438e7a100b"
+)
+
+func Test_NewEd25519(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrReadEntropyFailed error", func(t *testing.T) {
+		t.Parallel()
+		rng := NewStdRNG(FnStdRNG{
+			Read: func([]byte) (int, error) {
+				return 0, errors.New("")
+			},
+		})
+		expSigner := Ed25519{}
+		const expErr = crypto.ErrReadEntropyFailed
+
+		signer, err := NewEd25519(rng)
+		assert.Equal(t, expSigner, signer)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		seed, _ := hex.DecodeString(ed25519Seed)
+		rng := NewStdRNG(FnStdRNG{
+			Read: func(block []byte) (int, error) {
+				return copy(block, seed), nil
+			},
+		})
+		expPublicKey, _ := hex.DecodeString(ed25519PublicKey)
+
+		signer, err := NewEd25519(rng)
+		assert.Equal(t, seed, signer.Seed().Bytes())
+		assert.Equal(t, expPublicKey, signer.PublicKey())
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_LoadEd25519(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrInvalidSeedLen error", func(t *testing.T) {
+		t.Parallel()
+		seed, _ := crypto.NewSecretFrom([]byte{0x01})
+		expSigner := Ed25519{}
+		const expErr = crypto.ErrInvalidSeedLen
+
+		signer, err := LoadEd25519(seed)
+		assert.Equal(t, expSigner, signer)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		rawSeed, _ := hex.DecodeString(ed25519Seed)
+		seed, _ := crypto.NewSecretFrom(rawSeed)
+		expPublicKey, _ := hex.DecodeString(ed25519PublicKey)
+
+		signer, err := LoadEd25519(seed)
+		assert.Equal(t, expPublicKey, signer.PublicKey())
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_Ed25519_Sign(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrInvalidSeedLen error", func(t *testing.T) {
+		t.Parallel()
+		signer := Ed25519{}
+		var expSig []byte = nil
+		const expErr = crypto.ErrInvalidSeedLen
+
+		sig, err := signer.Sign(nil)
+		assert.Equal(t, expSig, sig)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		rawSeed, _ := hex.DecodeString(ed25519Seed)
+		seed, _ := crypto.NewSecretFrom(rawSeed)
+		signer, _ := LoadEd25519(seed)
+		expSig, _ := hex.DecodeString(ed25519Sig)
+
+		sig, err := signer.Sign(nil)
+		assert.Equal(t, expSig, sig)
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_Ed25519Verifier_Verify(t *testing.T) {
+	t.Parallel()
+	verifier := Ed25519Verifier{}
+	publicKey, _ := hex.DecodeString(ed25519PublicKey)
+	sig, _ := hex.DecodeString(ed25519Sig)
+
+	t.Run("ErrInvalidPublicKeyLen error", func(t *testing.T) {
+		t.Parallel()
+		const expErr = crypto.ErrInvalidPublicKeyLen
+
+		err := verifier.Verify(publicKey[1:], nil, sig)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrInvalidSignature error", func(t *testing.T) {
+		t.Parallel()
+		const expErr = crypto.ErrInvalidSignature
+
+		err := verifier.Verify(publicKey, []byte{0x00}, sig)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		err := verifier.Verify(publicKey, nil, sig)
+		assert.ErrorIs(t, err, nil)
+	})
+}
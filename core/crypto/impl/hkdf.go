@@ -0,0 +1,40 @@
+package crypto_impl
+
+import (
+	"crypto/sha256"
+	"io"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/reshifr/secure-env/core/failure"
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	HKDFAlgorithm = "hkdf-sha256"
+)
+
+type HKDF struct {
+	info []byte
+}
+
+func NewHKDF(info []byte) HKDF {
+	return HKDF{info: info}
+}
+
+func (HKDF) Params() crypto.KDFParams {
+	return crypto.KDFParams{Algorithm: HKDFAlgorithm}
+}
+
+func (kdf HKDF) Key(secret *crypto.Secret,
+	salt []byte, keyLen uint32) (*crypto.Secret, error) {
+	key, err := crypto.NewSecret(int(keyLen))
+	if err != nil {
+		return nil, err
+	}
+	r := hkdf.New(sha256.New, secret.Bytes(), salt, kdf.info)
+	if _, err := io.ReadFull(r, key.Bytes()); err != nil {
+		key.Destroy()
+		return nil, failure.New(crypto.OpDerive, crypto.ErrInvalidKeyLen, err)
+	}
+	return key, nil
+}
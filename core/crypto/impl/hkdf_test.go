@@ -0,0 +1,63 @@
+package crypto_impl
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewHKDF(t *testing.T) {
+	t.Parallel()
+	info := []byte("secure-env")
+	expKDF := HKDF{info: info}
+
+	kdf := NewHKDF(info)
+	assert.Equal(t, expKDF, kdf)
+}
+
+func Test_HKDF_Params(t *testing.T) {
+	t.Parallel()
+	kdf := NewHKDF(nil)
+	expParams := crypto.KDFParams{Algorithm: "hkdf-sha256"}
+
+	params := kdf.Params()
+	assert.Equal(t, expParams, params)
+}
+
+func Test_HKDF_Key(t *testing.T) {
+	t.Parallel()
+	t.Run("Empty input", func(t *testing.T) {
+		t.Parallel()
+		kdf := NewHKDF(nil)
+		expKey, _ := hex.DecodeString("eb70f01dede9afafa449eee1b1286504")
+
+		key, err := kdf.Key(nil, nil, 16)
+		assert.Equal(t, expKey, key.Bytes())
+		assert.ErrorIs(t, err, nil)
+	})
+	t.Run("Filled input", func(t *testing.T) {
+		t.Parallel()
+		kdf := NewHKDF([]byte("secure-env"))
+		rawSecret, _ := hex.DecodeString("c4fcdf96ba5fb52c72ad024d8b7eaeef")
+		secret, _ := crypto.NewSecretFrom(rawSecret)
+		salt := []byte("prod")
+		expKey, _ := hex.DecodeString(
+			"7e54e017e4993e93324f833e0d6ebff8" +
+				"38392dfd03cf2cd2364a54d136c5866b")
+
+		key, err := kdf.Key(secret, salt, 32)
+		assert.Equal(t, expKey, key.Bytes())
+		assert.ErrorIs(t, err, nil)
+	})
+	t.Run("ErrInvalidKeyLen error", func(t *testing.T) {
+		t.Parallel()
+		kdf := NewHKDF(nil)
+		var expKey *crypto.Secret = nil
+
+		key, err := kdf.Key(nil, nil, 255*32+1)
+		assert.Equal(t, expKey, key)
+		assert.ErrorIs(t, err, crypto.ErrInvalidKeyLen)
+	})
+}
@@ -0,0 +1,84 @@
+package crypto_impl
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"sync"
+
+	"github.com/reshifr/secure-env/core/crypto"
+)
+
+const (
+	HMACDRBGMinSeedLen    = 32
+	HMACDRBGMaxRequestLen = 1 << 16
+)
+
+type HMACDRBG struct {
+	mu  sync.Mutex
+	key []byte
+	v   []byte
+}
+
+func NewHMACDRBG(seed []byte, personalization []byte) (*HMACDRBG, error) {
+	if len(seed) < HMACDRBGMinSeedLen {
+		return nil, crypto.ErrShortSeed
+	}
+	drbg := &HMACDRBG{
+		key: make([]byte, sha256.Size),
+		v:   make([]byte, sha256.Size),
+	}
+	for i := range drbg.v {
+		drbg.v[i] = 0x01
+	}
+	drbg.update(seed, personalization)
+	return drbg, nil
+}
+
+func (drbg *HMACDRBG) mac(data ...[]byte) []byte {
+	mac := hmac.New(sha256.New, drbg.key)
+	for _, b := range data {
+		mac.Write(b)
+	}
+	return mac.Sum(nil)
+}
+
+func (drbg *HMACDRBG) update(data ...[]byte) {
+	provided := false
+	for _, b := range data {
+		provided = provided || len(b) > 0
+	}
+	drbg.key = drbg.mac(append([][]byte{drbg.v, {0x00}}, data...)...)
+	drbg.v = drbg.mac(drbg.v)
+	if !provided {
+		return
+	}
+	drbg.key = drbg.mac(append([][]byte{drbg.v, {0x01}}, data...)...)
+	drbg.v = drbg.mac(drbg.v)
+}
+
+func (drbg *HMACDRBG) generate(block []byte) {
+	for n := 0; n < len(block); n += len(drbg.v) {
+		drbg.v = drbg.mac(drbg.v)
+		copy(block[n:], drbg.v)
+	}
+	drbg.update()
+}
+
+func (drbg *HMACDRBG) Block(blockLen int) ([]byte, error) {
+	block := make([]byte, blockLen)
+	if err := drbg.Read(block); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+func (drbg *HMACDRBG) Read(block []byte) error {
+	drbg.mu.Lock()
+	defer drbg.mu.Unlock()
+	for len(block) > HMACDRBGMaxRequestLen {
+		drbg.generate(block[:HMACDRBGMaxRequestLen])
+		block = block[HMACDRBGMaxRequestLen:]
+	}
+	drbg.generate(block)
+	return nil
+}
@@ -0,0 +1,79 @@
+package crypto_impl
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewHMACDRBG(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrShortSeed error", func(t *testing.T) {
+		t.Parallel()
+		seed := make([]byte, HMACDRBGMinSeedLen-1)
+		var expDRBG *HMACDRBG = nil
+		const expErr = crypto.ErrShortSeed
+
+		drbg, err := NewHMACDRBG(seed, nil)
+		assert.Equal(t, expDRBG, drbg)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		seed := make([]byte, HMACDRBGMinSeedLen)
+
+		drbg, err := NewHMACDRBG(seed, nil)
+		assert.NotNil(t, drbg)
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_HMACDRBG_Block(t *testing.T) {
+	t.Parallel()
+	t.Run("Known answer", func(t *testing.T) {
+		t.Parallel()
+		entropy, _ := hex.DecodeString("ca851911349384bffe89de1cbdc46e68" +
+			"31e44d34a4fb935ee285dd14b71a7488")
+		nonce, _ := hex.DecodeString("659ba96c601dc69fc902940805ec0ca8")
+		expBlock, _ := hex.DecodeString("e528e9abf2dece54d47c7e75e5fe3021" +
+			"49f817ea9fb4bee6f4199697d04d5b89d54fbb978a15b5c443c9ec21036d2460" +
+			"b6f73ebad0dc2aba6e624abf07745bc107694bb7547bb0995f70de25d6b29e2d" +
+			"3011bb19d27676c07162c8b5ccde0668961df86803482cb37ed6d5c0bb8d50cf" +
+			"1f50d476aa0458bdaba806f48be9dcb8")
+
+		drbg, _ := NewHMACDRBG(append(entropy, nonce...), nil)
+		drbg.Block(len(expBlock))
+		block, err := drbg.Block(len(expBlock))
+		assert.Equal(t, expBlock, block)
+		assert.ErrorIs(t, err, nil)
+	})
+	t.Run("Personalization", func(t *testing.T) {
+		t.Parallel()
+		seed := bytes.Repeat([]byte{0x01}, HMACDRBGMinSeedLen)
+
+		drbg, _ := NewHMACDRBG(seed, nil)
+		personalized, _ := NewHMACDRBG(seed, []byte("secure-env"))
+		block, _ := drbg.Block(32)
+		personalizedBlock, _ := personalized.Block(32)
+		assert.NotEqual(t, block, personalizedBlock)
+	})
+}
+
+func Test_HMACDRBG_Read(t *testing.T) {
+	t.Parallel()
+	seed := bytes.Repeat([]byte{0x01}, HMACDRBGMinSeedLen)
+	drbg, _ := NewHMACDRBG(seed, nil)
+	expDRBG, _ := NewHMACDRBG(seed, nil)
+	expBlock := make([]byte, 2*HMACDRBGMaxRequestLen+1)
+	expDRBG.Read(expBlock[:HMACDRBGMaxRequestLen])
+	expDRBG.Read(expBlock[HMACDRBGMaxRequestLen : 2*HMACDRBGMaxRequestLen])
+	expDRBG.Read(expBlock[2*HMACDRBGMaxRequestLen:])
+
+	block := make([]byte, len(expBlock))
+	err := drbg.Read(block)
+	assert.Equal(t, expBlock, block)
+	assert.ErrorIs(t, err, nil)
+}
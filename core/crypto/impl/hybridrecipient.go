@@ -0,0 +1,184 @@
+package crypto_impl
+
+import (
+	"crypto/ecdh"
+	"crypto/mlkem"
+
+	"github.com/reshifr/secure-env/core/crypto"
+)
+
+const (
+	HybridRecipientKind      = "x25519-mlkem768"
+	HybridRecipientInfo      = "secure-env hybrid x25519-mlkem768 v1"
+	HybridX25519Len          = 32
+	HybridMLKEMSeedLen       = mlkem.SeedSize
+	HybridMLKEMPublicKeyLen  = mlkem.EncapsulationKeySize768
+	HybridMLKEMCiphertextLen = mlkem.CiphertextSize768
+	HybridIdentityLen        = HybridX25519Len + HybridMLKEMSeedLen
+	HybridPublicKeyLen       = HybridX25519Len + HybridMLKEMPublicKeyLen
+	HybridRecipientHeaderLen = HybridX25519Len + HybridMLKEMCiphertextLen
+	HybridSharedKeyLen       = 2 * mlkem.SharedKeySize
+)
+
+type HybridRecipient[RNG crypto.RNG, Cipher crypto.AE] struct {
+	rng    RNG
+	cipher Cipher
+	kdf    HKDF
+}
+
+func NewHybridRecipient[RNG crypto.RNG, Cipher crypto.AE](
+	rng RNG, cipher Cipher) HybridRecipient[RNG, Cipher] {
+	return HybridRecipient[RNG, Cipher]{
+		rng:    rng,
+		cipher: cipher,
+		kdf:    NewHKDF([]byte(HybridRecipientInfo)),
+	}
+}
+
+func NewHybridIdentity[RNG crypto.RNG](rng RNG) (*crypto.Secret, error) {
+	identity, err := crypto.NewSecret(HybridIdentityLen)
+	if err != nil {
+		return nil, err
+	}
+	if err := rng.Read(identity.Bytes()); err != nil {
+		identity.Destroy()
+		return nil, err
+	}
+	return identity, nil
+}
+
+func hybridKeys(identity *crypto.Secret) (
+	*ecdh.PrivateKey, *mlkem.DecapsulationKey768, error) {
+	if identity.Len() != HybridIdentityLen {
+		return nil, nil, crypto.ErrInvalidIdentity
+	}
+	x25519, err := ecdh.X25519().NewPrivateKey(
+		identity.Bytes()[:HybridX25519Len])
+	if err != nil {
+		return nil, nil, crypto.ErrInvalidIdentity
+	}
+	kem, err := mlkem.NewDecapsulationKey768(
+		identity.Bytes()[HybridX25519Len:])
+	if err != nil {
+		return nil, nil, crypto.ErrInvalidIdentity
+	}
+	return x25519, kem, nil
+}
+
+func HybridPublicKey(identity *crypto.Secret) ([]byte, error) {
+	x25519, kem, err := hybridKeys(identity)
+	if err != nil {
+		return nil, err
+	}
+	publicKey := make([]byte, 0, HybridPublicKeyLen)
+	publicKey = append(publicKey, x25519.PublicKey().Bytes()...)
+	publicKey = append(publicKey, kem.EncapsulationKey().Bytes()...)
+	return publicKey, nil
+}
+
+func (HybridRecipient[RNG, Cipher]) Kind() string {
+	return HybridRecipientKind
+}
+
+func (recipient HybridRecipient[RNG, Cipher]) wrapKey(
+	kemShared []byte,
+	x25519Shared []byte,
+	ephemeral []byte,
+	x25519PublicKey []byte) (*crypto.Secret, error) {
+	defer clear(kemShared)
+	defer clear(x25519Shared)
+	shared, err := crypto.NewSecret(HybridSharedKeyLen)
+	if err != nil {
+		return nil, err
+	}
+	defer shared.Destroy()
+	copy(shared.Bytes(), kemShared)
+	copy(shared.Bytes()[mlkem.SharedKeySize:], x25519Shared)
+	salt := make([]byte, 0, 2*HybridX25519Len)
+	salt = append(salt, ephemeral...)
+	salt = append(salt, x25519PublicKey...)
+	return recipient.kdf.Key(shared, salt, recipient.cipher.KeyLen())
+}
+
+func (recipient HybridRecipient[RNG, Cipher]) Wrap(
+	iv crypto.IV,
+	publicKey []byte,
+	accessKey *crypto.Secret) ([]byte, error) {
+	if len(publicKey) != HybridPublicKeyLen {
+		return nil, crypto.ErrInvalidRecipient
+	}
+	x25519PublicKey, err := ecdh.X25519().NewPublicKey(
+		publicKey[:HybridX25519Len])
+	if err != nil {
+		return nil, crypto.ErrInvalidRecipient
+	}
+	kemPublicKey, err := mlkem.NewEncapsulationKey768(
+		publicKey[HybridX25519Len:])
+	if err != nil {
+		return nil, crypto.ErrInvalidRecipient
+	}
+	seed, err := crypto.NewSecret(HybridX25519Len)
+	if err != nil {
+		return nil, err
+	}
+	defer seed.Destroy()
+	if err := recipient.rng.Read(seed.Bytes()); err != nil {
+		return nil, err
+	}
+	ephemeral, err := ecdh.X25519().NewPrivateKey(seed.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	x25519Shared, err := ephemeral.ECDH(x25519PublicKey)
+	if err != nil {
+		return nil, crypto.ErrInvalidRecipient
+	}
+	kemShared, ciphertext := kemPublicKey.Encapsulate()
+	key, err := recipient.wrapKey(kemShared, x25519Shared,
+		ephemeral.PublicKey().Bytes(), x25519PublicKey.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	defer key.Destroy()
+	buf, err := recipient.cipher.Seal(iv, key, accessKey.Bytes(), nil)
+	if err != nil {
+		return nil, err
+	}
+	block := make([]byte, 0, HybridRecipientHeaderLen+len(buf))
+	block = append(block, ephemeral.PublicKey().Bytes()...)
+	block = append(block, ciphertext...)
+	block = append(block, buf...)
+	return block, nil
+}
+
+func (recipient HybridRecipient[RNG, Cipher]) Unwrap(
+	identity *crypto.Secret, block []byte) (*crypto.Secret, error) {
+	x25519, kem, err := hybridKeys(identity)
+	if err != nil {
+		return nil, err
+	}
+	if len(block) < HybridRecipientHeaderLen {
+		return nil, crypto.ErrInvalidBlockLen
+	}
+	ephemeral, err := ecdh.X25519().NewPublicKey(block[:HybridX25519Len])
+	if err != nil {
+		return nil, crypto.ErrAuthFailed
+	}
+	x25519Shared, err := x25519.ECDH(ephemeral)
+	if err != nil {
+		return nil, crypto.ErrAuthFailed
+	}
+	kemShared, err := kem.Decapsulate(
+		block[HybridX25519Len:HybridRecipientHeaderLen])
+	if err != nil {
+		clear(x25519Shared)
+		return nil, crypto.ErrAuthFailed
+	}
+	key, err := recipient.wrapKey(kemShared, x25519Shared,
+		ephemeral.Bytes(), x25519.PublicKey().Bytes())
+	if err != nil {
+		return nil, err
+	}
+	defer key.Destroy()
+	return recipient.cipher.Open(key, block[HybridRecipientHeaderLen:], nil)
+}
@@ -0,0 +1,216 @@
+package crypto_impl
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	hybridX25519Scalar = "77076d0a7318a57d3c16c17251b26645" +
+		"df4c2f87ebc0992ab177fba51db92c2a"
+	hybridX25519PublicKey = "8520f0098930a754748b7ddcb43ef75a" +
+		"0dbf3a0d26381af4eba4a98eaa9b4e6a"
+)
+
+func newHybridIdentity() *crypto.Secret {
+	rng := NewStdRNG(FnStdRNG{Read: rand.Read})
+	identity, _ := NewHybridIdentity(rng)
+	return identity
+}
+
+func Test_NewHybridRecipient(t *testing.T) {
+	t.Parallel()
+	rng := NewStdRNG(FnStdRNG{})
+	cipher := ChaChaPoly{}
+	expRecipient := HybridRecipient[StdRNG, ChaChaPoly]{
+		rng:    rng,
+		cipher: cipher,
+		kdf:    NewHKDF([]byte(HybridRecipientInfo)),
+	}
+
+	recipient := NewHybridRecipient(rng, cipher)
+	assert.Equal(t, expRecipient, recipient)
+	assert.Equal(t, HybridRecipientKind, recipient.Kind())
+}
+
+func Test_NewHybridIdentity(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrReadEntropyFailed error", func(t *testing.T) {
+		t.Parallel()
+		rng := NewStdRNG(FnStdRNG{
+			Read: func([]byte) (int, error) {
+				return 0, errors.New("")
+			},
+		})
+		var expIdentity *crypto.Secret = nil
+		const expErr = crypto.ErrReadEntropyFailed
+
+		identity, err := NewHybridIdentity(rng)
+		assert.Equal(t, expIdentity, identity)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		rng := NewStdRNG(FnStdRNG{Read: counterRead})
+		expIdentity := make([]byte, HybridIdentityLen)
+		counterRead(expIdentity)
+
+		identity, err := NewHybridIdentity(rng)
+		assert.Equal(t, expIdentity, identity.Bytes())
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_HybridPublicKey(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrInvalidIdentity error", func(t *testing.T) {
+		t.Parallel()
+		identity, _ := crypto.NewSecretFrom([]byte{0x01})
+		var expPublicKey []byte = nil
+		const expErr = crypto.ErrInvalidIdentity
+
+		publicKey, err := HybridPublicKey(identity)
+		assert.Equal(t, expPublicKey, publicKey)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		rawIdentity := make([]byte, HybridIdentityLen)
+		scalar, _ := hex.DecodeString(hybridX25519Scalar)
+		copy(rawIdentity, scalar)
+		identity, _ := crypto.NewSecretFrom(rawIdentity)
+		expX25519PublicKey, _ := hex.DecodeString(hybridX25519PublicKey)
+
+		publicKey, err := HybridPublicKey(identity)
+		assert.Len(t, publicKey, HybridPublicKeyLen)
+		assert.Equal(t, expX25519PublicKey, publicKey[:HybridX25519Len])
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_HybridRecipient_Wrap(t *testing.T) {
+	t.Parallel()
+	accessKey, _ := crypto.NewSecretFrom([]byte("1f3b6a90d2c8e4a7"))
+
+	t.Run("ErrInvalidRecipient error", func(t *testing.T) {
+		t.Parallel()
+		rng := NewStdRNG(FnStdRNG{Read: rand.Read})
+		publicKey := make([]byte, HybridPublicKeyLen-1)
+		var expBlock []byte = nil
+		const expErr = crypto.ErrInvalidRecipient
+
+		recipient := NewHybridRecipient(rng, ChaChaPoly{})
+		block, err := recipient.Wrap(nil, publicKey, accessKey)
+		assert.Equal(t, expBlock, block)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrInvalidRecipient value", func(t *testing.T) {
+		t.Parallel()
+		rng := NewStdRNG(FnStdRNG{Read: rand.Read})
+		publicKey := make([]byte, HybridPublicKeyLen)
+		for i := range publicKey {
+			publicKey[i] = 0xff
+		}
+		var expBlock []byte = nil
+		const expErr = crypto.ErrInvalidRecipient
+
+		recipient := NewHybridRecipient(rng, ChaChaPoly{})
+		block, err := recipient.Wrap(nil, publicKey, accessKey)
+		assert.Equal(t, expBlock, block)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrReadEntropyFailed error", func(t *testing.T) {
+		t.Parallel()
+		rng := NewStdRNG(FnStdRNG{
+			Read: func([]byte) (int, error) {
+				return 0, errors.New("")
+			},
+		})
+		publicKey, _ := HybridPublicKey(newHybridIdentity())
+		var expBlock []byte = nil
+		const expErr = crypto.ErrReadEntropyFailed
+
+		recipient := NewHybridRecipient(rng, ChaChaPoly{})
+		block, err := recipient.Wrap(nil, publicKey, accessKey)
+		assert.Equal(t, expBlock, block)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		rng := NewStdRNG(FnStdRNG{Read: rand.Read})
+		rawIV, _ := rng.Block(IV96Len)
+		iv, _ := LoadIV96(rawIV)
+		identity := newHybridIdentity()
+		publicKey, _ := HybridPublicKey(identity)
+
+		recipient := NewHybridRecipient(rng, ChaChaPoly{})
+		block, err := recipient.Wrap(iv, publicKey, accessKey)
+		assert.ErrorIs(t, err, nil)
+		assert.Len(t, block, HybridRecipientHeaderLen+
+			ChaChaPolyIVLen+accessKey.Len()+16)
+		otherBlock, _ := recipient.Wrap(iv, publicKey, accessKey)
+		assert.NotEqual(t, block[:HybridRecipientHeaderLen],
+			otherBlock[:HybridRecipientHeaderLen])
+		unwrapped, err := recipient.Unwrap(identity, block)
+		assert.Equal(t, accessKey.Bytes(), unwrapped.Bytes())
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_HybridRecipient_Unwrap(t *testing.T) {
+	t.Parallel()
+	rng := NewStdRNG(FnStdRNG{Read: rand.Read})
+	rawIV, _ := rng.Block(IV96Len)
+	iv, _ := LoadIV96(rawIV)
+	accessKey, _ := crypto.NewSecretFrom([]byte("1f3b6a90d2c8e4a7"))
+	identity := newHybridIdentity()
+	publicKey, _ := HybridPublicKey(identity)
+	recipient := NewHybridRecipient(rng, ChaChaPoly{})
+	block, _ := recipient.Wrap(iv, publicKey, accessKey)
+
+	t.Run("ErrInvalidIdentity error", func(t *testing.T) {
+		t.Parallel()
+		identity, _ := crypto.NewSecretFrom([]byte{0x01})
+		var expAccessKey *crypto.Secret = nil
+		const expErr = crypto.ErrInvalidIdentity
+
+		accessKey, err := recipient.Unwrap(identity, block)
+		assert.Equal(t, expAccessKey, accessKey)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrInvalidBlockLen error", func(t *testing.T) {
+		t.Parallel()
+		var expAccessKey *crypto.Secret = nil
+		const expErr = crypto.ErrInvalidBlockLen
+
+		accessKey, err := recipient.Unwrap(
+			identity, block[:HybridRecipientHeaderLen-1])
+		assert.Equal(t, expAccessKey, accessKey)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrAuthFailed error", func(t *testing.T) {
+		t.Parallel()
+		var expAccessKey *crypto.Secret = nil
+		const expErr = crypto.ErrAuthFailed
+
+		accessKey, err := recipient.Unwrap(newHybridIdentity(), block)
+		assert.Equal(t, expAccessKey, accessKey)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrAuthFailed value", func(t *testing.T) {
+		t.Parallel()
+		tampered := append([]byte{}, block...)
+		tampered[HybridX25519Len] ^= 0x01
+		var expAccessKey *crypto.Secret = nil
+		const expErr = crypto.ErrAuthFailed
+
+		accessKey, err := recipient.Unwrap(identity, tampered)
+		assert.Equal(t, expAccessKey, accessKey)
+		assert.ErrorIs(t, err, expErr)
+	})
+}
@@ -0,0 +1,184 @@
+package crypto_impl
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	katDir            = "testdata/kat"
+	katKindAE         = "ae"
+	katKindKDF        = "kdf"
+	katKindAuthorizer = "authorizer"
+)
+
+type katHex []byte
+
+type katIV []byte
+
+type katVector struct {
+	Cipher     string
+	Params     crypto.KDFParams
+	Key        katHex
+	IV         katHex
+	AAD        katHex
+	Plaintext  katHex
+	Ciphertext katHex
+	Tag        katHex
+	Secret     katHex
+	Salt       katHex
+	Info       katHex
+	Block      katHex
+}
+
+type katFile struct {
+	Kind      string
+	Algorithm string
+	Source    string
+	Vectors   []katVector
+}
+
+func (b *katHex) UnmarshalText(text []byte) error {
+	buf, err := hex.DecodeString(string(text))
+	*b = buf
+	return err
+}
+
+func (iv katIV) Len() uint32 {
+	return uint32(len(iv))
+}
+
+func (iv katIV) Invoke() []byte {
+	return bytes.Clone(iv)
+}
+
+func katFiles(t *testing.T) []katFile {
+	paths, _ := filepath.Glob(filepath.Join(katDir, "*.json"))
+	files := []katFile{}
+	for _, path := range paths {
+		buf, err := os.ReadFile(path)
+		assert.ErrorIs(t, err, nil)
+		file := katFile{}
+		assert.ErrorIs(t, json.Unmarshal(buf, &file), nil, path)
+		files = append(files, file)
+	}
+	return files
+}
+
+func katSecret(b []byte) *crypto.Secret {
+	secret, _ := crypto.NewSecretFrom(bytes.Clone(b))
+	return secret
+}
+
+func Test_KAT_Coverage(t *testing.T) {
+	t.Parallel()
+	covered := map[string]int{}
+	for _, file := range katFiles(t) {
+		covered[file.Kind+"/"+file.Algorithm] += len(file.Vectors)
+	}
+	for _, name := range katCipherAlgorithms() {
+		assert.NotZero(t, covered[katKindAE+"/"+name], name)
+	}
+	for _, name := range katKDFAlgorithms() {
+		assert.NotZero(t, covered[katKindKDF+"/"+name], name)
+	}
+	assert.NotZero(t, covered[katKindAuthorizer+"/"+RoleAuthorizerKind])
+}
+
+func Test_KAT_AE(t *testing.T) {
+	t.Parallel()
+	for _, file := range katFiles(t) {
+		if file.Kind != katKindAE {
+			continue
+		}
+		cipher, ok := katLookupCipher(file.Algorithm)
+		if !assert.True(t, ok, file.Algorithm) {
+			continue
+		}
+		for i, vector := range file.Vectors {
+			expBuf := append(bytes.Clone(vector.Ciphertext), vector.Tag...)
+			aead, err := cipher.AEAD(vector.Key)
+			assert.ErrorIs(t, err, nil)
+			buf := aead.Seal(nil, vector.IV, vector.Plaintext, vector.AAD)
+			assert.Equal(t, expBuf, buf, "%s #%d", file.Algorithm, i)
+			plaintext, err := aead.Open(nil, vector.IV, buf, vector.AAD)
+			assert.Equal(t, []byte(vector.Plaintext),
+				append([]byte{}, plaintext...))
+			assert.ErrorIs(t, err, nil)
+			key := katSecret(vector.Key)
+			expBuf = append(bytes.Clone(vector.IV), expBuf...)
+			buf, err = cipher.Seal(
+				katIV(vector.IV), key, vector.Plaintext, vector.AAD)
+			assert.Equal(t, expBuf, buf, "%s #%d", file.Algorithm, i)
+			assert.ErrorIs(t, err, nil)
+			opened, err := cipher.Open(key, buf, vector.AAD)
+			assert.Equal(t, []byte(vector.Plaintext),
+				append([]byte{}, opened.Bytes()...))
+			assert.ErrorIs(t, err, nil)
+			buf[len(buf)-1] ^= 0x01
+			_, err = cipher.Open(key, buf, vector.AAD)
+			assert.ErrorIs(t, err, crypto.ErrAuthFailed)
+		}
+	}
+}
+
+func Test_KAT_KDF(t *testing.T) {
+	t.Parallel()
+	for _, file := range katFiles(t) {
+		if file.Kind != katKindKDF {
+			continue
+		}
+		for i, vector := range file.Vectors {
+			kdf, ok := katLookupKDF(vector.Params, vector.Info)
+			if !assert.True(t, ok, file.Algorithm) {
+				continue
+			}
+			assert.Equal(t, file.Algorithm, kdf.Params().Algorithm)
+			key, err := kdf.Key(katSecret(vector.Secret),
+				vector.Salt, uint32(len(vector.Key)))
+			assert.Equal(t, []byte(vector.Key), key.Bytes(),
+				"%s #%d", file.Algorithm, i)
+			assert.ErrorIs(t, err, nil)
+		}
+	}
+}
+
+func Test_KAT_RoleAuthorizer(t *testing.T) {
+	t.Parallel()
+	for _, file := range katFiles(t) {
+		if file.Kind != katKindAuthorizer {
+			continue
+		}
+		for i, vector := range file.Vectors {
+			cipher, ok := katLookupCipher(vector.Cipher)
+			if !assert.True(t, ok, vector.Cipher) {
+				continue
+			}
+			kdf, ok := katLookupKDF(vector.Params, vector.Info)
+			if !assert.True(t, ok, vector.Params.Algorithm) {
+				continue
+			}
+			authorizer := NewRoleAuthorizer(kdf, StdRNG{}, cipher)
+			passphrase := katSecret(vector.Secret)
+			salt := [RoleAuthorizerSaltLen]byte{}
+			copy(salt[:], vector.Salt)
+
+			block, err := authorizer.sealAccessKey(
+				katIV(vector.IV), passphrase, salt, katSecret(vector.Key))
+			assert.Equal(t, []byte(vector.Block), block,
+				"%s #%d", vector.Cipher, i)
+			assert.ErrorIs(t, err, nil)
+			accessKey, err := authorizer.Open(
+				vector.Params, passphrase, vector.Block)
+			assert.Equal(t, []byte(vector.Key), accessKey.Bytes())
+			assert.ErrorIs(t, err, nil)
+		}
+	}
+}
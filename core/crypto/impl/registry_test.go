@@ -0,0 +1,124 @@
+package crypto_impl
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+// The known-answer suite looks every implementation up here, and
+// Test_KAT_Coverage fails for any entry without vectors.
+const (
+	katChaChaPoly = "chacha20-poly1305"
+	katAESGCM     = "aes-256-gcm"
+)
+
+var (
+	katCiphers = map[string]StreamCipher{
+		katChaChaPoly: ChaChaPoly{},
+		katAESGCM:     AESGCM{},
+	}
+	katKDFs = map[string]func(params crypto.KDFParams, info []byte) crypto.KDF{
+		ArgonAlgorithm: func(params crypto.KDFParams, _ []byte) crypto.KDF {
+			return NewArgon(params)
+		},
+		HKDFAlgorithm: func(_ crypto.KDFParams, info []byte) crypto.KDF {
+			return NewHKDF(info)
+		},
+	}
+)
+
+func katCipherAlgorithms() []string {
+	return slices.Sorted(maps.Keys(katCiphers))
+}
+
+func katLookupCipher(algorithm string) (StreamCipher, bool) {
+	cipher, ok := katCiphers[algorithm]
+	return cipher, ok
+}
+
+func katKDFAlgorithms() []string {
+	return slices.Sorted(maps.Keys(katKDFs))
+}
+
+func katLookupKDF(params crypto.KDFParams, info []byte) (crypto.KDF, bool) {
+	newKDF, ok := katKDFs[params.Algorithm]
+	if !ok {
+		return nil, false
+	}
+	return newKDF(params, info), true
+}
+
+func Test_katCipherAlgorithms(t *testing.T) {
+	t.Parallel()
+	expAlgorithms := []string{katAESGCM, katChaChaPoly}
+
+	algorithms := katCipherAlgorithms()
+	assert.Equal(t, expAlgorithms, algorithms)
+}
+
+func Test_katLookupCipher(t *testing.T) {
+	t.Parallel()
+	t.Run("Unknown algorithm", func(t *testing.T) {
+		t.Parallel()
+		var expCipher StreamCipher = nil
+
+		cipher, ok := katLookupCipher("des")
+		assert.Equal(t, expCipher, cipher)
+		assert.False(t, ok)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		var expCipher StreamCipher = ChaChaPoly{}
+
+		cipher, ok := katLookupCipher(katChaChaPoly)
+		assert.Equal(t, expCipher, cipher)
+		assert.True(t, ok)
+	})
+}
+
+func Test_katKDFAlgorithms(t *testing.T) {
+	t.Parallel()
+	expAlgorithms := []string{ArgonAlgorithm, HKDFAlgorithm}
+
+	algorithms := katKDFAlgorithms()
+	assert.Equal(t, expAlgorithms, algorithms)
+}
+
+func Test_katLookupKDF(t *testing.T) {
+	t.Parallel()
+	t.Run("Unknown algorithm", func(t *testing.T) {
+		t.Parallel()
+		params := crypto.KDFParams{Algorithm: "scrypt"}
+		var expKDF crypto.KDF = nil
+
+		kdf, ok := katLookupKDF(params, nil)
+		assert.Equal(t, expKDF, kdf)
+		assert.False(t, ok)
+	})
+	t.Run("Argon params", func(t *testing.T) {
+		t.Parallel()
+		params := crypto.KDFParams{
+			Algorithm: ArgonAlgorithm,
+			Time:      2,
+			Memory:    256,
+			Threads:   1,
+		}
+
+		kdf, ok := katLookupKDF(params, nil)
+		assert.Equal(t, params, kdf.Params())
+		assert.True(t, ok)
+	})
+	t.Run("HKDF info", func(t *testing.T) {
+		t.Parallel()
+		params := crypto.KDFParams{Algorithm: HKDFAlgorithm}
+		var expKDF crypto.KDF = NewHKDF([]byte("secure-env"))
+
+		kdf, ok := katLookupKDF(params, []byte("secure-env"))
+		assert.Equal(t, expKDF, kdf)
+		assert.True(t, ok)
+	})
+}
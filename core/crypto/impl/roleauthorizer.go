@@ -6,6 +6,7 @@ import (
 
 const (
 	RoleAuthorizerSaltLen = 16
+	RoleAuthorizerKind    = "passphrase"
 )
 
 type RoleAuthorizer[
@@ -29,13 +30,26 @@ func NewRoleAuthorizer[
 	}
 }
 
+func (RoleAuthorizer[KDF, RNG, Cipher]) Kind() string {
+	return RoleAuthorizerKind
+}
+
+func (authorizer RoleAuthorizer[KDF, RNG, Cipher]) KDFParams() crypto.KDFParams {
+	return authorizer.kdf.Params()
+}
+
 func (authorizer RoleAuthorizer[KDF, RNG, Cipher]) sealAccessKey(
 	iv crypto.IV,
-	passphrase []byte,
+	passphrase *crypto.Secret,
 	salt [RoleAuthorizerSaltLen]byte,
-	accessKey []byte) (block []byte, err error) {
-	key := authorizer.kdf.Key(passphrase, salt[:], authorizer.cipher.KeyLen())
-	buf, err := authorizer.cipher.Seal(iv, key, accessKey)
+	accessKey *crypto.Secret) (block []byte, err error) {
+	key, err := authorizer.kdf.Key(
+		passphrase, salt[:], authorizer.cipher.KeyLen())
+	if err != nil {
+		return nil, err
+	}
+	defer key.Destroy()
+	buf, err := authorizer.cipher.Seal(iv, key, accessKey.Bytes(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -46,28 +60,63 @@ func (authorizer RoleAuthorizer[KDF, RNG, Cipher]) sealAccessKey(
 }
 
 func (authorizer RoleAuthorizer[KDF, RNG, Cipher]) Make(
-	iv crypto.IV, passphrase []byte, keyLen uint32) ([]byte, []byte, error) {
-	accessKey, err := authorizer.rng.Block(int(keyLen))
+	iv crypto.IV,
+	passphrase *crypto.Secret,
+	keyLen uint32) (*crypto.Secret, []byte, error) {
+	accessKey, err := crypto.NewSecret(int(keyLen))
 	if err != nil {
 		return nil, nil, err
 	}
+	if err := authorizer.rng.Read(accessKey.Bytes()); err != nil {
+		accessKey.Destroy()
+		return nil, nil, err
+	}
 	salt := [RoleAuthorizerSaltLen]byte{}
 	if err := authorizer.rng.Read(salt[:]); err != nil {
+		accessKey.Destroy()
 		return nil, nil, err
 	}
 	block, err := authorizer.sealAccessKey(iv, passphrase, salt, accessKey)
-	return accessKey, block, err
+	if err != nil {
+		accessKey.Destroy()
+		return nil, nil, err
+	}
+	return accessKey, block, nil
+}
+
+// slotKDF returns the KDF a block was sealed with. Slots that predate
+// recorded parameters use the authorizer's own KDF.
+func (authorizer RoleAuthorizer[KDF, RNG, Cipher]) slotKDF(
+	params crypto.KDFParams) (crypto.KDF, error) {
+	if params.Algorithm == "" {
+		return authorizer.kdf, nil
+	}
+	if params.Algorithm != ArgonAlgorithm || params.Time < 1 ||
+		params.Threads < 1 || params.Memory < 8*uint32(params.Threads) {
+		return nil, crypto.ErrInvalidKDFParams
+	}
+	return NewArgon(params), nil
 }
 
 func (authorizer RoleAuthorizer[KDF, RNG, Cipher]) Open(
-	passphrase []byte, block []byte) ([]byte, error) {
+	params crypto.KDFParams,
+	passphrase *crypto.Secret,
+	block []byte) (*crypto.Secret, error) {
 	if len(block) < RoleAuthorizerSaltLen {
 		return nil, crypto.ErrInvalidBlockLen
 	}
+	kdf, err := authorizer.slotKDF(params)
+	if err != nil {
+		return nil, err
+	}
 	salt := block[:RoleAuthorizerSaltLen]
 	buf := block[RoleAuthorizerSaltLen:]
-	key := authorizer.kdf.Key(passphrase, salt, authorizer.cipher.KeyLen())
-	accessKey, err := authorizer.cipher.Open(key, buf)
+	key, err := kdf.Key(passphrase, salt, authorizer.cipher.KeyLen())
+	if err != nil {
+		return nil, err
+	}
+	defer key.Destroy()
+	accessKey, err := authorizer.cipher.Open(key, buf, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -76,18 +125,24 @@ func (authorizer RoleAuthorizer[KDF, RNG, Cipher]) Open(
 
 func (authorizer RoleAuthorizer[KDF, RNG, Cipher]) Inherit(
 	iv crypto.IV,
-	passphrase []byte,
-	childPassphrase []byte,
-	block []byte) ([]byte, []byte, error) {
-	accessKey, err := authorizer.Open(passphrase, block)
+	params crypto.KDFParams,
+	passphrase *crypto.Secret,
+	childPassphrase *crypto.Secret,
+	block []byte) (*crypto.Secret, []byte, error) {
+	accessKey, err := authorizer.Open(params, passphrase, block)
 	if err != nil {
 		return nil, nil, err
 	}
 	salt := [RoleAuthorizerSaltLen]byte{}
 	if err := authorizer.rng.Read(salt[:]); err != nil {
+		accessKey.Destroy()
 		return nil, nil, err
 	}
 	childBlock, err := authorizer.sealAccessKey(
 		iv, childPassphrase, salt, accessKey)
-	return accessKey, childBlock, err
+	if err != nil {
+		accessKey.Destroy()
+		return nil, nil, err
+	}
+	return accessKey, childBlock, nil
 }
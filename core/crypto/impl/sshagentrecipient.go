@@ -0,0 +1,149 @@
+package crypto_impl
+
+import (
+	"encoding/binary"
+	"net"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/reshifr/secure-env/core/failure"
+	"golang.org/x/crypto/ssh"
+	sshagent "golang.org/x/crypto/ssh/agent"
+)
+
+const (
+	SSHAgentRecipientKind = "ssh-agent-ed25519"
+	SSHAgentRecipientInfo = "secure-env ssh-agent v1"
+	SSHAgentChallengeLen  = 32
+	SSHAuthSockEnv        = "SSH_AUTH_SOCK"
+)
+
+type SSHAgent interface {
+	Sign(key ssh.PublicKey, data []byte) (sig *ssh.Signature, err error)
+}
+
+type SSHAgentRecipient[
+	Agent SSHAgent,
+	RNG crypto.RNG,
+	Cipher crypto.AE] struct {
+	agent  Agent
+	rng    RNG
+	cipher Cipher
+	kdf    HKDF
+}
+
+type FnSSHAgent struct {
+	LookupEnv func(key string) (value string, ok bool)
+	Dial      func(network string, address string) (conn net.Conn, err error)
+}
+
+func DialSSHAgent(fn FnSSHAgent) (sshagent.ExtendedAgent, net.Conn, error) {
+	path, ok := fn.LookupEnv(SSHAuthSockEnv)
+	if !ok || path == "" {
+		return nil, nil, crypto.ErrAgentFailed
+	}
+	conn, err := fn.Dial("unix", path)
+	if err != nil {
+		return nil, nil, failure.New(crypto.OpOpen, crypto.ErrAgentFailed, err)
+	}
+	return sshagent.NewClient(conn), conn, nil
+}
+
+func NewSSHAgentRecipient[
+	Agent SSHAgent,
+	RNG crypto.RNG,
+	Cipher crypto.AE](
+	agent Agent,
+	rng RNG,
+	cipher Cipher) SSHAgentRecipient[Agent, RNG, Cipher] {
+	return SSHAgentRecipient[Agent, RNG, Cipher]{
+		agent:  agent,
+		rng:    rng,
+		cipher: cipher,
+		kdf:    NewHKDF([]byte(SSHAgentRecipientInfo)),
+	}
+}
+
+func (SSHAgentRecipient[Agent, RNG, Cipher]) Kind() string {
+	return SSHAgentRecipientKind
+}
+
+func (recipient SSHAgentRecipient[Agent, RNG, Cipher]) wrapKey(
+	op string,
+	publicKey ssh.PublicKey,
+	salt []byte) (*crypto.Secret, error) {
+	challenge := append([]byte(SSHAgentRecipientInfo), salt...)
+	sig, err := recipient.agent.Sign(publicKey, challenge)
+	if err != nil {
+		return nil, failure.New(op, crypto.ErrAgentFailed, err)
+	}
+	if err := publicKey.Verify(challenge, sig); err != nil {
+		return nil, failure.New(op, crypto.ErrAgentFailed, err)
+	}
+	ikm, err := crypto.NewSecretFrom(sig.Blob)
+	if err != nil {
+		return nil, err
+	}
+	defer ikm.Destroy()
+	return recipient.kdf.Key(ikm, salt, recipient.cipher.KeyLen())
+}
+
+func (recipient SSHAgentRecipient[Agent, RNG, Cipher]) Wrap(
+	iv crypto.IV,
+	publicKey []byte,
+	accessKey *crypto.Secret) ([]byte, error) {
+	sshPublicKey, err := parseSSHPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	if sshPublicKey.Type() != ssh.KeyAlgoED25519 {
+		return nil, crypto.ErrUnsupportedKey
+	}
+	salt := make([]byte, SSHAgentChallengeLen)
+	if err := recipient.rng.Read(salt); err != nil {
+		return nil, err
+	}
+	key, err := recipient.wrapKey(crypto.OpSeal, sshPublicKey, salt)
+	if err != nil {
+		return nil, err
+	}
+	defer key.Destroy()
+	buf, err := recipient.cipher.Seal(iv, key, accessKey.Bytes(), nil)
+	if err != nil {
+		return nil, err
+	}
+	wire := sshPublicKey.Marshal()
+	block := make([]byte, 2, 2+len(wire)+len(salt)+len(buf))
+	binary.BigEndian.PutUint16(block, uint16(len(wire)))
+	block = append(block, wire...)
+	block = append(block, salt...)
+	block = append(block, buf...)
+	return block, nil
+}
+
+func (recipient SSHAgentRecipient[Agent, RNG, Cipher]) Unwrap(
+	_ *crypto.Secret, block []byte) (*crypto.Secret, error) {
+	if len(block) < 2 {
+		return nil, crypto.ErrInvalidBlockLen
+	}
+	wireLen := int(binary.BigEndian.Uint16(block))
+	if len(block) < 2+wireLen+SSHAgentChallengeLen {
+		return nil, crypto.ErrInvalidBlockLen
+	}
+	publicKey, err := ssh.ParsePublicKey(block[2 : 2+wireLen])
+	if err != nil {
+		return nil, crypto.ErrAuthFailed
+	}
+	// Only Ed25519 signatures are deterministic, so only they can
+	// reproduce the key that Wrap derived.
+	if publicKey.Type() != ssh.KeyAlgoED25519 {
+		return nil, crypto.ErrUnsupportedKey
+	}
+	salt := block[2+wireLen : 2+wireLen+SSHAgentChallengeLen]
+	key, err := recipient.wrapKey(crypto.OpOpen, publicKey, salt)
+	if err != nil {
+		return nil, err
+	}
+	defer key.Destroy()
+	buf := block[2+wireLen+SSHAgentChallengeLen:]
+	return recipient.cipher.Open(key, buf, nil)
+}
@@ -0,0 +1,241 @@
+package crypto_impl
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/binary"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+	sshagent "golang.org/x/crypto/ssh/agent"
+)
+
+func sshKeyring(keys ...any) sshagent.Agent {
+	keyring := sshagent.NewKeyring()
+	for _, key := range keys {
+		keyring.Add(sshagent.AddedKey{PrivateKey: key})
+	}
+	return keyring
+}
+
+func Test_DialSSHAgent(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrAgentFailed error", func(t *testing.T) {
+		t.Parallel()
+		fn := FnSSHAgent{
+			LookupEnv: func(string) (string, bool) {
+				return "", false
+			},
+		}
+		const expErr = crypto.ErrAgentFailed
+
+		agent, conn, err := DialSSHAgent(fn)
+		assert.Nil(t, agent)
+		assert.Nil(t, conn)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrAgentFailed value", func(t *testing.T) {
+		t.Parallel()
+		dialErr := errors.New("connection refused")
+		fn := FnSSHAgent{
+			LookupEnv: func(string) (string, bool) {
+				return "/tmp/ssh-agent.sock", true
+			},
+			Dial: func(string, string) (net.Conn, error) {
+				return nil, dialErr
+			},
+		}
+		const expErr = crypto.ErrAgentFailed
+
+		agent, conn, err := DialSSHAgent(fn)
+		assert.Nil(t, agent)
+		assert.Nil(t, conn)
+		assert.ErrorIs(t, err, expErr)
+		assert.ErrorIs(t, err, dialErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		client, server := net.Pipe()
+		go sshagent.ServeAgent(sshKeyring(sshEd25519Key()), server)
+		fn := FnSSHAgent{
+			LookupEnv: func(key string) (string, bool) {
+				return "/tmp/ssh-agent.sock", key == SSHAuthSockEnv
+			},
+			Dial: func(network string, address string) (net.Conn, error) {
+				assert.Equal(t, "unix", network)
+				assert.Equal(t, "/tmp/ssh-agent.sock", address)
+				return client, nil
+			},
+		}
+
+		agent, conn, err := DialSSHAgent(fn)
+		assert.ErrorIs(t, err, nil)
+		keys, err := agent.List()
+		assert.Len(t, keys, 1)
+		assert.ErrorIs(t, err, nil)
+		conn.Close()
+	})
+}
+
+func Test_NewSSHAgentRecipient(t *testing.T) {
+	t.Parallel()
+	agent := sshKeyring()
+	rng := NewStdRNG(FnStdRNG{})
+	cipher := ChaChaPoly{}
+	expRecipient := SSHAgentRecipient[sshagent.Agent, StdRNG, ChaChaPoly]{
+		agent:  agent,
+		rng:    rng,
+		cipher: cipher,
+		kdf:    NewHKDF([]byte(SSHAgentRecipientInfo)),
+	}
+
+	recipient := NewSSHAgentRecipient(agent, rng, cipher)
+	assert.Equal(t, expRecipient, recipient)
+	assert.Equal(t, SSHAgentRecipientKind, recipient.Kind())
+}
+
+func Test_SSHAgentRecipient_Wrap(t *testing.T) {
+	t.Parallel()
+	accessKey, _ := crypto.NewSecretFrom([]byte("1f3b6a90d2c8e4a7"))
+	key := sshEd25519Key()
+	authorizedKey := sshAuthorizedKey(key.Public())
+
+	t.Run("ErrInvalidRecipient error", func(t *testing.T) {
+		t.Parallel()
+		rng := NewStdRNG(FnStdRNG{Read: rand.Read})
+		var expBlock []byte = nil
+		const expErr = crypto.ErrInvalidRecipient
+
+		recipient := NewSSHAgentRecipient(sshKeyring(key), rng, ChaChaPoly{})
+		block, err := recipient.Wrap(nil, []byte("ssh-ed25519"), accessKey)
+		assert.Equal(t, expBlock, block)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrUnsupportedKey error", func(t *testing.T) {
+		t.Parallel()
+		rng := NewStdRNG(FnStdRNG{Read: rand.Read})
+		rsaKey, _ := rsa.GenerateKey(rand.Reader, SSHRSAMinBits)
+		var expBlock []byte = nil
+		const expErr = crypto.ErrUnsupportedKey
+
+		recipient := NewSSHAgentRecipient(
+			sshKeyring(rsaKey), rng, ChaChaPoly{})
+		block, err := recipient.Wrap(
+			nil, sshAuthorizedKey(rsaKey.Public()), accessKey)
+		assert.Equal(t, expBlock, block)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrReadEntropyFailed error", func(t *testing.T) {
+		t.Parallel()
+		rng := NewStdRNG(FnStdRNG{
+			Read: func([]byte) (int, error) {
+				return 0, errors.New("")
+			},
+		})
+		var expBlock []byte = nil
+		const expErr = crypto.ErrReadEntropyFailed
+
+		recipient := NewSSHAgentRecipient(sshKeyring(key), rng, ChaChaPoly{})
+		block, err := recipient.Wrap(nil, authorizedKey, accessKey)
+		assert.Equal(t, expBlock, block)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrAgentFailed error", func(t *testing.T) {
+		t.Parallel()
+		rng := NewStdRNG(FnStdRNG{Read: rand.Read})
+		var expBlock []byte = nil
+		const expErr = crypto.ErrAgentFailed
+
+		recipient := NewSSHAgentRecipient(sshKeyring(), rng, ChaChaPoly{})
+		block, err := recipient.Wrap(nil, authorizedKey, accessKey)
+		assert.Equal(t, expBlock, block)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		rng := NewStdRNG(FnStdRNG{Read: rand.Read})
+		rawIV, _ := rng.Block(IV96Len)
+		iv, _ := LoadIV96(rawIV)
+
+		recipient := NewSSHAgentRecipient(sshKeyring(key), rng, ChaChaPoly{})
+		block, err := recipient.Wrap(iv, authorizedKey, accessKey)
+		assert.ErrorIs(t, err, nil)
+		unwrapped, err := recipient.Unwrap(nil, block)
+		assert.Equal(t, accessKey.Bytes(), unwrapped.Bytes())
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_SSHAgentRecipient_Unwrap(t *testing.T) {
+	t.Parallel()
+	rng := NewStdRNG(FnStdRNG{Read: rand.Read})
+	rawIV, _ := rng.Block(IV96Len)
+	iv, _ := LoadIV96(rawIV)
+	accessKey, _ := crypto.NewSecretFrom([]byte("1f3b6a90d2c8e4a7"))
+	key := sshEd25519Key()
+	recipient := NewSSHAgentRecipient(sshKeyring(key), rng, ChaChaPoly{})
+	block, _ := recipient.Wrap(
+		iv, sshAuthorizedKey(key.Public()), accessKey)
+
+	t.Run("ErrInvalidBlockLen error", func(t *testing.T) {
+		t.Parallel()
+		var expAccessKey *crypto.Secret = nil
+		const expErr = crypto.ErrInvalidBlockLen
+
+		accessKey, err := recipient.Unwrap(nil, block[:1])
+		assert.Equal(t, expAccessKey, accessKey)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrInvalidBlockLen value", func(t *testing.T) {
+		t.Parallel()
+		var expAccessKey *crypto.Secret = nil
+		const expErr = crypto.ErrInvalidBlockLen
+
+		accessKey, err := recipient.Unwrap(nil, block[:40])
+		assert.Equal(t, expAccessKey, accessKey)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrUnsupportedKey error", func(t *testing.T) {
+		t.Parallel()
+		rsaKey, _ := rsa.GenerateKey(rand.Reader, SSHRSAMinBits)
+		rsaPublicKey, _ := ssh.NewPublicKey(rsaKey.Public())
+		wire := rsaPublicKey.Marshal()
+		rsaBlock := binary.BigEndian.AppendUint16(nil, uint16(len(wire)))
+		rsaBlock = append(rsaBlock, wire...)
+		rsaBlock = append(rsaBlock,
+			block[2+binary.BigEndian.Uint16(block):]...)
+		var expAccessKey *crypto.Secret = nil
+		const expErr = crypto.ErrUnsupportedKey
+
+		recipient := NewSSHAgentRecipient(
+			sshKeyring(rsaKey), rng, ChaChaPoly{})
+		accessKey, err := recipient.Unwrap(nil, rsaBlock)
+		assert.Equal(t, expAccessKey, accessKey)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrAgentFailed error", func(t *testing.T) {
+		t.Parallel()
+		var expAccessKey *crypto.Secret = nil
+		const expErr = crypto.ErrAgentFailed
+
+		recipient := NewSSHAgentRecipient(sshKeyring(), rng, ChaChaPoly{})
+		accessKey, err := recipient.Unwrap(nil, block)
+		assert.Equal(t, expAccessKey, accessKey)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrAuthFailed error", func(t *testing.T) {
+		t.Parallel()
+		tampered := append([]byte{}, block...)
+		tampered[len(tampered)-1] ^= 0x01
+		var expAccessKey *crypto.Secret = nil
+		const expErr = crypto.ErrAuthFailed
+
+		accessKey, err := recipient.Unwrap(nil, tampered)
+		assert.Equal(t, expAccessKey, accessKey)
+		assert.ErrorIs(t, err, expErr)
+	})
+}
@@ -0,0 +1,284 @@
+package crypto_impl
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"errors"
+
+	"filippo.io/edwards25519"
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/reshifr/secure-env/core/failure"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	SSHEd25519RecipientKind = "ssh-ed25519"
+	SSHEd25519RecipientInfo = "secure-env ssh-ed25519 v1"
+	SSHEd25519IdentityLen   = ed25519.SeedSize
+	SSHRSARecipientKind     = "ssh-rsa-oaep"
+	SSHRSARecipientLabel    = "secure-env ssh-rsa-oaep v1"
+	SSHRSAMinBits           = 2048
+)
+
+type SSHEd25519Recipient[RNG crypto.RNG, Cipher crypto.AE] struct {
+	rng    RNG
+	cipher Cipher
+	kdf    HKDF
+}
+
+type SSHRSARecipient[RNG crypto.RNG] struct {
+	rng RNG
+}
+
+type rngReader[RNG crypto.RNG] struct {
+	rng RNG
+}
+
+func NewSSHEd25519Recipient[RNG crypto.RNG, Cipher crypto.AE](
+	rng RNG, cipher Cipher) SSHEd25519Recipient[RNG, Cipher] {
+	return SSHEd25519Recipient[RNG, Cipher]{
+		rng:    rng,
+		cipher: cipher,
+		kdf:    NewHKDF([]byte(SSHEd25519RecipientInfo)),
+	}
+}
+
+func NewSSHRSARecipient[RNG crypto.RNG](rng RNG) SSHRSARecipient[RNG] {
+	return SSHRSARecipient[RNG]{rng: rng}
+}
+
+func (reader rngReader[RNG]) Read(block []byte) (int, error) {
+	if err := reader.rng.Read(block); err != nil {
+		return 0, err
+	}
+	return len(block), nil
+}
+
+func parseSSHPublicKey(authorizedKey []byte) (ssh.PublicKey, error) {
+	publicKey, _, _, _, err := ssh.ParseAuthorizedKey(authorizedKey)
+	if err != nil {
+		return nil, failure.New(crypto.OpSeal, crypto.ErrInvalidRecipient, err)
+	}
+	return publicKey, nil
+}
+
+func SSHRecipientKind(authorizedKey []byte) (string, error) {
+	publicKey, err := parseSSHPublicKey(authorizedKey)
+	if err != nil {
+		return "", err
+	}
+	switch publicKey.Type() {
+	case ssh.KeyAlgoED25519:
+		return SSHEd25519RecipientKind, nil
+	case ssh.KeyAlgoRSA:
+		return SSHRSARecipientKind, nil
+	default:
+		return "", crypto.ErrUnsupportedKey
+	}
+}
+
+func LoadSSHIdentity(pemBytes []byte,
+	passphrase *crypto.Secret) (*crypto.Secret, string, error) {
+	var key any
+	var err error
+	if passphrase == nil {
+		key, err = ssh.ParseRawPrivateKey(pemBytes)
+	} else {
+		key, err = ssh.ParseRawPrivateKeyWithPassphrase(
+			pemBytes, passphrase.Bytes())
+	}
+	missing := &ssh.PassphraseMissingError{}
+	switch {
+	case errors.As(err, &missing):
+		return nil, "", crypto.ErrIdentityLocked
+	case errors.Is(err, x509.IncorrectPasswordError):
+		return nil, "", crypto.ErrAuthFailed
+	case err != nil:
+		return nil, "",
+			failure.New(crypto.OpOpen, crypto.ErrInvalidIdentity, err)
+	}
+	switch key := key.(type) {
+	case *ed25519.PrivateKey:
+		defer clear(*key)
+		identity, err := crypto.NewSecretFrom(key.Seed())
+		return identity, SSHEd25519RecipientKind, err
+	case *rsa.PrivateKey:
+		defer clearRSAKey(key)
+		der := x509.MarshalPKCS1PrivateKey(key)
+		defer clear(der)
+		identity, err := crypto.NewSecretFrom(der)
+		return identity, SSHRSARecipientKind, err
+	default:
+		return nil, "", crypto.ErrUnsupportedKey
+	}
+}
+
+func ed25519X25519(publicKey ed25519.PublicKey) ([]byte, error) {
+	point, err := new(edwards25519.Point).SetBytes(publicKey)
+	if err != nil || !bytes.Equal(point.Bytes(), publicKey) {
+		return nil, crypto.ErrInvalidRecipient
+	}
+	if point.Equal(edwards25519.NewIdentityPoint()) == 1 {
+		return nil, crypto.ErrInvalidRecipient
+	}
+	return point.BytesMontgomery(), nil
+}
+
+func clearRSAKey(key *rsa.PrivateKey) {
+	clear(key.D.Bits())
+	for _, prime := range key.Primes {
+		clear(prime.Bits())
+	}
+	if key.Precomputed.Dp != nil {
+		clear(key.Precomputed.Dp.Bits())
+		clear(key.Precomputed.Dq.Bits())
+		clear(key.Precomputed.Qinv.Bits())
+	}
+}
+
+func (SSHEd25519Recipient[RNG, Cipher]) Kind() string {
+	return SSHEd25519RecipientKind
+}
+
+func (recipient SSHEd25519Recipient[RNG, Cipher]) wrapKey(
+	shared []byte,
+	ephemeral []byte,
+	x25519PublicKey []byte) (*crypto.Secret, error) {
+	ikm, err := crypto.NewSecretFrom(shared)
+	if err != nil {
+		return nil, err
+	}
+	defer ikm.Destroy()
+	salt := make([]byte, 0, 2*HybridX25519Len)
+	salt = append(salt, ephemeral...)
+	salt = append(salt, x25519PublicKey...)
+	return recipient.kdf.Key(ikm, salt, recipient.cipher.KeyLen())
+}
+
+func (recipient SSHEd25519Recipient[RNG, Cipher]) Wrap(
+	iv crypto.IV,
+	publicKey []byte,
+	accessKey *crypto.Secret) ([]byte, error) {
+	sshPublicKey, err := parseSSHPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	if sshPublicKey.Type() != ssh.KeyAlgoED25519 {
+		return nil, crypto.ErrUnsupportedKey
+	}
+	edPublicKey := sshPublicKey.(ssh.CryptoPublicKey).
+		CryptoPublicKey().(ed25519.PublicKey)
+	rawX25519, err := ed25519X25519(edPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	x25519PublicKey, err := ecdh.X25519().NewPublicKey(rawX25519)
+	if err != nil {
+		return nil, crypto.ErrInvalidRecipient
+	}
+	seed, err := crypto.NewSecret(HybridX25519Len)
+	if err != nil {
+		return nil, err
+	}
+	defer seed.Destroy()
+	if err := recipient.rng.Read(seed.Bytes()); err != nil {
+		return nil, err
+	}
+	ephemeral, err := ecdh.X25519().NewPrivateKey(seed.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	shared, err := ephemeral.ECDH(x25519PublicKey)
+	if err != nil {
+		return nil, crypto.ErrInvalidRecipient
+	}
+	key, err := recipient.wrapKey(
+		shared, ephemeral.PublicKey().Bytes(), rawX25519)
+	if err != nil {
+		return nil, err
+	}
+	defer key.Destroy()
+	buf, err := recipient.cipher.Seal(iv, key, accessKey.Bytes(), nil)
+	if err != nil {
+		return nil, err
+	}
+	block := make([]byte, 0, HybridX25519Len+len(buf))
+	block = append(block, ephemeral.PublicKey().Bytes()...)
+	block = append(block, buf...)
+	return block, nil
+}
+
+func (recipient SSHEd25519Recipient[RNG, Cipher]) Unwrap(
+	identity *crypto.Secret, block []byte) (*crypto.Secret, error) {
+	if identity.Len() != SSHEd25519IdentityLen {
+		return nil, crypto.ErrInvalidIdentity
+	}
+	if len(block) < HybridX25519Len {
+		return nil, crypto.ErrInvalidBlockLen
+	}
+	digest := sha512.Sum512(identity.Bytes())
+	defer clear(digest[:])
+	x25519, err := ecdh.X25519().NewPrivateKey(digest[:HybridX25519Len])
+	if err != nil {
+		return nil, crypto.ErrInvalidIdentity
+	}
+	ephemeral, err := ecdh.X25519().NewPublicKey(block[:HybridX25519Len])
+	if err != nil {
+		return nil, crypto.ErrAuthFailed
+	}
+	shared, err := x25519.ECDH(ephemeral)
+	if err != nil {
+		return nil, crypto.ErrAuthFailed
+	}
+	key, err := recipient.wrapKey(
+		shared, ephemeral.Bytes(), x25519.PublicKey().Bytes())
+	if err != nil {
+		return nil, err
+	}
+	defer key.Destroy()
+	return recipient.cipher.Open(key, block[HybridX25519Len:], nil)
+}
+
+func (SSHRSARecipient[RNG]) Kind() string {
+	return SSHRSARecipientKind
+}
+
+func (recipient SSHRSARecipient[RNG]) Wrap(
+	iv crypto.IV,
+	publicKey []byte,
+	accessKey *crypto.Secret) ([]byte, error) {
+	sshPublicKey, err := parseSSHPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	if sshPublicKey.Type() != ssh.KeyAlgoRSA {
+		return nil, crypto.ErrUnsupportedKey
+	}
+	rsaPublicKey := sshPublicKey.(ssh.CryptoPublicKey).
+		CryptoPublicKey().(*rsa.PublicKey)
+	if rsaPublicKey.N.BitLen() < SSHRSAMinBits {
+		return nil, crypto.ErrUnsupportedKey
+	}
+	return rsa.EncryptOAEP(sha256.New(), rngReader[RNG]{rng: recipient.rng},
+		rsaPublicKey, accessKey.Bytes(), []byte(SSHRSARecipientLabel))
+}
+
+func (SSHRSARecipient[RNG]) Unwrap(
+	identity *crypto.Secret, block []byte) (*crypto.Secret, error) {
+	privateKey, err := x509.ParsePKCS1PrivateKey(identity.Bytes())
+	if err != nil {
+		return nil, crypto.ErrInvalidIdentity
+	}
+	defer clearRSAKey(privateKey)
+	accessKey, err := rsa.DecryptOAEP(sha256.New(), nil,
+		privateKey, block, []byte(SSHRSARecipientLabel))
+	if err != nil {
+		return nil, crypto.ErrAuthFailed
+	}
+	return crypto.NewSecretFrom(accessKey)
+}
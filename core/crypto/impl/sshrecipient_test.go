@@ -0,0 +1,465 @@
+package crypto_impl
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+)
+
+func sshEd25519Key() ed25519.PrivateKey {
+	seed, _ := hex.DecodeString(ed25519Seed)
+	return ed25519.NewKeyFromSeed(seed)
+}
+
+func sshAuthorizedKey(key any) []byte {
+	publicKey, _ := ssh.NewPublicKey(key)
+	return ssh.MarshalAuthorizedKey(publicKey)
+}
+
+func sshPrivateKey(key any, passphrase string) []byte {
+	if passphrase == "" {
+		block, _ := ssh.MarshalPrivateKey(key, "")
+		return pem.EncodeToMemory(block)
+	}
+	block, _ := ssh.MarshalPrivateKeyWithPassphrase(
+		key, "", []byte(passphrase))
+	return pem.EncodeToMemory(block)
+}
+
+func Test_NewSSHEd25519Recipient(t *testing.T) {
+	t.Parallel()
+	rng := NewStdRNG(FnStdRNG{})
+	cipher := ChaChaPoly{}
+	expRecipient := SSHEd25519Recipient[StdRNG, ChaChaPoly]{
+		rng:    rng,
+		cipher: cipher,
+		kdf:    NewHKDF([]byte(SSHEd25519RecipientInfo)),
+	}
+
+	recipient := NewSSHEd25519Recipient(rng, cipher)
+	assert.Equal(t, expRecipient, recipient)
+	assert.Equal(t, SSHEd25519RecipientKind, recipient.Kind())
+}
+
+func Test_NewSSHRSARecipient(t *testing.T) {
+	t.Parallel()
+	rng := NewStdRNG(FnStdRNG{})
+	expRecipient := SSHRSARecipient[StdRNG]{rng: rng}
+
+	recipient := NewSSHRSARecipient(rng)
+	assert.Equal(t, expRecipient, recipient)
+	assert.Equal(t, SSHRSARecipientKind, recipient.Kind())
+}
+
+func Test_SSHRecipientKind(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrInvalidRecipient error", func(t *testing.T) {
+		t.Parallel()
+		const expKind = ""
+		const expErr = crypto.ErrInvalidRecipient
+
+		kind, err := SSHRecipientKind([]byte("ssh-ed25519 AAAA"))
+		assert.Equal(t, expKind, kind)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrUnsupportedKey error", func(t *testing.T) {
+		t.Parallel()
+		key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		const expKind = ""
+		const expErr = crypto.ErrUnsupportedKey
+
+		kind, err := SSHRecipientKind(sshAuthorizedKey(key.Public()))
+		assert.Equal(t, expKind, kind)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		key := sshEd25519Key()
+		const expKind = SSHEd25519RecipientKind
+
+		kind, err := SSHRecipientKind(sshAuthorizedKey(key.Public()))
+		assert.Equal(t, expKind, kind)
+		assert.ErrorIs(t, err, nil)
+	})
+	t.Run("SSHRSARecipientKind value", func(t *testing.T) {
+		t.Parallel()
+		key, _ := rsa.GenerateKey(rand.Reader, SSHRSAMinBits)
+		const expKind = SSHRSARecipientKind
+
+		kind, err := SSHRecipientKind(sshAuthorizedKey(key.Public()))
+		assert.Equal(t, expKind, kind)
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_LoadSSHIdentity(t *testing.T) {
+	t.Parallel()
+	key := sshEd25519Key()
+	passphrase, _ := crypto.NewSecretFrom([]byte("+DF7Rc-X/MOYjkNj"))
+
+	t.Run("ErrInvalidIdentity error", func(t *testing.T) {
+		t.Parallel()
+		var expIdentity *crypto.Secret = nil
+		const expKind = ""
+		const expErr = crypto.ErrInvalidIdentity
+
+		identity, kind, err := LoadSSHIdentity([]byte("id_ed25519"), nil)
+		assert.Equal(t, expIdentity, identity)
+		assert.Equal(t, expKind, kind)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrIdentityLocked error", func(t *testing.T) {
+		t.Parallel()
+		pemBytes := sshPrivateKey(key, "+DF7Rc-X/MOYjkNj")
+		var expIdentity *crypto.Secret = nil
+		const expKind = ""
+		const expErr = crypto.ErrIdentityLocked
+
+		identity, kind, err := LoadSSHIdentity(pemBytes, nil)
+		assert.Equal(t, expIdentity, identity)
+		assert.Equal(t, expKind, kind)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrAuthFailed error", func(t *testing.T) {
+		t.Parallel()
+		pemBytes := sshPrivateKey(key, "q7!Lw2#zR9@pXe4v")
+		var expIdentity *crypto.Secret = nil
+		const expKind = ""
+		const expErr = crypto.ErrAuthFailed
+
+		identity, kind, err := LoadSSHIdentity(pemBytes, passphrase)
+		assert.Equal(t, expIdentity, identity)
+		assert.Equal(t, expKind, kind)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrUnsupportedKey error", func(t *testing.T) {
+		t.Parallel()
+		ecdsaKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		pemBytes := sshPrivateKey(ecdsaKey, "")
+		var expIdentity *crypto.Secret = nil
+		const expKind = ""
+		const expErr = crypto.ErrUnsupportedKey
+
+		identity, kind, err := LoadSSHIdentity(pemBytes, nil)
+		assert.Equal(t, expIdentity, identity)
+		assert.Equal(t, expKind, kind)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		pemBytes := sshPrivateKey(key, "")
+		const expKind = SSHEd25519RecipientKind
+
+		identity, kind, err := LoadSSHIdentity(pemBytes, nil)
+		assert.Equal(t, key.Seed(), identity.Bytes())
+		assert.Equal(t, expKind, kind)
+		assert.ErrorIs(t, err, nil)
+	})
+	t.Run("Passphrase value", func(t *testing.T) {
+		t.Parallel()
+		pemBytes := sshPrivateKey(key, "+DF7Rc-X/MOYjkNj")
+		const expKind = SSHEd25519RecipientKind
+
+		identity, kind, err := LoadSSHIdentity(pemBytes, passphrase)
+		assert.Equal(t, key.Seed(), identity.Bytes())
+		assert.Equal(t, expKind, kind)
+		assert.ErrorIs(t, err, nil)
+	})
+	t.Run("SSHRSARecipientKind value", func(t *testing.T) {
+		t.Parallel()
+		rsaKey, _ := rsa.GenerateKey(rand.Reader, SSHRSAMinBits)
+		pemBytes := sshPrivateKey(rsaKey, "")
+		const expKind = SSHRSARecipientKind
+
+		identity, kind, err := LoadSSHIdentity(pemBytes, nil)
+		privateKey, _ := x509.ParsePKCS1PrivateKey(identity.Bytes())
+		assert.True(t, rsaKey.Equal(privateKey))
+		assert.Equal(t, expKind, kind)
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_ed25519X25519(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrInvalidRecipient error", func(t *testing.T) {
+		t.Parallel()
+		publicKey := make([]byte, ed25519.PublicKeySize)
+		for i := range publicKey {
+			publicKey[i] = 0xff
+		}
+		var expX25519 []byte = nil
+		const expErr = crypto.ErrInvalidRecipient
+
+		x25519, err := ed25519X25519(publicKey)
+		assert.Equal(t, expX25519, x25519)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrInvalidRecipient value", func(t *testing.T) {
+		t.Parallel()
+		publicKey := make([]byte, ed25519.PublicKeySize)
+		publicKey[0] = 0x01
+		var expX25519 []byte = nil
+		const expErr = crypto.ErrInvalidRecipient
+
+		x25519, err := ed25519X25519(publicKey)
+		assert.Equal(t, expX25519, x25519)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		key := sshEd25519Key()
+		digest := sha512.Sum512(key.Seed())
+		x25519Key, _ := ecdh.X25519().NewPrivateKey(digest[:32])
+		expX25519 := x25519Key.PublicKey().Bytes()
+
+		x25519, err := ed25519X25519(key.Public().(ed25519.PublicKey))
+		assert.Equal(t, expX25519, x25519)
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_clearRSAKey(t *testing.T) {
+	t.Parallel()
+	key, _ := rsa.GenerateKey(rand.Reader, SSHRSAMinBits)
+	key.Precompute()
+	ints := []*big.Int{
+		key.D,
+		key.Primes[0],
+		key.Primes[1],
+		key.Precomputed.Dp,
+		key.Precomputed.Dq,
+		key.Precomputed.Qinv,
+	}
+
+	clearRSAKey(key)
+	for _, n := range ints {
+		for _, word := range n.Bits() {
+			assert.Zero(t, word)
+		}
+	}
+}
+
+func Test_SSHEd25519Recipient_Wrap(t *testing.T) {
+	t.Parallel()
+	accessKey, _ := crypto.NewSecretFrom([]byte("1f3b6a90d2c8e4a7"))
+	key := sshEd25519Key()
+	authorizedKey := sshAuthorizedKey(key.Public())
+
+	t.Run("ErrInvalidRecipient error", func(t *testing.T) {
+		t.Parallel()
+		rng := NewStdRNG(FnStdRNG{Read: rand.Read})
+		var expBlock []byte = nil
+		const expErr = crypto.ErrInvalidRecipient
+
+		recipient := NewSSHEd25519Recipient(rng, ChaChaPoly{})
+		block, err := recipient.Wrap(nil, []byte("ssh-ed25519"), accessKey)
+		assert.Equal(t, expBlock, block)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrUnsupportedKey error", func(t *testing.T) {
+		t.Parallel()
+		rng := NewStdRNG(FnStdRNG{Read: rand.Read})
+		ecdsaKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		var expBlock []byte = nil
+		const expErr = crypto.ErrUnsupportedKey
+
+		recipient := NewSSHEd25519Recipient(rng, ChaChaPoly{})
+		block, err := recipient.Wrap(
+			nil, sshAuthorizedKey(ecdsaKey.Public()), accessKey)
+		assert.Equal(t, expBlock, block)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrReadEntropyFailed error", func(t *testing.T) {
+		t.Parallel()
+		rng := NewStdRNG(FnStdRNG{
+			Read: func([]byte) (int, error) {
+				return 0, errors.New("")
+			},
+		})
+		var expBlock []byte = nil
+		const expErr = crypto.ErrReadEntropyFailed
+
+		recipient := NewSSHEd25519Recipient(rng, ChaChaPoly{})
+		block, err := recipient.Wrap(nil, authorizedKey, accessKey)
+		assert.Equal(t, expBlock, block)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		rng := NewStdRNG(FnStdRNG{Read: rand.Read})
+		rawIV, _ := rng.Block(IV96Len)
+		iv, _ := LoadIV96(rawIV)
+		identity, _, _ := LoadSSHIdentity(sshPrivateKey(key, ""), nil)
+
+		recipient := NewSSHEd25519Recipient(rng, ChaChaPoly{})
+		block, err := recipient.Wrap(iv, authorizedKey, accessKey)
+		assert.ErrorIs(t, err, nil)
+		assert.Len(t, block,
+			HybridX25519Len+ChaChaPolyIVLen+accessKey.Len()+16)
+		unwrapped, err := recipient.Unwrap(identity, block)
+		assert.Equal(t, accessKey.Bytes(), unwrapped.Bytes())
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_SSHEd25519Recipient_Unwrap(t *testing.T) {
+	t.Parallel()
+	rng := NewStdRNG(FnStdRNG{Read: rand.Read})
+	rawIV, _ := rng.Block(IV96Len)
+	iv, _ := LoadIV96(rawIV)
+	accessKey, _ := crypto.NewSecretFrom([]byte("1f3b6a90d2c8e4a7"))
+	key := sshEd25519Key()
+	identity, _, _ := LoadSSHIdentity(sshPrivateKey(key, ""), nil)
+	recipient := NewSSHEd25519Recipient(rng, ChaChaPoly{})
+	block, _ := recipient.Wrap(
+		iv, sshAuthorizedKey(key.Public()), accessKey)
+
+	t.Run("ErrInvalidIdentity error", func(t *testing.T) {
+		t.Parallel()
+		identity, _ := crypto.NewSecretFrom([]byte{0x01})
+		var expAccessKey *crypto.Secret = nil
+		const expErr = crypto.ErrInvalidIdentity
+
+		accessKey, err := recipient.Unwrap(identity, block)
+		assert.Equal(t, expAccessKey, accessKey)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrInvalidBlockLen error", func(t *testing.T) {
+		t.Parallel()
+		var expAccessKey *crypto.Secret = nil
+		const expErr = crypto.ErrInvalidBlockLen
+
+		accessKey, err := recipient.Unwrap(
+			identity, block[:HybridX25519Len-1])
+		assert.Equal(t, expAccessKey, accessKey)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrAuthFailed error", func(t *testing.T) {
+		t.Parallel()
+		otherIdentity, _ := crypto.NewSecretFrom(
+			make([]byte, SSHEd25519IdentityLen))
+		var expAccessKey *crypto.Secret = nil
+		const expErr = crypto.ErrAuthFailed
+
+		accessKey, err := recipient.Unwrap(otherIdentity, block)
+		assert.Equal(t, expAccessKey, accessKey)
+		assert.ErrorIs(t, err, expErr)
+	})
+}
+
+func Test_SSHRSARecipient_Wrap(t *testing.T) {
+	t.Parallel()
+	accessKey, _ := crypto.NewSecretFrom([]byte("1f3b6a90d2c8e4a7"))
+	key, _ := rsa.GenerateKey(rand.Reader, SSHRSAMinBits)
+	authorizedKey := sshAuthorizedKey(key.Public())
+
+	t.Run("ErrInvalidRecipient error", func(t *testing.T) {
+		t.Parallel()
+		rng := NewStdRNG(FnStdRNG{Read: rand.Read})
+		var expBlock []byte = nil
+		const expErr = crypto.ErrInvalidRecipient
+
+		recipient := NewSSHRSARecipient(rng)
+		block, err := recipient.Wrap(nil, []byte("ssh-rsa"), accessKey)
+		assert.Equal(t, expBlock, block)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrUnsupportedKey error", func(t *testing.T) {
+		t.Parallel()
+		rng := NewStdRNG(FnStdRNG{Read: rand.Read})
+		var expBlock []byte = nil
+		const expErr = crypto.ErrUnsupportedKey
+
+		recipient := NewSSHRSARecipient(rng)
+		block, err := recipient.Wrap(
+			nil, sshAuthorizedKey(sshEd25519Key().Public()), accessKey)
+		assert.Equal(t, expBlock, block)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrUnsupportedKey value", func(t *testing.T) {
+		t.Parallel()
+		rng := NewStdRNG(FnStdRNG{Read: rand.Read})
+		weakKey, _ := rsa.GenerateKey(rand.Reader, 1024)
+		var expBlock []byte = nil
+		const expErr = crypto.ErrUnsupportedKey
+
+		recipient := NewSSHRSARecipient(rng)
+		block, err := recipient.Wrap(
+			nil, sshAuthorizedKey(weakKey.Public()), accessKey)
+		assert.Equal(t, expBlock, block)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrReadEntropyFailed error", func(t *testing.T) {
+		t.Parallel()
+		rng := NewStdRNG(FnStdRNG{
+			Read: func([]byte) (int, error) {
+				return 0, errors.New("")
+			},
+		})
+		var expBlock []byte = nil
+		const expErr = crypto.ErrReadEntropyFailed
+
+		recipient := NewSSHRSARecipient(rng)
+		block, err := recipient.Wrap(nil, authorizedKey, accessKey)
+		assert.Equal(t, expBlock, block)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		rng := NewStdRNG(FnStdRNG{Read: rand.Read})
+		identity, _, _ := LoadSSHIdentity(sshPrivateKey(key, ""), nil)
+
+		recipient := NewSSHRSARecipient(rng)
+		block, err := recipient.Wrap(nil, authorizedKey, accessKey)
+		assert.ErrorIs(t, err, nil)
+		assert.Len(t, block, SSHRSAMinBits/8)
+		unwrapped, err := recipient.Unwrap(identity, block)
+		assert.Equal(t, accessKey.Bytes(), unwrapped.Bytes())
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_SSHRSARecipient_Unwrap(t *testing.T) {
+	t.Parallel()
+	rng := NewStdRNG(FnStdRNG{Read: rand.Read})
+	accessKey, _ := crypto.NewSecretFrom([]byte("1f3b6a90d2c8e4a7"))
+	key, _ := rsa.GenerateKey(rand.Reader, SSHRSAMinBits)
+	recipient := NewSSHRSARecipient(rng)
+	block, _ := recipient.Wrap(nil, sshAuthorizedKey(key.Public()), accessKey)
+
+	t.Run("ErrInvalidIdentity error", func(t *testing.T) {
+		t.Parallel()
+		identity, _ := crypto.NewSecretFrom([]byte{0x01})
+		var expAccessKey *crypto.Secret = nil
+		const expErr = crypto.ErrInvalidIdentity
+
+		accessKey, err := recipient.Unwrap(identity, block)
+		assert.Equal(t, expAccessKey, accessKey)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrAuthFailed error", func(t *testing.T) {
+		t.Parallel()
+		otherKey, _ := rsa.GenerateKey(rand.Reader, SSHRSAMinBits)
+		identity, _, _ := LoadSSHIdentity(sshPrivateKey(otherKey, ""), nil)
+		var expAccessKey *crypto.Secret = nil
+		const expErr = crypto.ErrAuthFailed
+
+		accessKey, err := recipient.Unwrap(identity, block)
+		assert.Equal(t, expAccessKey, accessKey)
+		assert.ErrorIs(t, err, expErr)
+	})
+}
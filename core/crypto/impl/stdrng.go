@@ -1,32 +1,103 @@
 package crypto_impl
 
 import (
+	"sync"
+
 	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/reshifr/secure-env/core/failure"
+)
+
+// The cutoffs follow SP 800-90B 4.4 for a source with 8 bits of
+// min-entropy per byte and a false positive rate of 2^-20 per test. StdRNG
+// reads the conditioned output of the OS CSPRNG rather than raw noise, so
+// it claims full entropy; a lower claim loosens the cutoffs until only a
+// grossly broken source fails.
+const (
+	StdRNGRepetitionCutoff = 4
+	StdRNGProportionWindow = 512
+	StdRNGProportionCutoff = 13
 )
 
 type StdRNG struct {
-	fn FnStdRNG
+	fn     FnStdRNG
+	health *stdRNGHealth
 }
 
 type FnStdRNG struct {
 	Read func(b []byte) (n int, err error)
 }
 
+type stdRNGHealth struct {
+	mu          sync.Mutex
+	started     bool
+	last        byte
+	repetitions int
+	reference   byte
+	proportion  int
+	window      int
+}
+
 func NewStdRNG(fn FnStdRNG) StdRNG {
-	return StdRNG{fn: fn}
+	return StdRNG{fn: fn, health: &stdRNGHealth{}}
+}
+
+func (health *stdRNGHealth) check(block []byte) error {
+	health.mu.Lock()
+	defer health.mu.Unlock()
+	for _, b := range block {
+		if health.started && b == health.last {
+			health.repetitions++
+		} else {
+			health.last = b
+			health.repetitions = 1
+		}
+		health.started = true
+		if health.repetitions >= StdRNGRepetitionCutoff {
+			health.repetitions = 0
+			return crypto.ErrRepetitionCount
+		}
+		if health.window == 0 {
+			health.reference = b
+			health.proportion = 0
+		}
+		if b == health.reference {
+			health.proportion++
+		}
+		health.window = (health.window + 1) % StdRNGProportionWindow
+		if health.proportion >= StdRNGProportionCutoff {
+			health.window = 0
+			return crypto.ErrAdaptiveProportion
+		}
+	}
+	return nil
+}
+
+func (rng StdRNG) fill(block []byte) error {
+	for n := 0; n < len(block); {
+		m, err := rng.fn.Read(block[n:])
+		if err != nil {
+			return err
+		}
+		if m <= 0 {
+			return crypto.ErrShortRead
+		}
+		n += m
+	}
+	return rng.health.check(block)
 }
 
 func (rng StdRNG) Block(blockLen int) ([]byte, error) {
 	block := make([]byte, blockLen)
-	if _, err := rng.fn.Read(block); err != nil {
-		return nil, crypto.ErrReadEntropyFailed
+	if err := rng.Read(block); err != nil {
+		return nil, err
 	}
 	return block, nil
 }
 
 func (rng StdRNG) Read(block []byte) error {
-	if _, err := rng.fn.Read(block); err != nil {
-		return crypto.ErrReadEntropyFailed
+	if err := rng.fill(block); err != nil {
+		clear(block)
+		return failure.New(crypto.OpRead, crypto.ErrReadEntropyFailed, err)
 	}
 	return nil
 }
@@ -3,28 +3,77 @@ package crypto_impl
 import (
 	"bytes"
 	"errors"
+	"math"
 	"testing"
 
 	"github.com/reshifr/secure-env/core/crypto"
 	"github.com/stretchr/testify/assert"
 )
 
+func counterRead(block []byte) (int, error) {
+	for i := range block {
+		block[i] = byte(i)
+	}
+	return len(block), nil
+}
+
 func Test_NewStdRNG(t *testing.T) {
 	t.Parallel()
 	fn := FnStdRNG{}
-	expRNG := StdRNG{fn: fn}
+	expRNG := StdRNG{fn: fn, health: &stdRNGHealth{}}
 
 	rng := NewStdRNG(fn)
 	assert.Equal(t, expRNG, rng)
 }
 
+// The cutoffs below derive the StdRNG constants from SP 800-90B 4.4.
+const (
+	stdRNGEntropy       = 8
+	stdRNGFalsePositive = 20
+)
+
+func repetitionCutoff(entropy float64) int {
+	return 1 + int(math.Ceil(stdRNGFalsePositive/entropy))
+}
+
+func proportionCutoff(entropy float64) int {
+	const w = StdRNGProportionWindow
+	p := math.Exp2(-entropy)
+	limit := 1 - math.Exp2(-stdRNGFalsePositive)
+	lw, _ := math.Lgamma(w + 1)
+	cdf := 0.0
+	for k := 0; k < w; k++ {
+		lk, _ := math.Lgamma(float64(k + 1))
+		lr, _ := math.Lgamma(float64(w - k + 1))
+		cdf += math.Exp(lw - lk - lr +
+			float64(k)*math.Log(p) + float64(w-k)*math.Log1p(-p))
+		if cdf >= limit {
+			return 1 + k
+		}
+	}
+	return w
+}
+
+func Test_StdRNGRepetitionCutoff(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, StdRNGRepetitionCutoff, repetitionCutoff(stdRNGEntropy))
+	assert.Equal(t, 6, repetitionCutoff(4))
+}
+
+func Test_StdRNGProportionCutoff(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, StdRNGProportionCutoff, proportionCutoff(stdRNGEntropy))
+	assert.Equal(t, 62, proportionCutoff(4))
+}
+
 func Test_StdRNG_Block(t *testing.T) {
 	t.Parallel()
 	t.Run("ErrReadEntropyFailed error", func(t *testing.T) {
 		t.Parallel()
+		cause := errors.New("entropy source closed")
 		fn := FnStdRNG{
 			Read: func([]byte) (int, error) {
-				return 0, errors.New("")
+				return 0, cause
 			},
 		}
 		var expBlock []byte = nil
@@ -34,16 +83,12 @@ func Test_StdRNG_Block(t *testing.T) {
 		block, err := rng.Block(8)
 		assert.Equal(t, expBlock, block)
 		assert.ErrorIs(t, err, expErr)
+		assert.ErrorIs(t, err, cause)
 	})
 	t.Run("Succeed", func(t *testing.T) {
 		t.Parallel()
-		expBlock := bytes.Repeat([]byte{0xff}, 8)
-		fn := FnStdRNG{
-			Read: func(block []byte) (n int, err error) {
-				copy(block, expBlock)
-				return len(block), nil
-			},
-		}
+		fn := FnStdRNG{Read: counterRead}
+		expBlock := []byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07}
 
 		rng := NewStdRNG(fn)
 		block, err := rng.Block(8)
@@ -70,20 +115,120 @@ func Test_StdRNG_Read(t *testing.T) {
 		assert.Equal(t, expBlock, block)
 		assert.ErrorIs(t, err, expErr)
 	})
-	t.Run("Succeed", func(t *testing.T) {
+	t.Run("ErrShortRead error", func(t *testing.T) {
 		t.Parallel()
+		fn := FnStdRNG{
+			Read: func(block []byte) (int, error) {
+				block[0] = 0xff
+				return 0, nil
+			},
+		}
 		block := make([]byte, 8)
-		expBlock := bytes.Repeat([]byte{0xff}, 8)
+		expBlock := make([]byte, 8)
+		const expErr = crypto.ErrShortRead
+
+		rng := NewStdRNG(fn)
+		err := rng.Read(block)
+		assert.Equal(t, expBlock, block)
+		assert.ErrorIs(t, err, crypto.ErrReadEntropyFailed)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrRepetitionCount error", func(t *testing.T) {
+		t.Parallel()
 		fn := FnStdRNG{
 			Read: func(block []byte) (int, error) {
-				copy(block, expBlock)
+				copy(block, bytes.Repeat([]byte{0xff}, len(block)))
 				return len(block), nil
 			},
 		}
+		block := make([]byte, StdRNGRepetitionCutoff)
+		expBlock := make([]byte, StdRNGRepetitionCutoff)
+		const expErr = crypto.ErrRepetitionCount
 
 		rng := NewStdRNG(fn)
 		err := rng.Read(block)
 		assert.Equal(t, expBlock, block)
+		assert.ErrorIs(t, err, crypto.ErrReadEntropyFailed)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrRepetitionCount across reads", func(t *testing.T) {
+		t.Parallel()
+		fn := FnStdRNG{
+			Read: func(block []byte) (int, error) {
+				copy(block, bytes.Repeat([]byte{0xff}, len(block)))
+				return len(block), nil
+			},
+		}
+		const expErr = crypto.ErrRepetitionCount
+
+		rng := NewStdRNG(fn)
+		err := rng.Read(make([]byte, StdRNGRepetitionCutoff-1))
+		assert.ErrorIs(t, err, nil)
+		err = rng.Read(make([]byte, 1))
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrAdaptiveProportion error", func(t *testing.T) {
+		t.Parallel()
+		fn := FnStdRNG{
+			Read: func(block []byte) (int, error) {
+				for i := range block {
+					block[i] = byte(i % 2 * (i % 7))
+				}
+				return len(block), nil
+			},
+		}
+		block := make([]byte, StdRNGProportionWindow)
+		const expErr = crypto.ErrAdaptiveProportion
+
+		rng := NewStdRNG(fn)
+		err := rng.Read(block)
+		assert.ErrorIs(t, err, crypto.ErrReadEntropyFailed)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrAdaptiveProportion from a biased source", func(t *testing.T) {
+		t.Parallel()
+		state := uint32(1)
+		fn := FnStdRNG{
+			Read: func(block []byte) (int, error) {
+				for i := range block {
+					state = state*1664525 + 1013904223
+					block[i] = byte(state>>24) & 0x0f
+				}
+				return len(block), nil
+			},
+		}
+		const expErr = crypto.ErrAdaptiveProportion
+
+		rng := NewStdRNG(fn)
+		err := rng.Read(make([]byte, 4*StdRNGProportionWindow))
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Partial reads", func(t *testing.T) {
+		t.Parallel()
+		fn := FnStdRNG{
+			Read: func(block []byte) (int, error) {
+				return counterRead(block[:1])
+			},
+		}
+		block := make([]byte, StdRNGRepetitionCutoff-1)
+		expBlock := make([]byte, StdRNGRepetitionCutoff-1)
+		const expErr = crypto.ErrRepetitionCount
+
+		rng := NewStdRNG(fn)
+		err := rng.Read(block)
+		assert.Equal(t, expBlock, block)
+		assert.ErrorIs(t, err, nil)
+		err = rng.Read(make([]byte, 1))
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		fn := FnStdRNG{Read: counterRead}
+		block := make([]byte, 2*StdRNGProportionWindow)
+
+		rng := NewStdRNG(fn)
+		err := rng.Read(block)
+		assert.Equal(t, byte(0xff), block[0xff])
 		assert.ErrorIs(t, err, nil)
 	})
 }
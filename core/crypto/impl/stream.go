@@ -0,0 +1,269 @@
+package crypto_impl
+
+import (
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/reshifr/secure-env/core/failure"
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	StreamChunkLen = 64 * 1024
+	StreamInfo     = "secure-env stream v1"
+	StreamNonceLen = 12
+)
+
+type StreamCipher interface {
+	crypto.AE
+	AEAD(key []byte) (cipher.AEAD, error)
+}
+
+type Stream[Cipher StreamCipher] struct {
+	cipher   Cipher
+	chunkLen int
+}
+
+type streamWriter struct {
+	aead    cipher.AEAD
+	ad      []byte
+	w       io.Writer
+	key     *crypto.Secret
+	buf     *crypto.Secret
+	n       int
+	counter uint64
+	out     []byte
+	closed  bool
+}
+
+type streamReader struct {
+	aead    cipher.AEAD
+	ad      []byte
+	r       io.Reader
+	key     *crypto.Secret
+	buf     *crypto.Secret
+	in      []byte
+	pending int
+	plain   []byte
+	counter uint64
+	done    bool
+	err     error
+}
+
+func NewStream[Cipher StreamCipher](
+	cipher Cipher, chunkLen int) (Stream[Cipher], error) {
+	if chunkLen <= 0 {
+		return Stream[Cipher]{}, crypto.ErrInvalidChunkLen
+	}
+	return Stream[Cipher]{cipher: cipher, chunkLen: chunkLen}, nil
+}
+
+func streamNonce(counter uint64, last bool) []byte {
+	nonce := make([]byte, StreamNonceLen)
+	binary.BigEndian.PutUint64(nonce[3:], counter)
+	if last {
+		nonce[StreamNonceLen-1] = 1
+	}
+	return nonce
+}
+
+func (stream Stream[Cipher]) streamAEAD(op string, key *crypto.Secret,
+	salt []byte, info []byte) (cipher.AEAD, *crypto.Secret, error) {
+	streamKey, err := crypto.NewSecret(int(stream.cipher.KeyLen()))
+	if err != nil {
+		return nil, nil, err
+	}
+	kdf := hkdf.New(sha256.New, key.Bytes(), salt, info)
+	if _, err := io.ReadFull(kdf, streamKey.Bytes()); err != nil {
+		streamKey.Destroy()
+		return nil, nil,
+			failure.New(crypto.OpDerive, crypto.ErrInvalidKeyLen, err)
+	}
+	aead, err := stream.cipher.AEAD(streamKey.Bytes())
+	if err != nil {
+		streamKey.Destroy()
+		return nil, nil, failure.New(op, crypto.ErrInvalidKeyLen, err)
+	}
+	return aead, streamKey, nil
+}
+
+func (stream Stream[Cipher]) newWriter(key *crypto.Secret,
+	salt []byte, info []byte, ad []byte, w io.Writer) (*streamWriter, error) {
+	aead, streamKey, err := stream.streamAEAD(crypto.OpSeal, key, salt, info)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := crypto.NewSecret(stream.chunkLen)
+	if err != nil {
+		streamKey.Destroy()
+		return nil, err
+	}
+	if _, err := w.Write(salt); err != nil {
+		streamKey.Destroy()
+		buf.Destroy()
+		return nil, err
+	}
+	return &streamWriter{
+		aead: aead,
+		ad:   ad,
+		w:    w,
+		key:  streamKey,
+		buf:  buf,
+		out:  make([]byte, 0, stream.chunkLen+aead.Overhead()),
+	}, nil
+}
+
+func (stream Stream[Cipher]) newReader(key *crypto.Secret,
+	salt []byte, info []byte, ad []byte, r io.Reader) (*streamReader, error) {
+	aead, streamKey, err := stream.streamAEAD(crypto.OpOpen, key, salt, info)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := crypto.NewSecret(stream.chunkLen)
+	if err != nil {
+		streamKey.Destroy()
+		return nil, err
+	}
+	return &streamReader{
+		aead: aead,
+		ad:   ad,
+		r:    r,
+		key:  streamKey,
+		buf:  buf,
+		in:   make([]byte, stream.chunkLen+aead.Overhead()+1),
+	}, nil
+}
+
+func (stream Stream[Cipher]) NewWriter(iv crypto.IV,
+	key *crypto.Secret, ad []byte, w io.Writer) (io.WriteCloser, error) {
+	if iv.Len() != StreamNonceLen {
+		return nil, crypto.ErrInvalidIVLen
+	}
+	writer, err := stream.newWriter(
+		key, iv.Invoke(), []byte(StreamInfo), ad, w)
+	if err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+func (stream Stream[Cipher]) NewReader(key *crypto.Secret,
+	ad []byte, r io.Reader) (io.ReadCloser, error) {
+	rawIV := make([]byte, StreamNonceLen)
+	if _, err := io.ReadFull(r, rawIV); err != nil {
+		return nil, failure.New(crypto.OpOpen, crypto.ErrStreamTruncated, err)
+	}
+	reader, err := stream.newReader(key, rawIV, []byte(StreamInfo), ad, r)
+	if err != nil {
+		return nil, err
+	}
+	return reader, nil
+}
+
+func (writer *streamWriter) seal(last bool) error {
+	nonce := streamNonce(writer.counter, last)
+	writer.out = writer.aead.Seal(
+		writer.out[:0], nonce, writer.buf.Bytes()[:writer.n], writer.ad)
+	writer.n = 0
+	writer.counter++
+	_, err := writer.w.Write(writer.out)
+	return err
+}
+
+func (writer *streamWriter) Write(p []byte) (int, error) {
+	if writer.closed {
+		return 0, crypto.ErrStreamClosed
+	}
+	written := 0
+	for len(p) > 0 {
+		if writer.n == writer.buf.Len() {
+			if err := writer.seal(false); err != nil {
+				return written, err
+			}
+		}
+		n := copy(writer.buf.Bytes()[writer.n:], p)
+		writer.n += n
+		written += n
+		p = p[n:]
+	}
+	return written, nil
+}
+
+func (writer *streamWriter) Close() error {
+	if writer.closed {
+		return crypto.ErrStreamClosed
+	}
+	writer.closed = true
+	defer writer.key.Destroy()
+	defer writer.buf.Destroy()
+	return writer.seal(true)
+}
+
+func (reader *streamReader) open() error {
+	n, err := io.ReadFull(reader.r, reader.in[reader.pending:])
+	n += reader.pending
+	reader.pending = 0
+	last := false
+	switch {
+	case err == nil:
+		n--
+		defer func() {
+			reader.in[0] = reader.in[n]
+			reader.pending = 1
+		}()
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		last = true
+	default:
+		return err
+	}
+	if n < reader.aead.Overhead() {
+		return crypto.ErrStreamTruncated
+	}
+	if last && reader.counter > 0 && n == reader.aead.Overhead() {
+		return crypto.ErrAuthFailed
+	}
+	nonce := streamNonce(reader.counter, last)
+	plain, err := reader.aead.Open(
+		reader.buf.Bytes()[:0], nonce, reader.in[:n], reader.ad)
+	if err != nil {
+		return crypto.ErrAuthFailed
+	}
+	reader.plain = plain
+	reader.counter++
+	reader.done = last
+	return nil
+}
+
+func (reader *streamReader) Read(p []byte) (int, error) {
+	if reader.err != nil {
+		return 0, reader.err
+	}
+	for len(reader.plain) == 0 {
+		if reader.done {
+			reader.err = io.EOF
+			return 0, io.EOF
+		}
+		if err := reader.open(); err != nil {
+			reader.err = err
+			return 0, err
+		}
+	}
+	n := copy(p, reader.plain)
+	reader.plain = reader.plain[n:]
+	return n, nil
+}
+
+func (reader *streamReader) Close() error {
+	if reader.err == crypto.ErrStreamClosed {
+		return crypto.ErrStreamClosed
+	}
+	reader.err = crypto.ErrStreamClosed
+	reader.plain = nil
+	reader.key.Destroy()
+	reader.buf.Destroy()
+	return nil
+}
@@ -0,0 +1,200 @@
+package crypto_impl
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"testing"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	cmock "github.com/reshifr/secure-env/core/crypto/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	streamChunkLen = 16
+)
+
+func seal(t *testing.T, stream Stream[ChaChaPoly],
+	key *crypto.Secret, plaintext []byte) []byte {
+	rawIV, _ := hex.DecodeString("000000000000000000000001")
+	iv, _ := LoadIV96(rawIV)
+	buf := &bytes.Buffer{}
+	w, err := stream.NewWriter(iv, key, nil, buf)
+	assert.ErrorIs(t, err, nil)
+	w.Write(plaintext)
+	w.Close()
+	return buf.Bytes()
+}
+
+func Test_NewStream(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrInvalidChunkLen error", func(t *testing.T) {
+		t.Parallel()
+		expStream := Stream[ChaChaPoly]{}
+		const expErr = crypto.ErrInvalidChunkLen
+
+		stream, err := NewStream(ChaChaPoly{}, 0)
+		assert.Equal(t, expStream, stream)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		expStream := Stream[ChaChaPoly]{chunkLen: StreamChunkLen}
+
+		stream, err := NewStream(ChaChaPoly{}, StreamChunkLen)
+		assert.Equal(t, expStream, stream)
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_Stream_NewWriter(t *testing.T) {
+	t.Parallel()
+	stream, _ := NewStream(ChaChaPoly{}, streamChunkLen)
+
+	t.Run("ErrInvalidIVLen error", func(t *testing.T) {
+		t.Parallel()
+		iv := cmock.NewIV(t)
+		iv.EXPECT().Len().Return(8).Once()
+		key, _ := crypto.NewSecretFrom(bytes.Repeat([]byte{0x01}, 32))
+		var expWriter io.WriteCloser = nil
+		const expErr = crypto.ErrInvalidIVLen
+
+		w, err := stream.NewWriter(iv, key, nil, io.Discard)
+		assert.Equal(t, expWriter, w)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrStreamClosed error", func(t *testing.T) {
+		t.Parallel()
+		rawIV, _ := hex.DecodeString("000000000000000000000001")
+		iv, _ := LoadIV96(rawIV)
+		key, _ := crypto.NewSecretFrom(bytes.Repeat([]byte{0x01}, 32))
+		const expErr = crypto.ErrStreamClosed
+
+		w, _ := stream.NewWriter(iv, key, nil, io.Discard)
+		w.Close()
+		_, err := w.Write([]byte{0x01})
+		assert.ErrorIs(t, err, expErr)
+		err = w.Close()
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		key, _ := crypto.NewSecretFrom(bytes.Repeat([]byte{0x01}, 32))
+		plaintext := bytes.Repeat([]byte{0x02}, 2*streamChunkLen)
+		expLen := IV96Len + 2*(streamChunkLen+16)
+
+		buf := seal(t, stream, key, plaintext)
+		assert.Len(t, buf, expLen)
+		assert.Equal(t,
+			"000000000000000000000002", hex.EncodeToString(buf[:IV96Len]))
+	})
+}
+
+func Test_Stream_NewReader(t *testing.T) {
+	t.Parallel()
+	stream, _ := NewStream(ChaChaPoly{}, streamChunkLen)
+	key, _ := crypto.NewSecretFrom(bytes.Repeat([]byte{0x01}, 32))
+
+	t.Run("ErrStreamTruncated error", func(t *testing.T) {
+		t.Parallel()
+		var expReader io.ReadCloser = nil
+		const expErr = crypto.ErrStreamTruncated
+
+		r, err := stream.NewReader(key, nil, bytes.NewReader(make([]byte, 11)))
+		assert.Equal(t, expReader, r)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Truncated chunk", func(t *testing.T) {
+		t.Parallel()
+		buf := seal(t, stream, key, nil)
+		const expErr = crypto.ErrStreamTruncated
+
+		r, _ := stream.NewReader(key, nil, bytes.NewReader(buf[:len(buf)-1]))
+		_, err := io.ReadAll(r)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Dropped final chunk", func(t *testing.T) {
+		t.Parallel()
+		plaintext := bytes.Repeat([]byte{0x02}, 2*streamChunkLen)
+		buf := seal(t, stream, key, plaintext)
+		const expErr = crypto.ErrAuthFailed
+
+		dropped := buf[:len(buf)-streamChunkLen-16]
+		r, _ := stream.NewReader(key, nil, bytes.NewReader(dropped))
+		_, err := io.ReadAll(r)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Empty final chunk", func(t *testing.T) {
+		t.Parallel()
+		rawIV, _ := hex.DecodeString("000000000000000000000001")
+		iv, _ := LoadIV96(rawIV)
+		buf := &bytes.Buffer{}
+		w, _ := stream.NewWriter(iv, key, nil, buf)
+		w.Write(bytes.Repeat([]byte{0x02}, streamChunkLen))
+		w.(*streamWriter).seal(false)
+		w.Close()
+		const expErr = crypto.ErrAuthFailed
+
+		r, _ := stream.NewReader(key, nil, bytes.NewReader(buf.Bytes()))
+		_, err := io.ReadAll(r)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Reordered chunks", func(t *testing.T) {
+		t.Parallel()
+		const chunk = streamChunkLen + 16
+		plaintext := bytes.Repeat([]byte{0x02}, 3*streamChunkLen)
+		buf := seal(t, stream, key, plaintext)
+		reordered := append([]byte{}, buf[:IV96Len]...)
+		reordered = append(reordered, buf[IV96Len+chunk:IV96Len+2*chunk]...)
+		reordered = append(reordered, buf[IV96Len:IV96Len+chunk]...)
+		reordered = append(reordered, buf[IV96Len+2*chunk:]...)
+		const expErr = crypto.ErrAuthFailed
+
+		r, _ := stream.NewReader(key, nil, bytes.NewReader(reordered))
+		_, err := io.ReadAll(r)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Associated data mismatch", func(t *testing.T) {
+		t.Parallel()
+		buf := seal(t, stream, key, []byte{0x02})
+		ad := []byte("prod\x00KEY")
+		const expErr = crypto.ErrAuthFailed
+
+		r, _ := stream.NewReader(key, ad, bytes.NewReader(buf))
+		_, err := io.ReadAll(r)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrStreamClosed error", func(t *testing.T) {
+		t.Parallel()
+		buf := seal(t, stream, key, []byte{0x02})
+		const expErr = crypto.ErrStreamClosed
+
+		r, _ := stream.NewReader(key, nil, bytes.NewReader(buf))
+		r.Close()
+		_, err := r.Read(make([]byte, 1))
+		assert.ErrorIs(t, err, expErr)
+		err = r.Close()
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		for _, n := range []int{
+			0, 1, streamChunkLen - 1, streamChunkLen,
+			streamChunkLen + 1, 3 * streamChunkLen, 100,
+		} {
+			plaintext := make([]byte, n)
+			for i := range plaintext {
+				plaintext[i] = byte(i)
+			}
+			buf := seal(t, stream, key, plaintext)
+
+			r, err := stream.NewReader(key, nil, bytes.NewReader(buf))
+			assert.ErrorIs(t, err, nil)
+			decrypted, err := io.ReadAll(r)
+			assert.Equal(t, plaintext, append([]byte{}, decrypted...))
+			assert.ErrorIs(t, err, nil)
+			r.Close()
+		}
+	})
+}
@@ -1,5 +1,17 @@
 package crypto
 
+const (
+	OpDerive = "derive"
+)
+
+type KDFParams struct {
+	Algorithm string
+	Time      uint32
+	Memory    uint32
+	Threads   uint8
+}
+
 type KDF interface {
-	Key(passphrase []byte, salt []byte, keyLen uint32) (key []byte)
+	Params() (params KDFParams)
+	Key(passphrase *Secret, salt []byte, keyLen uint32) (key *Secret, err error)
 }
@@ -65,29 +65,29 @@ func (_c *AE_KeyLen_Call) RunAndReturn(run func() uint32) *AE_KeyLen_Call {
 	return _c
 }
 
-// Open provides a mock function with given fields: key, buf
-func (_m *AE) Open(key []byte, buf []byte) ([]byte, error) {
-	ret := _m.Called(key, buf)
+// Open provides a mock function with given fields: key, buf, ad
+func (_m *AE) Open(key *crypto.Secret, buf []byte, ad []byte) (*crypto.Secret, error) {
+	ret := _m.Called(key, buf, ad)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Open")
 	}
 
-	var r0 []byte
+	var r0 *crypto.Secret
 	var r1 error
-	if rf, ok := ret.Get(0).(func([]byte, []byte) ([]byte, error)); ok {
-		return rf(key, buf)
+	if rf, ok := ret.Get(0).(func(*crypto.Secret, []byte, []byte) (*crypto.Secret, error)); ok {
+		return rf(key, buf, ad)
 	}
-	if rf, ok := ret.Get(0).(func([]byte, []byte) []byte); ok {
-		r0 = rf(key, buf)
+	if rf, ok := ret.Get(0).(func(*crypto.Secret, []byte, []byte) *crypto.Secret); ok {
+		r0 = rf(key, buf, ad)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]byte)
+			r0 = ret.Get(0).(*crypto.Secret)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func([]byte, []byte) error); ok {
-		r1 = rf(key, buf)
+	if rf, ok := ret.Get(1).(func(*crypto.Secret, []byte, []byte) error); ok {
+		r1 = rf(key, buf, ad)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -101,32 +101,33 @@ type AE_Open_Call struct {
 }
 
 // Open is a helper method to define mock.On call
-//   - key []byte
+//   - key *crypto.Secret
 //   - buf []byte
-func (_e *AE_Expecter) Open(key interface{}, buf interface{}) *AE_Open_Call {
-	return &AE_Open_Call{Call: _e.mock.On("Open", key, buf)}
+//   - ad []byte
+func (_e *AE_Expecter) Open(key interface{}, buf interface{}, ad interface{}) *AE_Open_Call {
+	return &AE_Open_Call{Call: _e.mock.On("Open", key, buf, ad)}
 }
 
-func (_c *AE_Open_Call) Run(run func(key []byte, buf []byte)) *AE_Open_Call {
+func (_c *AE_Open_Call) Run(run func(key *crypto.Secret, buf []byte, ad []byte)) *AE_Open_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].([]byte), args[1].([]byte))
+		run(args[0].(*crypto.Secret), args[1].([]byte), args[2].([]byte))
 	})
 	return _c
 }
 
-func (_c *AE_Open_Call) Return(ciphertext []byte, err error) *AE_Open_Call {
-	_c.Call.Return(ciphertext, err)
+func (_c *AE_Open_Call) Return(plaintext *crypto.Secret, err error) *AE_Open_Call {
+	_c.Call.Return(plaintext, err)
 	return _c
 }
 
-func (_c *AE_Open_Call) RunAndReturn(run func([]byte, []byte) ([]byte, error)) *AE_Open_Call {
+func (_c *AE_Open_Call) RunAndReturn(run func(*crypto.Secret, []byte, []byte) (*crypto.Secret, error)) *AE_Open_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Seal provides a mock function with given fields: iv, key, plaintext
-func (_m *AE) Seal(iv crypto.IV, key []byte, plaintext []byte) ([]byte, error) {
-	ret := _m.Called(iv, key, plaintext)
+// Seal provides a mock function with given fields: iv, key, plaintext, ad
+func (_m *AE) Seal(iv crypto.IV, key *crypto.Secret, plaintext []byte, ad []byte) ([]byte, error) {
+	ret := _m.Called(iv, key, plaintext, ad)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Seal")
@@ -134,19 +135,19 @@ func (_m *AE) Seal(iv crypto.IV, key []byte, plaintext []byte) ([]byte, error) {
 
 	var r0 []byte
 	var r1 error
-	if rf, ok := ret.Get(0).(func(crypto.IV, []byte, []byte) ([]byte, error)); ok {
-		return rf(iv, key, plaintext)
+	if rf, ok := ret.Get(0).(func(crypto.IV, *crypto.Secret, []byte, []byte) ([]byte, error)); ok {
+		return rf(iv, key, plaintext, ad)
 	}
-	if rf, ok := ret.Get(0).(func(crypto.IV, []byte, []byte) []byte); ok {
-		r0 = rf(iv, key, plaintext)
+	if rf, ok := ret.Get(0).(func(crypto.IV, *crypto.Secret, []byte, []byte) []byte); ok {
+		r0 = rf(iv, key, plaintext, ad)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]byte)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(crypto.IV, []byte, []byte) error); ok {
-		r1 = rf(iv, key, plaintext)
+	if rf, ok := ret.Get(1).(func(crypto.IV, *crypto.Secret, []byte, []byte) error); ok {
+		r1 = rf(iv, key, plaintext, ad)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -161,15 +162,16 @@ type AE_Seal_Call struct {
 
 // Seal is a helper method to define mock.On call
 //   - iv crypto.IV
-//   - key []byte
+//   - key *crypto.Secret
 //   - plaintext []byte
-func (_e *AE_Expecter) Seal(iv interface{}, key interface{}, plaintext interface{}) *AE_Seal_Call {
-	return &AE_Seal_Call{Call: _e.mock.On("Seal", iv, key, plaintext)}
+//   - ad []byte
+func (_e *AE_Expecter) Seal(iv interface{}, key interface{}, plaintext interface{}, ad interface{}) *AE_Seal_Call {
+	return &AE_Seal_Call{Call: _e.mock.On("Seal", iv, key, plaintext, ad)}
 }
 
-func (_c *AE_Seal_Call) Run(run func(iv crypto.IV, key []byte, plaintext []byte)) *AE_Seal_Call {
+func (_c *AE_Seal_Call) Run(run func(iv crypto.IV, key *crypto.Secret, plaintext []byte, ad []byte)) *AE_Seal_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(crypto.IV), args[1].([]byte), args[2].([]byte))
+		run(args[0].(crypto.IV), args[1].(*crypto.Secret), args[2].([]byte), args[3].([]byte))
 	})
 	return _c
 }
@@ -179,7 +181,7 @@ func (_c *AE_Seal_Call) Return(buf []byte, err error) *AE_Seal_Call {
 	return _c
 }
 
-func (_c *AE_Seal_Call) RunAndReturn(run func(crypto.IV, []byte, []byte) ([]byte, error)) *AE_Seal_Call {
+func (_c *AE_Seal_Call) RunAndReturn(run func(crypto.IV, *crypto.Secret, []byte, []byte) ([]byte, error)) *AE_Seal_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -2,7 +2,10 @@
 
 package crypto_mock
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	crypto "github.com/reshifr/secure-env/core/crypto"
+	mock "github.com/stretchr/testify/mock"
+)
 
 // KDF is an autogenerated mock type for the KDF type
 type KDF struct {
@@ -18,23 +21,33 @@ func (_m *KDF) EXPECT() *KDF_Expecter {
 }
 
 // Key provides a mock function with given fields: passphrase, salt, keyLen
-func (_m *KDF) Key(passphrase []byte, salt []byte, keyLen uint32) []byte {
+func (_m *KDF) Key(passphrase *crypto.Secret, salt []byte, keyLen uint32) (*crypto.Secret, error) {
 	ret := _m.Called(passphrase, salt, keyLen)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Key")
 	}
 
-	var r0 []byte
-	if rf, ok := ret.Get(0).(func([]byte, []byte, uint32) []byte); ok {
+	var r0 *crypto.Secret
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*crypto.Secret, []byte, uint32) (*crypto.Secret, error)); ok {
+		return rf(passphrase, salt, keyLen)
+	}
+	if rf, ok := ret.Get(0).(func(*crypto.Secret, []byte, uint32) *crypto.Secret); ok {
 		r0 = rf(passphrase, salt, keyLen)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]byte)
+			r0 = ret.Get(0).(*crypto.Secret)
 		}
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(*crypto.Secret, []byte, uint32) error); ok {
+		r1 = rf(passphrase, salt, keyLen)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // KDF_Key_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Key'
@@ -43,26 +56,71 @@ type KDF_Key_Call struct {
 }
 
 // Key is a helper method to define mock.On call
-//   - passphrase []byte
+//   - passphrase *crypto.Secret
 //   - salt []byte
 //   - keyLen uint32
 func (_e *KDF_Expecter) Key(passphrase interface{}, salt interface{}, keyLen interface{}) *KDF_Key_Call {
 	return &KDF_Key_Call{Call: _e.mock.On("Key", passphrase, salt, keyLen)}
 }
 
-func (_c *KDF_Key_Call) Run(run func(passphrase []byte, salt []byte, keyLen uint32)) *KDF_Key_Call {
+func (_c *KDF_Key_Call) Run(run func(passphrase *crypto.Secret, salt []byte, keyLen uint32)) *KDF_Key_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*crypto.Secret), args[1].([]byte), args[2].(uint32))
+	})
+	return _c
+}
+
+func (_c *KDF_Key_Call) Return(key *crypto.Secret, err error) *KDF_Key_Call {
+	_c.Call.Return(key, err)
+	return _c
+}
+
+func (_c *KDF_Key_Call) RunAndReturn(run func(*crypto.Secret, []byte, uint32) (*crypto.Secret, error)) *KDF_Key_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Params provides a mock function with given fields:
+func (_m *KDF) Params() crypto.KDFParams {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Params")
+	}
+
+	var r0 crypto.KDFParams
+	if rf, ok := ret.Get(0).(func() crypto.KDFParams); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(crypto.KDFParams)
+	}
+
+	return r0
+}
+
+// KDF_Params_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Params'
+type KDF_Params_Call struct {
+	*mock.Call
+}
+
+// Params is a helper method to define mock.On call
+func (_e *KDF_Expecter) Params() *KDF_Params_Call {
+	return &KDF_Params_Call{Call: _e.mock.On("Params")}
+}
+
+func (_c *KDF_Params_Call) Run(run func()) *KDF_Params_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].([]byte), args[1].([]byte), args[2].(uint32))
+		run()
 	})
 	return _c
 }
 
-func (_c *KDF_Key_Call) Return(key []byte) *KDF_Key_Call {
-	_c.Call.Return(key)
+func (_c *KDF_Params_Call) Return(params crypto.KDFParams) *KDF_Params_Call {
+	_c.Call.Return(params)
 	return _c
 }
 
-func (_c *KDF_Key_Call) RunAndReturn(run func([]byte, []byte, uint32) []byte) *KDF_Key_Call {
+func (_c *KDF_Params_Call) RunAndReturn(run func() crypto.KDFParams) *KDF_Params_Call {
 	_c.Call.Return(run)
 	return _c
 }
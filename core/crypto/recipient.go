@@ -0,0 +1,49 @@
+package crypto
+
+import (
+	"github.com/reshifr/secure-env/core/failure"
+)
+
+type RecipientError int
+
+const (
+	ErrInvalidRecipient RecipientError = iota + 1
+	ErrInvalidIdentity
+	ErrUnsupportedKey
+	ErrIdentityLocked
+	ErrAgentFailed
+)
+
+func (err RecipientError) Error() string {
+	switch err {
+	case ErrInvalidRecipient:
+		return "ErrInvalidRecipient: the recipient public key is malformed."
+	case ErrInvalidIdentity:
+		return "ErrInvalidIdentity: the identity is malformed."
+	case ErrUnsupportedKey:
+		return "ErrUnsupportedKey: the key type is not supported."
+	case ErrIdentityLocked:
+		return "ErrIdentityLocked: the identity is passphrase protected."
+	case ErrAgentFailed:
+		return "ErrAgentFailed: the ssh-agent did not sign the challenge."
+	default:
+		return "Error: unknown."
+	}
+}
+
+func (err RecipientError) Kind() failure.Kind {
+	switch err {
+	case ErrInvalidRecipient, ErrInvalidIdentity, ErrUnsupportedKey:
+		return failure.KindInvalid
+	case ErrIdentityLocked, ErrAgentFailed:
+		return failure.KindAuth
+	default:
+		return failure.KindInternal
+	}
+}
+
+type Recipient interface {
+	Kind() (kind string)
+	Wrap(iv IV, publicKey []byte, accessKey *Secret) (block []byte, err error)
+	Unwrap(identity *Secret, block []byte) (accessKey *Secret, err error)
+}
@@ -0,0 +1,102 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/reshifr/secure-env/core/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RecipientError_Error(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrInvalidRecipient value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrInvalidRecipient
+		const expMsg = "ErrInvalidRecipient: " +
+			"the recipient public key is malformed."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrInvalidIdentity value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrInvalidIdentity
+		const expMsg = "ErrInvalidIdentity: the identity is malformed."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrUnsupportedKey value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrUnsupportedKey
+		const expMsg = "ErrUnsupportedKey: the key type is not supported."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrIdentityLocked value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrIdentityLocked
+		const expMsg = "ErrIdentityLocked: " +
+			"the identity is passphrase protected."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrAgentFailed value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrAgentFailed
+		const expMsg = "ErrAgentFailed: " +
+			"the ssh-agent did not sign the challenge."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("Unknown value", func(t *testing.T) {
+		t.Parallel()
+		const err = RecipientError(957361)
+		const expMsg = "Error: unknown."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+}
+
+func Test_RecipientError_Kind(t *testing.T) {
+	t.Parallel()
+	t.Run("KindAuth value", func(t *testing.T) {
+		t.Parallel()
+		errs := []RecipientError{
+			ErrIdentityLocked,
+			ErrAgentFailed,
+		}
+		const expKind = failure.KindAuth
+
+		for _, err := range errs {
+			kind := err.Kind()
+			assert.Equal(t, expKind, kind, err.Error())
+		}
+	})
+	t.Run("KindInvalid value", func(t *testing.T) {
+		t.Parallel()
+		errs := []RecipientError{
+			ErrInvalidRecipient,
+			ErrInvalidIdentity,
+			ErrUnsupportedKey,
+		}
+		const expKind = failure.KindInvalid
+
+		for _, err := range errs {
+			kind := err.Kind()
+			assert.Equal(t, expKind, kind, err.Error())
+		}
+	})
+	t.Run("Unknown value", func(t *testing.T) {
+		t.Parallel()
+		const err = RecipientError(613724)
+		const expKind = failure.KindInternal
+
+		kind := err.Kind()
+		assert.Equal(t, expKind, kind)
+	})
+}
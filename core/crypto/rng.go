@@ -1,9 +1,21 @@
 package crypto
 
+import (
+	"github.com/reshifr/secure-env/core/failure"
+)
+
+const (
+	OpRead = "read"
+)
+
 type RNGError int
 
 const (
 	ErrReadEntropyFailed RNGError = iota + 1
+	ErrShortSeed
+	ErrShortRead
+	ErrRepetitionCount
+	ErrAdaptiveProportion
 )
 
 func (err RNGError) Error() string {
@@ -11,11 +23,33 @@ func (err RNGError) Error() string {
 	case ErrReadEntropyFailed:
 		return "ErrReadEntropyFailed: " +
 			"Failed to read a random value from the entropy sources."
+	case ErrShortSeed:
+		return "ErrShortSeed: the DRBG seed is too short."
+	case ErrShortRead:
+		return "ErrShortRead: the entropy source returned too few bytes."
+	case ErrRepetitionCount:
+		return "ErrRepetitionCount: " +
+			"the entropy source repeated the same value too often."
+	case ErrAdaptiveProportion:
+		return "ErrAdaptiveProportion: " +
+			"one value is too frequent in the entropy source output."
 	default:
 		return "Error: unknown."
 	}
 }
 
+func (err RNGError) Kind() failure.Kind {
+	switch err {
+	case ErrShortSeed:
+		return failure.KindInvalid
+	case ErrReadEntropyFailed, ErrShortRead,
+		ErrRepetitionCount, ErrAdaptiveProportion:
+		return failure.KindEntropy
+	default:
+		return failure.KindInternal
+	}
+}
+
 type RNG interface {
 	Block(blockLen int) (block []byte, err error)
 	Read(block []byte) (err error)
@@ -3,6 +3,7 @@ package crypto
 import (
 	"testing"
 
+	"github.com/reshifr/secure-env/core/failure"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -17,6 +18,41 @@ func Test_RNGError_Error(t *testing.T) {
 		msg := err.Error()
 		assert.Equal(t, expMsg, msg)
 	})
+	t.Run("ErrShortSeed value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrShortSeed
+		const expMsg = "ErrShortSeed: the DRBG seed is too short."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrShortRead value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrShortRead
+		const expMsg = "ErrShortRead: " +
+			"the entropy source returned too few bytes."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrRepetitionCount value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrRepetitionCount
+		const expMsg = "ErrRepetitionCount: " +
+			"the entropy source repeated the same value too often."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrAdaptiveProportion value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrAdaptiveProportion
+		const expMsg = "ErrAdaptiveProportion: " +
+			"one value is too frequent in the entropy source output."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
 	t.Run("Unknown value", func(t *testing.T) {
 		t.Parallel()
 		const err = RNGError(957361)
@@ -26,3 +62,38 @@ func Test_RNGError_Error(t *testing.T) {
 		assert.Equal(t, expMsg, msg)
 	})
 }
+
+func Test_RNGError_Kind(t *testing.T) {
+	t.Parallel()
+	t.Run("KindInvalid value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrShortSeed
+		const expKind = failure.KindInvalid
+
+		kind := err.Kind()
+		assert.Equal(t, expKind, kind)
+	})
+	t.Run("KindEntropy value", func(t *testing.T) {
+		t.Parallel()
+		errs := []RNGError{
+			ErrReadEntropyFailed,
+			ErrShortRead,
+			ErrRepetitionCount,
+			ErrAdaptiveProportion,
+		}
+		const expKind = failure.KindEntropy
+
+		for _, err := range errs {
+			kind := err.Kind()
+			assert.Equal(t, expKind, kind, err.Error())
+		}
+	})
+	t.Run("Unknown value", func(t *testing.T) {
+		t.Parallel()
+		const err = RNGError(613724)
+		const expKind = failure.KindInternal
+
+		kind := err.Kind()
+		assert.Equal(t, expKind, kind)
+	})
+}
@@ -0,0 +1,79 @@
+package crypto
+
+import (
+	"unsafe"
+
+	"github.com/reshifr/secure-env/core/failure"
+)
+
+type SecretError int
+
+const (
+	ErrAllocSecretFailed SecretError = iota + 1
+)
+
+func (err SecretError) Error() string {
+	switch err {
+	case ErrAllocSecretFailed:
+		return "ErrAllocSecretFailed: " +
+			"failed to allocate locked memory for the secret."
+	default:
+		return "Error: unknown."
+	}
+}
+
+func (err SecretError) Kind() failure.Kind {
+	return failure.KindInternal
+}
+
+type Secret struct {
+	region    unsafe.Pointer
+	regionLen int
+	locked    bool
+	buf       []byte
+}
+
+func NewSecret(secretLen int) (*Secret, error) {
+	if secretLen == 0 {
+		return &Secret{buf: []byte{}}, nil
+	}
+	return allocSecret(secretLen)
+}
+
+func NewSecretFrom(b []byte) (*Secret, error) {
+	secret, err := NewSecret(len(b))
+	if err != nil {
+		clear(b)
+		return nil, err
+	}
+	copy(secret.buf, b)
+	clear(b)
+	return secret, nil
+}
+
+func (secret *Secret) Bytes() []byte {
+	if secret == nil {
+		return nil
+	}
+	return secret.buf
+}
+
+func (secret *Secret) Len() int {
+	return len(secret.Bytes())
+}
+
+func (secret *Secret) String() string {
+	return "crypto.Secret(redacted)"
+}
+
+func (secret *Secret) Destroy() {
+	if secret == nil || secret.buf == nil {
+		return
+	}
+	clear(secret.buf)
+	secret.free()
+	secret.region = nil
+	secret.regionLen = 0
+	secret.locked = false
+	secret.buf = nil
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package crypto
+
+func allocSecret(secretLen int) (*Secret, error) {
+	return &Secret{buf: make([]byte, secretLen)}, nil
+}
+
+func (secret *Secret) free() {}
@@ -0,0 +1,115 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/reshifr/secure-env/core/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SecretError_Error(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrAllocSecretFailed value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrAllocSecretFailed
+		const expMsg = "ErrAllocSecretFailed: " +
+			"failed to allocate locked memory for the secret."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("Unknown value", func(t *testing.T) {
+		t.Parallel()
+		const err = SecretError(0)
+		const expMsg = "Error: unknown."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+}
+
+func Test_NewSecret(t *testing.T) {
+	t.Parallel()
+	t.Run("Empty secret", func(t *testing.T) {
+		t.Parallel()
+		secret, err := NewSecret(0)
+		assert.Equal(t, []byte{}, secret.Bytes())
+		assert.ErrorIs(t, err, nil)
+		secret.Destroy()
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		const secretLen = 5000
+		expBytes := make([]byte, secretLen)
+
+		secret, err := NewSecret(secretLen)
+		assert.Equal(t, expBytes, secret.Bytes())
+		assert.Equal(t, secretLen, secret.Len())
+		assert.ErrorIs(t, err, nil)
+		secret.Destroy()
+	})
+}
+
+func Test_NewSecretFrom(t *testing.T) {
+	t.Parallel()
+	b := []byte{0x11, 0x22, 0x33}
+	expBytes := []byte{0x11, 0x22, 0x33}
+
+	secret, err := NewSecretFrom(b)
+	assert.Equal(t, expBytes, secret.Bytes())
+	assert.Equal(t, []byte{0x00, 0x00, 0x00}, b)
+	assert.ErrorIs(t, err, nil)
+	secret.Destroy()
+}
+
+func Test_Secret_String(t *testing.T) {
+	t.Parallel()
+	secret, _ := NewSecretFrom([]byte("s3cr3t"))
+	const expStr = "crypto.Secret(redacted)"
+
+	str := secret.String()
+	assert.Equal(t, expStr, str)
+	secret.Destroy()
+}
+
+func Test_Secret_Destroy(t *testing.T) {
+	t.Parallel()
+	t.Run("Nil secret", func(t *testing.T) {
+		t.Parallel()
+		var secret *Secret = nil
+
+		secret.Destroy()
+		assert.Equal(t, []byte(nil), secret.Bytes())
+		assert.Equal(t, 0, secret.Len())
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		secret, _ := NewSecretFrom([]byte{0x11, 0x22})
+
+		secret.Destroy()
+		assert.Equal(t, []byte(nil), secret.Bytes())
+		assert.Equal(t, 0, secret.Len())
+		secret.Destroy()
+		assert.Equal(t, []byte(nil), secret.Bytes())
+	})
+}
+
+func Test_SecretError_Kind(t *testing.T) {
+	t.Parallel()
+	t.Run("KindInternal value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrAllocSecretFailed
+		const expKind = failure.KindInternal
+
+		kind := err.Kind()
+		assert.Equal(t, expKind, kind)
+	})
+	t.Run("Unknown value", func(t *testing.T) {
+		t.Parallel()
+		const err = SecretError(613724)
+		const expKind = failure.KindInternal
+
+		kind := err.Kind()
+		assert.Equal(t, expKind, kind)
+	})
+}
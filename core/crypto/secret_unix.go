@@ -0,0 +1,49 @@
+//go:build unix
+
+package crypto
+
+import (
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+func allocSecret(secretLen int) (*Secret, error) {
+	pageLen := os.Getpagesize()
+	dataLen := (secretLen + pageLen - 1) / pageLen * pageLen
+	region, err := unix.Mmap(-1, 0, dataLen+2*pageLen,
+		unix.PROT_READ|unix.PROT_WRITE, unix.MAP_PRIVATE|unix.MAP_ANON)
+	if err != nil {
+		return nil, ErrAllocSecretFailed
+	}
+	data := region[pageLen : pageLen+dataLen]
+	if unix.Mprotect(region[:pageLen], unix.PROT_NONE) != nil ||
+		unix.Mprotect(region[pageLen+dataLen:], unix.PROT_NONE) != nil {
+		unix.Munmap(region)
+		return nil, ErrAllocSecretFailed
+	}
+	locked := unix.Mlock(data) == nil
+	excludeFromDump(data)
+	secret := &Secret{
+		region:    unsafe.Pointer(&region[0]),
+		regionLen: len(region),
+		locked:    locked,
+		buf:       data[dataLen-secretLen:],
+	}
+	return secret, nil
+}
+
+func (secret *Secret) free() {
+	if secret.region == nil {
+		return
+	}
+	pageLen := os.Getpagesize()
+	region := unsafe.Slice((*byte)(secret.region), secret.regionLen)
+	data := region[pageLen : len(region)-pageLen]
+	clear(data)
+	if secret.locked {
+		unix.Munlock(data)
+	}
+	unix.Munmap(region)
+}
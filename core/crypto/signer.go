@@ -0,0 +1,46 @@
+package crypto
+
+import (
+	"github.com/reshifr/secure-env/core/failure"
+)
+
+type SignerError int
+
+const (
+	ErrInvalidSignature SignerError = iota + 1
+	ErrInvalidPublicKeyLen
+	ErrInvalidSeedLen
+)
+
+func (err SignerError) Error() string {
+	switch err {
+	case ErrInvalidSignature:
+		return "ErrInvalidSignature: the signature does not match the data."
+	case ErrInvalidPublicKeyLen:
+		return "ErrInvalidPublicKeyLen: invalid public key length."
+	case ErrInvalidSeedLen:
+		return "ErrInvalidSeedLen: invalid seed length."
+	default:
+		return "Error: unknown."
+	}
+}
+
+func (err SignerError) Kind() failure.Kind {
+	switch err {
+	case ErrInvalidSignature:
+		return failure.KindIntegrity
+	case ErrInvalidPublicKeyLen, ErrInvalidSeedLen:
+		return failure.KindInvalid
+	default:
+		return failure.KindInternal
+	}
+}
+
+type Signer interface {
+	PublicKey() (publicKey []byte)
+	Sign(msg []byte) (sig []byte, err error)
+}
+
+type Verifier interface {
+	Verify(publicKey []byte, msg []byte, sig []byte) (err error)
+}
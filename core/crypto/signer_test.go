@@ -0,0 +1,78 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/reshifr/secure-env/core/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SignerError_Error(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrInvalidSignature value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrInvalidSignature
+		const expMsg = "ErrInvalidSignature: " +
+			"the signature does not match the data."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrInvalidPublicKeyLen value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrInvalidPublicKeyLen
+		const expMsg = "ErrInvalidPublicKeyLen: invalid public key length."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrInvalidSeedLen value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrInvalidSeedLen
+		const expMsg = "ErrInvalidSeedLen: invalid seed length."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("Unknown value", func(t *testing.T) {
+		t.Parallel()
+		const err = SignerError(957361)
+		const expMsg = "Error: unknown."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+}
+
+func Test_SignerError_Kind(t *testing.T) {
+	t.Parallel()
+	t.Run("KindIntegrity value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrInvalidSignature
+		const expKind = failure.KindIntegrity
+
+		kind := err.Kind()
+		assert.Equal(t, expKind, kind)
+	})
+	t.Run("KindInvalid value", func(t *testing.T) {
+		t.Parallel()
+		errs := []SignerError{
+			ErrInvalidPublicKeyLen,
+			ErrInvalidSeedLen,
+		}
+		const expKind = failure.KindInvalid
+
+		for _, err := range errs {
+			kind := err.Kind()
+			assert.Equal(t, expKind, kind, err.Error())
+		}
+	})
+	t.Run("Unknown value", func(t *testing.T) {
+		t.Parallel()
+		const err = SignerError(613724)
+		const expKind = failure.KindInternal
+
+		kind := err.Kind()
+		assert.Equal(t, expKind, kind)
+	})
+}
@@ -0,0 +1,46 @@
+package crypto
+
+import (
+	"io"
+
+	"github.com/reshifr/secure-env/core/failure"
+)
+
+type StreamError int
+
+const (
+	ErrStreamTruncated StreamError = iota + 1
+	ErrStreamClosed
+	ErrInvalidChunkLen
+)
+
+func (err StreamError) Error() string {
+	switch err {
+	case ErrStreamTruncated:
+		return "ErrStreamTruncated: the stream ended before its final chunk."
+	case ErrStreamClosed:
+		return "ErrStreamClosed: the stream is already closed."
+	case ErrInvalidChunkLen:
+		return "ErrInvalidChunkLen: invalid chunk length."
+	default:
+		return "Error: unknown."
+	}
+}
+
+func (err StreamError) Kind() failure.Kind {
+	switch err {
+	case ErrStreamTruncated:
+		return failure.KindIntegrity
+	case ErrInvalidChunkLen:
+		return failure.KindInvalid
+	default:
+		return failure.KindInternal
+	}
+}
+
+type Stream interface {
+	NewWriter(iv IV, key *Secret, ad []byte, w io.Writer) (
+		stream io.WriteCloser, err error)
+	NewReader(key *Secret, ad []byte, r io.Reader) (
+		stream io.ReadCloser, err error)
+}
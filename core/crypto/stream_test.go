@@ -0,0 +1,81 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/reshifr/secure-env/core/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_StreamError_Error(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrStreamTruncated value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrStreamTruncated
+		const expMsg = "ErrStreamTruncated: " +
+			"the stream ended before its final chunk."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrStreamClosed value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrStreamClosed
+		const expMsg = "ErrStreamClosed: the stream is already closed."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrInvalidChunkLen value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrInvalidChunkLen
+		const expMsg = "ErrInvalidChunkLen: invalid chunk length."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("Unknown value", func(t *testing.T) {
+		t.Parallel()
+		const err = StreamError(957361)
+		const expMsg = "Error: unknown."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+}
+
+func Test_StreamError_Kind(t *testing.T) {
+	t.Parallel()
+	t.Run("KindIntegrity value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrStreamTruncated
+		const expKind = failure.KindIntegrity
+
+		kind := err.Kind()
+		assert.Equal(t, expKind, kind)
+	})
+	t.Run("KindInvalid value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrInvalidChunkLen
+		const expKind = failure.KindInvalid
+
+		kind := err.Kind()
+		assert.Equal(t, expKind, kind)
+	})
+	t.Run("KindInternal value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrStreamClosed
+		const expKind = failure.KindInternal
+
+		kind := err.Kind()
+		assert.Equal(t, expKind, kind)
+	})
+	t.Run("Unknown value", func(t *testing.T) {
+		t.Parallel()
+		const err = StreamError(613724)
+		const expKind = failure.KindInternal
+
+		kind := err.Kind()
+		assert.Equal(t, expKind, kind)
+	})
+}
@@ -0,0 +1,64 @@
+package crypto_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	cimpl "github.com/reshifr/secure-env/core/crypto/impl"
+	"github.com/reshifr/secure-env/core/env"
+	eimpl "github.com/reshifr/secure-env/core/env/impl"
+	"github.com/reshifr/secure-env/core/vault"
+	vimpl "github.com/reshifr/secure-env/core/vault/impl"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	vaultFixture = "testdata/vault.json"
+)
+
+var (
+	interpolator = eimpl.NewInterpolator(eimpl.FnInterpolator{})
+)
+
+func Test_Vault_Fixture(t *testing.T) {
+	t.Parallel()
+	seed := bytes.Repeat([]byte{0x5e}, cimpl.HMACDRBGMinSeedLen)
+	rng, _ := cimpl.NewHMACDRBG(seed, []byte("secure-env"))
+	cipher := cimpl.ChaChaPoly{}
+	stream, _ := cimpl.NewStream(cipher, cimpl.StreamChunkLen)
+	kdf := cimpl.NewArgon(crypto.KDFParams{Time: 1, Memory: 64, Threads: 1})
+	authorizer := cimpl.NewRoleAuthorizer(kdf, rng, cipher)
+	now := func() time.Time { return time.Unix(1700000000, 0).UTC() }
+	keeper := vimpl.NewKeeper(vimpl.FnKeeper{Now: now},
+		authorizer, cipher, stream, cimpl.NewHKDF([]byte("secure-env")))
+	rawIV, _ := rng.Block(cimpl.IV96Len)
+	iv, _ := cimpl.LoadIV96(rawIV)
+	id, _ := vimpl.NewVaultID(rng)
+	expBuf, _ := os.ReadFile(vaultFixture)
+
+	passphrase, _ := crypto.NewSecretFrom([]byte("+DF7Rc-X/MOYjkNj"))
+	v := &vault.Vault{ID: id}
+	keyring, err := keeper.CreateEnv(iv, v, "prod", "", "admin", passphrase)
+	assert.ErrorIs(t, err, nil)
+	err = keeper.Set(iv, v, keyring, "prod", "admin",
+		env.Var{Name: "DB_PASS", Value: "s3cr3t"})
+	assert.ErrorIs(t, err, nil)
+	err = keeper.SetFile(iv, v, keyring, "prod", "admin",
+		"CA_BUNDLE", strings.NewReader("-----BEGIN CERTIFICATE-----\n"))
+	assert.ErrorIs(t, err, nil)
+	err = keeper.Stamp(iv, v, keyring)
+	assert.ErrorIs(t, err, nil)
+
+	buf, err := json.MarshalIndent(v, "", "\t")
+	assert.Equal(t, string(expBuf), string(buf)+"\n")
+	assert.ErrorIs(t, err, nil)
+
+	vars, err := keeper.Resolve(v, keyring, "prod", interpolator)
+	assert.Equal(t, "s3cr3t", vars[0].Value)
+	assert.ErrorIs(t, err, nil)
+}
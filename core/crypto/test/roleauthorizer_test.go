@@ -0,0 +1,79 @@
+package crypto_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	cimpl "github.com/reshifr/secure-env/core/crypto/impl"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RoleAuthorizer_Make(t *testing.T) {
+	t.Parallel()
+	seed := bytes.Repeat([]byte{0x5e}, cimpl.HMACDRBGMinSeedLen)
+	rng, _ := cimpl.NewHMACDRBG(seed, []byte("secure-env"))
+	cipher := cimpl.ChaChaPoly{}
+	kdf := cimpl.NewArgon(crypto.KDFParams{Time: 1, Memory: 64, Threads: 1})
+	authorizer := cimpl.NewRoleAuthorizer(kdf, rng, cipher)
+	rawIV, _ := rng.Block(cimpl.IV96Len)
+	iv, _ := cimpl.LoadIV96(rawIV)
+	passphrase, _ := crypto.NewSecretFrom([]byte("+DF7Rc-X/MOYjkNj"))
+	expAccessKey, _ := hex.DecodeString("d68b5df289f3e9536f33ed5bb2dc6a20" +
+		"e7897e9529f588bc7c542b91dec36e24")
+	expBlock, _ := hex.DecodeString("3e9b977944c0adec9eb72e2d28e7959b" +
+		"b284140c373bc7b7cbfb1a65e2bf78f2755de5f8cfe752538dfb8fe80737132c" +
+		"cf123e15713f548a58fbee08a454cd3669030c8e78b132d4e2a9542d")
+
+	accessKey, block, err := authorizer.Make(iv, passphrase, cipher.KeyLen())
+	assert.Equal(t, expAccessKey, accessKey.Bytes())
+	assert.Equal(t, expBlock, block)
+	assert.ErrorIs(t, err, nil)
+
+	openedAccessKey, err := authorizer.Open(
+		authorizer.KDFParams(), passphrase, block)
+	assert.Equal(t, expAccessKey, openedAccessKey.Bytes())
+	assert.ErrorIs(t, err, nil)
+}
+
+func Test_RoleAuthorizer_Open(t *testing.T) {
+	t.Parallel()
+	seed := bytes.Repeat([]byte{0x5e}, cimpl.HMACDRBGMinSeedLen)
+	rng, _ := cimpl.NewHMACDRBG(seed, []byte("secure-env"))
+	cipher := cimpl.ChaChaPoly{}
+	params := crypto.KDFParams{Time: 1, Memory: 64, Threads: 1}
+	rawIV, _ := rng.Block(cimpl.IV96Len)
+	iv, _ := cimpl.LoadIV96(rawIV)
+	passphrase, _ := crypto.NewSecretFrom([]byte("+DF7Rc-X/MOYjkNj"))
+	maker := cimpl.NewRoleAuthorizer(cimpl.NewArgon(params), rng, cipher)
+	accessKey, block, _ := maker.Make(iv, passphrase, cipher.KeyLen())
+	authorizer := cimpl.NewRoleAuthorizer(cimpl.Argon{}, rng, cipher)
+
+	t.Run("ErrInvalidKDFParams error", func(t *testing.T) {
+		t.Parallel()
+		invalid := []crypto.KDFParams{
+			{Algorithm: cimpl.HKDFAlgorithm},
+			{Algorithm: cimpl.ArgonAlgorithm, Memory: 64, Threads: 1},
+			{Algorithm: cimpl.ArgonAlgorithm, Time: 1, Memory: 64},
+			{Algorithm: cimpl.ArgonAlgorithm, Time: 1, Memory: 4, Threads: 1},
+		}
+		var expAccessKey *crypto.Secret = nil
+		const expErr = crypto.ErrInvalidKDFParams
+
+		for _, params := range invalid {
+			accessKey, err := authorizer.Open(params, passphrase, block)
+			assert.Equal(t, expAccessKey, accessKey)
+			assert.ErrorIs(t, err, expErr)
+		}
+	})
+	t.Run("Slot params", func(t *testing.T) {
+		t.Parallel()
+		expAccessKey := accessKey.Bytes()
+
+		openedAccessKey, err := authorizer.Open(
+			maker.KDFParams(), passphrase, block)
+		assert.Equal(t, expAccessKey, openedAccessKey.Bytes())
+		assert.ErrorIs(t, err, nil)
+	})
+}
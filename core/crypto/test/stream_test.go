@@ -0,0 +1,51 @@
+package crypto_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"io"
+	"testing"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	cimpl "github.com/reshifr/secure-env/core/crypto/impl"
+	"github.com/stretchr/testify/assert"
+)
+
+func roundTrip[Cipher cimpl.StreamCipher](t *testing.T, cipher Cipher) {
+	rng := cimpl.NewStdRNG(cimpl.FnStdRNG{Read: rand.Read})
+	rawIV, _ := rng.Block(cimpl.IV96Len)
+	iv, _ := cimpl.LoadIV96(rawIV)
+	rawKey, _ := rng.Block(int(cipher.KeyLen()))
+	key, _ := crypto.NewSecretFrom(rawKey)
+	stream, _ := cimpl.NewStream(cipher, cimpl.StreamChunkLen)
+	const plaintextLen = 4<<20 + 123
+
+	src := sha256.New()
+	sealed := &bytes.Buffer{}
+	w, err := stream.NewWriter(iv, key, nil, sealed)
+	assert.ErrorIs(t, err, nil)
+	plaintext := io.LimitReader(rand.Reader, plaintextLen)
+	_, err = io.Copy(w, io.TeeReader(plaintext, src))
+	assert.ErrorIs(t, err, nil)
+	assert.ErrorIs(t, w.Close(), nil)
+
+	dst := sha256.New()
+	r, err := stream.NewReader(key, nil, sealed)
+	assert.ErrorIs(t, err, nil)
+	n, err := io.Copy(dst, r)
+	assert.Equal(t, int64(plaintextLen), n)
+	assert.ErrorIs(t, err, nil)
+	assert.Equal(t, src.Sum(nil), dst.Sum(nil))
+	assert.ErrorIs(t, r.Close(), nil)
+}
+
+func Test_Stream_ChaChaPoly(t *testing.T) {
+	t.Parallel()
+	roundTrip(t, cimpl.ChaChaPoly{})
+}
+
+func Test_Stream_AESGCM(t *testing.T) {
+	t.Parallel()
+	roundTrip(t, cimpl.AESGCM{})
+}
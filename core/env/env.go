@@ -0,0 +1,68 @@
+package env
+
+import (
+	"github.com/reshifr/secure-env/core/failure"
+)
+
+type FormatError int
+
+const (
+	ErrInvalidVarName FormatError = iota + 1
+	ErrInvalidVarValue
+	ErrInvalidSyntax
+	ErrUnknownFormat
+)
+
+func (err FormatError) Error() string {
+	switch err {
+	case ErrInvalidVarName:
+		return "ErrInvalidVarName: invalid variable name."
+	case ErrInvalidVarValue:
+		return "ErrInvalidVarValue: " +
+			"the variable value cannot be represented in this format."
+	case ErrInvalidSyntax:
+		return "ErrInvalidSyntax: the input cannot be parsed."
+	case ErrUnknownFormat:
+		return "ErrUnknownFormat: unknown output format."
+	default:
+		return "Error: unknown."
+	}
+}
+
+func (err FormatError) Kind() failure.Kind {
+	switch err {
+	case ErrInvalidVarName, ErrInvalidVarValue, ErrInvalidSyntax, ErrUnknownFormat:
+		return failure.KindInvalid
+	default:
+		return failure.KindInternal
+	}
+}
+
+type Var struct {
+	Name  string
+	Value string
+	// Interpolate stores the value as a template whose ${VAR} references
+	// are expanded on resolve. Other values are kept literally.
+	Interpolate bool
+}
+
+type Format interface {
+	Encode(vars []Var) (buf []byte, err error)
+	Decode(buf []byte) (vars []Var, err error)
+}
+
+func ValidName(name string) bool {
+	if len(name) == 0 {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c == '_', c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
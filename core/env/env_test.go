@@ -0,0 +1,100 @@
+package env
+
+import (
+	"testing"
+
+	"github.com/reshifr/secure-env/core/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_FormatError_Error(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrInvalidVarName value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrInvalidVarName
+		const expMsg = "ErrInvalidVarName: invalid variable name."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrInvalidVarValue value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrInvalidVarValue
+		const expMsg = "ErrInvalidVarValue: " +
+			"the variable value cannot be represented in this format."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrInvalidSyntax value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrInvalidSyntax
+		const expMsg = "ErrInvalidSyntax: the input cannot be parsed."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrUnknownFormat value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrUnknownFormat
+		const expMsg = "ErrUnknownFormat: unknown output format."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("Unknown value", func(t *testing.T) {
+		t.Parallel()
+		const err = FormatError(846213)
+		const expMsg = "Error: unknown."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+}
+
+func Test_ValidName(t *testing.T) {
+	t.Parallel()
+	t.Run("Invalid name", func(t *testing.T) {
+		t.Parallel()
+		names := []string{"", "1A", "A-B", "A B", "A=B", "Ä"}
+
+		for _, name := range names {
+			assert.False(t, ValidName(name))
+		}
+	})
+	t.Run("Valid name", func(t *testing.T) {
+		t.Parallel()
+		names := []string{"A", "_", "a1", "DB_HOST", "_X9"}
+
+		for _, name := range names {
+			assert.True(t, ValidName(name))
+		}
+	})
+}
+
+func Test_FormatError_Kind(t *testing.T) {
+	t.Parallel()
+	t.Run("KindInvalid value", func(t *testing.T) {
+		t.Parallel()
+		errs := []FormatError{
+			ErrInvalidVarName,
+			ErrInvalidVarValue,
+			ErrInvalidSyntax,
+			ErrUnknownFormat,
+		}
+		const expKind = failure.KindInvalid
+
+		for _, err := range errs {
+			kind := err.Kind()
+			assert.Equal(t, expKind, kind, err.Error())
+		}
+	})
+	t.Run("Unknown value", func(t *testing.T) {
+		t.Parallel()
+		const err = FormatError(613724)
+		const expKind = failure.KindInternal
+
+		kind := err.Kind()
+		assert.Equal(t, expKind, kind)
+	})
+}
@@ -0,0 +1,53 @@
+package env_impl
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+
+	"github.com/reshifr/secure-env/core/env"
+)
+
+type Docker struct{}
+
+func (Docker) Encode(vars []env.Var) ([]byte, error) {
+	if err := validateVars(vars); err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	for _, v := range vars {
+		if strings.ContainsAny(v.Value, "\n\r\x00") {
+			return nil, env.ErrInvalidVarValue
+		}
+		b.WriteString(v.Name)
+		b.WriteString("=")
+		b.WriteString(v.Value)
+		b.WriteString("\n")
+	}
+	return []byte(b.String()), nil
+}
+
+func (Docker) Decode(buf []byte) ([]env.Var, error) {
+	vars := []env.Var{}
+	lines := bufio.NewScanner(bytes.NewReader(buf))
+	// Like the other decoders, accept a line as long as the whole input.
+	lines.Buffer(nil, len(buf)+1)
+	for lines.Scan() {
+		line := strings.TrimLeft(lines.Text(), " \t")
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, env.ErrInvalidSyntax
+		}
+		if !env.ValidName(name) {
+			return nil, env.ErrInvalidVarName
+		}
+		vars = append(vars, env.Var{Name: name, Value: value})
+	}
+	if err := lines.Err(); err != nil {
+		return nil, env.ErrInvalidSyntax
+	}
+	return vars, nil
+}
@@ -0,0 +1,115 @@
+package env_impl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/reshifr/secure-env/core/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Docker_Encode(t *testing.T) {
+	t.Parallel()
+	format := Docker{}
+
+	t.Run("ErrInvalidVarName error", func(t *testing.T) {
+		t.Parallel()
+		vars := []env.Var{{Name: "A=B", Value: "x"}}
+		var expBuf []byte = nil
+		const expErr = env.ErrInvalidVarName
+
+		buf, err := format.Encode(vars)
+		assert.Equal(t, expBuf, buf)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrInvalidVarValue error", func(t *testing.T) {
+		t.Parallel()
+		vars := []env.Var{{Name: "CERT", Value: "line1\nline2"}}
+		var expBuf []byte = nil
+		const expErr = env.ErrInvalidVarValue
+
+		buf, err := format.Encode(vars)
+		assert.Equal(t, expBuf, buf)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		vars := []env.Var{
+			{Name: "USER", Value: "admin"},
+			{Name: "PASS", Value: " \"quoted\" $x # y"},
+		}
+		expBuf := []byte("USER=admin\n" +
+			"PASS= \"quoted\" $x # y\n")
+
+		buf, err := format.Encode(vars)
+		assert.Equal(t, expBuf, buf)
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_Docker_Decode(t *testing.T) {
+	t.Parallel()
+	format := Docker{}
+
+	t.Run("ErrInvalidSyntax error", func(t *testing.T) {
+		t.Parallel()
+		buf := []byte("USER\n")
+		var expVars []env.Var = nil
+		const expErr = env.ErrInvalidSyntax
+
+		vars, err := format.Decode(buf)
+		assert.Equal(t, expVars, vars)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrInvalidVarName error", func(t *testing.T) {
+		t.Parallel()
+		buf := []byte("A B=x\n")
+		var expVars []env.Var = nil
+		const expErr = env.ErrInvalidVarName
+
+		vars, err := format.Decode(buf)
+		assert.Equal(t, expVars, vars)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		buf := []byte("# comment\n" +
+			"\n" +
+			"  USER=admin\n" +
+			"PASS= \"quoted\" # y\r\n")
+		expVars := []env.Var{
+			{Name: "USER", Value: "admin"},
+			{Name: "PASS", Value: " \"quoted\" # y"},
+		}
+
+		vars, err := format.Decode(buf)
+		assert.Equal(t, expVars, vars)
+		assert.ErrorIs(t, err, nil)
+	})
+	t.Run("Succeed CRLF", func(t *testing.T) {
+		t.Parallel()
+		buf := []byte("USER=admin\r\n\r\nPASS=x\r\r\nHOST=db\r")
+		expVars := []env.Var{
+			{Name: "USER", Value: "admin"},
+			{Name: "PASS", Value: "x\r"},
+			{Name: "HOST", Value: "db"},
+		}
+
+		vars, err := format.Decode(buf)
+		assert.Equal(t, expVars, vars)
+		assert.ErrorIs(t, err, nil)
+	})
+	t.Run("Succeed long line", func(t *testing.T) {
+		t.Parallel()
+		value := strings.Repeat("x", 256*1024)
+		buf := []byte("CERT=" + value + "\nUSER=admin")
+		expVars := []env.Var{
+			{Name: "CERT", Value: value},
+			{Name: "USER", Value: "admin"},
+		}
+
+		vars, err := format.Decode(buf)
+		assert.Equal(t, expVars, vars)
+		assert.ErrorIs(t, err, nil)
+	})
+}
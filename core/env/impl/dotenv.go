@@ -0,0 +1,127 @@
+package env_impl
+
+import (
+	"strings"
+
+	"github.com/reshifr/secure-env/core/env"
+)
+
+const (
+	dotenvComments = "#"
+)
+
+type Dotenv struct{}
+
+func (Dotenv) Encode(vars []env.Var) ([]byte, error) {
+	if err := validateVars(vars); err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	for _, v := range vars {
+		b.WriteString(v.Name)
+		b.WriteString("=\"")
+		for i := 0; i < len(v.Value); i++ {
+			switch c := v.Value[i]; c {
+			case '\\', '"', '$':
+				b.WriteByte('\\')
+				b.WriteByte(c)
+			case '\n':
+				b.WriteString("\\n")
+			case '\r':
+				b.WriteString("\\r")
+			case '\t':
+				b.WriteString("\\t")
+			default:
+				b.WriteByte(c)
+			}
+		}
+		b.WriteString("\"\n")
+	}
+	return []byte(b.String()), nil
+}
+
+func (Dotenv) Decode(buf []byte) ([]env.Var, error) {
+	s := &scanner{buf: buf}
+	vars := []env.Var{}
+	for s.next(dotenvComments) {
+		s.keyword("export")
+		name, err := s.name()
+		if err != nil {
+			return nil, err
+		}
+		if err := s.assign(true); err != nil {
+			return nil, err
+		}
+		value, err := dotenvValue(s)
+		if err != nil {
+			return nil, err
+		}
+		vars = append(vars, env.Var{Name: name, Value: value})
+	}
+	return vars, nil
+}
+
+func dotenvValue(s *scanner) (string, error) {
+	if s.eof() {
+		return "", nil
+	}
+	var b strings.Builder
+	switch s.peek() {
+	case '"':
+		s.pos++
+		for {
+			if s.eof() {
+				return "", env.ErrInvalidSyntax
+			}
+			c := s.peek()
+			s.pos++
+			if c == '"' {
+				break
+			}
+			if c != '\\' || s.eof() {
+				b.WriteByte(c)
+				continue
+			}
+			e := s.peek()
+			s.pos++
+			switch e {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case '\\', '"', '$':
+				b.WriteByte(e)
+			default:
+				b.WriteByte('\\')
+				b.WriteByte(e)
+			}
+		}
+	case '\'':
+		s.pos++
+		for {
+			if s.eof() {
+				return "", env.ErrInvalidSyntax
+			}
+			c := s.peek()
+			s.pos++
+			if c == '\'' {
+				break
+			}
+			b.WriteByte(c)
+		}
+	default:
+		for !s.eof() && s.peek() != '\n' {
+			c := s.peek()
+			if c == '#' && s.pos > 0 &&
+				(s.buf[s.pos-1] == ' ' || s.buf[s.pos-1] == '\t') {
+				break
+			}
+			b.WriteByte(c)
+			s.pos++
+		}
+		return strings.TrimRight(b.String(), " \t\r"), s.endOfLine(dotenvComments)
+	}
+	return b.String(), s.endOfLine(dotenvComments)
+}
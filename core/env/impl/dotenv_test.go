@@ -0,0 +1,93 @@
+package env_impl
+
+import (
+	"testing"
+
+	"github.com/reshifr/secure-env/core/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Dotenv_Encode(t *testing.T) {
+	t.Parallel()
+	format := Dotenv{}
+
+	t.Run("ErrInvalidVarName error", func(t *testing.T) {
+		t.Parallel()
+		vars := []env.Var{{Name: "1ABC", Value: "x"}}
+		var expBuf []byte = nil
+		const expErr = env.ErrInvalidVarName
+
+		buf, err := format.Encode(vars)
+		assert.Equal(t, expBuf, buf)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		vars := []env.Var{
+			{Name: "USER", Value: "admin"},
+			{Name: "PASS", Value: "a\"b\\c$d\ne\tf"},
+		}
+		expBuf := []byte("USER=\"admin\"\n" +
+			"PASS=\"a\\\"b\\\\c\\$d\\ne\\tf\"\n")
+
+		buf, err := format.Encode(vars)
+		assert.Equal(t, expBuf, buf)
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_Dotenv_Decode(t *testing.T) {
+	t.Parallel()
+	format := Dotenv{}
+
+	t.Run("ErrInvalidVarName error", func(t *testing.T) {
+		t.Parallel()
+		buf := []byte("-A=1\n")
+		var expVars []env.Var = nil
+		const expErr = env.ErrInvalidVarName
+
+		vars, err := format.Decode(buf)
+		assert.Equal(t, expVars, vars)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrInvalidSyntax error", func(t *testing.T) {
+		t.Parallel()
+		bufs := [][]byte{
+			[]byte("A\n"),
+			[]byte("A=\"unterminated\n"),
+			[]byte("A='unterminated\n"),
+			[]byte("A=\"x\" y\n"),
+		}
+		var expVars []env.Var = nil
+		const expErr = env.ErrInvalidSyntax
+
+		for _, buf := range bufs {
+			vars, err := format.Decode(buf)
+			assert.Equal(t, expVars, vars)
+			assert.ErrorIs(t, err, expErr)
+		}
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		buf := []byte("# comment\n" +
+			"\n" +
+			"export USER=admin # trailing\n" +
+			"HOST = db.local\r\n" +
+			"PASS=\"a\\\"b\\\\c\\$d\\ne\"\n" +
+			"RAW='x\\ny'\n" +
+			"EMPTY=\n" +
+			"TAG=a#b")
+		expVars := []env.Var{
+			{Name: "USER", Value: "admin"},
+			{Name: "HOST", Value: "db.local"},
+			{Name: "PASS", Value: "a\"b\\c$d\ne"},
+			{Name: "RAW", Value: "x\\ny"},
+			{Name: "EMPTY", Value: ""},
+			{Name: "TAG", Value: "a#b"},
+		}
+
+		vars, err := format.Decode(buf)
+		assert.Equal(t, expVars, vars)
+		assert.ErrorIs(t, err, nil)
+	})
+}
@@ -0,0 +1,161 @@
+package env_impl
+
+import (
+	"github.com/reshifr/secure-env/core/env"
+)
+
+const (
+	FormatDotenv    = "dotenv"
+	FormatShell     = "shell"
+	FormatJSON      = "json"
+	FormatDocker    = "docker"
+	FormatSystemd   = "systemd"
+	FormatK8sSecret = "k8s"
+)
+
+func NewFormat(name string) (env.Format, error) {
+	switch name {
+	case FormatDotenv:
+		return Dotenv{}, nil
+	case FormatShell:
+		return Shell{}, nil
+	case FormatJSON:
+		return JSON{}, nil
+	case FormatDocker:
+		return Docker{}, nil
+	case FormatSystemd:
+		return Systemd{}, nil
+	case FormatK8sSecret:
+		return K8sSecret{Name: K8sSecretDefaultName}, nil
+	default:
+		return nil, env.ErrUnknownFormat
+	}
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z')
+}
+
+func isNameChar(c byte) bool {
+	return isNameStart(c) || (c >= '0' && c <= '9')
+}
+
+func validateVars(vars []env.Var) error {
+	for _, v := range vars {
+		if !env.ValidName(v.Name) {
+			return env.ErrInvalidVarName
+		}
+	}
+	return nil
+}
+
+type scanner struct {
+	buf []byte
+	pos int
+}
+
+func (s *scanner) eof() bool {
+	return s.pos >= len(s.buf)
+}
+
+func (s *scanner) peek() byte {
+	return s.buf[s.pos]
+}
+
+func (s *scanner) skipSpaces() {
+	for !s.eof() && (s.peek() == ' ' || s.peek() == '\t') {
+		s.pos++
+	}
+}
+
+func (s *scanner) skipLine() {
+	for !s.eof() && s.peek() != '\n' {
+		s.pos++
+	}
+	if !s.eof() {
+		s.pos++
+	}
+}
+
+func (s *scanner) next(comments string) bool {
+	for {
+		s.skipSpaces()
+		if s.eof() {
+			return false
+		}
+		c := s.peek()
+		switch {
+		case c == '\n' || c == '\r':
+			s.pos++
+		case contains(comments, c):
+			s.skipLine()
+		default:
+			return true
+		}
+	}
+}
+
+func (s *scanner) keyword(word string) bool {
+	end := s.pos + len(word)
+	if end >= len(s.buf) || string(s.buf[s.pos:end]) != word {
+		return false
+	}
+	if s.buf[end] != ' ' && s.buf[end] != '\t' {
+		return false
+	}
+	s.pos = end
+	s.skipSpaces()
+	return true
+}
+
+func (s *scanner) name() (string, error) {
+	start := s.pos
+	if s.eof() || !isNameStart(s.peek()) {
+		return "", env.ErrInvalidVarName
+	}
+	for !s.eof() && isNameChar(s.peek()) {
+		s.pos++
+	}
+	return string(s.buf[start:s.pos]), nil
+}
+
+func (s *scanner) assign(spaced bool) error {
+	if spaced {
+		s.skipSpaces()
+	}
+	if s.eof() || s.peek() != '=' {
+		return env.ErrInvalidSyntax
+	}
+	s.pos++
+	if spaced {
+		s.skipSpaces()
+	}
+	return nil
+}
+
+func (s *scanner) endOfLine(comments string) error {
+	s.skipSpaces()
+	if s.eof() {
+		return nil
+	}
+	switch c := s.peek(); {
+	case c == '\n':
+		s.pos++
+	case c == '\r' && s.pos+1 < len(s.buf) && s.buf[s.pos+1] == '\n':
+		s.pos += 2
+	case contains(comments, c):
+		s.skipLine()
+	default:
+		return env.ErrInvalidSyntax
+	}
+	return nil
+}
+
+func contains(set string, c byte) bool {
+	for i := 0; i < len(set); i++ {
+		if set[i] == c {
+			return true
+		}
+	}
+	return false
+}
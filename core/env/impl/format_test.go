@@ -0,0 +1,38 @@
+package env_impl
+
+import (
+	"testing"
+
+	"github.com/reshifr/secure-env/core/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewFormat(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrUnknownFormat error", func(t *testing.T) {
+		t.Parallel()
+		var expFormat env.Format = nil
+		const expErr = env.ErrUnknownFormat
+
+		format, err := NewFormat("toml")
+		assert.Equal(t, expFormat, format)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		expFormats := map[string]env.Format{
+			FormatDotenv:    Dotenv{},
+			FormatShell:     Shell{},
+			FormatJSON:      JSON{},
+			FormatDocker:    Docker{},
+			FormatSystemd:   Systemd{},
+			FormatK8sSecret: K8sSecret{Name: K8sSecretDefaultName},
+		}
+
+		for name, expFormat := range expFormats {
+			format, err := NewFormat(name)
+			assert.Equal(t, expFormat, format)
+			assert.ErrorIs(t, err, nil)
+		}
+	})
+}
@@ -0,0 +1,144 @@
+package env_impl
+
+import (
+	"strings"
+
+	"github.com/reshifr/secure-env/core/env"
+)
+
+const (
+	InterpolatorDefaultSep = ":-"
+)
+
+type Interpolator struct {
+	fn FnInterpolator
+}
+
+type FnInterpolator struct {
+	LookupEnv func(key string) (value string, ok bool)
+}
+
+func NewInterpolator(fn FnInterpolator) Interpolator {
+	return Interpolator{fn: fn}
+}
+
+func (interpolator Interpolator) Expand(vars []env.Var) ([]env.Var, error) {
+	x := &expansion{
+		lookupEnv: interpolator.fn.LookupEnv,
+		raw:       map[string]string{},
+		expanded:  map[string]string{},
+		active:    map[string]struct{}{},
+	}
+	for _, v := range vars {
+		x.raw[v.Name] = v.Value
+	}
+	expandedVars := make([]env.Var, len(vars))
+	for i, v := range vars {
+		value, _, err := x.resolve(v.Name)
+		if err != nil {
+			return nil, err
+		}
+		expandedVars[i] = env.Var{Name: v.Name, Value: value}
+	}
+	return expandedVars, nil
+}
+
+type expansion struct {
+	lookupEnv func(key string) (value string, ok bool)
+	raw       map[string]string
+	expanded  map[string]string
+	active    map[string]struct{}
+}
+
+func (x *expansion) resolve(name string) (string, bool, error) {
+	if value, ok := x.expanded[name]; ok {
+		return value, true, nil
+	}
+	raw, ok := x.raw[name]
+	if !ok {
+		if x.lookupEnv == nil {
+			return "", false, nil
+		}
+		value, ok := x.lookupEnv(name)
+		return value, ok, nil
+	}
+	if _, ok := x.active[name]; ok {
+		return "", false, env.ErrInterpolationCycle
+	}
+	x.active[name] = struct{}{}
+	value, err := x.expand(raw)
+	delete(x.active, name)
+	if err != nil {
+		return "", false, err
+	}
+	x.expanded[name] = value
+	return value, true, nil
+}
+
+func (x *expansion) expand(s string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		if s[i] != '$' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			i++
+			continue
+		}
+		switch s[i+1] {
+		case '$':
+			b.WriteByte('$')
+			i += 2
+		case '{':
+			end, err := closingBrace(s, i+2)
+			if err != nil {
+				return "", err
+			}
+			value, err := x.reference(s[i+2 : end])
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(value)
+			i = end + 1
+		default:
+			b.WriteByte(s[i])
+			i++
+		}
+	}
+	return b.String(), nil
+}
+
+func (x *expansion) reference(ref string) (string, error) {
+	name, def, hasDef := strings.Cut(ref, InterpolatorDefaultSep)
+	if !env.ValidName(name) {
+		return "", env.ErrInvalidVarName
+	}
+	value, ok, err := x.resolve(name)
+	if err != nil {
+		return "", err
+	}
+	if hasDef && (!ok || value == "") {
+		return x.expand(def)
+	}
+	if !ok {
+		return "", env.ErrUndefinedVar
+	}
+	return value, nil
+}
+
+func closingBrace(s string, start int) (int, error) {
+	depth := 1
+	for i := start; i < len(s); i++ {
+		switch {
+		case s[i] == '$' && i+1 < len(s) && s[i+1] == '$':
+			i++
+		case s[i] == '$' && i+1 < len(s) && s[i+1] == '{':
+			depth++
+			i++
+		case s[i] == '}':
+			depth--
+			if depth == 0 {
+				return i, nil
+			}
+		}
+	}
+	return 0, env.ErrInvalidSyntax
+}
@@ -0,0 +1,76 @@
+package env_impl
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"unicode/utf8"
+
+	"github.com/reshifr/secure-env/core/env"
+)
+
+type JSON struct{}
+
+func (JSON) Encode(vars []env.Var) ([]byte, error) {
+	if err := validateVars(vars); err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	b.WriteString("{")
+	for i, v := range vars {
+		if !utf8.ValidString(v.Value) {
+			return nil, env.ErrInvalidVarValue
+		}
+		if i > 0 {
+			b.WriteString(",")
+		}
+		name, _ := json.Marshal(v.Name)
+		value, _ := json.Marshal(v.Value)
+		b.WriteString("\n  ")
+		b.Write(name)
+		b.WriteString(": ")
+		b.Write(value)
+	}
+	if len(vars) > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString("}\n")
+	return b.Bytes(), nil
+}
+
+func (JSON) Decode(buf []byte) ([]env.Var, error) {
+	if !utf8.Valid(buf) {
+		return nil, env.ErrInvalidVarValue
+	}
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, env.ErrInvalidSyntax
+	}
+	vars := []env.Var{}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, env.ErrInvalidSyntax
+		}
+		name := tok.(string)
+		if !env.ValidName(name) {
+			return nil, env.ErrInvalidVarName
+		}
+		tok, err = dec.Token()
+		if err != nil {
+			return nil, env.ErrInvalidSyntax
+		}
+		value, ok := tok.(string)
+		if !ok {
+			return nil, env.ErrInvalidVarValue
+		}
+		vars = append(vars, env.Var{Name: name, Value: value})
+	}
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('}') {
+		return nil, env.ErrInvalidSyntax
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, env.ErrInvalidSyntax
+	}
+	return vars, nil
+}
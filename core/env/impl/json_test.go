@@ -0,0 +1,116 @@
+package env_impl
+
+import (
+	"testing"
+
+	"github.com/reshifr/secure-env/core/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_JSON_Encode(t *testing.T) {
+	t.Parallel()
+	format := JSON{}
+
+	t.Run("ErrInvalidVarName error", func(t *testing.T) {
+		t.Parallel()
+		vars := []env.Var{{Name: "", Value: "x"}}
+		var expBuf []byte = nil
+		const expErr = env.ErrInvalidVarName
+
+		buf, err := format.Encode(vars)
+		assert.Equal(t, expBuf, buf)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrInvalidVarValue error", func(t *testing.T) {
+		t.Parallel()
+		vars := []env.Var{{Name: "A", Value: "a\xffb"}}
+		var expBuf []byte = nil
+		const expErr = env.ErrInvalidVarValue
+
+		buf, err := format.Encode(vars)
+		assert.Equal(t, expBuf, buf)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Empty input", func(t *testing.T) {
+		t.Parallel()
+		expBuf := []byte("{}\n")
+
+		buf, err := format.Encode(nil)
+		assert.Equal(t, expBuf, buf)
+		assert.ErrorIs(t, err, nil)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		vars := []env.Var{
+			{Name: "USER", Value: "admin"},
+			{Name: "PASS", Value: "a\"b\n<c>"},
+		}
+		expBuf := []byte("{\n" +
+			"  \"USER\": \"admin\",\n" +
+			"  \"PASS\": \"a\\\"b\\n\\u003cc\\u003e\"\n" +
+			"}\n")
+
+		buf, err := format.Encode(vars)
+		assert.Equal(t, expBuf, buf)
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_JSON_Decode(t *testing.T) {
+	t.Parallel()
+	format := JSON{}
+
+	t.Run("ErrInvalidSyntax error", func(t *testing.T) {
+		t.Parallel()
+		bufs := [][]byte{
+			[]byte("[]"),
+			[]byte("{\"A\": \"x\""),
+			[]byte("{\"A\": \"x\"} {}"),
+		}
+		var expVars []env.Var = nil
+		const expErr = env.ErrInvalidSyntax
+
+		for _, buf := range bufs {
+			vars, err := format.Decode(buf)
+			assert.Equal(t, expVars, vars)
+			assert.ErrorIs(t, err, expErr)
+		}
+	})
+	t.Run("ErrInvalidVarName error", func(t *testing.T) {
+		t.Parallel()
+		buf := []byte("{\"A B\": \"x\"}")
+		var expVars []env.Var = nil
+		const expErr = env.ErrInvalidVarName
+
+		vars, err := format.Decode(buf)
+		assert.Equal(t, expVars, vars)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrInvalidVarValue error", func(t *testing.T) {
+		t.Parallel()
+		bufs := [][]byte{
+			[]byte("{\"A\": 1}"),
+			[]byte("{\"A\": \"a\xffb\"}"),
+		}
+		var expVars []env.Var = nil
+		const expErr = env.ErrInvalidVarValue
+
+		for _, buf := range bufs {
+			vars, err := format.Decode(buf)
+			assert.Equal(t, expVars, vars)
+			assert.ErrorIs(t, err, expErr)
+		}
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		buf := []byte("{\"USER\": \"admin\", \"PASS\": \"a\\nb\"}")
+		expVars := []env.Var{
+			{Name: "USER", Value: "admin"},
+			{Name: "PASS", Value: "a\nb"},
+		}
+
+		vars, err := format.Decode(buf)
+		assert.Equal(t, expVars, vars)
+		assert.ErrorIs(t, err, nil)
+	})
+}
@@ -0,0 +1,93 @@
+package env_impl
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/reshifr/secure-env/core/env"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	K8sSecretDefaultName = "senv"
+)
+
+type K8sSecret struct {
+	Name string
+}
+
+func (secret K8sSecret) Encode(vars []env.Var) ([]byte, error) {
+	if err := validateVars(vars); err != nil {
+		return nil, err
+	}
+	name, _ := json.Marshal(secret.Name)
+	var b strings.Builder
+	b.WriteString("apiVersion: v1\n")
+	b.WriteString("kind: Secret\n")
+	b.WriteString("metadata:\n")
+	b.WriteString("  name: ")
+	b.Write(name)
+	b.WriteString("\n")
+	b.WriteString("type: Opaque\n")
+	if len(vars) == 0 {
+		b.WriteString("data: {}\n")
+		return []byte(b.String()), nil
+	}
+	b.WriteString("data:\n")
+	for _, v := range vars {
+		b.WriteString("  ")
+		b.WriteString(v.Name)
+		b.WriteString(": ")
+		b.WriteString(base64.StdEncoding.EncodeToString([]byte(v.Value)))
+		b.WriteString("\n")
+	}
+	return []byte(b.String()), nil
+}
+
+func (K8sSecret) Decode(buf []byte) ([]env.Var, error) {
+	doc := yaml.Node{}
+	if err := yaml.Unmarshal(buf, &doc); err != nil {
+		return nil, env.ErrInvalidSyntax
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) != 1 {
+		return nil, env.ErrInvalidSyntax
+	}
+	manifest := doc.Content[0]
+	if manifest.Kind != yaml.MappingNode {
+		return nil, env.ErrInvalidSyntax
+	}
+	if k8sField(manifest, "kind") == nil ||
+		k8sField(manifest, "kind").Value != "Secret" {
+		return nil, env.ErrInvalidSyntax
+	}
+	vars := []env.Var{}
+	data := k8sField(manifest, "data")
+	if data == nil {
+		return vars, nil
+	}
+	if data.Kind != yaml.MappingNode {
+		return nil, env.ErrInvalidSyntax
+	}
+	for i := 0; i+1 < len(data.Content); i += 2 {
+		name := data.Content[i].Value
+		if !env.ValidName(name) {
+			return nil, env.ErrInvalidVarName
+		}
+		value, err := base64.StdEncoding.DecodeString(data.Content[i+1].Value)
+		if err != nil {
+			return nil, env.ErrInvalidVarValue
+		}
+		vars = append(vars, env.Var{Name: name, Value: string(value)})
+	}
+	return vars, nil
+}
+
+func k8sField(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
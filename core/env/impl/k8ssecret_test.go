@@ -0,0 +1,117 @@
+package env_impl
+
+import (
+	"testing"
+
+	"github.com/reshifr/secure-env/core/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_K8sSecret_Encode(t *testing.T) {
+	t.Parallel()
+	format := K8sSecret{Name: "app-secrets"}
+
+	t.Run("ErrInvalidVarName error", func(t *testing.T) {
+		t.Parallel()
+		vars := []env.Var{{Name: "A:B", Value: "x"}}
+		var expBuf []byte = nil
+		const expErr = env.ErrInvalidVarName
+
+		buf, err := format.Encode(vars)
+		assert.Equal(t, expBuf, buf)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Empty input", func(t *testing.T) {
+		t.Parallel()
+		expBuf := []byte("apiVersion: v1\n" +
+			"kind: Secret\n" +
+			"metadata:\n" +
+			"  name: \"app-secrets\"\n" +
+			"type: Opaque\n" +
+			"data: {}\n")
+
+		buf, err := format.Encode(nil)
+		assert.Equal(t, expBuf, buf)
+		assert.ErrorIs(t, err, nil)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		vars := []env.Var{
+			{Name: "USER", Value: "admin"},
+			{Name: "PASS", Value: "s3cr3t\n"},
+		}
+		expBuf := []byte("apiVersion: v1\n" +
+			"kind: Secret\n" +
+			"metadata:\n" +
+			"  name: \"app-secrets\"\n" +
+			"type: Opaque\n" +
+			"data:\n" +
+			"  USER: YWRtaW4=\n" +
+			"  PASS: czNjcjN0Cg==\n")
+
+		buf, err := format.Encode(vars)
+		assert.Equal(t, expBuf, buf)
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_K8sSecret_Decode(t *testing.T) {
+	t.Parallel()
+	format := K8sSecret{}
+
+	t.Run("ErrInvalidSyntax error", func(t *testing.T) {
+		t.Parallel()
+		bufs := [][]byte{
+			[]byte("kind: [Secret"),
+			[]byte("- a\n- b\n"),
+			[]byte("kind: ConfigMap\n"),
+			[]byte("kind: Secret\ndata: [a]\n"),
+		}
+		var expVars []env.Var = nil
+		const expErr = env.ErrInvalidSyntax
+
+		for _, buf := range bufs {
+			vars, err := format.Decode(buf)
+			assert.Equal(t, expVars, vars)
+			assert.ErrorIs(t, err, expErr)
+		}
+	})
+	t.Run("ErrInvalidVarName error", func(t *testing.T) {
+		t.Parallel()
+		buf := []byte("kind: Secret\ndata:\n  A-B: eA==\n")
+		var expVars []env.Var = nil
+		const expErr = env.ErrInvalidVarName
+
+		vars, err := format.Decode(buf)
+		assert.Equal(t, expVars, vars)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrInvalidVarValue error", func(t *testing.T) {
+		t.Parallel()
+		buf := []byte("kind: Secret\ndata:\n  A: '!!'\n")
+		var expVars []env.Var = nil
+		const expErr = env.ErrInvalidVarValue
+
+		vars, err := format.Decode(buf)
+		assert.Equal(t, expVars, vars)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		buf := []byte("apiVersion: v1\n" +
+			"kind: Secret\n" +
+			"metadata:\n" +
+			"  name: app\n" +
+			"data:\n" +
+			"  USER: YWRtaW4=\n" +
+			"  PASS: czNjcjN0Cg==\n")
+		expVars := []env.Var{
+			{Name: "USER", Value: "admin"},
+			{Name: "PASS", Value: "s3cr3t\n"},
+		}
+
+		vars, err := format.Decode(buf)
+		assert.Equal(t, expVars, vars)
+		assert.ErrorIs(t, err, nil)
+	})
+}
@@ -0,0 +1,112 @@
+package env_impl
+
+import (
+	"strings"
+
+	"github.com/reshifr/secure-env/core/env"
+)
+
+const (
+	shellComments = "#"
+)
+
+type Shell struct{}
+
+func (Shell) Encode(vars []env.Var) ([]byte, error) {
+	if err := validateVars(vars); err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	for _, v := range vars {
+		b.WriteString("export ")
+		b.WriteString(v.Name)
+		b.WriteString("='")
+		b.WriteString(strings.ReplaceAll(v.Value, "'", `'\''`))
+		b.WriteString("'\n")
+	}
+	return []byte(b.String()), nil
+}
+
+func (Shell) Decode(buf []byte) ([]env.Var, error) {
+	s := &scanner{buf: buf}
+	vars := []env.Var{}
+	for s.next(shellComments) {
+		s.keyword("export")
+		name, err := s.name()
+		if err != nil {
+			return nil, err
+		}
+		if err := s.assign(false); err != nil {
+			return nil, err
+		}
+		value, err := shellWord(s)
+		if err != nil {
+			return nil, err
+		}
+		if !s.eof() && s.peek() == ';' {
+			s.pos++
+		}
+		if err := s.endOfLine(shellComments); err != nil {
+			return nil, err
+		}
+		vars = append(vars, env.Var{Name: name, Value: value})
+	}
+	return vars, nil
+}
+
+func shellWord(s *scanner) (string, error) {
+	var b strings.Builder
+	for !s.eof() {
+		c := s.peek()
+		switch c {
+		case ' ', '\t', '\n', ';':
+			return b.String(), nil
+		case '\'':
+			s.pos++
+			end := strings.IndexByte(string(s.buf[s.pos:]), '\'')
+			if end < 0 {
+				return "", env.ErrInvalidSyntax
+			}
+			b.Write(s.buf[s.pos : s.pos+end])
+			s.pos += end + 1
+		case '"':
+			s.pos++
+			for {
+				if s.eof() {
+					return "", env.ErrInvalidSyntax
+				}
+				c := s.peek()
+				s.pos++
+				if c == '"' {
+					break
+				}
+				if c == '\\' && !s.eof() && contains("$`\"\\\n", s.peek()) {
+					if s.peek() != '\n' {
+						b.WriteByte(s.peek())
+					}
+					s.pos++
+					continue
+				}
+				if c == '$' || c == '`' {
+					return "", env.ErrInvalidSyntax
+				}
+				b.WriteByte(c)
+			}
+		case '\\':
+			s.pos++
+			if s.eof() {
+				return "", env.ErrInvalidSyntax
+			}
+			if s.peek() != '\n' {
+				b.WriteByte(s.peek())
+			}
+			s.pos++
+		case '$', '`', '|', '&', '<', '>', '(', ')':
+			return "", env.ErrInvalidSyntax
+		default:
+			b.WriteByte(c)
+			s.pos++
+		}
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,87 @@
+package env_impl
+
+import (
+	"testing"
+
+	"github.com/reshifr/secure-env/core/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Shell_Encode(t *testing.T) {
+	t.Parallel()
+	format := Shell{}
+
+	t.Run("ErrInvalidVarName error", func(t *testing.T) {
+		t.Parallel()
+		vars := []env.Var{{Name: "A-B", Value: "x"}}
+		var expBuf []byte = nil
+		const expErr = env.ErrInvalidVarName
+
+		buf, err := format.Encode(vars)
+		assert.Equal(t, expBuf, buf)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		vars := []env.Var{
+			{Name: "USER", Value: "admin"},
+			{Name: "PASS", Value: "it's $HOME\n"},
+		}
+		expBuf := []byte("export USER='admin'\n" +
+			"export PASS='it'\\''s $HOME\n'\n")
+
+		buf, err := format.Encode(vars)
+		assert.Equal(t, expBuf, buf)
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_Shell_Decode(t *testing.T) {
+	t.Parallel()
+	format := Shell{}
+
+	t.Run("ErrInvalidVarName error", func(t *testing.T) {
+		t.Parallel()
+		buf := []byte("export 9A=1\n")
+		var expVars []env.Var = nil
+		const expErr = env.ErrInvalidVarName
+
+		vars, err := format.Decode(buf)
+		assert.Equal(t, expVars, vars)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrInvalidSyntax error", func(t *testing.T) {
+		t.Parallel()
+		bufs := [][]byte{
+			[]byte("export A ='x'\n"),
+			[]byte("export A='x\n"),
+			[]byte("export A=$HOME\n"),
+			[]byte("export A=\"`id`\"\n"),
+			[]byte("export A=x y\n"),
+		}
+		var expVars []env.Var = nil
+		const expErr = env.ErrInvalidSyntax
+
+		for _, buf := range bufs {
+			vars, err := format.Decode(buf)
+			assert.Equal(t, expVars, vars)
+			assert.ErrorIs(t, err, expErr)
+		}
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		buf := []byte("#!/bin/sh\n" +
+			"export USER='admin'\n" +
+			"PASS='it'\\''s'\"\\$x\"\\ y; # trailing\n" +
+			"export EMPTY=\n")
+		expVars := []env.Var{
+			{Name: "USER", Value: "admin"},
+			{Name: "PASS", Value: "it's$x y"},
+			{Name: "EMPTY", Value: ""},
+		}
+
+		vars, err := format.Decode(buf)
+		assert.Equal(t, expVars, vars)
+		assert.ErrorIs(t, err, nil)
+	})
+}
@@ -0,0 +1,114 @@
+package env_impl
+
+import (
+	"strings"
+
+	"github.com/reshifr/secure-env/core/env"
+)
+
+const (
+	systemdComments = "#;"
+	systemdEscaped  = "\"\\`$"
+)
+
+type Systemd struct{}
+
+func (Systemd) Encode(vars []env.Var) ([]byte, error) {
+	if err := validateVars(vars); err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	for _, v := range vars {
+		if strings.ContainsRune(v.Value, 0) {
+			return nil, env.ErrInvalidVarValue
+		}
+		b.WriteString(v.Name)
+		b.WriteString("=\"")
+		for i := 0; i < len(v.Value); i++ {
+			if contains(systemdEscaped, v.Value[i]) {
+				b.WriteByte('\\')
+			}
+			b.WriteByte(v.Value[i])
+		}
+		b.WriteString("\"\n")
+	}
+	return []byte(b.String()), nil
+}
+
+func (Systemd) Decode(buf []byte) ([]env.Var, error) {
+	s := &scanner{buf: buf}
+	vars := []env.Var{}
+	for s.next(systemdComments) {
+		name, err := s.name()
+		if err != nil {
+			return nil, err
+		}
+		if err := s.assign(true); err != nil {
+			return nil, err
+		}
+		value, err := systemdValue(s)
+		if err != nil {
+			return nil, err
+		}
+		vars = append(vars, env.Var{Name: name, Value: value})
+	}
+	return vars, nil
+}
+
+func systemdValue(s *scanner) (string, error) {
+	var b strings.Builder
+	if s.eof() {
+		return "", nil
+	}
+	switch s.peek() {
+	case '"':
+		s.pos++
+		for {
+			if s.eof() {
+				return "", env.ErrInvalidSyntax
+			}
+			c := s.peek()
+			s.pos++
+			if c == '"' {
+				break
+			}
+			if c == '\\' && !s.eof() {
+				e := s.peek()
+				s.pos++
+				switch {
+				case e == '\n':
+				case contains(systemdEscaped, e):
+					b.WriteByte(e)
+				default:
+					b.WriteByte(c)
+					b.WriteByte(e)
+				}
+				continue
+			}
+			b.WriteByte(c)
+		}
+	case '\'':
+		s.pos++
+		end := strings.IndexByte(string(s.buf[s.pos:]), '\'')
+		if end < 0 {
+			return "", env.ErrInvalidSyntax
+		}
+		b.Write(s.buf[s.pos : s.pos+end])
+		s.pos += end + 1
+	default:
+		for !s.eof() && s.peek() != '\n' {
+			c := s.peek()
+			s.pos++
+			if c == '\\' && !s.eof() {
+				if s.peek() != '\n' {
+					b.WriteByte(s.peek())
+				}
+				s.pos++
+				continue
+			}
+			b.WriteByte(c)
+		}
+		return strings.TrimRight(b.String(), " \t\r"), s.endOfLine("")
+	}
+	return b.String(), s.endOfLine("")
+}
@@ -0,0 +1,98 @@
+package env_impl
+
+import (
+	"testing"
+
+	"github.com/reshifr/secure-env/core/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Systemd_Encode(t *testing.T) {
+	t.Parallel()
+	format := Systemd{}
+
+	t.Run("ErrInvalidVarName error", func(t *testing.T) {
+		t.Parallel()
+		vars := []env.Var{{Name: "A.B", Value: "x"}}
+		var expBuf []byte = nil
+		const expErr = env.ErrInvalidVarName
+
+		buf, err := format.Encode(vars)
+		assert.Equal(t, expBuf, buf)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrInvalidVarValue error", func(t *testing.T) {
+		t.Parallel()
+		vars := []env.Var{{Name: "A", Value: "x\x00y"}}
+		var expBuf []byte = nil
+		const expErr = env.ErrInvalidVarValue
+
+		buf, err := format.Encode(vars)
+		assert.Equal(t, expBuf, buf)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		vars := []env.Var{
+			{Name: "USER", Value: "admin"},
+			{Name: "PASS", Value: "a\"b\\c$d`e\nf"},
+		}
+		expBuf := []byte("USER=\"admin\"\n" +
+			"PASS=\"a\\\"b\\\\c\\$d\\`e\nf\"\n")
+
+		buf, err := format.Encode(vars)
+		assert.Equal(t, expBuf, buf)
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_Systemd_Decode(t *testing.T) {
+	t.Parallel()
+	format := Systemd{}
+
+	t.Run("ErrInvalidVarName error", func(t *testing.T) {
+		t.Parallel()
+		buf := []byte("1A=1\n")
+		var expVars []env.Var = nil
+		const expErr = env.ErrInvalidVarName
+
+		vars, err := format.Decode(buf)
+		assert.Equal(t, expVars, vars)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrInvalidSyntax error", func(t *testing.T) {
+		t.Parallel()
+		bufs := [][]byte{
+			[]byte("A\n"),
+			[]byte("A=\"x\n"),
+			[]byte("A='x\n"),
+		}
+		var expVars []env.Var = nil
+		const expErr = env.ErrInvalidSyntax
+
+		for _, buf := range bufs {
+			vars, err := format.Decode(buf)
+			assert.Equal(t, expVars, vars)
+			assert.ErrorIs(t, err, expErr)
+		}
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		buf := []byte("; comment\n" +
+			"# comment\n" +
+			"USER=admin  \n" +
+			"PASS=\"a\\\"b\\n\\$c\nd\"\n" +
+			"RAW='x\\y'\n" +
+			"LONG=a\\\nb\n")
+		expVars := []env.Var{
+			{Name: "USER", Value: "admin"},
+			{Name: "PASS", Value: "a\"b\\n$c\nd"},
+			{Name: "RAW", Value: "x\\y"},
+			{Name: "LONG", Value: "ab"},
+		}
+
+		vars, err := format.Decode(buf)
+		assert.Equal(t, expVars, vars)
+		assert.ErrorIs(t, err, nil)
+	})
+}
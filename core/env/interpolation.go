@@ -0,0 +1,42 @@
+package env
+
+import (
+	"strings"
+
+	"github.com/reshifr/secure-env/core/failure"
+)
+
+type InterpolationError int
+
+const (
+	ErrUndefinedVar InterpolationError = iota + 1
+	ErrInterpolationCycle
+)
+
+func (err InterpolationError) Error() string {
+	switch err {
+	case ErrUndefinedVar:
+		return "ErrUndefinedVar: the referenced variable is not defined."
+	case ErrInterpolationCycle:
+		return "ErrInterpolationCycle: the variable references itself."
+	default:
+		return "Error: unknown."
+	}
+}
+
+func (err InterpolationError) Kind() failure.Kind {
+	switch err {
+	case ErrUndefinedVar, ErrInterpolationCycle:
+		return failure.KindInvalid
+	default:
+		return failure.KindInternal
+	}
+}
+
+type Interpolator interface {
+	Expand(vars []Var) (expandedVars []Var, err error)
+}
+
+func Interpolated(value string) bool {
+	return strings.Contains(value, "${") || strings.Contains(value, "$$")
+}
@@ -0,0 +1,77 @@
+package env
+
+import (
+	"testing"
+
+	"github.com/reshifr/secure-env/core/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_InterpolationError_Error(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrUndefinedVar value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrUndefinedVar
+		const expMsg = "ErrUndefinedVar: the referenced variable is not defined."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrInterpolationCycle value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrInterpolationCycle
+		const expMsg = "ErrInterpolationCycle: the variable references itself."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("Unknown value", func(t *testing.T) {
+		t.Parallel()
+		const err = InterpolationError(402817)
+		const expMsg = "Error: unknown."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+}
+
+func Test_InterpolationError_Kind(t *testing.T) {
+	t.Parallel()
+	t.Run("KindInvalid value", func(t *testing.T) {
+		t.Parallel()
+		errs := []InterpolationError{
+			ErrUndefinedVar,
+			ErrInterpolationCycle,
+		}
+		const expKind = failure.KindInvalid
+
+		for _, err := range errs {
+			kind := err.Kind()
+			assert.Equal(t, expKind, kind, err.Error())
+		}
+	})
+	t.Run("Unknown value", func(t *testing.T) {
+		t.Parallel()
+		const err = InterpolationError(613724)
+		const expKind = failure.KindInternal
+
+		kind := err.Kind()
+		assert.Equal(t, expKind, kind)
+	})
+}
+
+func Test_Interpolated(t *testing.T) {
+	t.Parallel()
+	t.Run("Plain value", func(t *testing.T) {
+		t.Parallel()
+		for _, value := range []string{"", "8080", "$HOME", "a$b", "{x}"} {
+			assert.False(t, Interpolated(value), value)
+		}
+	})
+	t.Run("Reference or escape", func(t *testing.T) {
+		t.Parallel()
+		for _, value := range []string{"${PORT}", "${PORT:-80}", "pa$$word"} {
+			assert.True(t, Interpolated(value), value)
+		}
+	})
+}
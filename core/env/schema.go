@@ -0,0 +1,206 @@
+package env
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/reshifr/secure-env/core/failure"
+)
+
+type SchemaError int
+
+const (
+	ErrInvalidSchema SchemaError = iota + 1
+	ErrRequiredVar
+	ErrInvalidInt
+	ErrInvalidBool
+	ErrInvalidURL
+	ErrInvalidPort
+	ErrInvalidDuration
+	ErrPatternMismatch
+)
+
+func (err SchemaError) Error() string {
+	switch err {
+	case ErrInvalidSchema:
+		return "ErrInvalidSchema: the schema rule cannot be applied."
+	case ErrRequiredVar:
+		return "ErrRequiredVar: the required variable is missing."
+	case ErrInvalidInt:
+		return "ErrInvalidInt: the value is not an integer."
+	case ErrInvalidBool:
+		return "ErrInvalidBool: the value is not a boolean."
+	case ErrInvalidURL:
+		return "ErrInvalidURL: the value is not an absolute URL."
+	case ErrInvalidPort:
+		return "ErrInvalidPort: the value is not a port number."
+	case ErrInvalidDuration:
+		return "ErrInvalidDuration: the value is not a duration."
+	case ErrPatternMismatch:
+		return "ErrPatternMismatch: the value does not match the pattern."
+	default:
+		return "Error: unknown."
+	}
+}
+
+func (err SchemaError) Kind() failure.Kind {
+	switch err {
+	case ErrInvalidSchema, ErrRequiredVar, ErrInvalidInt, ErrInvalidBool,
+		ErrInvalidURL, ErrInvalidPort, ErrInvalidDuration, ErrPatternMismatch:
+		return failure.KindInvalid
+	default:
+		return failure.KindInternal
+	}
+}
+
+type VarType string
+
+const (
+	TypeString   VarType = "string"
+	TypeInt      VarType = "int"
+	TypeBool     VarType = "bool"
+	TypeURL      VarType = "url"
+	TypePort     VarType = "port"
+	TypeDuration VarType = "duration"
+	TypePattern  VarType = "pattern"
+)
+
+type Rule struct {
+	Name        string
+	Type        VarType
+	Pattern     string
+	Required    bool
+	Description string
+}
+
+type Schema struct {
+	Rules []Rule
+}
+
+type Violation struct {
+	Rule Rule
+	Err  error
+}
+
+type Report []Violation
+
+func (report Report) String() string {
+	var b strings.Builder
+	for _, violation := range report {
+		b.WriteString(violation.Rule.Name)
+		b.WriteString(": ")
+		b.WriteString(violation.Err.Error())
+		if violation.Rule.Description != "" {
+			b.WriteString(" (")
+			b.WriteString(violation.Rule.Description)
+			b.WriteString(")")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func (rule Rule) Check(value string) error {
+	switch rule.Type {
+	case "", TypeString:
+	case TypeInt:
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return ErrInvalidInt
+		}
+	case TypeBool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return ErrInvalidBool
+		}
+	case TypeURL:
+		u, err := url.Parse(value)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return ErrInvalidURL
+		}
+	case TypePort:
+		port, err := strconv.ParseUint(value, 10, 16)
+		if err != nil || port == 0 {
+			return ErrInvalidPort
+		}
+	case TypeDuration:
+		if _, err := time.ParseDuration(value); err != nil {
+			return ErrInvalidDuration
+		}
+	case TypePattern:
+		pattern, err := regexp.Compile("^(?:" + rule.Pattern + ")$")
+		if err != nil {
+			return ErrInvalidSchema
+		}
+		if !pattern.MatchString(value) {
+			return ErrPatternMismatch
+		}
+	default:
+		return ErrInvalidSchema
+	}
+	return nil
+}
+
+func (schema Schema) Validate() error {
+	seen := map[string]struct{}{}
+	for _, rule := range schema.Rules {
+		if !ValidName(rule.Name) {
+			return ErrInvalidVarName
+		}
+		if _, ok := seen[rule.Name]; ok {
+			return ErrInvalidSchema
+		}
+		seen[rule.Name] = struct{}{}
+		if err := rule.Check(""); err == ErrInvalidSchema {
+			return err
+		}
+	}
+	return nil
+}
+
+func (schema Schema) Rule(name string) (Rule, bool) {
+	for _, rule := range schema.Rules {
+		if rule.Name == name {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+func (schema Schema) CheckVar(v Var) error {
+	rule, ok := schema.Rule(v.Name)
+	if !ok {
+		return nil
+	}
+	return rule.Check(v.Value)
+}
+
+func (schema Schema) CheckFile(name string) error {
+	rule, ok := schema.Rule(name)
+	if !ok || rule.Type == "" || rule.Type == TypeString {
+		return nil
+	}
+	return ErrInvalidSchema
+}
+
+func (schema Schema) Check(vars []Var) Report {
+	values := map[string]string{}
+	for _, v := range vars {
+		values[v.Name] = v.Value
+	}
+	report := Report{}
+	for _, rule := range schema.Rules {
+		value, ok := values[rule.Name]
+		if !ok {
+			if rule.Required {
+				report = append(report, Violation{Rule: rule, Err: ErrRequiredVar})
+			}
+			continue
+		}
+		if err := rule.Check(value); err != nil {
+			report = append(report, Violation{Rule: rule, Err: err})
+		}
+	}
+	return report
+}
@@ -0,0 +1,265 @@
+package env
+
+import (
+	"testing"
+
+	"github.com/reshifr/secure-env/core/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SchemaError_Error(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrInvalidSchema value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrInvalidSchema
+		const expMsg = "ErrInvalidSchema: the schema rule cannot be applied."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrRequiredVar value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrRequiredVar
+		const expMsg = "ErrRequiredVar: the required variable is missing."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrInvalidInt value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrInvalidInt
+		const expMsg = "ErrInvalidInt: the value is not an integer."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrInvalidBool value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrInvalidBool
+		const expMsg = "ErrInvalidBool: the value is not a boolean."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrInvalidURL value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrInvalidURL
+		const expMsg = "ErrInvalidURL: the value is not an absolute URL."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrInvalidPort value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrInvalidPort
+		const expMsg = "ErrInvalidPort: the value is not a port number."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrInvalidDuration value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrInvalidDuration
+		const expMsg = "ErrInvalidDuration: the value is not a duration."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrPatternMismatch value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrPatternMismatch
+		const expMsg = "ErrPatternMismatch: " +
+			"the value does not match the pattern."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("Unknown value", func(t *testing.T) {
+		t.Parallel()
+		const err = SchemaError(731942)
+		const expMsg = "Error: unknown."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+}
+
+func Test_Rule_Check(t *testing.T) {
+	t.Parallel()
+	t.Run("Invalid value", func(t *testing.T) {
+		t.Parallel()
+		cases := []struct {
+			rule   Rule
+			value  string
+			expErr error
+		}{
+			{Rule{Type: "float"}, "1.5", ErrInvalidSchema},
+			{Rule{Type: TypeInt}, "12a", ErrInvalidInt},
+			{Rule{Type: TypeBool}, "yes", ErrInvalidBool},
+			{Rule{Type: TypeURL}, "db.local/app", ErrInvalidURL},
+			{Rule{Type: TypePort}, "0", ErrInvalidPort},
+			{Rule{Type: TypePort}, "65536", ErrInvalidPort},
+			{Rule{Type: TypeDuration}, "5", ErrInvalidDuration},
+			{Rule{Type: TypePattern, Pattern: "("}, "x", ErrInvalidSchema},
+			{Rule{Type: TypePattern, Pattern: "[a-z]+"}, "ab1", ErrPatternMismatch},
+		}
+
+		for _, c := range cases {
+			err := c.rule.Check(c.value)
+			assert.ErrorIs(t, err, c.expErr)
+		}
+	})
+	t.Run("Valid value", func(t *testing.T) {
+		t.Parallel()
+		cases := []struct {
+			rule  Rule
+			value string
+		}{
+			{Rule{}, "anything"},
+			{Rule{Type: TypeString}, ""},
+			{Rule{Type: TypeInt}, "-42"},
+			{Rule{Type: TypeBool}, "true"},
+			{Rule{Type: TypeURL}, "postgres://u:p@db.local:5432/app"},
+			{Rule{Type: TypePort}, "5432"},
+			{Rule{Type: TypeDuration}, "1h30m"},
+			{Rule{Type: TypePattern, Pattern: "[a-z]+|[0-9]+"}, "123"},
+		}
+
+		for _, c := range cases {
+			err := c.rule.Check(c.value)
+			assert.ErrorIs(t, err, nil)
+		}
+	})
+}
+
+func Test_Schema_Validate(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrInvalidVarName error", func(t *testing.T) {
+		t.Parallel()
+		schema := Schema{Rules: []Rule{{Name: "DB-PORT"}}}
+		const expErr = ErrInvalidVarName
+
+		err := schema.Validate()
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrInvalidSchema error", func(t *testing.T) {
+		t.Parallel()
+		schemas := []Schema{
+			{Rules: []Rule{{Name: "A"}, {Name: "A"}}},
+			{Rules: []Rule{{Name: "A", Type: "float"}}},
+			{Rules: []Rule{{Name: "A", Type: TypePattern, Pattern: "("}}},
+		}
+		const expErr = ErrInvalidSchema
+
+		for _, schema := range schemas {
+			err := schema.Validate()
+			assert.ErrorIs(t, err, expErr)
+		}
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		schema := Schema{Rules: []Rule{
+			{Name: "DB_PORT", Type: TypePort, Required: true},
+			{Name: "DB_HOST"},
+		}}
+
+		err := schema.Validate()
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_Schema_CheckVar(t *testing.T) {
+	t.Parallel()
+	schema := Schema{Rules: []Rule{{Name: "DB_PORT", Type: TypePort}}}
+
+	t.Run("ErrInvalidPort error", func(t *testing.T) {
+		t.Parallel()
+		const expErr = ErrInvalidPort
+
+		err := schema.CheckVar(Var{Name: "DB_PORT", Value: "http"})
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		assert.ErrorIs(t, schema.CheckVar(Var{Name: "DB_PORT", Value: "80"}), nil)
+		assert.ErrorIs(t, schema.CheckVar(Var{Name: "OTHER", Value: "x"}), nil)
+	})
+}
+
+func Test_Schema_CheckFile(t *testing.T) {
+	t.Parallel()
+	schema := Schema{Rules: []Rule{
+		{Name: "DB_PORT", Type: TypePort},
+		{Name: "CA_BUNDLE", Required: true},
+	}}
+
+	t.Run("ErrInvalidSchema error", func(t *testing.T) {
+		t.Parallel()
+		const expErr = ErrInvalidSchema
+
+		err := schema.CheckFile("DB_PORT")
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		assert.ErrorIs(t, schema.CheckFile("CA_BUNDLE"), nil)
+		assert.ErrorIs(t, schema.CheckFile("OTHER"), nil)
+	})
+}
+
+func Test_Schema_Check(t *testing.T) {
+	t.Parallel()
+	schema := Schema{Rules: []Rule{
+		{Name: "DB_HOST", Required: true, Description: "Database host"},
+		{Name: "DB_PORT", Type: TypePort, Required: true},
+		{Name: "DEBUG", Type: TypeBool},
+		{Name: "TIMEOUT", Type: TypeDuration},
+	}}
+	vars := []Var{
+		{Name: "DB_PORT", Value: "99999"},
+		{Name: "TIMEOUT", Value: "30s"},
+		{Name: "EXTRA", Value: "x"},
+	}
+	expReport := Report{
+		{Rule: schema.Rules[0], Err: ErrRequiredVar},
+		{Rule: schema.Rules[1], Err: ErrInvalidPort},
+	}
+	expMsg := "DB_HOST: ErrRequiredVar: the required variable is missing. " +
+		"(Database host)\n" +
+		"DB_PORT: ErrInvalidPort: the value is not a port number.\n"
+
+	report := schema.Check(vars)
+	assert.Equal(t, expReport, report)
+	assert.Equal(t, expMsg, report.String())
+}
+
+func Test_SchemaError_Kind(t *testing.T) {
+	t.Parallel()
+	t.Run("KindInvalid value", func(t *testing.T) {
+		t.Parallel()
+		errs := []SchemaError{
+			ErrInvalidSchema,
+			ErrRequiredVar,
+			ErrInvalidInt,
+			ErrInvalidBool,
+			ErrInvalidURL,
+			ErrInvalidPort,
+			ErrInvalidDuration,
+			ErrPatternMismatch,
+		}
+		const expKind = failure.KindInvalid
+
+		for _, err := range errs {
+			kind := err.Kind()
+			assert.Equal(t, expKind, kind, err.Error())
+		}
+	})
+	t.Run("Unknown value", func(t *testing.T) {
+		t.Parallel()
+		const err = SchemaError(613724)
+		const expKind = failure.KindInternal
+
+		kind := err.Kind()
+		assert.Equal(t, expKind, kind)
+	})
+}
@@ -0,0 +1,63 @@
+package env_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/reshifr/secure-env/core/env"
+	eimpl "github.com/reshifr/secure-env/core/env/impl"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Format_RoundTrip(t *testing.T) {
+	t.Parallel()
+	singleLine := []env.Var{
+		{Name: "EMPTY", Value: ""},
+		{Name: "PLAIN", Value: "admin"},
+		{Name: "SPACED", Value: "  leading and trailing  "},
+		{Name: "QUOTES", Value: `it's a "quoted" value`},
+		{Name: "SHELL", Value: "$HOME `id` $(id) ${X:-y} \\n"},
+		{Name: "COMMENT", Value: "a # b ; c"},
+		{Name: "UNICODE", Value: "pässwörd ✓"},
+	}
+	multiLine := append(singleLine,
+		env.Var{Name: "MULTILINE", Value: "line1\nline2\r\n\tline3\n"},
+		env.Var{Name: "BACKSLASH", Value: "a\\\nb\\"},
+	)
+
+	binary := []env.Var{{Name: "BINARY", Value: "a\xff\xfeb"}}
+
+	formats := map[string][]env.Var{
+		eimpl.FormatDotenv:    slices.Concat(multiLine, binary),
+		eimpl.FormatShell:     slices.Concat(multiLine, binary),
+		eimpl.FormatJSON:      multiLine,
+		eimpl.FormatDocker:    slices.Concat(singleLine, binary),
+		eimpl.FormatSystemd:   slices.Concat(multiLine, binary),
+		eimpl.FormatK8sSecret: slices.Concat(multiLine, binary),
+	}
+	for name, expVars := range formats {
+		name, expVars := name, expVars
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			format, _ := eimpl.NewFormat(name)
+
+			buf, err := format.Encode(expVars)
+			assert.ErrorIs(t, err, nil)
+			vars, err := format.Decode(buf)
+			assert.Equal(t, expVars, vars)
+			assert.ErrorIs(t, err, nil)
+		})
+	}
+	t.Run("JSON invalid UTF-8", func(t *testing.T) {
+		t.Parallel()
+		format, _ := eimpl.NewFormat(eimpl.FormatJSON)
+		const expErr = env.ErrInvalidVarValue
+
+		buf, err := format.Encode(binary)
+		assert.Nil(t, buf)
+		assert.ErrorIs(t, err, expErr)
+		vars, err := format.Decode([]byte("{\"BINARY\": \"a\xff\xfeb\"}"))
+		assert.Nil(t, vars)
+		assert.ErrorIs(t, err, expErr)
+	})
+}
@@ -0,0 +1,220 @@
+package failure
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindInvalid
+	KindNotFound
+	KindExists
+	KindAuth
+	KindIntegrity
+	KindEntropy
+	KindIO
+	KindPolicy
+	KindCanceled
+)
+
+const (
+	ExitOK        = 0
+	ExitInternal  = 1
+	ExitUsage     = 2
+	ExitInvalid   = 3
+	ExitNotFound  = 4
+	ExitExists    = 5
+	ExitAuth      = 6
+	ExitIntegrity = 7
+	ExitEntropy   = 8
+	ExitIO        = 9
+	ExitPolicy    = 10
+	ExitCanceled  = 11
+)
+
+func (kind Kind) String() string {
+	switch kind {
+	case KindInvalid:
+		return "invalid"
+	case KindNotFound:
+		return "not-found"
+	case KindExists:
+		return "exists"
+	case KindAuth:
+		return "auth"
+	case KindIntegrity:
+		return "integrity"
+	case KindEntropy:
+		return "entropy"
+	case KindIO:
+		return "io"
+	case KindPolicy:
+		return "policy"
+	case KindCanceled:
+		return "canceled"
+	default:
+		return "internal"
+	}
+}
+
+func (kind Kind) ExitCode() int {
+	switch kind {
+	case KindInvalid:
+		return ExitInvalid
+	case KindNotFound:
+		return ExitNotFound
+	case KindExists:
+		return ExitExists
+	case KindAuth:
+		return ExitAuth
+	case KindIntegrity:
+		return ExitIntegrity
+	case KindEntropy:
+		return ExitEntropy
+	case KindIO:
+		return ExitIO
+	case KindPolicy:
+		return ExitPolicy
+	case KindCanceled:
+		return ExitCanceled
+	default:
+		return ExitInternal
+	}
+}
+
+type Kinder interface {
+	error
+	Kind() (kind Kind)
+}
+
+type Error struct {
+	Op    string
+	Env   string
+	Role  string
+	Var   string
+	Err   error
+	Cause error
+}
+
+type Report struct {
+	Code    string
+	Kind    string
+	Exit    int
+	Op      string `json:",omitempty"`
+	Env     string `json:",omitempty"`
+	Role    string `json:",omitempty"`
+	Var     string `json:",omitempty"`
+	Message string
+	Cause   string `json:",omitempty"`
+}
+
+func New(op string, err error, cause error) error {
+	return &Error{Op: op, Err: err, Cause: cause}
+}
+
+func Wrap(err error, context Error) error {
+	if err == nil {
+		return nil
+	}
+	context.Err = err
+	return &context
+}
+
+func Annotate(err *error, context Error) {
+	*err = Wrap(*err, context)
+}
+
+func (err *Error) Error() string {
+	msg := &strings.Builder{}
+	msg.WriteString(err.Op)
+	for _, field := range [][2]string{
+		{"env", err.Env},
+		{"role", err.Role},
+		{"var", err.Var},
+	} {
+		if field[1] != "" {
+			msg.WriteString(" " + field[0] + "=" + field[1])
+		}
+	}
+	if msg.Len() > 0 {
+		msg.WriteString(": ")
+	}
+	if err.Err != nil {
+		msg.WriteString(err.Err.Error())
+	}
+	if err.Cause != nil {
+		msg.WriteString(" Cause: " + err.Cause.Error())
+	}
+	return msg.String()
+}
+
+func (err *Error) Unwrap() []error {
+	errs := []error{}
+	for _, e := range []error{err.Err, err.Cause} {
+		if e != nil {
+			errs = append(errs, e)
+		}
+	}
+	return errs
+}
+
+func KindOf(err error) Kind {
+	var kinder Kinder
+	if errors.As(err, &kinder) {
+		return kinder.Kind()
+	}
+	return KindInternal
+}
+
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	return KindOf(err).ExitCode()
+}
+
+func Code(err error) string {
+	var kinder Kinder
+	if !errors.As(err, &kinder) {
+		return "Error"
+	}
+	code, _, _ := strings.Cut(kinder.Error(), ":")
+	return code
+}
+
+func NewReport(err error) Report {
+	kind := KindOf(err)
+	report := Report{
+		Code:    Code(err),
+		Kind:    kind.String(),
+		Exit:    kind.ExitCode(),
+		Message: err.Error(),
+	}
+	var failure *Error
+	for e := error(err); errors.As(e, &failure); e = failure.Err {
+		report.Op = first(report.Op, failure.Op)
+		report.Env = first(report.Env, failure.Env)
+		report.Role = first(report.Role, failure.Role)
+		report.Var = first(report.Var, failure.Var)
+		if failure.Cause != nil && report.Cause == "" {
+			report.Cause = failure.Cause.Error()
+		}
+	}
+	return report
+}
+
+func first(value string, fallback string) string {
+	if value != "" {
+		return value
+	}
+	return fallback
+}
+
+func JSON(err error) []byte {
+	buf, _ := json.Marshal(struct{ Error Report }{Error: NewReport(err)})
+	return buf
+}
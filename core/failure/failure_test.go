@@ -0,0 +1,216 @@
+package failure
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type kindError int
+
+const (
+	errKindAuth kindError = iota + 1
+)
+
+func (err kindError) Error() string {
+	switch err {
+	case errKindAuth:
+		return "ErrKindAuth: the test error."
+	default:
+		return "Error: unknown."
+	}
+}
+
+func (err kindError) Kind() Kind {
+	switch err {
+	case errKindAuth:
+		return KindAuth
+	default:
+		return KindInternal
+	}
+}
+
+func Test_Kind_String(t *testing.T) {
+	t.Parallel()
+	kinds := map[Kind]string{
+		KindInternal:  "internal",
+		KindInvalid:   "invalid",
+		KindNotFound:  "not-found",
+		KindExists:    "exists",
+		KindAuth:      "auth",
+		KindIntegrity: "integrity",
+		KindEntropy:   "entropy",
+		KindIO:        "io",
+		KindPolicy:    "policy",
+		KindCanceled:  "canceled",
+		Kind(613724):  "internal",
+	}
+	for kind, expStr := range kinds {
+		assert.Equal(t, expStr, kind.String())
+	}
+}
+
+func Test_Kind_ExitCode(t *testing.T) {
+	t.Parallel()
+	kinds := map[Kind]int{
+		KindInternal:  ExitInternal,
+		KindInvalid:   ExitInvalid,
+		KindNotFound:  ExitNotFound,
+		KindExists:    ExitExists,
+		KindAuth:      ExitAuth,
+		KindIntegrity: ExitIntegrity,
+		KindEntropy:   ExitEntropy,
+		KindIO:        ExitIO,
+		KindPolicy:    ExitPolicy,
+		KindCanceled:  ExitCanceled,
+		Kind(613724):  ExitInternal,
+	}
+	for kind, expCode := range kinds {
+		assert.Equal(t, expCode, kind.ExitCode())
+	}
+}
+
+func Test_Error_Error(t *testing.T) {
+	t.Parallel()
+	t.Run("Context value", func(t *testing.T) {
+		t.Parallel()
+		err := &Error{
+			Op:    "open",
+			Env:   "prod",
+			Role:  "admin",
+			Var:   "DB_PASS",
+			Err:   errKindAuth,
+			Cause: io.EOF,
+		}
+		const expMsg = "open env=prod role=admin var=DB_PASS: " +
+			"ErrKindAuth: the test error. Cause: EOF"
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("Bare value", func(t *testing.T) {
+		t.Parallel()
+		err := &Error{Err: errKindAuth}
+		const expMsg = "ErrKindAuth: the test error."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+}
+
+func Test_Error_Unwrap(t *testing.T) {
+	t.Parallel()
+	t.Run("Cause value", func(t *testing.T) {
+		t.Parallel()
+		err := New("read", errKindAuth, io.EOF)
+
+		assert.ErrorIs(t, err, errKindAuth)
+		assert.ErrorIs(t, err, io.EOF)
+	})
+	t.Run("Nil cause value", func(t *testing.T) {
+		t.Parallel()
+		err := &Error{Err: errKindAuth}
+		expErrs := []error{errKindAuth}
+
+		errs := err.Unwrap()
+		assert.Equal(t, expErrs, errs)
+	})
+}
+
+func Test_Wrap(t *testing.T) {
+	t.Parallel()
+	t.Run("Nil value", func(t *testing.T) {
+		t.Parallel()
+		err := Wrap(nil, Error{Op: "open"})
+		assert.ErrorIs(t, err, nil)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		expErr := &Error{Op: "open", Env: "prod", Err: errKindAuth}
+
+		err := Wrap(errKindAuth, Error{Op: "open", Env: "prod"})
+		assert.Equal(t, expErr, err)
+	})
+}
+
+func Test_Annotate(t *testing.T) {
+	t.Parallel()
+	t.Run("Nil value", func(t *testing.T) {
+		t.Parallel()
+		var err error = nil
+
+		Annotate(&err, Error{Op: "open"})
+		assert.ErrorIs(t, err, nil)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		var err error = errKindAuth
+		expErr := &Error{Op: "open", Role: "admin", Err: errKindAuth}
+
+		Annotate(&err, Error{Op: "open", Role: "admin"})
+		assert.Equal(t, expErr, err)
+	})
+}
+
+func Test_KindOf(t *testing.T) {
+	t.Parallel()
+	t.Run("Kinder value", func(t *testing.T) {
+		t.Parallel()
+		err := Wrap(errKindAuth, Error{Op: "open"})
+
+		kind := KindOf(err)
+		assert.Equal(t, KindAuth, kind)
+	})
+	t.Run("Plain value", func(t *testing.T) {
+		t.Parallel()
+		err := errors.New("plain")
+
+		kind := KindOf(err)
+		assert.Equal(t, KindInternal, kind)
+	})
+}
+
+func Test_ExitCode(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, ExitOK, ExitCode(nil))
+	assert.Equal(t, ExitAuth, ExitCode(errKindAuth))
+	assert.Equal(t, ExitInternal, ExitCode(io.EOF))
+}
+
+func Test_Code(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "ErrKindAuth", Code(New("open", errKindAuth, nil)))
+	assert.Equal(t, "Error", Code(io.EOF))
+}
+
+func Test_NewReport(t *testing.T) {
+	t.Parallel()
+	inner := New("read", errKindAuth, io.ErrUnexpectedEOF)
+	err := Wrap(inner, Error{Op: "open", Env: "prod", Role: "admin"})
+	expReport := Report{
+		Code:    "ErrKindAuth",
+		Kind:    "auth",
+		Exit:    ExitAuth,
+		Op:      "open",
+		Env:     "prod",
+		Role:    "admin",
+		Message: err.Error(),
+		Cause:   "unexpected EOF",
+	}
+
+	report := NewReport(err)
+	assert.Equal(t, expReport, report)
+}
+
+func Test_JSON(t *testing.T) {
+	t.Parallel()
+	err := Wrap(errKindAuth, Error{Op: "open", Env: "prod"})
+	const expJSON = `{"Error":{"Code":"ErrKindAuth","Kind":"auth",` +
+		`"Exit":6,"Op":"open","Env":"prod",` +
+		`"Message":"open env=prod: ErrKindAuth: the test error."}}`
+
+	buf := JSON(err)
+	assert.JSONEq(t, expJSON, string(buf))
+}
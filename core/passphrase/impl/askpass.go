@@ -0,0 +1,53 @@
+package passphrase_impl
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/reshifr/secure-env/core/passphrase"
+)
+
+type Askpass struct {
+	fn FnAskpass
+}
+
+type FnAskpass struct {
+	LookupEnv func(key string) (value string, ok bool)
+}
+
+func NewAskpass(fn FnAskpass) Askpass {
+	return Askpass{fn: fn}
+}
+
+func (provider Askpass) Passphrase(prompt string) (*crypto.Secret, error) {
+	program, ok := provider.fn.LookupEnv(passphrase.AskpassEnv)
+	if !ok || program == "" {
+		return nil, passphrase.ErrProviderUnavailable
+	}
+	cmd := exec.Command(program, prompt)
+	cmd.Stderr = os.Stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, passphrase.ErrProviderUnavailable
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, passphrase.ErrProviderUnavailable
+	}
+	secret, readErr := readLine(stdout)
+	io.Copy(io.Discard, stdout)
+	waitErr := cmd.Wait()
+	if errors.Is(readErr, passphrase.ErrPassphraseTooLong) {
+		return nil, readErr
+	}
+	if readErr != nil {
+		return nil, passphrase.ErrInvalidResponse
+	}
+	if waitErr != nil {
+		secret.Destroy()
+		return nil, passphrase.ErrPassphraseCanceled
+	}
+	return secret, nil
+}
@@ -0,0 +1,36 @@
+package passphrase_impl
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/reshifr/secure-env/core/passphrase"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Askpass_Passphrase(t *testing.T) {
+	t.Parallel()
+	t.Run("Unset program", func(t *testing.T) {
+		t.Parallel()
+		provider := NewAskpass(FnAskpass{LookupEnv: lookupEnv(nil)})
+		var expSecret *crypto.Secret = nil
+		const expErr = passphrase.ErrProviderUnavailable
+
+		secret, err := provider.Passphrase("Vault passphrase")
+		assert.Equal(t, expSecret, secret)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Missing program", func(t *testing.T) {
+		t.Parallel()
+		provider := NewAskpass(FnAskpass{LookupEnv: lookupEnv(map[string]string{
+			passphrase.AskpassEnv: filepath.Join(t.TempDir(), "askpass"),
+		})})
+		var expSecret *crypto.Secret = nil
+		const expErr = passphrase.ErrProviderUnavailable
+
+		secret, err := provider.Passphrase("Vault passphrase")
+		assert.Equal(t, expSecret, secret)
+		assert.ErrorIs(t, err, expErr)
+	})
+}
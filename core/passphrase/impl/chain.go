@@ -0,0 +1,45 @@
+package passphrase_impl
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/reshifr/secure-env/core/passphrase"
+)
+
+type Chain struct {
+	providers []passphrase.Provider
+}
+
+func NewChain(providers ...passphrase.Provider) Chain {
+	return Chain{providers: providers}
+}
+
+func NewChainFromOrder(order string,
+	providers map[string]passphrase.Provider) (Chain, error) {
+	chain := Chain{providers: []passphrase.Provider{}}
+	for _, name := range strings.Split(order, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		provider, ok := providers[name]
+		if !ok {
+			return Chain{}, passphrase.ErrUnknownProvider
+		}
+		chain.providers = append(chain.providers, provider)
+	}
+	return chain, nil
+}
+
+func (chain Chain) Passphrase(prompt string) (*crypto.Secret, error) {
+	for _, provider := range chain.providers {
+		secret, err := provider.Passphrase(prompt)
+		if errors.Is(err, passphrase.ErrProviderUnavailable) {
+			continue
+		}
+		return secret, err
+	}
+	return nil, passphrase.ErrProviderUnavailable
+}
@@ -0,0 +1,87 @@
+package passphrase_impl
+
+import (
+	"testing"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/reshifr/secure-env/core/passphrase"
+	pmock "github.com/reshifr/secure-env/core/passphrase/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewChainFromOrder(t *testing.T) {
+	t.Parallel()
+	env := pmock.NewProvider(t)
+	tty := pmock.NewProvider(t)
+	providers := map[string]passphrase.Provider{
+		passphrase.ProviderEnv: env,
+		passphrase.ProviderTTY: tty,
+	}
+
+	t.Run("ErrUnknownProvider error", func(t *testing.T) {
+		t.Parallel()
+		expChain := Chain{}
+		const expErr = passphrase.ErrUnknownProvider
+
+		chain, err := NewChainFromOrder("env,gui", providers)
+		assert.Equal(t, expChain, chain)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		expChain := Chain{providers: []passphrase.Provider{tty, env}}
+
+		chain, err := NewChainFromOrder(" tty, env,", providers)
+		assert.Equal(t, expChain, chain)
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_Chain_Passphrase(t *testing.T) {
+	t.Parallel()
+	const prompt = "Vault passphrase"
+
+	t.Run("ErrProviderUnavailable error", func(t *testing.T) {
+		t.Parallel()
+		env := pmock.NewProvider(t)
+		env.EXPECT().Passphrase(prompt).
+			Return(nil, passphrase.ErrProviderUnavailable).Once()
+
+		var expSecret *crypto.Secret = nil
+		const expErr = passphrase.ErrProviderUnavailable
+
+		chain := NewChain(env)
+		secret, err := chain.Passphrase(prompt)
+		assert.Equal(t, expSecret, secret)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrPassphraseCanceled error", func(t *testing.T) {
+		t.Parallel()
+		pinentry := pmock.NewProvider(t)
+		tty := pmock.NewProvider(t)
+		pinentry.EXPECT().Passphrase(prompt).
+			Return(nil, passphrase.ErrPassphraseCanceled).Once()
+
+		var expSecret *crypto.Secret = nil
+		const expErr = passphrase.ErrPassphraseCanceled
+
+		chain := NewChain(pinentry, tty)
+		secret, err := chain.Passphrase(prompt)
+		assert.Equal(t, expSecret, secret)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		env := pmock.NewProvider(t)
+		tty := pmock.NewProvider(t)
+		expSecret, _ := crypto.NewSecretFrom([]byte("+DF7Rc-X/MOYjkNj"))
+		env.EXPECT().Passphrase(prompt).
+			Return(nil, passphrase.ErrProviderUnavailable).Once()
+		tty.EXPECT().Passphrase(prompt).Return(expSecret, nil).Once()
+
+		chain := NewChain(env, tty)
+		secret, err := chain.Passphrase(prompt)
+		assert.Equal(t, expSecret, secret)
+		assert.ErrorIs(t, err, nil)
+	})
+}
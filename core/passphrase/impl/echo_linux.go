@@ -0,0 +1,10 @@
+package passphrase_impl
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+const (
+	ioctlGetTermios = unix.TCGETS
+	ioctlSetTermios = unix.TCSETS
+)
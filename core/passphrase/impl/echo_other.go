@@ -0,0 +1,11 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+
+package passphrase_impl
+
+import (
+	"github.com/reshifr/secure-env/core/passphrase"
+)
+
+func disableEcho(fd int) (func(), error) {
+	return nil, passphrase.ErrProviderUnavailable
+}
@@ -0,0 +1,37 @@
+package passphrase_impl
+
+import (
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/reshifr/secure-env/core/passphrase"
+)
+
+type Env struct {
+	fn  FnEnv
+	key string
+}
+
+type FnEnv struct {
+	LookupEnv func(key string) (value string, ok bool)
+	Unsetenv  func(key string) error
+}
+
+func NewEnv(fn FnEnv, key string) Env {
+	return Env{fn: fn, key: key}
+}
+
+func (provider Env) Passphrase(prompt string) (*crypto.Secret, error) {
+	value, ok := provider.fn.LookupEnv(provider.key)
+	if !ok {
+		return nil, passphrase.ErrProviderUnavailable
+	}
+	provider.fn.Unsetenv(provider.key)
+	if len(value) > passphrase.MaxLen {
+		return nil, passphrase.ErrPassphraseTooLong
+	}
+	secret, err := crypto.NewSecret(len(value))
+	if err != nil {
+		return nil, err
+	}
+	copy(secret.Bytes(), value)
+	return secret, nil
+}
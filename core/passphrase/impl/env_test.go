@@ -0,0 +1,92 @@
+package passphrase_impl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/reshifr/secure-env/core/passphrase"
+	"github.com/stretchr/testify/assert"
+)
+
+func lookupEnv(vars map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := vars[key]
+		return value, ok
+	}
+}
+
+func unsetenv(vars map[string]string) func(string) error {
+	return func(key string) error {
+		delete(vars, key)
+		return nil
+	}
+}
+
+func Test_Env_Passphrase(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrProviderUnavailable error", func(t *testing.T) {
+		t.Parallel()
+		provider := NewEnv(FnEnv{
+			LookupEnv: lookupEnv(nil),
+			Unsetenv:  unsetenv(nil),
+		}, passphrase.PassphraseEnv)
+		var expSecret *crypto.Secret = nil
+		const expErr = passphrase.ErrProviderUnavailable
+
+		secret, err := provider.Passphrase("Vault passphrase")
+		assert.Equal(t, expSecret, secret)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrPassphraseTooLong error", func(t *testing.T) {
+		t.Parallel()
+		vars := map[string]string{
+			passphrase.PassphraseEnv: strings.Repeat("a", passphrase.MaxLen+1),
+		}
+		provider := NewEnv(FnEnv{
+			LookupEnv: lookupEnv(vars),
+			Unsetenv:  unsetenv(vars),
+		}, passphrase.PassphraseEnv)
+		var expSecret *crypto.Secret = nil
+		const expErr = passphrase.ErrPassphraseTooLong
+
+		secret, err := provider.Passphrase("Vault passphrase")
+		assert.Equal(t, expSecret, secret)
+		assert.ErrorIs(t, err, expErr)
+		assert.Empty(t, vars)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		vars := map[string]string{
+			passphrase.PassphraseEnv: "+DF7Rc-X/MOYjkNj",
+		}
+		provider := NewEnv(FnEnv{
+			LookupEnv: lookupEnv(vars),
+			Unsetenv:  unsetenv(vars),
+		}, passphrase.PassphraseEnv)
+
+		secret, err := provider.Passphrase("Vault passphrase")
+		assert.Equal(t, []byte("+DF7Rc-X/MOYjkNj"), secret.Bytes())
+		assert.ErrorIs(t, err, nil)
+		assert.Empty(t, vars)
+	})
+	t.Run("New passphrase", func(t *testing.T) {
+		t.Parallel()
+		vars := map[string]string{
+			passphrase.PassphraseEnv:    "+DF7Rc-X/MOYjkNj",
+			passphrase.NewPassphraseEnv: "q7!Lw2#zR9@pXe4v",
+		}
+		provider := NewEnv(FnEnv{
+			LookupEnv: lookupEnv(vars),
+			Unsetenv:  unsetenv(vars),
+		}, passphrase.NewPassphraseEnv)
+		expVars := map[string]string{
+			passphrase.PassphraseEnv: "+DF7Rc-X/MOYjkNj",
+		}
+
+		secret, err := provider.Passphrase("New passphrase")
+		assert.Equal(t, []byte("q7!Lw2#zR9@pXe4v"), secret.Bytes())
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, expVars, vars)
+	})
+}
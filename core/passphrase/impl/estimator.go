@@ -0,0 +1,583 @@
+package passphrase_impl
+
+import (
+	"bytes"
+	_ "embed"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/reshifr/secure-env/core/passphrase"
+)
+
+const (
+	EstimatorMaxLen        = 100
+	estimatorMinYearSpace  = 20
+	estimatorKeyboardStart = 94
+	estimatorKeyboardDeg   = 4.6
+)
+
+//go:embed commonpasswords.txt
+var commonPasswords string
+
+var keyboardRows = [][2]string{
+	{"`1234567890-=", "~!@#$%^&*()_+"},
+	{"qwertyuiop[]\\", "QWERTYUIOP{}|"},
+	{"asdfghjkl;'", "ASDFGHJKL:\""},
+	{"zxcvbnm,./", "ZXCVBNM<>?"},
+}
+
+var l33tTable = map[byte][2]byte{
+	'4': {'a', 'a'}, '@': {'a', 'a'}, '8': {'b', 'b'}, '(': {'c', 'c'},
+	'{': {'c', 'c'}, '3': {'e', 'e'}, '6': {'g', 'g'}, '9': {'g', 'g'},
+	'1': {'i', 'l'}, '!': {'i', 'i'}, '|': {'i', 'l'}, '0': {'o', 'o'},
+	'$': {'s', 's'}, '5': {'s', 's'}, '7': {'t', 't'}, '+': {'t', 't'},
+	'%': {'x', 'x'}, '2': {'z', 'z'},
+}
+
+type pattern int
+
+const (
+	patternBruteforce pattern = iota
+	patternDictionary
+	patternSequence
+	patternRepeat
+	patternSpatial
+	patternYear
+)
+
+type match struct {
+	pattern  pattern
+	i        int
+	j        int
+	guesses  float64
+	rank     int
+	reversed bool
+	l33t     bool
+	turns    int
+	period   int
+}
+
+type keyPos struct {
+	row     int
+	col     int
+	shifted bool
+}
+
+type Estimator struct {
+	ranks         map[string]int
+	maxWordLen    int
+	keys          map[byte]keyPos
+	referenceYear int
+}
+
+type FnEstimator struct {
+	Now func() time.Time
+}
+
+func NewEstimator(fn FnEstimator) Estimator {
+	estimator := Estimator{
+		ranks:         map[string]int{},
+		keys:          map[byte]keyPos{},
+		referenceYear: fn.Now().Year(),
+	}
+	for i, word := range strings.Fields(commonPasswords) {
+		if _, ok := estimator.ranks[word]; !ok {
+			estimator.ranks[word] = i + 1
+		}
+		estimator.maxWordLen = max(estimator.maxWordLen, len(word))
+	}
+	for row, keys := range keyboardRows {
+		for col := range len(keys[0]) {
+			estimator.keys[keys[0][col]] = keyPos{row: row, col: col}
+			estimator.keys[keys[1][col]] = keyPos{row: row, col: col, shifted: true}
+		}
+	}
+	return estimator
+}
+
+func log10Binomial(n int, k int) float64 {
+	a, _ := math.Lgamma(float64(n + 1))
+	b, _ := math.Lgamma(float64(k + 1))
+	c, _ := math.Lgamma(float64(n - k + 1))
+	return (a - b - c) / math.Ln10
+}
+
+func log10Factorial(n int) float64 {
+	a, _ := math.Lgamma(float64(n + 1))
+	return a / math.Ln10
+}
+
+func log10Variations(a int, b int) float64 {
+	if a == 0 || b == 0 {
+		return math.Log10(2)
+	}
+	sum := 0.0
+	for k := 1; k <= min(a, b); k++ {
+		sum += math.Pow(10, log10Binomial(a+b, k))
+	}
+	return math.Log10(sum)
+}
+
+func isLower(c byte) bool {
+	return c >= 'a' && c <= 'z'
+}
+
+func isUpper(c byte) bool {
+	return c >= 'A' && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func uppercaseVariations(word []byte) float64 {
+	upper, lower := 0, 0
+	for _, c := range word {
+		switch {
+		case isUpper(c):
+			upper++
+		case isLower(c):
+			lower++
+		}
+	}
+	if upper == 0 {
+		return 0
+	}
+	if lower == 0 ||
+		(upper == 1 && (isUpper(word[0]) || isUpper(word[len(word)-1]))) {
+		return math.Log10(2)
+	}
+	return log10Variations(upper, lower)
+}
+
+func l33tVariations(word []byte, plain []byte) float64 {
+	subbed, unsubbed := 0, 0
+	for k := range word {
+		if word[k] != plain[k] && !isUpper(word[k]) {
+			subbed++
+			continue
+		}
+		if _, ok := l33tTable[word[k]]; !ok && isLower(plain[k]) {
+			for _, sub := range l33tTable {
+				if sub[0] == plain[k] || sub[1] == plain[k] {
+					unsubbed++
+					break
+				}
+			}
+		}
+	}
+	if subbed == 0 {
+		return 0
+	}
+	return log10Variations(subbed, unsubbed)
+}
+
+func (estimator Estimator) dictionaryMatches(
+	password []byte, scratch []byte) []match {
+	n := len(password)
+	lower := scratch[:n]
+	reversed := scratch[n : 2*n]
+	subs := [2][]byte{scratch[2*n : 3*n], scratch[3*n : 4*n]}
+	for k, c := range password {
+		if isUpper(c) {
+			c += 'a' - 'A'
+		}
+		lower[k] = c
+		reversed[n-1-k] = c
+		for v := range subs {
+			subs[v][k] = c
+			if sub, ok := l33tTable[c]; ok {
+				subs[v][k] = sub[v]
+			}
+		}
+	}
+	matches := []match{}
+	for i := range n {
+		for j := i + 1; j <= min(n, i+estimator.maxWordLen); j++ {
+			word := password[i:j]
+			if rank, ok := estimator.ranks[string(lower[i:j])]; ok {
+				matches = append(matches, match{
+					pattern: patternDictionary,
+					i:       i,
+					j:       j,
+					rank:    rank,
+					guesses: math.Log10(float64(rank)) + uppercaseVariations(word),
+				})
+			}
+			rev := reversed[n-j : n-i]
+			if rank, ok := estimator.ranks[string(rev)]; ok &&
+				!bytes.Equal(rev, lower[i:j]) {
+				matches = append(matches, match{
+					pattern:  patternDictionary,
+					i:        i,
+					j:        j,
+					rank:     rank,
+					reversed: true,
+					guesses: math.Log10(float64(rank)) +
+						uppercaseVariations(word) + math.Log10(2),
+				})
+			}
+			for v := range subs {
+				plain := subs[v][i:j]
+				if v == 1 && bytes.Equal(plain, subs[0][i:j]) {
+					continue
+				}
+				if bytes.Equal(plain, lower[i:j]) {
+					continue
+				}
+				if rank, ok := estimator.ranks[string(plain)]; ok {
+					matches = append(matches, match{
+						pattern: patternDictionary,
+						i:       i,
+						j:       j,
+						rank:    rank,
+						l33t:    true,
+						guesses: math.Log10(float64(rank)) +
+							uppercaseVariations(word) + l33tVariations(word, plain),
+					})
+				}
+			}
+		}
+	}
+	return matches
+}
+
+func sameClass(word []byte) bool {
+	classes := [3]bool{}
+	for _, c := range word {
+		switch {
+		case isLower(c):
+			classes[0] = true
+		case isUpper(c):
+			classes[1] = true
+		case isDigit(c):
+			classes[2] = true
+		default:
+			return false
+		}
+	}
+	count := 0
+	for _, ok := range classes {
+		if ok {
+			count++
+		}
+	}
+	return count == 1
+}
+
+func sequenceMatches(password []byte) []match {
+	matches := []match{}
+	n := len(password)
+	for i := 0; i < n-1; {
+		delta := int(password[i+1]) - int(password[i])
+		j := i + 1
+		for j < n-1 && int(password[j+1])-int(password[j]) == delta {
+			j++
+		}
+		seqLen := j - i + 1
+		if seqLen >= 3 && delta != 0 && delta >= -5 && delta <= 5 &&
+			sameClass(password[i:j+1]) {
+			base := 26.0
+			switch {
+			case strings.IndexByte("aAzZ019", password[i]) >= 0:
+				base = 4
+			case isDigit(password[i]):
+				base = 10
+			}
+			if delta < 0 {
+				base *= 2
+			}
+			matches = append(matches, match{
+				pattern: patternSequence,
+				i:       i,
+				j:       j + 1,
+				guesses: math.Log10(base * float64(seqLen)),
+			})
+		}
+		i = j
+	}
+	return matches
+}
+
+func (estimator Estimator) repeatMatches(password []byte) []match {
+	matches := []match{}
+	n := len(password)
+	for i := 0; i < n; {
+		best := match{}
+		for period := 1; i+2*period <= n; period++ {
+			base := password[i : i+period]
+			count := 1
+			for i+(count+1)*period <= n &&
+				bytes.Equal(password[i+count*period:i+(count+1)*period], base) {
+				count++
+			}
+			if count >= 2 && count*period > best.j-best.i {
+				best = match{
+					pattern: patternRepeat,
+					i:       i,
+					j:       i + count*period,
+					period:  period,
+				}
+			}
+		}
+		if best.period == 0 {
+			i++
+			continue
+		}
+		count := (best.j - best.i) / best.period
+		baseGuesses, _ := estimator.search(password[i : i+best.period])
+		best.guesses = baseGuesses + math.Log10(float64(count))
+		matches = append(matches, best)
+		i = best.j
+	}
+	return matches
+}
+
+func (estimator Estimator) spatialMatches(password []byte) []match {
+	matches := []match{}
+	n := len(password)
+	for i := 0; i < n-2; {
+		j := i
+		turns, direction := 0, 0
+		for j < n-1 {
+			a, okA := estimator.keys[password[j]]
+			b, okB := estimator.keys[password[j+1]]
+			if !okA || !okB || a.row != b.row || (b.col-a.col)*(b.col-a.col) != 1 {
+				break
+			}
+			if b.col-a.col != direction {
+				turns++
+				direction = b.col - a.col
+			}
+			j++
+		}
+		runLen := j - i + 1
+		if runLen < 3 {
+			i++
+			continue
+		}
+		sum := 0.0
+		for l := 2; l <= runLen; l++ {
+			for t := 1; t <= min(turns, l-1); t++ {
+				sum += math.Pow(10, log10Binomial(l-1, t-1)) *
+					estimatorKeyboardStart * math.Pow(estimatorKeyboardDeg, float64(t))
+			}
+		}
+		shifted, unshifted := 0, 0
+		for _, c := range password[i : j+1] {
+			if estimator.keys[c].shifted {
+				shifted++
+			} else {
+				unshifted++
+			}
+		}
+		guesses := math.Log10(sum)
+		if shifted > 0 {
+			guesses += log10Variations(shifted, unshifted)
+		}
+		matches = append(matches, match{
+			pattern: patternSpatial,
+			i:       i,
+			j:       j + 1,
+			turns:   turns,
+			guesses: guesses,
+		})
+		i = j
+	}
+	return matches
+}
+
+func (estimator Estimator) yearMatches(password []byte) []match {
+	matches := []match{}
+	for i := 0; i+4 <= len(password); i++ {
+		year := 0
+		for _, c := range password[i : i+4] {
+			if !isDigit(c) {
+				year = -1
+				break
+			}
+			year = year*10 + int(c-'0')
+		}
+		if year < 1900 || year > 2099 {
+			continue
+		}
+		space := max(year-estimator.referenceYear,
+			estimator.referenceYear-year)
+		matches = append(matches, match{
+			pattern: patternYear,
+			i:       i,
+			j:       i + 4,
+			guesses: math.Log10(float64(max(space, estimatorMinYearSpace))),
+		})
+	}
+	return matches
+}
+
+func (estimator Estimator) search(password []byte) (float64, []match) {
+	n := len(password)
+	if n == 0 {
+		return 0, nil
+	}
+	scratch, err := crypto.NewSecret(4 * n)
+	if err != nil {
+		return 0, nil
+	}
+	defer scratch.Destroy()
+	matches := estimator.dictionaryMatches(password, scratch.Bytes())
+	matches = append(matches, sequenceMatches(password)...)
+	matches = append(matches, estimator.repeatMatches(password)...)
+	matches = append(matches, estimator.spatialMatches(password)...)
+	matches = append(matches, estimator.yearMatches(password)...)
+	byEnd := make([][]match, n+1)
+	for _, m := range matches {
+		minGuesses := math.Log10(50)
+		if m.j-m.i == 1 {
+			minGuesses = 1
+		}
+		m.guesses = max(m.guesses, minGuesses)
+		byEnd[m.j] = append(byEnd[m.j], m)
+	}
+
+	best := make([][]float64, n+1)
+	back := make([][]match, n+1)
+	for k := range best {
+		best[k] = make([]float64, n+1)
+		back[k] = make([]match, n+1)
+		for j := range best[k] {
+			best[k][j] = math.Inf(1)
+		}
+	}
+	best[0][0] = 0
+	for j := 1; j <= n; j++ {
+		for k := 1; k <= j; k++ {
+			for i := 0; i < j; i++ {
+				if g := best[k-1][i] + float64(j-i); g < best[k][j] {
+					best[k][j] = g
+					back[k][j] = match{pattern: patternBruteforce, i: i, j: j}
+				}
+			}
+			for _, m := range byEnd[j] {
+				if g := best[k-1][m.i] + m.guesses; g < best[k][j] {
+					best[k][j] = g
+					back[k][j] = m
+				}
+			}
+		}
+	}
+	guesses, segments := math.Inf(1), 0
+	for k := 1; k <= n; k++ {
+		if g := best[k][n] + log10Factorial(k); g < guesses {
+			guesses, segments = g, k
+		}
+	}
+	sequence := make([]match, segments)
+	for k, j := segments, n; k > 0; k-- {
+		sequence[k-1] = back[k][j]
+		j = back[k][j].i
+	}
+	return guesses, sequence
+}
+
+func score(guesses float64) passphrase.Score {
+	switch {
+	case guesses < 3:
+		return passphrase.ScoreTooGuessable
+	case guesses < 6:
+		return passphrase.ScoreVeryGuessable
+	case guesses < 8:
+		return passphrase.ScoreSomewhatGuessable
+	case guesses < 10:
+		return passphrase.ScoreSafelyUnguessable
+	default:
+		return passphrase.ScoreVeryUnguessable
+	}
+}
+
+func feedback(password []byte,
+	s passphrase.Score, sequence []match) passphrase.Feedback {
+	if len(password) == 0 {
+		return passphrase.Feedback{Suggestions: []string{
+			"Use a few words, avoid common phrases.",
+			"No need for symbols, digits, or uppercase letters.",
+		}}
+	}
+	if s > passphrase.ScoreSomewhatGuessable {
+		return passphrase.Feedback{}
+	}
+	const extra = "Add another word or two. Uncommon words are better."
+	longest := match{}
+	for _, m := range sequence {
+		if m.pattern != patternBruteforce && m.j-m.i > longest.j-longest.i {
+			longest = m
+		}
+	}
+	result := passphrase.Feedback{Suggestions: []string{extra}}
+	switch longest.pattern {
+	case patternDictionary:
+		word := password[longest.i:longest.j]
+		switch {
+		case len(sequence) == 1 && !longest.l33t && !longest.reversed &&
+			longest.rank <= 10:
+			result.Warning = "This is a top-10 common password."
+		case len(sequence) == 1 && !longest.l33t && !longest.reversed &&
+			longest.rank <= 100:
+			result.Warning = "This is a top-100 common password."
+		case len(sequence) == 1:
+			result.Warning = "This is a very common password."
+		default:
+			result.Warning = "This is similar to a commonly used password."
+		}
+		if isUpper(word[0]) && uppercaseVariations(word) == math.Log10(2) {
+			result.Suggestions = append(result.Suggestions,
+				"Capitalization doesn't help very much.")
+		}
+		if longest.reversed {
+			result.Suggestions = append(result.Suggestions,
+				"Reversed words aren't much harder to guess.")
+		}
+		if longest.l33t {
+			result.Suggestions = append(result.Suggestions,
+				"Predictable substitutions like '@' instead of 'a' "+
+					"don't help very much.")
+		}
+	case patternSequence:
+		result.Warning = "Sequences like abc or 6543 are easy to guess."
+		result.Suggestions = append(result.Suggestions, "Avoid sequences.")
+	case patternRepeat:
+		result.Warning = "Repeats like \"abcabcabc\" are only slightly " +
+			"harder to guess than \"abc\"."
+		if longest.period == 1 {
+			result.Warning = "Repeats like \"aaa\" are easy to guess."
+		}
+		result.Suggestions = append(result.Suggestions,
+			"Avoid repeated words and characters.")
+	case patternSpatial:
+		result.Warning = "Short keyboard patterns are easy to guess."
+		if longest.turns == 1 {
+			result.Warning = "Straight rows of keys are easy to guess."
+		}
+		result.Suggestions = append(result.Suggestions,
+			"Use a longer keyboard pattern with more turns.")
+	case patternYear:
+		result.Warning = "Recent years are easy to guess."
+		result.Suggestions = append(result.Suggestions,
+			"Avoid recent years.", "Avoid years that are associated with you.")
+	}
+	return result
+}
+
+func (estimator Estimator) Estimate(
+	secret *crypto.Secret) passphrase.Strength {
+	password := secret.Bytes()
+	head := password[:min(len(password), EstimatorMaxLen)]
+	guesses, sequence := estimator.search(head)
+	guesses += float64(len(password) - len(head))
+	s := score(guesses)
+	return passphrase.Strength{
+		GuessesLog10: guesses,
+		Score:        s,
+		Feedback:     feedback(head, s, sequence),
+	}
+}
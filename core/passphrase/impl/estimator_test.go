@@ -0,0 +1,159 @@
+package passphrase_impl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/reshifr/secure-env/core/passphrase"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	fnEstimator = FnEstimator{Now: func() time.Time {
+		return time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	}}
+)
+
+func estimate(password string) passphrase.Strength {
+	secret, _ := crypto.NewSecretFrom([]byte(password))
+	defer secret.Destroy()
+	return NewEstimator(fnEstimator).Estimate(secret)
+}
+
+func Test_NewEstimator(t *testing.T) {
+	t.Parallel()
+	estimator := NewEstimator(fnEstimator)
+	assert.Equal(t, 2026, estimator.referenceYear)
+	assert.Equal(t, 1, estimator.ranks["123456"])
+	assert.Equal(t, 2, estimator.ranks["password"])
+	assert.Equal(t, keyPos{row: 1, col: 0, shifted: true}, estimator.keys['Q'])
+}
+
+func Test_Estimator_Estimate(t *testing.T) {
+	t.Parallel()
+	const extra = "Add another word or two. Uncommon words are better."
+
+	t.Run("Empty passphrase", func(t *testing.T) {
+		t.Parallel()
+		expStrength := passphrase.Strength{
+			Score: passphrase.ScoreTooGuessable,
+			Feedback: passphrase.Feedback{Suggestions: []string{
+				"Use a few words, avoid common phrases.",
+				"No need for symbols, digits, or uppercase letters.",
+			}},
+		}
+
+		strength := estimate("")
+		assert.Equal(t, expStrength, strength)
+	})
+	t.Run("Common password", func(t *testing.T) {
+		t.Parallel()
+		expFeedback := passphrase.Feedback{
+			Warning:     "This is a top-10 common password.",
+			Suggestions: []string{extra},
+		}
+
+		strength := estimate("password")
+		assert.Equal(t, passphrase.ScoreTooGuessable, strength.Score)
+		assert.Equal(t, expFeedback, strength.Feedback)
+	})
+	t.Run("L33t substitution", func(t *testing.T) {
+		t.Parallel()
+		expFeedback := passphrase.Feedback{
+			Warning: "This is a very common password.",
+			Suggestions: []string{
+				extra,
+				"Capitalization doesn't help very much.",
+				"Predictable substitutions like '@' instead of 'a' " +
+					"don't help very much.",
+			},
+		}
+
+		strength := estimate("P@ssw0rd")
+		assert.Equal(t, passphrase.ScoreTooGuessable, strength.Score)
+		assert.Equal(t, expFeedback, strength.Feedback)
+	})
+	t.Run("Reversed word", func(t *testing.T) {
+		t.Parallel()
+		expFeedback := passphrase.Feedback{
+			Warning: "This is a very common password.",
+			Suggestions: []string{
+				extra,
+				"Reversed words aren't much harder to guess.",
+			},
+		}
+
+		strength := estimate("drowssap")
+		assert.Equal(t, passphrase.ScoreTooGuessable, strength.Score)
+		assert.Equal(t, expFeedback, strength.Feedback)
+	})
+	t.Run("Sequence", func(t *testing.T) {
+		t.Parallel()
+		expFeedback := passphrase.Feedback{
+			Warning:     "Sequences like abc or 6543 are easy to guess.",
+			Suggestions: []string{extra, "Avoid sequences."},
+		}
+
+		strength := estimate("lmnopq")
+		assert.Equal(t, passphrase.ScoreTooGuessable, strength.Score)
+		assert.Equal(t, expFeedback, strength.Feedback)
+	})
+	t.Run("Repeat", func(t *testing.T) {
+		t.Parallel()
+		expFeedback := passphrase.Feedback{
+			Warning: "Repeats like \"aaa\" are easy to guess.",
+			Suggestions: []string{
+				extra,
+				"Avoid repeated words and characters.",
+			},
+		}
+
+		strength := estimate("zzzzzzzzzz")
+		assert.Equal(t, passphrase.ScoreTooGuessable, strength.Score)
+		assert.Equal(t, expFeedback, strength.Feedback)
+	})
+	t.Run("Keyboard row", func(t *testing.T) {
+		t.Parallel()
+		expFeedback := passphrase.Feedback{
+			Warning: "Straight rows of keys are easy to guess.",
+			Suggestions: []string{
+				extra,
+				"Use a longer keyboard pattern with more turns.",
+			},
+		}
+
+		strength := estimate("sdfghj")
+		assert.Equal(t, passphrase.ScoreVeryGuessable, strength.Score)
+		assert.Equal(t, expFeedback, strength.Feedback)
+	})
+	t.Run("Recent year", func(t *testing.T) {
+		t.Parallel()
+		expFeedback := passphrase.Feedback{
+			Warning: "Recent years are easy to guess.",
+			Suggestions: []string{
+				extra,
+				"Avoid recent years.",
+				"Avoid years that are associated with you.",
+			},
+		}
+
+		strength := estimate("1987")
+		assert.Equal(t, passphrase.ScoreTooGuessable, strength.Score)
+		assert.Equal(t, expFeedback, strength.Feedback)
+	})
+	t.Run("Strong passphrase", func(t *testing.T) {
+		t.Parallel()
+		expFeedback := passphrase.Feedback{}
+
+		strength := estimate("+DF7Rc-X/MOYjkNj")
+		assert.Equal(t, passphrase.ScoreVeryUnguessable, strength.Score)
+		assert.Equal(t, expFeedback, strength.Feedback)
+	})
+	t.Run("Long passphrase", func(t *testing.T) {
+		t.Parallel()
+		head := estimate(string(make([]byte, EstimatorMaxLen)))
+		strength := estimate(string(make([]byte, EstimatorMaxLen+10)))
+		assert.InDelta(t, head.GuessesLog10+10, strength.GuessesLog10, 1e-9)
+	})
+}
@@ -0,0 +1,32 @@
+package passphrase_impl
+
+import (
+	"errors"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/reshifr/secure-env/core/passphrase"
+)
+
+type FD struct {
+	fd int
+}
+
+type fdReader int
+
+func NewFD(fd int) FD {
+	return FD{fd: fd}
+}
+
+func (provider FD) Passphrase(prompt string) (*crypto.Secret, error) {
+	if provider.fd < 0 {
+		return nil, passphrase.ErrProviderUnavailable
+	}
+	secret, err := readLine(fdReader(provider.fd))
+	if errors.Is(err, passphrase.ErrPassphraseTooLong) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, passphrase.ErrProviderUnavailable
+	}
+	return secret, nil
+}
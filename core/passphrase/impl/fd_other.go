@@ -0,0 +1,11 @@
+//go:build !unix
+
+package passphrase_impl
+
+import (
+	"github.com/reshifr/secure-env/core/passphrase"
+)
+
+func (fdReader) Read([]byte) (int, error) {
+	return 0, passphrase.ErrProviderUnavailable
+}
@@ -0,0 +1,48 @@
+package passphrase_impl
+
+import (
+	"os"
+	"testing"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/reshifr/secure-env/core/passphrase"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewFD(t *testing.T) {
+	t.Parallel()
+	expProvider := FD{fd: 3}
+
+	provider := NewFD(3)
+	assert.Equal(t, expProvider, provider)
+}
+
+func Test_FD_Passphrase(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrProviderUnavailable error", func(t *testing.T) {
+		t.Parallel()
+		provider := NewFD(-1)
+		var expSecret *crypto.Secret = nil
+		const expErr = passphrase.ErrProviderUnavailable
+
+		secret, err := provider.Passphrase("Vault passphrase")
+		assert.Equal(t, expSecret, secret)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		r, w, _ := os.Pipe()
+		t.Cleanup(func() { r.Close() })
+		w.WriteString("+DF7Rc-X/MOYjkNj\nrest\n")
+		w.Close()
+		provider := NewFD(int(r.Fd()))
+
+		secret, err := provider.Passphrase("Vault passphrase")
+		assert.Equal(t, []byte("+DF7Rc-X/MOYjkNj"), secret.Bytes())
+		assert.ErrorIs(t, err, nil)
+
+		secret, err = provider.Passphrase("Vault passphrase")
+		assert.Equal(t, []byte("rest"), secret.Bytes())
+		assert.ErrorIs(t, err, nil)
+	})
+}
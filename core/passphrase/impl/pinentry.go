@@ -0,0 +1,176 @@
+package passphrase_impl
+
+import (
+	"bytes"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/reshifr/secure-env/core/passphrase"
+)
+
+const (
+	PinentryDefaultProgram = "pinentry"
+	PinentryPrompt         = "Passphrase:"
+	PinentryErrCanceled    = 99
+)
+
+type Pinentry struct {
+	fn FnPinentry
+}
+
+type FnPinentry struct {
+	LookupEnv func(key string) (value string, ok bool)
+}
+
+type assuanConn struct {
+	r io.Reader
+	w io.Writer
+}
+
+func NewPinentry(fn FnPinentry) Pinentry {
+	return Pinentry{fn: fn}
+}
+
+func assuanEscape(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+func assuanUnescape(dst []byte, src []byte) (int, error) {
+	n := 0
+	for i := 0; i < len(src); i++ {
+		if n == len(dst) {
+			return 0, passphrase.ErrPassphraseTooLong
+		}
+		if src[i] != '%' {
+			dst[n] = src[i]
+			n++
+			continue
+		}
+		if i+2 >= len(src) {
+			return 0, passphrase.ErrInvalidResponse
+		}
+		c, err := strconv.ParseUint(string(src[i+1:i+3]), 16, 8)
+		if err != nil {
+			return 0, passphrase.ErrInvalidResponse
+		}
+		dst[n] = byte(c)
+		n++
+		i += 2
+	}
+	return n, nil
+}
+
+func assuanIs(line []byte, word string) bool {
+	return bytes.Equal(line, []byte(word)) ||
+		bytes.HasPrefix(line, []byte(word+" "))
+}
+
+func assuanErr(line []byte) error {
+	fields := strings.Fields(string(line[len("ERR"):]))
+	if len(fields) > 0 {
+		code, err := strconv.ParseUint(fields[0], 10, 32)
+		if err == nil && code&0xffff == PinentryErrCanceled {
+			return passphrase.ErrPassphraseCanceled
+		}
+	}
+	return passphrase.ErrInvalidResponse
+}
+
+func (conn assuanConn) response(data []byte) (int, error) {
+	n := 0
+	for {
+		secret, err := readLine(conn.r)
+		if err != nil {
+			return 0, passphrase.ErrInvalidResponse
+		}
+		line := secret.Bytes()
+		switch {
+		case assuanIs(line, "OK"):
+			secret.Destroy()
+			return n, nil
+		case assuanIs(line, "ERR"):
+			err := assuanErr(line)
+			secret.Destroy()
+			return 0, err
+		case assuanIs(line, "D"):
+			payload := bytes.TrimPrefix(line[len("D"):], []byte(" "))
+			m, err := assuanUnescape(data[n:], payload)
+			secret.Destroy()
+			if err != nil {
+				return 0, err
+			}
+			n += m
+		case assuanIs(line, "S") || bytes.HasPrefix(line, []byte("#")):
+			secret.Destroy()
+		default:
+			secret.Destroy()
+			return 0, passphrase.ErrInvalidResponse
+		}
+	}
+}
+
+func (conn assuanConn) command(cmd string) error {
+	if _, err := io.WriteString(conn.w, cmd+"\n"); err != nil {
+		return passphrase.ErrInvalidResponse
+	}
+	_, err := conn.response(nil)
+	return err
+}
+
+func (provider Pinentry) Passphrase(prompt string) (*crypto.Secret, error) {
+	program, ok := provider.fn.LookupEnv(passphrase.PinentryEnv)
+	if !ok || program == "" {
+		program = PinentryDefaultProgram
+	}
+	cmd := exec.Command(program)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, passphrase.ErrProviderUnavailable
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, passphrase.ErrProviderUnavailable
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, passphrase.ErrProviderUnavailable
+	}
+	defer cmd.Wait()
+	defer stdin.Close()
+	conn := assuanConn{r: stdout, w: stdin}
+	if _, err := conn.response(nil); err != nil {
+		return nil, err
+	}
+	if ttyName, ok := provider.fn.LookupEnv("GPG_TTY"); ok && ttyName != "" {
+		if err := conn.command("OPTION ttyname=" + ttyName); err != nil {
+			return nil, err
+		}
+	}
+	if err := conn.command("SETDESC " + assuanEscape(prompt)); err != nil {
+		return nil, err
+	}
+	if err := conn.command("SETPROMPT " + PinentryPrompt); err != nil {
+		return nil, err
+	}
+	buf, err := crypto.NewSecret(passphrase.MaxLen)
+	if err != nil {
+		return nil, err
+	}
+	defer buf.Destroy()
+	if _, err := io.WriteString(stdin, "GETPIN\n"); err != nil {
+		return nil, passphrase.ErrInvalidResponse
+	}
+	n, err := conn.response(buf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	io.WriteString(stdin, "BYE\n")
+	secret, err := crypto.NewSecret(n)
+	if err != nil {
+		return nil, err
+	}
+	copy(secret.Bytes(), buf.Bytes()[:n])
+	return secret, nil
+}
@@ -0,0 +1,102 @@
+package passphrase_impl
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/reshifr/secure-env/core/passphrase"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_assuanEscape(t *testing.T) {
+	t.Parallel()
+	const expStr = "100%25 sure%0D%0Aok"
+
+	str := assuanEscape("100% sure\r\nok")
+	assert.Equal(t, expStr, str)
+}
+
+func Test_assuanUnescape(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrPassphraseTooLong error", func(t *testing.T) {
+		t.Parallel()
+		dst := make([]byte, 2)
+		const expErr = passphrase.ErrPassphraseTooLong
+
+		n, err := assuanUnescape(dst, []byte("abc"))
+		assert.Equal(t, 0, n)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrInvalidResponse error", func(t *testing.T) {
+		t.Parallel()
+		dst := make([]byte, 8)
+		const expErr = passphrase.ErrInvalidResponse
+
+		n, err := assuanUnescape(dst, []byte("ab%2"))
+		assert.Equal(t, 0, n)
+		assert.ErrorIs(t, err, expErr)
+
+		n, err = assuanUnescape(dst, []byte("ab%zz"))
+		assert.Equal(t, 0, n)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		dst := make([]byte, 8)
+		expDst := []byte("1%\r\n")
+
+		n, err := assuanUnescape(dst, []byte("1%25%0D%0a"))
+		assert.Equal(t, expDst, dst[:n])
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_assuanConn_response(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrPassphraseCanceled error", func(t *testing.T) {
+		t.Parallel()
+		conn := assuanConn{
+			r: strings.NewReader("ERR 83886179 Operation cancelled\n"),
+		}
+		const expErr = passphrase.ErrPassphraseCanceled
+
+		n, err := conn.response(nil)
+		assert.Equal(t, 0, n)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrInvalidResponse error", func(t *testing.T) {
+		t.Parallel()
+		conn := assuanConn{r: strings.NewReader("INQUIRE PINENTRY_LAUNCHED\n")}
+		const expErr = passphrase.ErrInvalidResponse
+
+		n, err := conn.response(nil)
+		assert.Equal(t, 0, n)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		conn := assuanConn{r: strings.NewReader(
+			"# comment\nS PASSWORD_FROM_CACHE\nD +DF7Rc\nD -X/MOYjkNj%25\nOK\n")}
+		data := make([]byte, 32)
+		expData := []byte("+DF7Rc-X/MOYjkNj%")
+
+		n, err := conn.response(data)
+		assert.Equal(t, expData, data[:n])
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_Pinentry_Passphrase(t *testing.T) {
+	t.Parallel()
+	provider := NewPinentry(FnPinentry{LookupEnv: lookupEnv(map[string]string{
+		passphrase.PinentryEnv: filepath.Join(t.TempDir(), "pinentry"),
+	})})
+	var expSecret *crypto.Secret = nil
+	const expErr = passphrase.ErrProviderUnavailable
+
+	secret, err := provider.Passphrase("Vault passphrase")
+	assert.Equal(t, expSecret, secret)
+	assert.ErrorIs(t, err, expErr)
+}
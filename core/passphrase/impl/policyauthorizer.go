@@ -0,0 +1,84 @@
+package passphrase_impl
+
+import (
+	"crypto/subtle"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/reshifr/secure-env/core/passphrase"
+)
+
+type PolicyAuthorizer[
+	Authorizer crypto.Authorizer,
+	Estimator passphrase.Estimator] struct {
+	authorizer Authorizer
+	estimator  Estimator
+	policy     passphrase.Policy
+}
+
+func NewPolicyAuthorizer[
+	Authorizer crypto.Authorizer,
+	Estimator passphrase.Estimator](
+	authorizer Authorizer,
+	estimator Estimator,
+	policy passphrase.Policy) PolicyAuthorizer[Authorizer, Estimator] {
+	return PolicyAuthorizer[Authorizer, Estimator]{
+		authorizer: authorizer,
+		estimator:  estimator,
+		policy:     policy,
+	}
+}
+
+func (authorizer PolicyAuthorizer[Authorizer, Estimator]) Kind() string {
+	return authorizer.authorizer.Kind()
+}
+
+func (authorizer PolicyAuthorizer[Authorizer, Estimator]) KDFParams() crypto.KDFParams {
+	return authorizer.authorizer.KDFParams()
+}
+
+func (authorizer PolicyAuthorizer[Authorizer, Estimator]) Enforce(
+	secret *crypto.Secret) error {
+	strength := authorizer.estimator.Estimate(secret)
+	return authorizer.policy.Check(secret, strength)
+}
+
+func (authorizer PolicyAuthorizer[Authorizer, Estimator]) enforceChild(
+	parent *crypto.Secret, child *crypto.Secret) error {
+	if authorizer.policy.RejectReuse &&
+		subtle.ConstantTimeCompare(parent.Bytes(), child.Bytes()) == 1 {
+		return &passphrase.PolicyViolation{
+			Err: passphrase.ErrPassphraseReused,
+			Feedback: passphrase.Feedback{Suggestions: []string{
+				"Choose a passphrase that differs from the old or parent one.",
+			}},
+		}
+	}
+	return authorizer.Enforce(child)
+}
+
+func (authorizer PolicyAuthorizer[Authorizer, Estimator]) Make(
+	iv crypto.IV,
+	passphrase *crypto.Secret,
+	keyLen uint32) (*crypto.Secret, []byte, error) {
+	return authorizer.authorizer.Make(iv, passphrase, keyLen)
+}
+
+func (authorizer PolicyAuthorizer[Authorizer, Estimator]) Open(
+	params crypto.KDFParams,
+	passphrase *crypto.Secret,
+	block []byte) (*crypto.Secret, error) {
+	return authorizer.authorizer.Open(params, passphrase, block)
+}
+
+func (authorizer PolicyAuthorizer[Authorizer, Estimator]) Inherit(
+	iv crypto.IV,
+	params crypto.KDFParams,
+	passphrase *crypto.Secret,
+	childPassphrase *crypto.Secret,
+	block []byte) (*crypto.Secret, []byte, error) {
+	if err := authorizer.enforceChild(passphrase, childPassphrase); err != nil {
+		return nil, nil, err
+	}
+	return authorizer.authorizer.Inherit(
+		iv, params, passphrase, childPassphrase, block)
+}
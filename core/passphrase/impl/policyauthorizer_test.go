@@ -0,0 +1,206 @@
+package passphrase_impl
+
+import (
+	"testing"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	cmock "github.com/reshifr/secure-env/core/crypto/mock"
+	"github.com/reshifr/secure-env/core/passphrase"
+	pmock "github.com/reshifr/secure-env/core/passphrase/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSecret(s string) *crypto.Secret {
+	secret, _ := crypto.NewSecretFrom([]byte(s))
+	return secret
+}
+
+func Test_NewPolicyAuthorizer(t *testing.T) {
+	t.Parallel()
+	inner := cmock.NewAuthorizer(t)
+	estimator := pmock.NewEstimator(t)
+	policy := passphrase.DefaultPolicy()
+	expAuthorizer := PolicyAuthorizer[*cmock.Authorizer, *pmock.Estimator]{
+		authorizer: inner,
+		estimator:  estimator,
+		policy:     policy,
+	}
+
+	authorizer := NewPolicyAuthorizer(inner, estimator, policy)
+	assert.Equal(t, expAuthorizer, authorizer)
+}
+
+func Test_PolicyAuthorizer_Kind(t *testing.T) {
+	t.Parallel()
+	inner := cmock.NewAuthorizer(t)
+	estimator := pmock.NewEstimator(t)
+	const expKind = "passphrase"
+	inner.EXPECT().Kind().Return(expKind).Once()
+
+	authorizer := NewPolicyAuthorizer(
+		inner, estimator, passphrase.DefaultPolicy())
+	kind := authorizer.Kind()
+	assert.Equal(t, expKind, kind)
+}
+
+func Test_PolicyAuthorizer_KDFParams(t *testing.T) {
+	t.Parallel()
+	inner := cmock.NewAuthorizer(t)
+	estimator := pmock.NewEstimator(t)
+	expParams := crypto.KDFParams{Algorithm: "argon2i", Time: 3}
+	inner.EXPECT().KDFParams().Return(expParams).Once()
+
+	authorizer := NewPolicyAuthorizer(
+		inner, estimator, passphrase.DefaultPolicy())
+	params := authorizer.KDFParams()
+	assert.Equal(t, expParams, params)
+}
+
+func Test_PolicyAuthorizer_Enforce(t *testing.T) {
+	t.Parallel()
+	policy := passphrase.Policy{
+		MinLen:   8,
+		MinScore: passphrase.ScoreSafelyUnguessable,
+	}
+
+	t.Run("ErrPassphraseTooWeak error", func(t *testing.T) {
+		t.Parallel()
+		inner := cmock.NewAuthorizer(t)
+		estimator := pmock.NewEstimator(t)
+		secret := newSecret("password")
+		feedback := passphrase.Feedback{
+			Warning: "This is a top-10 common password.",
+		}
+		estimator.EXPECT().Estimate(secret).Return(passphrase.Strength{
+			Score:    passphrase.ScoreTooGuessable,
+			Feedback: feedback,
+		}).Once()
+
+		expErr := &passphrase.PolicyViolation{
+			Err:      passphrase.ErrPassphraseTooWeak,
+			Feedback: feedback,
+		}
+
+		authorizer := NewPolicyAuthorizer(inner, estimator, policy)
+		err := authorizer.Enforce(secret)
+		assert.Equal(t, expErr, err)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		inner := cmock.NewAuthorizer(t)
+		estimator := pmock.NewEstimator(t)
+		secret := newSecret("+DF7Rc-X/MOYjkNj")
+		estimator.EXPECT().Estimate(secret).Return(passphrase.Strength{
+			Score: passphrase.ScoreVeryUnguessable,
+		}).Once()
+
+		authorizer := NewPolicyAuthorizer(inner, estimator, policy)
+		err := authorizer.Enforce(secret)
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_PolicyAuthorizer_Make(t *testing.T) {
+	t.Parallel()
+	iv := cmock.NewIV(t)
+	inner := cmock.NewAuthorizer(t)
+	estimator := pmock.NewEstimator(t)
+	secret := newSecret("password")
+	expAccessKey := newSecret("\x11")
+	expBlock := []byte{0x21}
+	inner.EXPECT().Make(iv, secret, uint32(32)).
+		Return(expAccessKey, expBlock, nil).Once()
+
+	authorizer := NewPolicyAuthorizer(
+		inner, estimator, passphrase.DefaultPolicy())
+	accessKey, block, err := authorizer.Make(iv, secret, 32)
+	assert.Equal(t, expAccessKey, accessKey)
+	assert.Equal(t, expBlock, block)
+	assert.ErrorIs(t, err, nil)
+}
+
+func Test_PolicyAuthorizer_Open(t *testing.T) {
+	t.Parallel()
+	inner := cmock.NewAuthorizer(t)
+	estimator := pmock.NewEstimator(t)
+	params := crypto.KDFParams{Algorithm: "argon2i", Time: 3}
+	secret := newSecret("pw")
+	block := []byte{0x21}
+	expAccessKey := newSecret("\x11")
+	inner.EXPECT().Open(params, secret, block).
+		Return(expAccessKey, nil).Once()
+
+	authorizer := NewPolicyAuthorizer(
+		inner, estimator, passphrase.DefaultPolicy())
+	accessKey, err := authorizer.Open(params, secret, block)
+	assert.Equal(t, expAccessKey, accessKey)
+	assert.ErrorIs(t, err, nil)
+}
+
+func Test_PolicyAuthorizer_Inherit(t *testing.T) {
+	t.Parallel()
+	policy := passphrase.DefaultPolicy()
+	params := crypto.KDFParams{Algorithm: "argon2i", Time: 3}
+	block := []byte{0x21}
+
+	t.Run("ErrPassphraseReused error", func(t *testing.T) {
+		t.Parallel()
+		inner := cmock.NewAuthorizer(t)
+		estimator := pmock.NewEstimator(t)
+		parent := newSecret("+DF7Rc-X/MOYjkNj")
+		child := newSecret("+DF7Rc-X/MOYjkNj")
+		var expAccessKey *crypto.Secret = nil
+		var expBlock []byte = nil
+		const expErr = passphrase.ErrPassphraseReused
+
+		authorizer := NewPolicyAuthorizer(inner, estimator, policy)
+		accessKey, childBlock, err := authorizer.Inherit(
+			nil, params, parent, child, block)
+		assert.Equal(t, expAccessKey, accessKey)
+		assert.Equal(t, expBlock, childBlock)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrPassphraseTooShort error", func(t *testing.T) {
+		t.Parallel()
+		inner := cmock.NewAuthorizer(t)
+		estimator := pmock.NewEstimator(t)
+		parent := newSecret("+DF7Rc-X/MOYjkNj")
+		child := newSecret("q7!Lw2#z")
+		estimator.EXPECT().Estimate(child).Return(passphrase.Strength{
+			Score: passphrase.ScoreVeryUnguessable,
+		}).Once()
+
+		var expAccessKey *crypto.Secret = nil
+		var expBlock []byte = nil
+		const expErr = passphrase.ErrPassphraseTooShort
+
+		authorizer := NewPolicyAuthorizer(inner, estimator, policy)
+		accessKey, childBlock, err := authorizer.Inherit(
+			nil, params, parent, child, block)
+		assert.Equal(t, expAccessKey, accessKey)
+		assert.Equal(t, expBlock, childBlock)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		iv := cmock.NewIV(t)
+		inner := cmock.NewAuthorizer(t)
+		estimator := pmock.NewEstimator(t)
+		parent := newSecret("+DF7Rc-X/MOYjkNj")
+		child := newSecret("q7!Lw2#zR9@pXe4v")
+		expAccessKey := newSecret("\x11")
+		expBlock := []byte{0x22}
+		estimator.EXPECT().Estimate(child).Return(passphrase.Strength{
+			Score: passphrase.ScoreVeryUnguessable,
+		}).Once()
+		inner.EXPECT().Inherit(iv, params, parent, child, block).
+			Return(expAccessKey, expBlock, nil).Once()
+
+		authorizer := NewPolicyAuthorizer(inner, estimator, policy)
+		accessKey, childBlock, err := authorizer.Inherit(
+			iv, params, parent, child, block)
+		assert.Equal(t, expAccessKey, accessKey)
+		assert.Equal(t, expBlock, childBlock)
+		assert.ErrorIs(t, err, nil)
+	})
+}
@@ -0,0 +1,44 @@
+package passphrase_impl
+
+import (
+	"errors"
+	"io"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/reshifr/secure-env/core/passphrase"
+)
+
+func readLine(r io.Reader) (*crypto.Secret, error) {
+	buf, err := crypto.NewSecret(passphrase.MaxLen + 1)
+	if err != nil {
+		return nil, err
+	}
+	defer buf.Destroy()
+	line := buf.Bytes()
+	n := 0
+	for {
+		if n > passphrase.MaxLen {
+			return nil, passphrase.ErrPassphraseTooLong
+		}
+		m, err := r.Read(line[n : n+1])
+		if m > 0 && line[n] == '\n' {
+			break
+		}
+		n += m
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if n > 0 && line[n-1] == '\r' {
+		n--
+	}
+	secret, err := crypto.NewSecret(n)
+	if err != nil {
+		return nil, err
+	}
+	copy(secret.Bytes(), line[:n])
+	return secret, nil
+}
@@ -0,0 +1,61 @@
+package passphrase_impl
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/reshifr/secure-env/core/passphrase"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_readLine(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrPassphraseTooLong error", func(t *testing.T) {
+		t.Parallel()
+		r := strings.NewReader(strings.Repeat("a", passphrase.MaxLen+1))
+		var expSecret *crypto.Secret = nil
+		const expErr = passphrase.ErrPassphraseTooLong
+
+		secret, err := readLine(r)
+		assert.Equal(t, expSecret, secret)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Read error", func(t *testing.T) {
+		t.Parallel()
+		expErr := errors.New("read failed")
+		r := iotest.ErrReader(expErr)
+		var expSecret *crypto.Secret = nil
+
+		secret, err := readLine(r)
+		assert.Equal(t, expSecret, secret)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Maximum length", func(t *testing.T) {
+		t.Parallel()
+		line := strings.Repeat("a", passphrase.MaxLen)
+		r := strings.NewReader(line + "\n")
+
+		secret, err := readLine(r)
+		assert.Equal(t, []byte(line), secret.Bytes())
+		assert.ErrorIs(t, err, nil)
+	})
+	t.Run("Line without newline", func(t *testing.T) {
+		t.Parallel()
+		r := iotest.OneByteReader(strings.NewReader("+DF7Rc-X/MOYjkNj"))
+
+		secret, err := readLine(r)
+		assert.Equal(t, []byte("+DF7Rc-X/MOYjkNj"), secret.Bytes())
+		assert.ErrorIs(t, err, nil)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		r := strings.NewReader("+DF7Rc-X/MOYjkNj\r\nrest\n")
+
+		secret, err := readLine(r)
+		assert.Equal(t, []byte("+DF7Rc-X/MOYjkNj"), secret.Bytes())
+		assert.ErrorIs(t, err, nil)
+	})
+}
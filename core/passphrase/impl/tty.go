@@ -0,0 +1,79 @@
+package passphrase_impl
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/reshifr/secure-env/core/passphrase"
+)
+
+const (
+	TTYPath = "/dev/tty"
+)
+
+var (
+	TTYSignals = []os.Signal{
+		syscall.SIGINT,
+		syscall.SIGTERM,
+		syscall.SIGHUP,
+		syscall.SIGQUIT,
+	}
+)
+
+type TTY struct {
+	path string
+}
+
+func NewTTY(path string) TTY {
+	return TTY{path: path}
+}
+
+func (provider TTY) Passphrase(prompt string) (*crypto.Secret, error) {
+	tty, err := os.OpenFile(provider.path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, passphrase.ErrProviderUnavailable
+	}
+	defer tty.Close()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, TTYSignals...)
+	defer signal.Stop(signals)
+	restore, err := disableEcho(int(tty.Fd()))
+	if err != nil {
+		return nil, passphrase.ErrProviderUnavailable
+	}
+	done := make(chan struct{})
+	go restoreOnSignal(signals, done, restore, raise)
+	fmt.Fprintf(tty, "%s: ", prompt)
+	secret, err := readLine(tty)
+	close(done)
+	restore()
+	fmt.Fprintln(tty)
+	if errors.Is(err, passphrase.ErrPassphraseTooLong) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, passphrase.ErrProviderUnavailable
+	}
+	return secret, nil
+}
+
+func restoreOnSignal(signals <-chan os.Signal,
+	done <-chan struct{}, restore func(), raise func(sig os.Signal)) {
+	select {
+	case sig := <-signals:
+		restore()
+		raise(sig)
+	case <-done:
+	}
+}
+
+func raise(sig os.Signal) {
+	signal.Reset(sig)
+	if process, err := os.FindProcess(os.Getpid()); err == nil {
+		process.Signal(sig)
+	}
+}
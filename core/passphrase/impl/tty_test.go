@@ -0,0 +1,74 @@
+package passphrase_impl
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/reshifr/secure-env/core/passphrase"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewTTY(t *testing.T) {
+	t.Parallel()
+	expProvider := TTY{path: TTYPath}
+
+	provider := NewTTY(TTYPath)
+	assert.Equal(t, expProvider, provider)
+}
+
+func Test_TTY_Passphrase(t *testing.T) {
+	t.Parallel()
+	t.Run("Missing terminal", func(t *testing.T) {
+		t.Parallel()
+		provider := NewTTY(filepath.Join(t.TempDir(), "tty"))
+		var expSecret *crypto.Secret = nil
+		const expErr = passphrase.ErrProviderUnavailable
+
+		secret, err := provider.Passphrase("Vault passphrase")
+		assert.Equal(t, expSecret, secret)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Not a terminal", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "tty")
+		os.WriteFile(path, []byte("+DF7Rc-X/MOYjkNj\n"), 0600)
+		provider := NewTTY(path)
+		var expSecret *crypto.Secret = nil
+		const expErr = passphrase.ErrProviderUnavailable
+
+		secret, err := provider.Passphrase("Vault passphrase")
+		assert.Equal(t, expSecret, secret)
+		assert.ErrorIs(t, err, expErr)
+	})
+}
+
+func Test_restoreOnSignal(t *testing.T) {
+	t.Parallel()
+	t.Run("Signal received", func(t *testing.T) {
+		t.Parallel()
+		signals := make(chan os.Signal, 1)
+		signals <- syscall.SIGINT
+		restored := false
+		var raised os.Signal = nil
+
+		restoreOnSignal(signals, nil,
+			func() { restored = true },
+			func(sig os.Signal) { raised = sig })
+		assert.True(t, restored)
+		assert.Equal(t, syscall.SIGINT, raised)
+	})
+	t.Run("Read done", func(t *testing.T) {
+		t.Parallel()
+		done := make(chan struct{})
+		close(done)
+		restored := false
+
+		restoreOnSignal(nil, done,
+			func() { restored = true },
+			func(os.Signal) { t.Fail() })
+		assert.False(t, restored)
+	})
+}
@@ -0,0 +1,77 @@
+package passphrase
+
+import (
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/reshifr/secure-env/core/failure"
+)
+
+const (
+	PassphraseEnv    = "SENV_PASSPHRASE"
+	NewPassphraseEnv = "SENV_NEW_PASSPHRASE"
+	AskpassEnv       = "SENV_ASKPASS"
+	PinentryEnv      = "SENV_PINENTRY"
+	OrderEnv         = "SENV_PASSPHRASE_ORDER"
+	MaxLen           = 1024
+)
+
+const (
+	ProviderFD       = "fd"
+	ProviderEnv      = "env"
+	ProviderAskpass  = "askpass"
+	ProviderPinentry = "pinentry"
+	ProviderTTY      = "tty"
+	DefaultOrder     = "fd,env,askpass,pinentry,tty"
+)
+
+type ProviderError int
+
+const (
+	ErrProviderUnavailable ProviderError = iota + 1
+	ErrPassphraseCanceled
+	ErrPassphraseTooLong
+	ErrInvalidResponse
+	ErrUnknownProvider
+	ErrNewPassphraseUnavailable
+)
+
+func (err ProviderError) Error() string {
+	switch err {
+	case ErrProviderUnavailable:
+		return "ErrProviderUnavailable: " +
+			"the passphrase provider is not available."
+	case ErrPassphraseCanceled:
+		return "ErrPassphraseCanceled: the passphrase entry was canceled."
+	case ErrPassphraseTooLong:
+		return "ErrPassphraseTooLong: " +
+			"the passphrase exceeds the maximum length."
+	case ErrInvalidResponse:
+		return "ErrInvalidResponse: " +
+			"the passphrase provider sent a malformed response."
+	case ErrUnknownProvider:
+		return "ErrUnknownProvider: " +
+			"the passphrase provider name is not recognized."
+	case ErrNewPassphraseUnavailable:
+		return "ErrNewPassphraseUnavailable: " +
+			"set " + NewPassphraseEnv + " or use --passphrase-fd or " +
+			AskpassEnv + " to supply the new passphrase."
+	default:
+		return "Error: unknown."
+	}
+}
+
+func (err ProviderError) Kind() failure.Kind {
+	switch err {
+	case ErrProviderUnavailable, ErrNewPassphraseUnavailable:
+		return failure.KindIO
+	case ErrPassphraseCanceled:
+		return failure.KindCanceled
+	case ErrPassphraseTooLong, ErrInvalidResponse, ErrUnknownProvider:
+		return failure.KindInvalid
+	default:
+		return failure.KindInternal
+	}
+}
+
+type Provider interface {
+	Passphrase(prompt string) (passphrase *crypto.Secret, err error)
+}
@@ -0,0 +1,122 @@
+package passphrase
+
+import (
+	"testing"
+
+	"github.com/reshifr/secure-env/core/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ProviderError_Error(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrProviderUnavailable value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrProviderUnavailable
+		const expMsg = "ErrProviderUnavailable: " +
+			"the passphrase provider is not available."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrPassphraseCanceled value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrPassphraseCanceled
+		const expMsg = "ErrPassphraseCanceled: " +
+			"the passphrase entry was canceled."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrPassphraseTooLong value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrPassphraseTooLong
+		const expMsg = "ErrPassphraseTooLong: " +
+			"the passphrase exceeds the maximum length."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrInvalidResponse value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrInvalidResponse
+		const expMsg = "ErrInvalidResponse: " +
+			"the passphrase provider sent a malformed response."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrUnknownProvider value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrUnknownProvider
+		const expMsg = "ErrUnknownProvider: " +
+			"the passphrase provider name is not recognized."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrNewPassphraseUnavailable value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrNewPassphraseUnavailable
+		const expMsg = "ErrNewPassphraseUnavailable: " +
+			"set SENV_NEW_PASSPHRASE or use --passphrase-fd or SENV_ASKPASS " +
+			"to supply the new passphrase."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("Unknown value", func(t *testing.T) {
+		t.Parallel()
+		const err = ProviderError(527162)
+		const expMsg = "Error: unknown."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+}
+
+func Test_ProviderError_Kind(t *testing.T) {
+	t.Parallel()
+	t.Run("KindIO value", func(t *testing.T) {
+		t.Parallel()
+		errs := []ProviderError{
+			ErrProviderUnavailable,
+			ErrNewPassphraseUnavailable,
+		}
+		const expKind = failure.KindIO
+
+		for _, err := range errs {
+			kind := err.Kind()
+			assert.Equal(t, expKind, kind, err.Error())
+		}
+	})
+	t.Run("KindCanceled value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrPassphraseCanceled
+		const expKind = failure.KindCanceled
+
+		kind := err.Kind()
+		assert.Equal(t, expKind, kind)
+	})
+	t.Run("KindInvalid value", func(t *testing.T) {
+		t.Parallel()
+		errs := []ProviderError{
+			ErrPassphraseTooLong,
+			ErrInvalidResponse,
+			ErrUnknownProvider,
+		}
+		const expKind = failure.KindInvalid
+
+		for _, err := range errs {
+			kind := err.Kind()
+			assert.Equal(t, expKind, kind, err.Error())
+		}
+	})
+	t.Run("Unknown value", func(t *testing.T) {
+		t.Parallel()
+		const err = ProviderError(613724)
+		const expKind = failure.KindInternal
+
+		kind := err.Kind()
+		assert.Equal(t, expKind, kind)
+	})
+}
@@ -0,0 +1,143 @@
+package passphrase
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/reshifr/secure-env/core/failure"
+)
+
+const (
+	PolicyDefaultMinLen   = 12
+	PolicyDefaultMinScore = ScoreSafelyUnguessable
+)
+
+type PolicyError int
+
+const (
+	ErrPassphraseTooShort PolicyError = iota + 1
+	ErrPassphraseTooWeak
+	ErrPassphraseReused
+	ErrInvalidPolicy
+)
+
+func (err PolicyError) Error() string {
+	switch err {
+	case ErrPassphraseTooShort:
+		return "ErrPassphraseTooShort: the passphrase is too short."
+	case ErrPassphraseTooWeak:
+		return "ErrPassphraseTooWeak: the passphrase is too easy to guess."
+	case ErrPassphraseReused:
+		return "ErrPassphraseReused: " +
+			"the new passphrase is the same as the one that authorized it."
+	case ErrInvalidPolicy:
+		return "ErrInvalidPolicy: the passphrase policy is out of range."
+	default:
+		return "Error: unknown."
+	}
+}
+
+func (err PolicyError) Kind() failure.Kind {
+	switch err {
+	case ErrPassphraseTooShort, ErrPassphraseTooWeak, ErrPassphraseReused:
+		return failure.KindPolicy
+	case ErrInvalidPolicy:
+		return failure.KindInvalid
+	default:
+		return failure.KindInternal
+	}
+}
+
+type Score int
+
+const (
+	ScoreTooGuessable Score = iota
+	ScoreVeryGuessable
+	ScoreSomewhatGuessable
+	ScoreSafelyUnguessable
+	ScoreVeryUnguessable
+)
+
+type Feedback struct {
+	Warning     string
+	Suggestions []string
+}
+
+type Strength struct {
+	GuessesLog10 float64
+	Score        Score
+	Feedback     Feedback
+}
+
+type Estimator interface {
+	Estimate(passphrase *crypto.Secret) (strength Strength)
+}
+
+type Enforcer interface {
+	Enforce(passphrase *crypto.Secret) (err error)
+}
+
+type Policy struct {
+	MinLen      int
+	MinScore    Score
+	RejectReuse bool
+}
+
+type PolicyViolation struct {
+	Err      PolicyError
+	Feedback Feedback
+}
+
+func DefaultPolicy() Policy {
+	return Policy{
+		MinLen:      PolicyDefaultMinLen,
+		MinScore:    PolicyDefaultMinScore,
+		RejectReuse: true,
+	}
+}
+
+func (violation *PolicyViolation) Error() string {
+	var b strings.Builder
+	b.WriteString(violation.Err.Error())
+	if violation.Feedback.Warning != "" {
+		b.WriteString(" ")
+		b.WriteString(violation.Feedback.Warning)
+	}
+	for _, suggestion := range violation.Feedback.Suggestions {
+		b.WriteString(" ")
+		b.WriteString(suggestion)
+	}
+	return b.String()
+}
+
+func (violation *PolicyViolation) Unwrap() error {
+	return violation.Err
+}
+
+func (policy Policy) Validate() error {
+	if policy.MinLen < 0 || policy.MinScore < ScoreTooGuessable ||
+		policy.MinScore > ScoreVeryUnguessable {
+		return ErrInvalidPolicy
+	}
+	return nil
+}
+
+func (policy Policy) Check(passphrase *crypto.Secret, strength Strength) error {
+	minLen := max(policy.MinLen, 1)
+	if utf8.RuneCount(passphrase.Bytes()) < minLen {
+		feedback := strength.Feedback
+		feedback.Suggestions = append([]string{
+			"Use at least " + strconv.Itoa(minLen) + " characters.",
+		}, feedback.Suggestions...)
+		return &PolicyViolation{Err: ErrPassphraseTooShort, Feedback: feedback}
+	}
+	if strength.Score < policy.MinScore {
+		return &PolicyViolation{
+			Err:      ErrPassphraseTooWeak,
+			Feedback: strength.Feedback,
+		}
+	}
+	return nil
+}
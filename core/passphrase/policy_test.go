@@ -0,0 +1,191 @@
+package passphrase
+
+import (
+	"testing"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/reshifr/secure-env/core/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PolicyError_Error(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrPassphraseTooShort value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrPassphraseTooShort
+		const expMsg = "ErrPassphraseTooShort: the passphrase is too short."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrPassphraseTooWeak value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrPassphraseTooWeak
+		const expMsg = "ErrPassphraseTooWeak: the passphrase is too easy to guess."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrPassphraseReused value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrPassphraseReused
+		const expMsg = "ErrPassphraseReused: " +
+			"the new passphrase is the same as the one that authorized it."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrInvalidPolicy value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrInvalidPolicy
+		const expMsg = "ErrInvalidPolicy: the passphrase policy is out of range."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("Unknown value", func(t *testing.T) {
+		t.Parallel()
+		const err = PolicyError(527162)
+		const expMsg = "Error: unknown."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+}
+
+func Test_DefaultPolicy(t *testing.T) {
+	t.Parallel()
+	expPolicy := Policy{
+		MinLen:      PolicyDefaultMinLen,
+		MinScore:    PolicyDefaultMinScore,
+		RejectReuse: true,
+	}
+
+	policy := DefaultPolicy()
+	assert.Equal(t, expPolicy, policy)
+}
+
+func Test_PolicyViolation_Error(t *testing.T) {
+	t.Parallel()
+	violation := &PolicyViolation{
+		Err: ErrPassphraseTooWeak,
+		Feedback: Feedback{
+			Warning:     "This is a top-10 common password.",
+			Suggestions: []string{"Add another word or two."},
+		},
+	}
+	const expMsg = "ErrPassphraseTooWeak: the passphrase is too easy to guess. " +
+		"This is a top-10 common password. Add another word or two."
+
+	msg := violation.Error()
+	assert.Equal(t, expMsg, msg)
+	assert.ErrorIs(t, violation, ErrPassphraseTooWeak)
+}
+
+func Test_Policy_Validate(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrInvalidPolicy error", func(t *testing.T) {
+		t.Parallel()
+		policies := []Policy{
+			{MinLen: -1},
+			{MinScore: ScoreTooGuessable - 1},
+			{MinScore: ScoreVeryUnguessable + 1},
+		}
+		const expErr = ErrInvalidPolicy
+
+		for _, policy := range policies {
+			err := policy.Validate()
+			assert.ErrorIs(t, err, expErr)
+		}
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		err := DefaultPolicy().Validate()
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_Policy_Check(t *testing.T) {
+	t.Parallel()
+	policy := Policy{MinLen: 4, MinScore: ScoreSafelyUnguessable}
+	feedback := Feedback{Warning: "Recent years are easy to guess."}
+
+	t.Run("ErrPassphraseTooShort error", func(t *testing.T) {
+		t.Parallel()
+		secret, _ := crypto.NewSecretFrom([]byte("ąęó"))
+		strength := Strength{Score: ScoreVeryUnguessable, Feedback: feedback}
+		expErr := &PolicyViolation{
+			Err: ErrPassphraseTooShort,
+			Feedback: Feedback{
+				Warning:     feedback.Warning,
+				Suggestions: []string{"Use at least 4 characters."},
+			},
+		}
+
+		err := policy.Check(secret, strength)
+		assert.Equal(t, expErr, err)
+		assert.ErrorIs(t, err, ErrPassphraseTooShort)
+	})
+	t.Run("Empty passphrase", func(t *testing.T) {
+		t.Parallel()
+		secret, _ := crypto.NewSecret(0)
+		strength := Strength{Score: ScoreVeryUnguessable}
+
+		err := Policy{}.Check(secret, strength)
+		assert.ErrorIs(t, err, ErrPassphraseTooShort)
+	})
+	t.Run("ErrPassphraseTooWeak error", func(t *testing.T) {
+		t.Parallel()
+		secret, _ := crypto.NewSecretFrom([]byte("2024"))
+		strength := Strength{Score: ScoreTooGuessable, Feedback: feedback}
+		expErr := &PolicyViolation{
+			Err:      ErrPassphraseTooWeak,
+			Feedback: feedback,
+		}
+
+		err := policy.Check(secret, strength)
+		assert.Equal(t, expErr, err)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		secret, _ := crypto.NewSecretFrom([]byte("+DF7Rc-X/MOYjkNj"))
+		strength := Strength{Score: ScoreSafelyUnguessable}
+
+		err := policy.Check(secret, strength)
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_PolicyError_Kind(t *testing.T) {
+	t.Parallel()
+	t.Run("KindPolicy value", func(t *testing.T) {
+		t.Parallel()
+		errs := []PolicyError{
+			ErrPassphraseTooShort,
+			ErrPassphraseTooWeak,
+			ErrPassphraseReused,
+		}
+		const expKind = failure.KindPolicy
+
+		for _, err := range errs {
+			kind := err.Kind()
+			assert.Equal(t, expKind, kind, err.Error())
+		}
+	})
+	t.Run("KindInvalid value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrInvalidPolicy
+		const expKind = failure.KindInvalid
+
+		kind := err.Kind()
+		assert.Equal(t, expKind, kind)
+	})
+	t.Run("Unknown value", func(t *testing.T) {
+		t.Parallel()
+		const err = PolicyError(613724)
+		const expKind = failure.KindInternal
+
+		kind := err.Kind()
+		assert.Equal(t, expKind, kind)
+	})
+}
@@ -0,0 +1,161 @@
+package passphrase_test
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/reshifr/secure-env/core/passphrase"
+	pimpl "github.com/reshifr/secure-env/core/passphrase/impl"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	fakePinentry       = "fake-pinentry"
+	fakePinentryCancel = "fake-pinentry-cancel"
+	fakeAskpass        = "fake-askpass"
+	fakeAskpassCancel  = "fake-askpass-cancel"
+	fakePassphrase     = "100% +DF7Rc-X/MOYjkNj"
+)
+
+func TestMain(m *testing.M) {
+	switch filepath.Base(os.Args[0]) {
+	case fakePinentry:
+		os.Exit(runPinentry(false))
+	case fakePinentryCancel:
+		os.Exit(runPinentry(true))
+	case fakeAskpass:
+		fmt.Println(fakePassphrase)
+		os.Exit(0)
+	case fakeAskpassCancel:
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+func runPinentry(cancel bool) int {
+	fmt.Println("OK Pleased to meet you")
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		cmd, _, _ := strings.Cut(scanner.Text(), " ")
+		switch cmd {
+		case "GETPIN":
+			if cancel {
+				fmt.Println("ERR 83886179 Operation cancelled <Pinentry>")
+				continue
+			}
+			fmt.Println("D " + strings.ReplaceAll(fakePassphrase, "%", "%25"))
+			fmt.Println("OK")
+		case "BYE":
+			fmt.Println("OK closing connection")
+			return 0
+		default:
+			fmt.Println("OK")
+		}
+	}
+	return 0
+}
+
+func fakeProgram(t *testing.T, name string) string {
+	exe, err := os.Executable()
+	assert.ErrorIs(t, err, nil)
+	path := filepath.Join(t.TempDir(), name)
+	assert.ErrorIs(t, os.Symlink(exe, path), nil)
+	return path
+}
+
+func lookupEnv(vars map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := vars[key]
+		return value, ok
+	}
+}
+
+func Test_Pinentry_Passphrase(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrPassphraseCanceled error", func(t *testing.T) {
+		t.Parallel()
+		provider := pimpl.NewPinentry(pimpl.FnPinentry{
+			LookupEnv: lookupEnv(map[string]string{
+				passphrase.PinentryEnv: fakeProgram(t, fakePinentryCancel),
+			}),
+		})
+		var expSecret *crypto.Secret = nil
+		const expErr = passphrase.ErrPassphraseCanceled
+
+		secret, err := provider.Passphrase("Vault passphrase")
+		assert.Equal(t, expSecret, secret)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		provider := pimpl.NewPinentry(pimpl.FnPinentry{
+			LookupEnv: lookupEnv(map[string]string{
+				passphrase.PinentryEnv: fakeProgram(t, fakePinentry),
+			}),
+		})
+
+		secret, err := provider.Passphrase("Vault passphrase\nfor prod")
+		assert.Equal(t, []byte(fakePassphrase), secret.Bytes())
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_Askpass_Passphrase(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrPassphraseCanceled error", func(t *testing.T) {
+		t.Parallel()
+		provider := pimpl.NewAskpass(pimpl.FnAskpass{
+			LookupEnv: lookupEnv(map[string]string{
+				passphrase.AskpassEnv: fakeProgram(t, fakeAskpassCancel),
+			}),
+		})
+		var expSecret *crypto.Secret = nil
+		const expErr = passphrase.ErrPassphraseCanceled
+
+		secret, err := provider.Passphrase("Vault passphrase")
+		assert.Equal(t, expSecret, secret)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		provider := pimpl.NewAskpass(pimpl.FnAskpass{
+			LookupEnv: lookupEnv(map[string]string{
+				passphrase.AskpassEnv: fakeProgram(t, fakeAskpass),
+			}),
+		})
+
+		secret, err := provider.Passphrase("Vault passphrase")
+		assert.Equal(t, []byte(fakePassphrase), secret.Bytes())
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_Chain_Passphrase(t *testing.T) {
+	t.Parallel()
+	vars := map[string]string{
+		passphrase.PinentryEnv: fakeProgram(t, fakePinentry),
+	}
+	providers := map[string]passphrase.Provider{
+		passphrase.ProviderFD: pimpl.NewFD(-1),
+		passphrase.ProviderEnv: pimpl.NewEnv(pimpl.FnEnv{
+			LookupEnv: lookupEnv(vars),
+			Unsetenv:  os.Unsetenv,
+		}, passphrase.PassphraseEnv),
+		passphrase.ProviderAskpass: pimpl.NewAskpass(
+			pimpl.FnAskpass{LookupEnv: lookupEnv(vars)}),
+		passphrase.ProviderPinentry: pimpl.NewPinentry(
+			pimpl.FnPinentry{LookupEnv: lookupEnv(vars)}),
+		passphrase.ProviderTTY: pimpl.NewTTY(filepath.Join(t.TempDir(), "tty")),
+	}
+
+	chain, err := pimpl.NewChainFromOrder(passphrase.DefaultOrder, providers)
+	assert.ErrorIs(t, err, nil)
+	secret, err := chain.Passphrase("Vault passphrase")
+	assert.Equal(t, []byte(fakePassphrase), secret.Bytes())
+	assert.ErrorIs(t, err, nil)
+}
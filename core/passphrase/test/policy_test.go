@@ -0,0 +1,45 @@
+package passphrase_test
+
+import (
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	cimpl "github.com/reshifr/secure-env/core/crypto/impl"
+	"github.com/reshifr/secure-env/core/passphrase"
+	pimpl "github.com/reshifr/secure-env/core/passphrase/impl"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PolicyAuthorizer(t *testing.T) {
+	t.Parallel()
+	rng := cimpl.NewStdRNG(cimpl.FnStdRNG{Read: rand.Read})
+	cipher := cimpl.ChaChaPoly{}
+	authorizer := pimpl.NewPolicyAuthorizer(
+		cimpl.NewRoleAuthorizer(cimpl.Argon{}, rng, cipher),
+		pimpl.NewEstimator(pimpl.FnEstimator{Now: time.Now}),
+		passphrase.DefaultPolicy(),
+	)
+	rawIV, _ := rng.Block(cimpl.IV96Len)
+	iv, _ := cimpl.LoadIV96(rawIV)
+
+	weak, _ := crypto.NewSecretFrom([]byte("Password2024"))
+	err := authorizer.Enforce(weak)
+	assert.ErrorIs(t, err, passphrase.ErrPassphraseTooWeak)
+
+	strong, _ := crypto.NewSecretFrom([]byte("+DF7Rc-X/MOYjkNj"))
+	accessKey, block, err := authorizer.Make(iv, strong, cipher.KeyLen())
+	assert.ErrorIs(t, err, nil)
+
+	reused, _ := crypto.NewSecretFrom([]byte("+DF7Rc-X/MOYjkNj"))
+	_, _, err = authorizer.Inherit(
+		iv, authorizer.KDFParams(), strong, reused, block)
+	assert.ErrorIs(t, err, passphrase.ErrPassphraseReused)
+
+	child, _ := crypto.NewSecretFrom([]byte("q7!Lw2#zR9@pXe4v"))
+	childAccessKey, _, err := authorizer.Inherit(
+		iv, authorizer.KDFParams(), strong, child, block)
+	assert.Equal(t, accessKey.Bytes(), childAccessKey.Bytes())
+	assert.ErrorIs(t, err, nil)
+}
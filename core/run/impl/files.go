@@ -0,0 +1,97 @@
+package run_impl
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/reshifr/secure-env/core/failure"
+	"github.com/reshifr/secure-env/core/run"
+)
+
+type Files struct {
+	dir   string
+	paths map[string]string
+}
+
+type FnFiles struct {
+	LookupEnv func(key string) (value string, ok bool)
+}
+
+// BaseDirs lists the directories tried for file secrets, in order. An
+// explicit SENV_FILES_DIR is the only candidate. Otherwise only memory-backed
+// directories are used, except on macOS, which has none: there the per-user
+// 0700 $TMPDIR is the fallback.
+func BaseDirs(fn FnFiles) []string {
+	if dir, ok := fn.LookupEnv(run.FilesDirEnv); ok && dir != "" {
+		return []string{dir}
+	}
+	dirs := []string{}
+	if dir, ok := fn.LookupEnv(run.RuntimeDirEnv); ok && dir != "" {
+		dirs = append(dirs, dir)
+	}
+	return append(dirs, fallbackDirs(fn)...)
+}
+
+func Materialize(fn FnFiles, files []run.File) (*Files, error) {
+	materialized := &Files{paths: map[string]string{}}
+	var err error
+	for _, base := range BaseDirs(fn) {
+		materialized.dir, err = os.MkdirTemp(base, run.FilesDirPattern)
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, failure.New(
+			run.OpMaterialize, run.ErrCreateFilesDirFailed, err)
+	}
+	for _, file := range files {
+		path := filepath.Join(materialized.dir, filepath.Base(file.Name))
+		if err := write(path, file); err != nil {
+			materialized.Remove()
+			return nil, err
+		}
+		materialized.paths[file.Name] = path
+	}
+	return materialized, nil
+}
+
+func write(path string, file run.File) error {
+	r, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	out, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return failure.New(run.OpMaterialize, run.ErrWriteFileFailed, err)
+	}
+	_, err = io.Copy(out, r)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+	return nil
+}
+
+func (files *Files) Dir() string {
+	return files.dir
+}
+
+func (files *Files) Path(name string) string {
+	return files.paths[name]
+}
+
+func (files *Files) Remove() error {
+	for _, path := range files.paths {
+		if info, err := os.Stat(path); err == nil {
+			os.WriteFile(path, make([]byte, info.Size()), 0600)
+		}
+	}
+	files.paths = map[string]string{}
+	return os.RemoveAll(files.dir)
+}
@@ -0,0 +1,12 @@
+package run_impl
+
+import (
+	"github.com/reshifr/secure-env/core/run"
+)
+
+func fallbackDirs(fn FnFiles) []string {
+	if dir, ok := fn.LookupEnv(run.TempDirEnv); ok && dir != "" {
+		return []string{dir}
+	}
+	return []string{}
+}
@@ -0,0 +1,11 @@
+//go:build !darwin
+
+package run_impl
+
+import (
+	"github.com/reshifr/secure-env/core/run"
+)
+
+func fallbackDirs(fn FnFiles) []string {
+	return []string{run.ShmDir}
+}
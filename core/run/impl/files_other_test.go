@@ -0,0 +1,21 @@
+//go:build !darwin
+
+package run_impl
+
+import (
+	"testing"
+
+	"github.com/reshifr/secure-env/core/run"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_fallbackDirs(t *testing.T) {
+	t.Parallel()
+	fn := FnFiles{LookupEnv: lookupEnv(map[string]string{
+		run.TempDirEnv: "/tmp",
+	})}
+	expDirs := []string{run.ShmDir}
+
+	dirs := fallbackDirs(fn)
+	assert.Equal(t, expDirs, dirs)
+}
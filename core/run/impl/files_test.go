@@ -0,0 +1,138 @@
+package run_impl
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/reshifr/secure-env/core/run"
+	"github.com/stretchr/testify/assert"
+)
+
+func lookupEnv(vars map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := vars[key]
+		return value, ok
+	}
+}
+
+func file(name string, content string) run.File {
+	return run.File{
+		Name: name,
+		Open: func() (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader(content)), nil
+		},
+	}
+}
+
+func Test_BaseDirs(t *testing.T) {
+	t.Parallel()
+	t.Run("Files directory", func(t *testing.T) {
+		t.Parallel()
+		fn := FnFiles{LookupEnv: lookupEnv(map[string]string{
+			run.FilesDirEnv:   "/Volumes/secrets",
+			run.RuntimeDirEnv: "/run/user/1000",
+		})}
+		expDirs := []string{"/Volumes/secrets"}
+
+		dirs := BaseDirs(fn)
+		assert.Equal(t, expDirs, dirs)
+	})
+	t.Run("Runtime directory", func(t *testing.T) {
+		t.Parallel()
+		fn := FnFiles{LookupEnv: lookupEnv(map[string]string{
+			run.RuntimeDirEnv: "/run/user/1000",
+		})}
+		expDirs := append([]string{"/run/user/1000"}, fallbackDirs(fn)...)
+
+		dirs := BaseDirs(fn)
+		assert.Equal(t, expDirs, dirs)
+	})
+	t.Run("No runtime directory", func(t *testing.T) {
+		t.Parallel()
+		fn := FnFiles{LookupEnv: lookupEnv(nil)}
+		expDirs := fallbackDirs(fn)
+
+		dirs := BaseDirs(fn)
+		assert.Equal(t, expDirs, dirs)
+	})
+}
+
+func Test_Materialize(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrAuthFailed error", func(t *testing.T) {
+		t.Parallel()
+		base := t.TempDir()
+		fn := FnFiles{LookupEnv: lookupEnv(map[string]string{
+			run.RuntimeDirEnv: base,
+		})}
+		files := []run.File{
+			file("CA_BUNDLE", "ca"),
+			{
+				Name: "CREDENTIALS",
+				Open: func() (io.ReadCloser, error) {
+					return nil, crypto.ErrAuthFailed
+				},
+			},
+		}
+		var expFiles *Files = nil
+		const expErr = crypto.ErrAuthFailed
+
+		materialized, err := Materialize(fn, files)
+		assert.Equal(t, expFiles, materialized)
+		assert.ErrorIs(t, err, expErr)
+		entries, _ := os.ReadDir(base)
+		assert.Empty(t, entries)
+	})
+	t.Run("Fallback directory", func(t *testing.T) {
+		t.Parallel()
+		fn := FnFiles{LookupEnv: lookupEnv(map[string]string{
+			run.RuntimeDirEnv: filepath.Join(t.TempDir(), "missing"),
+		})}
+
+		materialized, err := Materialize(fn, nil)
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, run.ShmDir, filepath.Dir(materialized.Dir()))
+		materialized.Remove()
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		base := t.TempDir()
+		fn := FnFiles{LookupEnv: lookupEnv(map[string]string{
+			run.RuntimeDirEnv: base,
+		})}
+		files := []run.File{file("CREDENTIALS", `{"type":"service_account"}`)}
+
+		materialized, err := Materialize(fn, files)
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, base, filepath.Dir(materialized.Dir()))
+		info, _ := os.Stat(materialized.Dir())
+		assert.Equal(t, os.FileMode(0700), info.Mode().Perm())
+
+		path := materialized.Path("CREDENTIALS")
+		buf, _ := os.ReadFile(path)
+		info, _ = os.Stat(path)
+		assert.Equal(t, `{"type":"service_account"}`, string(buf))
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+		assert.Equal(t, "", materialized.Path("OTHER"))
+	})
+}
+
+func Test_Files_Remove(t *testing.T) {
+	t.Parallel()
+	fn := FnFiles{LookupEnv: lookupEnv(map[string]string{
+		run.RuntimeDirEnv: t.TempDir(),
+	})}
+	materialized, _ := Materialize(fn, []run.File{file("CREDENTIALS", "key")})
+	dir := materialized.Dir()
+
+	err := materialized.Remove()
+	assert.ErrorIs(t, err, nil)
+	_, err = os.Stat(dir)
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+	assert.Equal(t, "", materialized.Path("CREDENTIALS"))
+}
@@ -0,0 +1,221 @@
+package run_impl
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+
+	"github.com/reshifr/secure-env/core/env"
+	"github.com/reshifr/secure-env/core/run"
+)
+
+type node struct {
+	next  map[byte]int
+	fail  int
+	depth int
+	match int
+}
+
+type Matcher struct {
+	nodes []node
+	masks [][]byte
+	lens  []int
+}
+
+type interval struct {
+	start int
+	end   int
+	match int
+}
+
+type Redactor struct {
+	matcher   *Matcher
+	w         io.Writer
+	state     int
+	pos       int
+	base      int
+	pending   []byte
+	intervals []interval
+	closed    bool
+}
+
+func Variants(value string) []string {
+	raw := []byte(value)
+	return []string{
+		value,
+		base64.StdEncoding.EncodeToString(raw),
+		base64.RawStdEncoding.EncodeToString(raw),
+		base64.URLEncoding.EncodeToString(raw),
+		base64.RawURLEncoding.EncodeToString(raw),
+		url.QueryEscape(value),
+		url.PathEscape(value),
+	}
+}
+
+func NewMatcher(vars []env.Var) *Matcher {
+	matcher := &Matcher{nodes: []node{{next: map[byte]int{}, match: -1}}}
+	for _, variable := range vars {
+		if len(variable.Value) < run.RedactMinLen {
+			continue
+		}
+		mask := []byte(fmt.Sprintf(run.RedactMask, variable.Name))
+		for _, variant := range Variants(variable.Value) {
+			matcher.insert(variant, mask)
+		}
+	}
+	matcher.build()
+	return matcher
+}
+
+func (matcher *Matcher) insert(pattern string, mask []byte) {
+	state := 0
+	for i := 0; i < len(pattern); i++ {
+		next, ok := matcher.nodes[state].next[pattern[i]]
+		if !ok {
+			next = len(matcher.nodes)
+			matcher.nodes = append(matcher.nodes, node{
+				next:  map[byte]int{},
+				depth: matcher.nodes[state].depth + 1,
+				match: -1,
+			})
+			matcher.nodes[state].next[pattern[i]] = next
+		}
+		state = next
+	}
+	if matcher.nodes[state].match < 0 {
+		matcher.nodes[state].match = len(matcher.masks)
+		matcher.masks = append(matcher.masks, mask)
+		matcher.lens = append(matcher.lens, len(pattern))
+	}
+}
+
+func (matcher *Matcher) step(state int, c byte) int {
+	for {
+		if next, ok := matcher.nodes[state].next[c]; ok {
+			return next
+		}
+		if state == 0 {
+			return 0
+		}
+		state = matcher.nodes[state].fail
+	}
+}
+
+func (matcher *Matcher) build() {
+	queue := []int{}
+	for _, next := range matcher.nodes[0].next {
+		queue = append(queue, next)
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		current := matcher.nodes[state]
+		if current.match < 0 {
+			matcher.nodes[state].match = matcher.nodes[current.fail].match
+		}
+		for c, next := range current.next {
+			matcher.nodes[next].fail = matcher.step(current.fail, c)
+			queue = append(queue, next)
+		}
+	}
+}
+
+func (matcher *Matcher) Redact(w io.Writer) *Redactor {
+	return &Redactor{matcher: matcher, w: w}
+}
+
+func (redactor *Redactor) add(match interval) {
+	intervals := append(redactor.intervals, match)
+	sort.Slice(intervals, func(i, j int) bool {
+		return intervals[i].start < intervals[j].start
+	})
+	merged := intervals[:1]
+	for _, next := range intervals[1:] {
+		last := &merged[len(merged)-1]
+		if next.start >= last.end {
+			merged = append(merged, next)
+			continue
+		}
+		if next.end-next.start > last.end-last.start {
+			last.match = next.match
+		}
+		last.end = max(last.end, next.end)
+	}
+	redactor.intervals = merged
+}
+
+func (redactor *Redactor) slice(start int, end int) []byte {
+	return redactor.pending[start-redactor.base : end-redactor.base]
+}
+
+func (redactor *Redactor) flush(out *bytes.Buffer, limit int) {
+	for _, match := range redactor.intervals {
+		if match.start < limit && match.end > limit {
+			limit = match.start
+		}
+	}
+	i := 0
+	offset := redactor.base
+	for ; i < len(redactor.intervals); i++ {
+		match := redactor.intervals[i]
+		if match.start >= limit {
+			break
+		}
+		out.Write(redactor.slice(offset, match.start))
+		out.Write(redactor.matcher.masks[match.match])
+		offset = match.end
+	}
+	if offset < limit {
+		out.Write(redactor.slice(offset, limit))
+		offset = limit
+	}
+	redactor.intervals = redactor.intervals[i:]
+	redactor.pending = append(
+		redactor.pending[:0], redactor.slice(offset, redactor.pos)...)
+	redactor.base = offset
+}
+
+func (redactor *Redactor) Write(p []byte) (int, error) {
+	if redactor.closed {
+		return 0, run.ErrRedactorClosed
+	}
+	matcher := redactor.matcher
+	redactor.pending = append(redactor.pending, p...)
+	for _, c := range p {
+		redactor.state = matcher.step(redactor.state, c)
+		redactor.pos++
+		if match := matcher.nodes[redactor.state].match; match >= 0 {
+			redactor.add(interval{
+				start: redactor.pos - matcher.lens[match],
+				end:   redactor.pos,
+				match: match,
+			})
+		}
+	}
+	out := &bytes.Buffer{}
+	redactor.flush(out,
+		redactor.pos-matcher.nodes[redactor.state].depth)
+	if out.Len() > 0 {
+		if _, err := redactor.w.Write(out.Bytes()); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (redactor *Redactor) Close() error {
+	if redactor.closed {
+		return run.ErrRedactorClosed
+	}
+	redactor.closed = true
+	out := &bytes.Buffer{}
+	redactor.flush(out, redactor.pos)
+	if out.Len() == 0 {
+		return nil
+	}
+	_, err := redactor.w.Write(out.Bytes())
+	return err
+}
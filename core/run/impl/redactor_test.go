@@ -0,0 +1,128 @@
+package run_impl
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/reshifr/secure-env/core/env"
+	"github.com/reshifr/secure-env/core/run"
+	"github.com/stretchr/testify/assert"
+)
+
+type errWriter struct{}
+
+func (errWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("closed pipe")
+}
+
+func redact(vars []env.Var, chunks ...string) string {
+	out := &bytes.Buffer{}
+	redactor := NewMatcher(vars).Redact(out)
+	for _, chunk := range chunks {
+		redactor.Write([]byte(chunk))
+	}
+	redactor.Close()
+	return out.String()
+}
+
+func Test_Variants(t *testing.T) {
+	t.Parallel()
+	expVariants := []string{
+		"a+b/c d?",
+		"YStiL2MgZD8=",
+		"YStiL2MgZD8",
+		"YStiL2MgZD8=",
+		"YStiL2MgZD8",
+		"a%2Bb%2Fc+d%3F",
+		"a+b%2Fc%20d%3F",
+	}
+
+	variants := Variants("a+b/c d?")
+	assert.Equal(t, expVariants, variants)
+}
+
+func Test_Redactor_Write(t *testing.T) {
+	t.Parallel()
+	vars := []env.Var{
+		{Name: "DB_PASS", Value: "s3cr3t+pass"},
+		{Name: "API_KEY", Value: "sk-123456"},
+		{Name: "API_KEY_ID", Value: "sk-123456789"},
+		{Name: "DEBUG", Value: "1"},
+	}
+
+	t.Run("Plain value", func(t *testing.T) {
+		t.Parallel()
+		const expOut = "password=***DB_PASS*** debug=1\n"
+
+		out := redact(vars, "password=s3cr3t+pass debug=1\n")
+		assert.Equal(t, expOut, out)
+	})
+	t.Run("Encoded values", func(t *testing.T) {
+		t.Parallel()
+		const expOut = "b64=***DB_PASS*** url=***DB_PASS*** " +
+			"path=***DB_PASS***\n"
+
+		out := redact(vars,
+			"b64=czNjcjN0K3Bhc3M= url=s3cr3t%2Bpass path=s3cr3t+pass\n")
+		assert.Equal(t, expOut, out)
+	})
+	t.Run("Split across writes", func(t *testing.T) {
+		t.Parallel()
+		const input = "token sk-123456 and s3cr3t+pass end"
+		const expOut = "token ***API_KEY*** and ***DB_PASS*** end"
+		chunks := []string{}
+		for i := 0; i < len(input); i++ {
+			chunks = append(chunks, input[i:i+1])
+		}
+
+		out := redact(vars, chunks...)
+		assert.Equal(t, expOut, out)
+	})
+	t.Run("Longest match", func(t *testing.T) {
+		t.Parallel()
+		const expOut = "id=***API_KEY_ID*** key=***API_KEY***7"
+
+		out := redact(vars, "id=sk-1234", "56789 key=sk-1234567")
+		assert.Equal(t, expOut, out)
+	})
+	t.Run("Overlapping values", func(t *testing.T) {
+		t.Parallel()
+		vars := []env.Var{
+			{Name: "A", Value: "abcdef"},
+			{Name: "B", Value: "defghi"},
+		}
+		const expOut = "x***A***y"
+
+		out := redact(vars, "xabcd", "efghiy")
+		assert.Equal(t, expOut, out)
+	})
+	t.Run("Pending prefix", func(t *testing.T) {
+		t.Parallel()
+		const expOut = "tail sk-12345"
+
+		out := redact(vars, "tail sk-1", "2345")
+		assert.Equal(t, expOut, out)
+	})
+	t.Run("ErrRedactorClosed error", func(t *testing.T) {
+		t.Parallel()
+		redactor := NewMatcher(vars).Redact(&bytes.Buffer{})
+		redactor.Close()
+		const expN = 0
+		const expErr = run.ErrRedactorClosed
+
+		n, err := redactor.Write([]byte("s3cr3t+pass"))
+		assert.Equal(t, expN, n)
+		assert.ErrorIs(t, err, expErr)
+		assert.ErrorIs(t, redactor.Close(), expErr)
+	})
+	t.Run("Writer error", func(t *testing.T) {
+		t.Parallel()
+		redactor := NewMatcher(vars).Redact(errWriter{})
+		const expN = 0
+
+		n, err := redactor.Write([]byte("hello\n"))
+		assert.Equal(t, expN, n)
+		assert.EqualError(t, err, "closed pipe")
+	})
+}
@@ -0,0 +1,74 @@
+package run_impl
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+
+	"github.com/reshifr/secure-env/core/failure"
+	"github.com/reshifr/secure-env/core/run"
+)
+
+var (
+	RunSignals = []os.Signal{
+		syscall.SIGINT,
+		syscall.SIGTERM,
+		syscall.SIGHUP,
+		syscall.SIGQUIT,
+	}
+)
+
+type Signals chan os.Signal
+
+// Notify starts catching RunSignals. Call it before materializing file
+// secrets, so a signal that arrives first lets Run clean them up instead of
+// killing senv and leaving them behind.
+func Notify() Signals {
+	signals := make(Signals, 1)
+	signal.Notify(signals, RunSignals...)
+	return signals
+}
+
+func (signals Signals) Stop() {
+	signal.Stop(signals)
+}
+
+func Run(cmd *exec.Cmd, signals Signals, cleanup func()) (int, error) {
+	defer cleanup()
+	select {
+	case sig := <-signals:
+		if sig, ok := sig.(syscall.Signal); ok {
+			return 128 + int(sig), nil
+		}
+	default:
+	}
+	if err := cmd.Start(); err != nil {
+		return run.ExitStartFailed,
+			failure.New(run.OpStart, run.ErrStartFailed, err)
+	}
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				cmd.Process.Signal(sig)
+			case <-done:
+				return
+			}
+		}
+	}()
+	err := cmd.Wait()
+	close(done)
+	exitErr := &exec.ExitError{}
+	if err != nil && !errors.As(err, &exitErr) {
+		return run.ExitWaitFailed,
+			failure.New(run.OpWait, run.ErrWaitFailed, err)
+	}
+	status, ok := cmd.ProcessState.Sys().(syscall.WaitStatus)
+	if ok && status.Signaled() {
+		return 128 + int(status.Signal()), nil
+	}
+	return cmd.ProcessState.ExitCode(), nil
+}
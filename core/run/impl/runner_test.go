@@ -0,0 +1,68 @@
+package run_impl
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/reshifr/secure-env/core/run"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, os.ErrClosed
+}
+
+func Test_Run(t *testing.T) {
+	t.Run("ErrStartFailed error", func(t *testing.T) {
+		cleaned := false
+		cmd := exec.Command(filepath.Join(t.TempDir(), "missing"))
+		const expCode = run.ExitStartFailed
+		const expErr = run.ErrStartFailed
+
+		code, err := Run(cmd, make(Signals, 1), func() { cleaned = true })
+		assert.Equal(t, expCode, code)
+		assert.ErrorIs(t, err, expErr)
+		assert.True(t, cleaned)
+	})
+	t.Run("Pending signal", func(t *testing.T) {
+		cleaned := false
+		started := filepath.Join(t.TempDir(), "started")
+		cmd := exec.Command("sh", "-c", `touch "$0"`, started)
+		signals := make(Signals, 1)
+		signals <- syscall.SIGTERM
+		const expCode = 128 + int(syscall.SIGTERM)
+
+		code, err := Run(cmd, signals, func() { cleaned = true })
+		assert.Equal(t, expCode, code)
+		assert.ErrorIs(t, err, nil)
+		assert.True(t, cleaned)
+		assert.NoFileExists(t, started)
+	})
+	t.Run("Exit code", func(t *testing.T) {
+		cleaned := false
+		cmd := exec.Command("sh", "-c", "exit 3")
+		const expCode = 3
+
+		code, err := Run(cmd, make(Signals, 1), func() { cleaned = true })
+		assert.Equal(t, expCode, code)
+		assert.ErrorIs(t, err, nil)
+		assert.True(t, cleaned)
+	})
+	t.Run("ErrWaitFailed error", func(t *testing.T) {
+		cleaned := false
+		cmd := exec.Command("sh", "-c", "echo output")
+		cmd.Stdout = failingWriter{}
+		const expCode = run.ExitWaitFailed
+		const expErr = run.ErrWaitFailed
+
+		code, err := Run(cmd, make(Signals, 1), func() { cleaned = true })
+		assert.Equal(t, expCode, code)
+		assert.ErrorIs(t, err, expErr)
+		assert.True(t, cleaned)
+	})
+}
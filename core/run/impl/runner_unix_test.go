@@ -0,0 +1,39 @@
+//go:build unix
+
+package run_impl
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Run_Signal(t *testing.T) {
+	t.Run("Forwarded signal", func(t *testing.T) {
+		cleaned := false
+		ready := filepath.Join(t.TempDir(), "ready")
+		cmd := exec.Command("sh", "-c", `touch "$0"; exec sleep 10`, ready)
+		const expCode = 128 + int(syscall.SIGTERM)
+
+		go func() {
+			for {
+				if _, err := os.Stat(ready); err == nil {
+					syscall.Kill(os.Getpid(), syscall.SIGTERM)
+					return
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+		}()
+		signals := Notify()
+		defer signals.Stop()
+		code, err := Run(cmd, signals, func() { cleaned = true })
+		assert.Equal(t, expCode, code)
+		assert.ErrorIs(t, err, nil)
+		assert.True(t, cleaned)
+	})
+}
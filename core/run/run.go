@@ -0,0 +1,69 @@
+package run
+
+import (
+	"io"
+
+	"github.com/reshifr/secure-env/core/failure"
+)
+
+const (
+	FilesDirEnv     = "SENV_FILES_DIR"
+	RuntimeDirEnv   = "XDG_RUNTIME_DIR"
+	TempDirEnv      = "TMPDIR"
+	ShmDir          = "/dev/shm"
+	FilesDirPattern = "senv-*"
+	ExitStartFailed = 127
+	ExitWaitFailed  = 1
+	RedactMinLen    = 4
+	RedactMask      = "***%s***"
+)
+
+const (
+	OpMaterialize = "materialize"
+	OpStart       = "start"
+	OpWait        = "wait"
+)
+
+type RunError int
+
+const (
+	ErrCreateFilesDirFailed RunError = iota + 1
+	ErrWriteFileFailed
+	ErrStartFailed
+	ErrRedactorClosed
+	ErrWaitFailed
+)
+
+func (err RunError) Error() string {
+	switch err {
+	case ErrCreateFilesDirFailed:
+		return "ErrCreateFilesDirFailed: " +
+			"failed to create a private directory for file secrets; " +
+			"set " + FilesDirEnv + " to a private directory."
+	case ErrWriteFileFailed:
+		return "ErrWriteFileFailed: failed to write a file secret."
+	case ErrStartFailed:
+		return "ErrStartFailed: failed to start the command."
+	case ErrRedactorClosed:
+		return "ErrRedactorClosed: the redactor is already closed."
+	case ErrWaitFailed:
+		return "ErrWaitFailed: failed to wait for the command."
+	default:
+		return "Error: unknown."
+	}
+}
+
+func (err RunError) Kind() failure.Kind {
+	switch err {
+	case ErrCreateFilesDirFailed, ErrWriteFileFailed,
+		ErrStartFailed, ErrWaitFailed:
+		return failure.KindIO
+	default:
+		return failure.KindInternal
+	}
+}
+
+type File struct {
+	Name string
+	Open func() (r io.ReadCloser, err error)
+}
@@ -0,0 +1,97 @@
+package run
+
+import (
+	"testing"
+
+	"github.com/reshifr/secure-env/core/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RunError_Error(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrCreateFilesDirFailed value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrCreateFilesDirFailed
+		const expMsg = "ErrCreateFilesDirFailed: " +
+			"failed to create a private directory for file secrets; " +
+			"set SENV_FILES_DIR to a private directory."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrWriteFileFailed value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrWriteFileFailed
+		const expMsg = "ErrWriteFileFailed: failed to write a file secret."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrStartFailed value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrStartFailed
+		const expMsg = "ErrStartFailed: failed to start the command."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrRedactorClosed value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrRedactorClosed
+		const expMsg = "ErrRedactorClosed: the redactor is already closed."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrWaitFailed value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrWaitFailed
+		const expMsg = "ErrWaitFailed: failed to wait for the command."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("Unknown value", func(t *testing.T) {
+		t.Parallel()
+		const err = RunError(613724)
+		const expMsg = "Error: unknown."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+}
+
+func Test_RunError_Kind(t *testing.T) {
+	t.Parallel()
+	t.Run("KindInternal value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrRedactorClosed
+		const expKind = failure.KindInternal
+
+		kind := err.Kind()
+		assert.Equal(t, expKind, kind)
+	})
+	t.Run("KindIO value", func(t *testing.T) {
+		t.Parallel()
+		errs := []RunError{
+			ErrCreateFilesDirFailed,
+			ErrWriteFileFailed,
+			ErrStartFailed,
+			ErrWaitFailed,
+		}
+		const expKind = failure.KindIO
+
+		for _, err := range errs {
+			kind := err.Kind()
+			assert.Equal(t, expKind, kind, err.Error())
+		}
+	})
+	t.Run("Unknown value", func(t *testing.T) {
+		t.Parallel()
+		const err = RunError(613724)
+		const expKind = failure.KindInternal
+
+		kind := err.Kind()
+		assert.Equal(t, expKind, kind)
+	})
+}
@@ -0,0 +1,117 @@
+package run_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	cimpl "github.com/reshifr/secure-env/core/crypto/impl"
+	"github.com/reshifr/secure-env/core/env"
+	eimpl "github.com/reshifr/secure-env/core/env/impl"
+	"github.com/reshifr/secure-env/core/run"
+	rimpl "github.com/reshifr/secure-env/core/run/impl"
+	"github.com/reshifr/secure-env/core/vault"
+	vimpl "github.com/reshifr/secure-env/core/vault/impl"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	interpolator = eimpl.NewInterpolator(eimpl.FnInterpolator{})
+)
+
+func Test_Run(t *testing.T) {
+	rng := cimpl.NewStdRNG(cimpl.FnStdRNG{Read: rand.Read})
+	cipher := cimpl.ChaChaPoly{}
+	stream, _ := cimpl.NewStream(cipher, cimpl.StreamChunkLen)
+	authorizer := cimpl.NewRoleAuthorizer(cimpl.Argon{}, rng, cipher)
+	keeper := vimpl.NewKeeper(vimpl.FnKeeper{Now: time.Now},
+		authorizer, cipher, stream, cimpl.NewHKDF([]byte("secure-env")))
+	rawIV, _ := rng.Block(cimpl.IV96Len)
+	iv, _ := cimpl.LoadIV96(rawIV)
+
+	passphrase, _ := crypto.NewSecretFrom([]byte("+DF7Rc-X/MOYjkNj"))
+	content := `{"type":"service_account"}`
+	v := &vault.Vault{}
+	keyring, err := keeper.CreateEnv(iv, v, "prod", "", "admin", passphrase)
+	assert.ErrorIs(t, err, nil)
+	err = keeper.SetFile(iv, v, keyring, "prod", "admin",
+		"GCP_CREDENTIALS", strings.NewReader(content))
+	assert.ErrorIs(t, err, nil)
+
+	vars, err := keeper.Resolve(v, keyring, "prod", interpolator)
+	assert.ErrorIs(t, err, nil)
+	files := []run.File{}
+	for _, variable := range vars {
+		if variable.Kind != vault.EntryKindFile {
+			continue
+		}
+		name, origin := variable.Name, variable.Origin
+		files = append(files, run.File{
+			Name: name,
+			Open: func() (io.ReadCloser, error) {
+				return keeper.OpenFile(v, keyring, origin, name)
+			},
+		})
+	}
+
+	base := t.TempDir()
+	fn := rimpl.FnFiles{LookupEnv: func(key string) (string, bool) {
+		if key == run.RuntimeDirEnv {
+			return base, true
+		}
+		return "", false
+	}}
+	signals := rimpl.Notify()
+	defer signals.Stop()
+	materialized, err := rimpl.Materialize(fn, files)
+	assert.ErrorIs(t, err, nil)
+	path := materialized.Path("GCP_CREDENTIALS")
+
+	out := filepath.Join(t.TempDir(), "out")
+	cmd := exec.Command("sh", "-c", `cat "$GCP_CREDENTIALS" > "$0"`, out)
+	cmd.Env = append(os.Environ(), "GCP_CREDENTIALS="+path)
+	code, err := rimpl.Run(cmd, signals, func() { materialized.Remove() })
+	assert.Equal(t, 0, code)
+	assert.ErrorIs(t, err, nil)
+
+	buf, _ := os.ReadFile(out)
+	assert.Equal(t, content, string(buf))
+	_, err = os.Stat(path)
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+}
+
+func Test_Run_Redact(t *testing.T) {
+	vars := []env.Var{
+		{Name: "DB_PASS", Value: "s3cr3t+pass"},
+		{Name: "API_KEY", Value: "sk-123456789"},
+	}
+	matcher := rimpl.NewMatcher(vars)
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	redactedStdout := matcher.Redact(stdout)
+	redactedStderr := matcher.Redact(stderr)
+
+	cmd := exec.Command("sh", "-c",
+		`printf 'db=%s\n' "$DB_PASS"; printf '%s' "$DB_PASS" | base64; `+
+			`printf 'key=%s' "$API_KEY" >&2`)
+	cmd.Env = append(os.Environ(),
+		"DB_PASS="+vars[0].Value, "API_KEY="+vars[1].Value)
+	cmd.Stdout = redactedStdout
+	cmd.Stderr = redactedStderr
+	code, err := rimpl.Run(cmd, make(rimpl.Signals, 1), func() {
+		redactedStdout.Close()
+		redactedStderr.Close()
+	})
+	assert.Equal(t, 0, code)
+	assert.ErrorIs(t, err, nil)
+	assert.Equal(t, "db=***DB_PASS***\n***DB_PASS***\n", stdout.String())
+	assert.Equal(t, "key=***API_KEY***", stderr.String())
+}
@@ -0,0 +1,431 @@
+package sops_impl
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/reshifr/secure-env/core/env"
+	"github.com/reshifr/secure-env/core/sops"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	FormatDotenv = "dotenv"
+	FormatYAML   = "yaml"
+)
+
+const (
+	SOPSMetadataKey    = "sops"
+	SOPSDotenvPrefix   = "sops_"
+	SOPSDataKeyLen     = 32
+	SOPSAgeArmorBegin  = "-----BEGIN AGE ENCRYPTED FILE-----"
+	SOPSAgeArmorEnd    = "-----END AGE ENCRYPTED FILE-----"
+	SOPSTypeStr        = "str"
+	SOPSTypeInt        = "int"
+	SOPSTypeFloat      = "float"
+	SOPSTypeBool       = "bool"
+	SOPSTypeBytes      = "bytes"
+	SOPSTypeComment    = "comment"
+	sopsPathSeparator  = ":"
+	sopsNameSeparator  = "_"
+	sopsDotenvAgeKey   = "age__list_"
+	sopsDotenvMapField = "__map_"
+)
+
+var (
+	sopsEncPattern = regexp.MustCompile(
+		`^ENC\[AES256_GCM,data:(.+),iv:(.+),tag:(.+),type:(.+)\]$`)
+)
+
+type SOPS[Age crypto.Age] struct {
+	age    Age
+	format string
+}
+
+type sopsAgeKey struct {
+	Recipient string `yaml:"recipient"`
+	Enc       string `yaml:"enc"`
+}
+
+type sopsMetadata struct {
+	Age              []sopsAgeKey `yaml:"age"`
+	KeyGroups        []yaml.Node  `yaml:"key_groups"`
+	LastModified     string       `yaml:"lastmodified"`
+	MAC              string       `yaml:"mac"`
+	MACOnlyEncrypted bool         `yaml:"mac_only_encrypted"`
+}
+
+type sopsLeaf struct {
+	name  string
+	path  string
+	value string
+	kind  string
+}
+
+func NewSOPS[Age crypto.Age](age Age, format string) (SOPS[Age], error) {
+	switch format {
+	case FormatDotenv, FormatYAML:
+		return SOPS[Age]{age: age, format: format}, nil
+	default:
+		return SOPS[Age]{}, env.ErrUnknownFormat
+	}
+}
+
+func sopsDecrypt(key *crypto.Secret,
+	value string, path string) ([]byte, string, error) {
+	match := sopsEncPattern.FindStringSubmatch(value)
+	if match == nil {
+		return nil, "", sops.ErrInvalidSOPSFile
+	}
+	fields := make([][]byte, 3)
+	for i := range fields {
+		field, err := base64.StdEncoding.DecodeString(match[i+1])
+		if err != nil {
+			return nil, "", sops.ErrInvalidSOPSFile
+		}
+		fields[i] = field
+	}
+	data, iv, tag := fields[0], fields[1], fields[2]
+	block, err := aes.NewCipher(key.Bytes())
+	if err != nil {
+		return nil, "", sops.ErrInvalidSOPSFile
+	}
+	if len(iv) == 0 {
+		return nil, "", sops.ErrInvalidSOPSFile
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	if err != nil {
+		return nil, "", sops.ErrInvalidSOPSFile
+	}
+	plaintext, err := gcm.Open(nil, iv, append(data, tag...), []byte(path))
+	if err != nil {
+		return nil, "", crypto.ErrAuthFailed
+	}
+	kind := match[4]
+	switch kind {
+	case SOPSTypeStr, SOPSTypeBytes:
+	case SOPSTypeInt:
+		_, err = strconv.Atoi(string(plaintext))
+	case SOPSTypeFloat:
+		_, err = strconv.ParseFloat(string(plaintext), 64)
+	case SOPSTypeBool:
+		_, err = strconv.ParseBool(string(plaintext))
+	case SOPSTypeComment:
+		return nil, "", sops.ErrUnsupportedSOPSFile
+	default:
+		return nil, "", sops.ErrInvalidSOPSFile
+	}
+	if err != nil {
+		return nil, "", sops.ErrInvalidSOPSFile
+	}
+	return plaintext, kind, nil
+}
+
+func sopsAgeArmor(enc string) ([]byte, error) {
+	body, ok := strings.CutPrefix(strings.TrimSpace(enc), SOPSAgeArmorBegin)
+	if !ok {
+		return nil, sops.ErrInvalidSOPSFile
+	}
+	body, ok = strings.CutSuffix(body, SOPSAgeArmorEnd)
+	if !ok {
+		return nil, sops.ErrInvalidSOPSFile
+	}
+	buf, err := base64.StdEncoding.DecodeString(
+		strings.Join(strings.Fields(body), ""))
+	if err != nil {
+		return nil, sops.ErrInvalidSOPSFile
+	}
+	return buf, nil
+}
+
+func sopsScalar(node *yaml.Node) (string, string, error) {
+	switch node.ShortTag() {
+	case "!!str":
+		return node.Value, SOPSTypeStr, nil
+	case "!!int":
+		var value int
+		if err := node.Decode(&value); err != nil {
+			return "", "", sops.ErrUnsupportedSOPSFile
+		}
+		return strconv.Itoa(value), SOPSTypeInt, nil
+	case "!!float":
+		var value float64
+		if err := node.Decode(&value); err != nil {
+			return "", "", sops.ErrUnsupportedSOPSFile
+		}
+		return strconv.FormatFloat(value, 'f', -1, 64), SOPSTypeFloat, nil
+	case "!!bool":
+		var value bool
+		if err := node.Decode(&value); err != nil {
+			return "", "", sops.ErrUnsupportedSOPSFile
+		}
+		if value {
+			return "True", SOPSTypeBool, nil
+		}
+		return "False", SOPSTypeBool, nil
+	default:
+		return "", "", sops.ErrUnsupportedSOPSFile
+	}
+}
+
+func sopsYAMLWalk(node *yaml.Node,
+	name string, path string, leaves []sopsLeaf) ([]sopsLeaf, error) {
+	if node.HeadComment != "" || node.LineComment != "" ||
+		node.FootComment != "" {
+		return nil, sops.ErrUnsupportedSOPSFile
+	}
+	switch node.Kind {
+	case yaml.AliasNode:
+		// SOPS never writes anchors, and expanding them can blow up.
+		return nil, sops.ErrUnsupportedSOPSFile
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			if key.Kind != yaml.ScalarNode ||
+				key.HeadComment != "" || key.LineComment != "" ||
+				key.FootComment != "" {
+				return nil, sops.ErrUnsupportedSOPSFile
+			}
+			var err error
+			leaves, err = sopsYAMLWalk(node.Content[i+1],
+				name+sopsNameSeparator+key.Value,
+				path+key.Value+sopsPathSeparator, leaves)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return leaves, nil
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			var err error
+			leaves, err = sopsYAMLWalk(item,
+				name+sopsNameSeparator+strconv.Itoa(i), path, leaves)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return leaves, nil
+	case yaml.ScalarNode:
+		value, kind, err := sopsScalar(node)
+		if err != nil {
+			return nil, err
+		}
+		return append(leaves, sopsLeaf{
+			name:  name[len(sopsNameSeparator):],
+			path:  path,
+			value: value,
+			kind:  kind,
+		}), nil
+	default:
+		return nil, sops.ErrInvalidSOPSFile
+	}
+}
+
+func parseSOPSYAML(buf []byte) ([]sopsLeaf, sopsMetadata, error) {
+	doc := yaml.Node{}
+	if err := yaml.Unmarshal(buf, &doc); err != nil {
+		return nil, sopsMetadata{}, sops.ErrInvalidSOPSFile
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) != 1 ||
+		doc.Content[0].Kind != yaml.MappingNode {
+		return nil, sopsMetadata{}, sops.ErrInvalidSOPSFile
+	}
+	tree := doc.Content[0]
+	data := &yaml.Node{Kind: yaml.MappingNode}
+	var metadata *yaml.Node
+	for i := 0; i+1 < len(tree.Content); i += 2 {
+		if tree.Content[i].Value == SOPSMetadataKey {
+			metadata = tree.Content[i+1]
+			continue
+		}
+		data.Content = append(data.Content, tree.Content[i], tree.Content[i+1])
+	}
+	if metadata == nil {
+		return nil, sopsMetadata{}, sops.ErrInvalidSOPSFile
+	}
+	meta := sopsMetadata{}
+	if err := metadata.Decode(&meta); err != nil {
+		return nil, sopsMetadata{}, sops.ErrInvalidSOPSFile
+	}
+	leaves, err := sopsYAMLWalk(data, "", "", []sopsLeaf{})
+	if err != nil {
+		return nil, sopsMetadata{}, err
+	}
+	return leaves, meta, nil
+}
+
+func sopsDotenvMetadata(meta *sopsMetadata, key string, value string) error {
+	switch {
+	case key == "lastmodified":
+		meta.LastModified = value
+	case key == "mac":
+		meta.MAC = value
+	case key == "mac_only_encrypted":
+		meta.MACOnlyEncrypted = value == "true"
+	case strings.HasPrefix(key, "key_groups"):
+		meta.KeyGroups = append(meta.KeyGroups, yaml.Node{})
+	case strings.HasPrefix(key, sopsDotenvAgeKey):
+		index, field, ok := strings.Cut(
+			key[len(sopsDotenvAgeKey):], sopsDotenvMapField)
+		i, err := strconv.Atoi(index)
+		if !ok || err != nil || i < 0 || i > len(meta.Age) {
+			return sops.ErrInvalidSOPSFile
+		}
+		if i == len(meta.Age) {
+			meta.Age = append(meta.Age, sopsAgeKey{})
+		}
+		switch field {
+		case "recipient":
+			meta.Age[i].Recipient = value
+		case "enc":
+			meta.Age[i].Enc = value
+		}
+	}
+	return nil
+}
+
+func parseSOPSDotenv(buf []byte) ([]sopsLeaf, sopsMetadata, error) {
+	leaves := []sopsLeaf{}
+	meta := sopsMetadata{}
+	found := false
+	for _, line := range strings.Split(string(buf), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			return nil, sopsMetadata{}, sops.ErrUnsupportedSOPSFile
+		}
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, sopsMetadata{}, sops.ErrInvalidSOPSFile
+		}
+		value = strings.ReplaceAll(value, "\\n", "\n")
+		if key, ok := strings.CutPrefix(name, SOPSDotenvPrefix); ok {
+			found = true
+			if err := sopsDotenvMetadata(&meta, key, value); err != nil {
+				return nil, sopsMetadata{}, err
+			}
+			continue
+		}
+		leaves = append(leaves, sopsLeaf{
+			name:  name,
+			path:  name + sopsPathSeparator,
+			value: value,
+			kind:  SOPSTypeStr,
+		})
+	}
+	if !found {
+		return nil, sopsMetadata{}, sops.ErrInvalidSOPSFile
+	}
+	return leaves, meta, nil
+}
+
+func (decoder SOPS[Age]) dataKey(keys []sopsAgeKey,
+	identities []*crypto.Secret) (*crypto.Secret, error) {
+	var skipped error = crypto.ErrNoAgeIdentity
+	for _, key := range keys {
+		buf, err := sopsAgeArmor(key.Enc)
+		if err != nil {
+			skipped = err
+			continue
+		}
+		r, err := decoder.age.Decrypt(identities, nil, bytes.NewReader(buf))
+		if errors.Is(err, crypto.ErrNoAgeIdentity) {
+			continue
+		}
+		if err != nil {
+			skipped = err
+			continue
+		}
+		dataKey, err := crypto.NewSecret(SOPSDataKeyLen)
+		if err != nil {
+			r.Close()
+			return nil, err
+		}
+		_, err = io.ReadFull(r, dataKey.Bytes())
+		if err == nil {
+			if n, _ := r.Read(make([]byte, 1)); n != 0 {
+				err = sops.ErrInvalidSOPSFile
+			}
+		}
+		r.Close()
+		if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+			err = sops.ErrInvalidSOPSFile
+		}
+		if err != nil {
+			dataKey.Destroy()
+			return nil, err
+		}
+		return dataKey, nil
+	}
+	return nil, skipped
+}
+
+func (decoder SOPS[Age]) Decode(buf []byte,
+	identities []*crypto.Secret) ([]env.Var, error) {
+	parse := parseSOPSDotenv
+	if decoder.format == FormatYAML {
+		parse = parseSOPSYAML
+	}
+	leaves, meta, err := parse(buf)
+	if err != nil {
+		return nil, err
+	}
+	if len(meta.KeyGroups) != 0 || len(meta.Age) == 0 {
+		return nil, sops.ErrUnsupportedSOPSFile
+	}
+	lastModified, err := time.Parse(time.RFC3339, meta.LastModified)
+	if err != nil {
+		return nil, sops.ErrInvalidSOPSFile
+	}
+	dataKey, err := decoder.dataKey(meta.Age, identities)
+	if err != nil {
+		return nil, err
+	}
+	defer dataKey.Destroy()
+	hash := sha512.New()
+	vars := make([]env.Var, 0, len(leaves))
+	for _, leaf := range leaves {
+		value, kind := []byte(leaf.value), leaf.kind
+		encrypted := strings.HasPrefix(leaf.value, "ENC[")
+		if encrypted {
+			value, kind, err = sopsDecrypt(dataKey, leaf.value, leaf.path)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if encrypted || !meta.MACOnlyEncrypted {
+			hash.Write(value)
+		}
+		if kind == SOPSTypeBool {
+			b, _ := strconv.ParseBool(string(value))
+			value = []byte(strconv.FormatBool(b))
+		}
+		vars = append(vars, env.Var{Name: leaf.name, Value: string(value)})
+	}
+	mac, _, err := sopsDecrypt(dataKey,
+		meta.MAC, lastModified.Format(time.RFC3339))
+	if err == crypto.ErrAuthFailed {
+		return nil, sops.ErrSOPSMACMismatch
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(mac, []byte(fmt.Sprintf("%X", hash.Sum(nil)))) {
+		return nil, sops.ErrSOPSMACMismatch
+	}
+	return vars, nil
+}
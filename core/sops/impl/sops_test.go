@@ -0,0 +1,451 @@
+package sops_impl
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"strings"
+	"testing"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	cimpl "github.com/reshifr/secure-env/core/crypto/impl"
+	"github.com/reshifr/secure-env/core/env"
+	"github.com/reshifr/secure-env/core/sops"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	sopsLastModified = "2024-05-01T10:20:30Z"
+)
+
+type sopsFixture struct {
+	t        *testing.T
+	age      cimpl.Age[cimpl.StdRNG]
+	key      []byte
+	identity *crypto.Secret
+	hash     hash.Hash
+}
+
+func newSOPSFixture(t *testing.T) *sopsFixture {
+	rng := cimpl.NewStdRNG(cimpl.FnStdRNG{Read: rand.Read})
+	age, _ := cimpl.NewAge(rng, 10)
+	key, _ := rng.Block(SOPSDataKeyLen)
+	rawIdentity, _ := rng.Block(cimpl.HybridX25519Len)
+	identity, _ := crypto.NewSecretFrom(rawIdentity)
+	return &sopsFixture{
+		t:        t,
+		age:      age,
+		key:      key,
+		identity: identity,
+		hash:     sha512.New(),
+	}
+}
+
+func (fixture *sopsFixture) seal(
+	plaintext string, path string, kind string) string {
+	iv := make([]byte, 32)
+	rand.Read(iv)
+	block, _ := aes.NewCipher(fixture.key)
+	gcm, _ := cipher.NewGCMWithNonceSize(block, len(iv))
+	sealed := gcm.Seal(nil, iv, []byte(plaintext), []byte(path))
+	data, tag := sealed[:len(plaintext)], sealed[len(plaintext):]
+	return fmt.Sprintf("ENC[AES256_GCM,data:%s,iv:%s,tag:%s,type:%s]",
+		base64.StdEncoding.EncodeToString(data),
+		base64.StdEncoding.EncodeToString(iv),
+		base64.StdEncoding.EncodeToString(tag), kind)
+}
+
+func (fixture *sopsFixture) enc(
+	plaintext string, path string, kind string) string {
+	fixture.hash.Write([]byte(plaintext))
+	return fixture.seal(plaintext, path, kind)
+}
+
+func (fixture *sopsFixture) plain(plaintext string) string {
+	fixture.hash.Write([]byte(plaintext))
+	return plaintext
+}
+
+func (fixture *sopsFixture) mac() string {
+	sum := fmt.Sprintf("%X", fixture.hash.Sum(nil))
+	return fixture.seal(sum, sopsLastModified, SOPSTypeStr)
+}
+
+func (fixture *sopsFixture) armor() string {
+	recipient, _ := cimpl.AgeRecipient(fixture.identity)
+	buf := &bytes.Buffer{}
+	w, err := fixture.age.Encrypt([][]byte{recipient}, buf)
+	assert.ErrorIs(fixture.t, err, nil)
+	w.Write(fixture.key)
+	w.Close()
+	encoded := base64.StdEncoding.EncodeToString(buf.Bytes())
+	armor := &strings.Builder{}
+	armor.WriteString(SOPSAgeArmorBegin + "\n")
+	for len(encoded) > 64 {
+		armor.WriteString(encoded[:64] + "\n")
+		encoded = encoded[64:]
+	}
+	armor.WriteString(encoded + "\n" + SOPSAgeArmorEnd + "\n")
+	return armor.String()
+}
+
+func (fixture *sopsFixture) dotenv(lines ...string) []byte {
+	armor := strings.ReplaceAll(fixture.armor(), "\n", "\\n")
+	lines = append(lines,
+		"sops_age__list_0__map_enc="+armor,
+		"sops_age__list_0__map_recipient=age1",
+		"sops_lastmodified="+sopsLastModified,
+		"sops_mac="+fixture.mac(),
+		"sops_unencrypted_suffix=_unencrypted",
+		"sops_version=3.8.1",
+	)
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
+
+func (fixture *sopsFixture) yaml(data string) []byte {
+	armor := strings.ReplaceAll(fixture.armor(), "\n", "\n            ")
+	return []byte(data + "sops:\n" +
+		"    age:\n" +
+		"        - recipient: age1\n" +
+		"          enc: |\n" +
+		"            " + strings.TrimSpace(armor) + "\n" +
+		"    lastmodified: \"" + sopsLastModified + "\"\n" +
+		"    mac: " + fixture.mac() + "\n" +
+		"    unencrypted_suffix: _unencrypted\n" +
+		"    version: 3.8.1\n")
+}
+
+func Test_NewSOPS(t *testing.T) {
+	t.Parallel()
+	age, _ := cimpl.NewAge(cimpl.NewStdRNG(cimpl.FnStdRNG{}), 10)
+
+	t.Run("ErrUnknownFormat error", func(t *testing.T) {
+		t.Parallel()
+		expDecoder := SOPS[cimpl.Age[cimpl.StdRNG]]{}
+		const expErr = env.ErrUnknownFormat
+
+		decoder, err := NewSOPS(age, "json")
+		assert.Equal(t, expDecoder, decoder)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		expDecoder := SOPS[cimpl.Age[cimpl.StdRNG]]{
+			age:    age,
+			format: FormatYAML,
+		}
+
+		decoder, err := NewSOPS(age, FormatYAML)
+		assert.Equal(t, expDecoder, decoder)
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_SOPS_Decode_Dotenv(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ErrInvalidSOPSFile error", func(t *testing.T) {
+		t.Parallel()
+		fixture := newSOPSFixture(t)
+		decoder, _ := NewSOPS(fixture.age, FormatDotenv)
+		var expVars []env.Var = nil
+		const expErr = sops.ErrInvalidSOPSFile
+
+		vars, err := decoder.Decode([]byte("DB_PASS=s3cr3t\n"),
+			[]*crypto.Secret{fixture.identity})
+		assert.Equal(t, expVars, vars)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrInvalidSOPSFile value", func(t *testing.T) {
+		t.Parallel()
+		fixture := newSOPSFixture(t)
+		decoder, _ := NewSOPS(fixture.age, FormatDotenv)
+		buf := fixture.dotenv(
+			"DB_PASS="+fixture.enc("s3cr3t", "DB_PASS:", SOPSTypeStr),
+			"DB_USER")
+		var expVars []env.Var = nil
+		const expErr = sops.ErrInvalidSOPSFile
+
+		vars, err := decoder.Decode(buf, []*crypto.Secret{fixture.identity})
+		assert.Equal(t, expVars, vars)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrUnsupportedSOPSFile error", func(t *testing.T) {
+		t.Parallel()
+		fixture := newSOPSFixture(t)
+		decoder, _ := NewSOPS(fixture.age, FormatDotenv)
+		buf := fixture.dotenv(
+			"#ENC[AES256_GCM,data:AA==,iv:AA==,tag:AA==,type:comment]",
+			"DB_PASS="+fixture.enc("s3cr3t", "DB_PASS:", SOPSTypeStr))
+		var expVars []env.Var = nil
+		const expErr = sops.ErrUnsupportedSOPSFile
+
+		vars, err := decoder.Decode(buf, []*crypto.Secret{fixture.identity})
+		assert.Equal(t, expVars, vars)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrUnsupportedSOPSFile value", func(t *testing.T) {
+		t.Parallel()
+		fixture := newSOPSFixture(t)
+		decoder, _ := NewSOPS(fixture.age, FormatDotenv)
+		buf := fixture.dotenv(
+			"DB_PASS="+fixture.enc("s3cr3t", "DB_PASS:", SOPSTypeStr),
+			"sops_key_groups__list_0__map_age__list_0__map_enc=")
+		var expVars []env.Var = nil
+		const expErr = sops.ErrUnsupportedSOPSFile
+
+		vars, err := decoder.Decode(buf, []*crypto.Secret{fixture.identity})
+		assert.Equal(t, expVars, vars)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrNoAgeIdentity error", func(t *testing.T) {
+		t.Parallel()
+		fixture := newSOPSFixture(t)
+		other := newSOPSFixture(t)
+		decoder, _ := NewSOPS(fixture.age, FormatDotenv)
+		buf := fixture.dotenv(
+			"DB_PASS=" + fixture.enc("s3cr3t", "DB_PASS:", SOPSTypeStr))
+		var expVars []env.Var = nil
+		const expErr = crypto.ErrNoAgeIdentity
+
+		vars, err := decoder.Decode(buf, []*crypto.Secret{other.identity})
+		assert.Equal(t, expVars, vars)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrAuthFailed error", func(t *testing.T) {
+		t.Parallel()
+		fixture := newSOPSFixture(t)
+		decoder, _ := NewSOPS(fixture.age, FormatDotenv)
+		buf := fixture.dotenv(
+			"DB_PASS=" + fixture.enc("s3cr3t", "DB_USER:", SOPSTypeStr))
+		var expVars []env.Var = nil
+		const expErr = crypto.ErrAuthFailed
+
+		vars, err := decoder.Decode(buf, []*crypto.Secret{fixture.identity})
+		assert.Equal(t, expVars, vars)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrSOPSMACMismatch error", func(t *testing.T) {
+		t.Parallel()
+		fixture := newSOPSFixture(t)
+		decoder, _ := NewSOPS(fixture.age, FormatDotenv)
+		fixture.plain("db.local")
+		buf := fixture.dotenv(
+			"DB_HOST_unencrypted=db.attacker",
+			"DB_PASS="+fixture.enc("s3cr3t", "DB_PASS:", SOPSTypeStr))
+		var expVars []env.Var = nil
+		const expErr = sops.ErrSOPSMACMismatch
+
+		vars, err := decoder.Decode(buf, []*crypto.Secret{fixture.identity})
+		assert.Equal(t, expVars, vars)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrSOPSMACMismatch value", func(t *testing.T) {
+		t.Parallel()
+		fixture := newSOPSFixture(t)
+		decoder, _ := NewSOPS(fixture.age, FormatDotenv)
+		buf := fixture.dotenv(
+			"DB_PASS=" + fixture.enc("s3cr3t", "DB_PASS:", SOPSTypeStr))
+		buf = bytes.Replace(buf, []byte(sopsLastModified),
+			[]byte("2024-05-01T10:20:31Z"), 1)
+		var expVars []env.Var = nil
+		const expErr = sops.ErrSOPSMACMismatch
+
+		vars, err := decoder.Decode(buf, []*crypto.Secret{fixture.identity})
+		assert.Equal(t, expVars, vars)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		fixture := newSOPSFixture(t)
+		decoder, _ := NewSOPS(fixture.age, FormatDotenv)
+		buf := fixture.dotenv(
+			"DB_HOST_unencrypted="+fixture.plain("db.local"),
+			"DB_PASS="+fixture.enc("s3cr3t", "DB_PASS:", SOPSTypeStr),
+			"",
+			"TLS_KEY="+fixture.enc("line1\nline2", "TLS_KEY:", SOPSTypeStr),
+			"EMPTY="+fixture.plain(""))
+		identities := []*crypto.Secret{
+			newSOPSFixture(t).identity,
+			fixture.identity,
+		}
+		expVars := []env.Var{
+			{Name: "DB_HOST_unencrypted", Value: "db.local"},
+			{Name: "DB_PASS", Value: "s3cr3t"},
+			{Name: "TLS_KEY", Value: "line1\nline2"},
+			{Name: "EMPTY", Value: ""},
+		}
+
+		vars, err := decoder.Decode(buf, identities)
+		assert.Equal(t, expVars, vars)
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_SOPS_Decode_YAML(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ErrInvalidSOPSFile error", func(t *testing.T) {
+		t.Parallel()
+		fixture := newSOPSFixture(t)
+		decoder, _ := NewSOPS(fixture.age, FormatYAML)
+		var expVars []env.Var = nil
+		const expErr = sops.ErrInvalidSOPSFile
+
+		vars, err := decoder.Decode([]byte("db_pass: [\n"),
+			[]*crypto.Secret{fixture.identity})
+		assert.Equal(t, expVars, vars)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrInvalidSOPSFile value", func(t *testing.T) {
+		t.Parallel()
+		fixture := newSOPSFixture(t)
+		decoder, _ := NewSOPS(fixture.age, FormatYAML)
+		var expVars []env.Var = nil
+		const expErr = sops.ErrInvalidSOPSFile
+
+		vars, err := decoder.Decode([]byte("db_pass: s3cr3t\n"),
+			[]*crypto.Secret{fixture.identity})
+		assert.Equal(t, expVars, vars)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrUnsupportedSOPSFile error", func(t *testing.T) {
+		t.Parallel()
+		fixture := newSOPSFixture(t)
+		decoder, _ := NewSOPS(fixture.age, FormatYAML)
+		buf := fixture.yaml("# primary database\n" +
+			"db_pass: " + fixture.enc("s3cr3t", "db_pass:", SOPSTypeStr) + "\n")
+		var expVars []env.Var = nil
+		const expErr = sops.ErrUnsupportedSOPSFile
+
+		vars, err := decoder.Decode(buf, []*crypto.Secret{fixture.identity})
+		assert.Equal(t, expVars, vars)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrUnsupportedSOPSFile value", func(t *testing.T) {
+		t.Parallel()
+		fixture := newSOPSFixture(t)
+		decoder, _ := NewSOPS(fixture.age, FormatYAML)
+		buf := fixture.yaml("db_pass: null\n")
+		var expVars []env.Var = nil
+		const expErr = sops.ErrUnsupportedSOPSFile
+
+		vars, err := decoder.Decode(buf, []*crypto.Secret{fixture.identity})
+		assert.Equal(t, expVars, vars)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrUnsupportedSOPSFile alias", func(t *testing.T) {
+		t.Parallel()
+		fixture := newSOPSFixture(t)
+		decoder, _ := NewSOPS(fixture.age, FormatYAML)
+		buf := fixture.yaml("a: &a [x, x]\n" +
+			"b: &b [*a, *a]\n" +
+			"c: [*b, *b]\n")
+		var expVars []env.Var = nil
+		const expErr = sops.ErrUnsupportedSOPSFile
+
+		vars, err := decoder.Decode(buf, []*crypto.Secret{fixture.identity})
+		assert.Equal(t, expVars, vars)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrSOPSMACMismatch error", func(t *testing.T) {
+		t.Parallel()
+		fixture := newSOPSFixture(t)
+		decoder, _ := NewSOPS(fixture.age, FormatYAML)
+		fixture.plain("5432")
+		buf := fixture.yaml("port_unencrypted: 6543\n")
+		var expVars []env.Var = nil
+		const expErr = sops.ErrSOPSMACMismatch
+
+		vars, err := decoder.Decode(buf, []*crypto.Secret{fixture.identity})
+		assert.Equal(t, expVars, vars)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		fixture := newSOPSFixture(t)
+		decoder, _ := NewSOPS(fixture.age, FormatYAML)
+		buf := fixture.yaml(
+			"db:\n" +
+				"    pass: " +
+				fixture.enc("s3cr3t", "db:pass:", SOPSTypeStr) + "\n" +
+				"    port: " +
+				fixture.enc("5432", "db:port:", SOPSTypeInt) + "\n" +
+				"    port_unencrypted: " + fixture.plain("5432") + "\n" +
+				"debug: " + fixture.enc("True", "debug:", SOPSTypeBool) + "\n" +
+				"ratio_unencrypted: 1.50\n" +
+				"hosts:\n" +
+				"    - " + fixture.enc("a.local", "hosts:", SOPSTypeStr) + "\n" +
+				"    - " + fixture.enc("b.local", "hosts:", SOPSTypeStr) + "\n")
+		fixture.hash.Reset()
+		fixture.plain("s3cr3t" + "5432" + "5432" + "True" + "1.5" +
+			"a.local" + "b.local")
+		buf = fixture.yaml(string(buf[:bytes.Index(buf, []byte("sops:"))]))
+		expVars := []env.Var{
+			{Name: "db_pass", Value: "s3cr3t"},
+			{Name: "db_port", Value: "5432"},
+			{Name: "db_port_unencrypted", Value: "5432"},
+			{Name: "debug", Value: "true"},
+			{Name: "ratio_unencrypted", Value: "1.5"},
+			{Name: "hosts_0", Value: "a.local"},
+			{Name: "hosts_1", Value: "b.local"},
+		}
+
+		vars, err := decoder.Decode(buf, []*crypto.Secret{fixture.identity})
+		assert.Equal(t, expVars, vars)
+		assert.ErrorIs(t, err, nil)
+	})
+	t.Run("ErrInvalidSOPSFile armor", func(t *testing.T) {
+		t.Parallel()
+		fixture := newSOPSFixture(t)
+		decoder, _ := NewSOPS(fixture.age, FormatYAML)
+		buf := fixture.yaml(
+			"db_pass: " + fixture.enc("s3cr3t", "db_pass:", SOPSTypeStr) + "\n")
+		buf = bytes.Replace(buf, []byte(SOPSAgeArmorBegin),
+			[]byte("-----BEGIN PGP MESSAGE-----"), 1)
+		var expVars []env.Var = nil
+		const expErr = sops.ErrInvalidSOPSFile
+
+		vars, err := decoder.Decode(buf, []*crypto.Secret{fixture.identity})
+		assert.Equal(t, expVars, vars)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Malformed recipient skipped", func(t *testing.T) {
+		t.Parallel()
+		fixture := newSOPSFixture(t)
+		decoder, _ := NewSOPS(fixture.age, FormatYAML)
+		buf := fixture.yaml(
+			"db_pass: " + fixture.enc("s3cr3t", "db_pass:", SOPSTypeStr) + "\n")
+		malformed := "    age:\n" +
+			"        - recipient: age1\n" +
+			"          enc: not an armor\n"
+		buf = bytes.Replace(buf, []byte("    age:\n"), []byte(malformed), 1)
+		expVars := []env.Var{{Name: "db_pass", Value: "s3cr3t"}}
+
+		vars, err := decoder.Decode(buf, []*crypto.Secret{fixture.identity})
+		assert.Equal(t, expVars, vars)
+		assert.ErrorIs(t, err, nil)
+	})
+	t.Run("MAC only encrypted value", func(t *testing.T) {
+		t.Parallel()
+		fixture := newSOPSFixture(t)
+		decoder, _ := NewSOPS(fixture.age, FormatYAML)
+		data := "db_host_unencrypted: db.local\n" +
+			"db_pass: " + fixture.enc("s3cr3t", "db_pass:", SOPSTypeStr) + "\n"
+		buf := fixture.yaml(data)
+		buf = append(buf, []byte("    mac_only_encrypted: true\n")...)
+		expVars := []env.Var{
+			{Name: "db_host_unencrypted", Value: "db.local"},
+			{Name: "db_pass", Value: "s3cr3t"},
+		}
+
+		vars, err := decoder.Decode(buf, []*crypto.Secret{fixture.identity})
+		assert.Equal(t, expVars, vars)
+		assert.ErrorIs(t, err, nil)
+	})
+}
@@ -0,0 +1,45 @@
+package sops
+
+import (
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/reshifr/secure-env/core/env"
+	"github.com/reshifr/secure-env/core/failure"
+)
+
+type SOPSError int
+
+const (
+	ErrInvalidSOPSFile SOPSError = iota + 1
+	ErrUnsupportedSOPSFile
+	ErrSOPSMACMismatch
+)
+
+func (err SOPSError) Error() string {
+	switch err {
+	case ErrInvalidSOPSFile:
+		return "ErrInvalidSOPSFile: the SOPS file is malformed."
+	case ErrUnsupportedSOPSFile:
+		return "ErrUnsupportedSOPSFile: " +
+			"the SOPS file uses an unsupported feature."
+	case ErrSOPSMACMismatch:
+		return "ErrSOPSMACMismatch: " +
+			"the SOPS MAC does not match the file contents."
+	default:
+		return "Error: unknown."
+	}
+}
+
+func (err SOPSError) Kind() failure.Kind {
+	switch err {
+	case ErrInvalidSOPSFile, ErrUnsupportedSOPSFile:
+		return failure.KindInvalid
+	case ErrSOPSMACMismatch:
+		return failure.KindIntegrity
+	default:
+		return failure.KindInternal
+	}
+}
+
+type Decoder interface {
+	Decode(buf []byte, identities []*crypto.Secret) (vars []env.Var, err error)
+}
@@ -0,0 +1,79 @@
+package sops
+
+import (
+	"testing"
+
+	"github.com/reshifr/secure-env/core/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SOPSError_Error(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrInvalidSOPSFile value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrInvalidSOPSFile
+		const expMsg = "ErrInvalidSOPSFile: the SOPS file is malformed."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrUnsupportedSOPSFile value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrUnsupportedSOPSFile
+		const expMsg = "ErrUnsupportedSOPSFile: " +
+			"the SOPS file uses an unsupported feature."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrSOPSMACMismatch value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrSOPSMACMismatch
+		const expMsg = "ErrSOPSMACMismatch: " +
+			"the SOPS MAC does not match the file contents."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("Unknown value", func(t *testing.T) {
+		t.Parallel()
+		const err = SOPSError(957361)
+		const expMsg = "Error: unknown."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+}
+
+func Test_SOPSError_Kind(t *testing.T) {
+	t.Parallel()
+	t.Run("KindInvalid value", func(t *testing.T) {
+		t.Parallel()
+		errs := []SOPSError{
+			ErrInvalidSOPSFile,
+			ErrUnsupportedSOPSFile,
+		}
+		const expKind = failure.KindInvalid
+
+		for _, err := range errs {
+			kind := err.Kind()
+			assert.Equal(t, expKind, kind, err.Error())
+		}
+	})
+	t.Run("KindIntegrity value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrSOPSMACMismatch
+		const expKind = failure.KindIntegrity
+
+		kind := err.Kind()
+		assert.Equal(t, expKind, kind)
+	})
+	t.Run("Unknown value", func(t *testing.T) {
+		t.Parallel()
+		const err = SOPSError(613724)
+		const expKind = failure.KindInternal
+
+		kind := err.Kind()
+		assert.Equal(t, expKind, kind)
+	})
+}
@@ -0,0 +1,117 @@
+package sops_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	cimpl "github.com/reshifr/secure-env/core/crypto/impl"
+	"github.com/reshifr/secure-env/core/env"
+	eimpl "github.com/reshifr/secure-env/core/env/impl"
+	"github.com/reshifr/secure-env/core/failure"
+	"github.com/reshifr/secure-env/core/sops"
+	simpl "github.com/reshifr/secure-env/core/sops/impl"
+	"github.com/reshifr/secure-env/core/vault"
+	vimpl "github.com/reshifr/secure-env/core/vault/impl"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	dotenvFixture = "testdata/secrets.env"
+	yamlFixture   = "testdata/secrets.yaml"
+	ageIdentity   = "AGE-SECRET-KEY-1755YVEUH6KDX9PZ2U4J54LNZ2JHZSWRD8AR7QV" +
+		"88QVQMHVC46FJS9PMP6V"
+)
+
+var (
+	interpolator = eimpl.NewInterpolator(eimpl.FnInterpolator{})
+)
+
+func Test_SOPS_Import(t *testing.T) {
+	t.Parallel()
+	rng := cimpl.NewStdRNG(cimpl.FnStdRNG{Read: rand.Read})
+	cipher := cimpl.ChaChaPoly{}
+	stream, _ := cimpl.NewStream(cipher, cimpl.StreamChunkLen)
+	authorizer := cimpl.NewRoleAuthorizer(cimpl.Argon{}, rng, cipher)
+	keeper := vimpl.NewKeeper(vimpl.FnKeeper{Now: time.Now},
+		authorizer, cipher, stream, cimpl.NewHKDF([]byte("secure-env")))
+	age, _ := cimpl.NewAge(rng, cimpl.AgeScryptWorkFactor)
+	rawIV, _ := rng.Block(cimpl.IV96Len)
+	iv, _ := cimpl.LoadIV96(rawIV)
+	identities, err := cimpl.ParseAgeIdentities([]byte(ageIdentity))
+	assert.ErrorIs(t, err, nil)
+
+	passphrase, _ := crypto.NewSecretFrom([]byte("+DF7Rc-X/MOYjkNj"))
+	v := &vault.Vault{}
+	keyring, err := keeper.CreateEnv(iv, v, "prod", "", "admin", passphrase)
+	assert.ErrorIs(t, err, nil)
+
+	t.Run("Dotenv value", func(t *testing.T) {
+		buf, _ := os.ReadFile(dotenvFixture)
+		decoder, _ := simpl.NewSOPS(age, simpl.FormatDotenv)
+		expReport := vault.ImportReport{
+			Env:     "prod",
+			Added:   []string{"DB_HOST_unencrypted", "DB_PASS", "API_TOKEN"},
+			Updated: []string{},
+		}
+
+		vars, err := decoder.Decode(buf, identities)
+		assert.ErrorIs(t, err, nil)
+		report, err := keeper.Import(iv, v, keyring, "prod", "admin", vars)
+		assert.Equal(t, expReport, report)
+		assert.ErrorIs(t, err, nil)
+	})
+	t.Run("YAML value", func(t *testing.T) {
+		buf, _ := os.ReadFile(yamlFixture)
+		decoder, _ := simpl.NewSOPS(age, simpl.FormatYAML)
+		expReport := vault.ImportReport{
+			Env:     "prod",
+			Added:   []string{"DB_PORT", "SMTP_PASSWORD"},
+			Updated: []string{"DB_HOST_unencrypted", "DB_PASS"},
+		}
+
+		vars, err := decoder.Decode(buf, identities)
+		assert.ErrorIs(t, err, nil)
+		report, err := keeper.Import(iv, v, keyring, "prod", "admin", vars)
+		assert.Equal(t, expReport, report)
+		assert.ErrorIs(t, err, nil)
+	})
+	t.Run("Resolved value", func(t *testing.T) {
+		expVars := []env.Var{
+			{Name: "DB_HOST_unencrypted", Value: "db.local"},
+			{Name: "DB_PASS", Value: "s3cr3t"},
+			{Name: "API_TOKEN", Value: "tok_9f8e7d6c"},
+			{Name: "DB_PORT", Value: "5432"},
+			{Name: "SMTP_PASSWORD", Value: "m4il"},
+		}
+
+		reopened, err := keeper.Open(v, "prod", "admin", passphrase)
+		assert.ErrorIs(t, err, nil)
+		resolved, err := keeper.Resolve(v, reopened, "prod", interpolator)
+		assert.ErrorIs(t, err, nil)
+		vars := []env.Var{}
+		for _, variable := range resolved {
+			vars = append(vars, variable.Var)
+		}
+		assert.Equal(t, expVars, vars)
+		for _, entry := range v.Envs[0].Entries {
+			assert.NotContains(t, string(entry.Buf), "s3cr3t")
+		}
+	})
+	t.Run("Tampered value", func(t *testing.T) {
+		buf, _ := os.ReadFile(dotenvFixture)
+		buf = bytes.Replace(buf, []byte("db.local"), []byte("db.evil1"), 1)
+		decoder, _ := simpl.NewSOPS(age, simpl.FormatDotenv)
+		var expVars []env.Var = nil
+
+		vars, err := decoder.Decode(buf, identities)
+		assert.Equal(t, expVars, vars)
+		assert.ErrorIs(t, err, sops.ErrSOPSMACMismatch)
+		report := failure.NewReport(err)
+		assert.Equal(t, "ErrSOPSMACMismatch", report.Code)
+		assert.Equal(t, failure.ExitIntegrity, report.Exit)
+	})
+}
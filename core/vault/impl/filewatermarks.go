@@ -0,0 +1,100 @@
+package vault_impl
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/reshifr/secure-env/core/failure"
+	"github.com/reshifr/secure-env/core/vault"
+)
+
+const (
+	WatermarksTempPattern = ".watermarks-*"
+)
+
+type FileWatermarks struct {
+	path string
+}
+
+func NewFileWatermarks(path string) FileWatermarks {
+	return FileWatermarks{path: path}
+}
+
+func (watermarks FileWatermarks) read() (map[string]uint64, error) {
+	marks := map[string]uint64{}
+	buf, err := os.ReadFile(watermarks.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return marks, nil
+	}
+	if err == nil {
+		err = json.Unmarshal(buf, &marks)
+	}
+	if err != nil {
+		return nil, failure.New(
+			vault.OpReadWatermarks, vault.ErrReadWatermarksFailed, err)
+	}
+	return marks, nil
+}
+
+func (watermarks FileWatermarks) Get(id string) (uint64, error) {
+	marks, err := watermarks.read()
+	if err != nil {
+		return 0, err
+	}
+	generation, ok := marks[id]
+	if !ok {
+		return 0, vault.ErrWatermarkNotFound
+	}
+	return generation, nil
+}
+
+func writeFailed(err error) error {
+	return failure.New(
+		vault.OpWriteWatermarks, vault.ErrWriteWatermarksFailed, err)
+}
+
+func (watermarks FileWatermarks) Set(id string, generation uint64) error {
+	dir := filepath.Dir(watermarks.path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return writeFailed(err)
+	}
+	lock, err := os.OpenFile(
+		watermarks.path+".lock", os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return writeFailed(err)
+	}
+	defer lock.Close()
+	if err := lockFile(lock); err != nil {
+		return writeFailed(err)
+	}
+	defer unlockFile(lock)
+	marks, err := watermarks.read()
+	if err != nil {
+		return err
+	}
+	// Another process may have raised the mark since the caller read it.
+	marks[id] = max(marks[id], generation)
+	buf, _ := json.Marshal(marks)
+	tmp, err := os.CreateTemp(dir, WatermarksTempPattern)
+	if err != nil {
+		return writeFailed(err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(buf)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), watermarks.path)
+	}
+	if err != nil {
+		return writeFailed(err)
+	}
+	return nil
+}
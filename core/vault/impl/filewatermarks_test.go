@@ -0,0 +1,108 @@
+package vault_impl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/reshifr/secure-env/core/vault"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewFileWatermarks(t *testing.T) {
+	t.Parallel()
+	expWatermarks := FileWatermarks{path: "watermarks.json"}
+
+	watermarks := NewFileWatermarks("watermarks.json")
+	assert.Equal(t, expWatermarks, watermarks)
+}
+
+func Test_FileWatermarks_Get(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrWatermarkNotFound error", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "watermarks.json")
+		const expErr = vault.ErrWatermarkNotFound
+
+		watermarks := NewFileWatermarks(path)
+		generation, err := watermarks.Get("id")
+		assert.Zero(t, generation)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrReadWatermarksFailed error", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "watermarks.json")
+		os.WriteFile(path, []byte("{"), 0600)
+		const expErr = vault.ErrReadWatermarksFailed
+
+		watermarks := NewFileWatermarks(path)
+		generation, err := watermarks.Get("id")
+		assert.Zero(t, generation)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "watermarks.json")
+		os.WriteFile(path, []byte(`{"id":7}`), 0600)
+		const expGeneration = 7
+
+		watermarks := NewFileWatermarks(path)
+		generation, err := watermarks.Get("id")
+		assert.Equal(t, uint64(expGeneration), generation)
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_FileWatermarks_Set(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrWriteWatermarksFailed error", func(t *testing.T) {
+		t.Parallel()
+		file := filepath.Join(t.TempDir(), "file")
+		os.WriteFile(file, nil, 0600)
+		const expErr = vault.ErrWriteWatermarksFailed
+
+		watermarks := NewFileWatermarks(filepath.Join(file, "watermarks.json"))
+		err := watermarks.Set("id", 7)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrReadWatermarksFailed error", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "watermarks.json")
+		os.WriteFile(path, []byte("{"), 0600)
+		const expErr = vault.ErrReadWatermarksFailed
+
+		watermarks := NewFileWatermarks(path)
+		err := watermarks.Set("id", 7)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "senv", "watermarks.json")
+		const expMarks = `{"a":1,"b":2}`
+
+		watermarks := NewFileWatermarks(path)
+		err := watermarks.Set("a", 1)
+		assert.ErrorIs(t, err, nil)
+		err = watermarks.Set("b", 2)
+		assert.ErrorIs(t, err, nil)
+
+		buf, _ := os.ReadFile(path)
+		info, _ := os.Stat(path)
+		assert.Equal(t, expMarks, string(buf))
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	})
+	t.Run("Lower generation", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "watermarks.json")
+		const expMarks = `{"a":5}`
+
+		watermarks := NewFileWatermarks(path)
+		err := watermarks.Set("a", 5)
+		assert.ErrorIs(t, err, nil)
+		err = watermarks.Set("a", 3)
+		assert.ErrorIs(t, err, nil)
+
+		buf, _ := os.ReadFile(path)
+		assert.Equal(t, expMarks, string(buf))
+	})
+}
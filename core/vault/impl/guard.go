@@ -0,0 +1,75 @@
+package vault_impl
+
+import (
+	"encoding/hex"
+	"errors"
+	"maps"
+	"slices"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/reshifr/secure-env/core/vault"
+)
+
+const (
+	VaultIDLen = 16
+)
+
+type Guard[Watermarks vault.Watermarks] struct {
+	watermarks Watermarks
+}
+
+func NewGuard[Watermarks vault.Watermarks](
+	watermarks Watermarks) Guard[Watermarks] {
+	return Guard[Watermarks]{watermarks: watermarks}
+}
+
+func NewVaultID[RNG crypto.RNG](rng RNG) (string, error) {
+	id, err := rng.Block(VaultIDLen)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
+
+func watermarkID(id string, name string) string {
+	return id + "/" + name
+}
+
+func (guard Guard[Watermarks]) Check(
+	v *vault.Vault,
+	generations map[string]uint64,
+	allowRollback bool) (bool, error) {
+	if v.ID == "" {
+		return false, vault.ErrMissingVaultID
+	}
+	names := slices.Sorted(maps.Keys(generations))
+	updates := map[string]uint64{}
+	rolledBack := false
+	for _, name := range names {
+		id := watermarkID(v.ID, name)
+		generation := generations[name]
+		seen, err := guard.watermarks.Get(id)
+		if errors.Is(err, vault.ErrWatermarkNotFound) {
+			updates[id] = generation
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		if generation < seen {
+			rolledBack = true
+		}
+		if generation > seen {
+			updates[id] = generation
+		}
+	}
+	if rolledBack && !allowRollback {
+		return true, vault.ErrRollback
+	}
+	for _, id := range slices.Sorted(maps.Keys(updates)) {
+		if err := guard.watermarks.Set(id, updates[id]); err != nil {
+			return rolledBack, err
+		}
+	}
+	return rolledBack, nil
+}
@@ -0,0 +1,155 @@
+package vault_impl
+
+import (
+	"testing"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	cmock "github.com/reshifr/secure-env/core/crypto/mock"
+	"github.com/reshifr/secure-env/core/failure"
+	"github.com/reshifr/secure-env/core/vault"
+	vmock "github.com/reshifr/secure-env/core/vault/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewGuard(t *testing.T) {
+	t.Parallel()
+	watermarks := vmock.NewWatermarks(t)
+	expGuard := Guard[*vmock.Watermarks]{watermarks: watermarks}
+
+	guard := NewGuard(watermarks)
+	assert.Equal(t, expGuard, guard)
+}
+
+func Test_NewVaultID(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrReadEntropyFailed error", func(t *testing.T) {
+		t.Parallel()
+		rng := cmock.NewRNG(t)
+		rng.EXPECT().Block(VaultIDLen).
+			Return(nil, crypto.ErrReadEntropyFailed).Once()
+
+		const expID = ""
+		const expErr = crypto.ErrReadEntropyFailed
+
+		id, err := NewVaultID(rng)
+		assert.Equal(t, expID, id)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		rng := cmock.NewRNG(t)
+		rng.EXPECT().Block(VaultIDLen).
+			Return([]byte{
+				0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77,
+				0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff,
+			}, nil).Once()
+
+		const expID = "00112233445566778899aabbccddeeff"
+
+		id, err := NewVaultID(rng)
+		assert.Equal(t, expID, id)
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_Guard_Check(t *testing.T) {
+	t.Parallel()
+	const id = "00112233445566778899aabbccddeeff"
+	const prodID = id + "/prod"
+	const devID = id + "/dev"
+
+	t.Run("ErrMissingVaultID error", func(t *testing.T) {
+		t.Parallel()
+		watermarks := vmock.NewWatermarks(t)
+		v := &vault.Vault{Generation: 3}
+		const expErr = vault.ErrMissingVaultID
+
+		guard := NewGuard(watermarks)
+		rolledBack, err := guard.Check(v, map[string]uint64{"prod": 3}, false)
+		assert.False(t, rolledBack)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrReadWatermarksFailed error", func(t *testing.T) {
+		t.Parallel()
+		watermarks := vmock.NewWatermarks(t)
+		watermarks.EXPECT().Get(prodID).
+			Return(0, vault.ErrReadWatermarksFailed).Once()
+
+		v := &vault.Vault{ID: id}
+		const expErr = vault.ErrReadWatermarksFailed
+
+		guard := NewGuard(watermarks)
+		rolledBack, err := guard.Check(v, map[string]uint64{"prod": 3}, false)
+		assert.False(t, rolledBack)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("First seen", func(t *testing.T) {
+		t.Parallel()
+		watermarks := vmock.NewWatermarks(t)
+		notFound := failure.New(vault.OpReadWatermarks,
+			vault.ErrWatermarkNotFound, nil)
+		watermarks.EXPECT().Get(prodID).Return(0, notFound).Once()
+		watermarks.EXPECT().Set(prodID, uint64(3)).Return(nil).Once()
+
+		v := &vault.Vault{ID: id}
+
+		guard := NewGuard(watermarks)
+		rolledBack, err := guard.Check(v, map[string]uint64{"prod": 3}, false)
+		assert.False(t, rolledBack)
+		assert.ErrorIs(t, err, nil)
+	})
+	t.Run("ErrRollback error", func(t *testing.T) {
+		t.Parallel()
+		watermarks := vmock.NewWatermarks(t)
+		watermarks.EXPECT().Get(devID).Return(1, nil).Once()
+		watermarks.EXPECT().Get(prodID).Return(5, nil).Once()
+
+		v := &vault.Vault{ID: id}
+		generations := map[string]uint64{"dev": 3, "prod": 3}
+		const expErr = vault.ErrRollback
+
+		guard := NewGuard(watermarks)
+		rolledBack, err := guard.Check(v, generations, false)
+		assert.True(t, rolledBack)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Allowed rollback", func(t *testing.T) {
+		t.Parallel()
+		watermarks := vmock.NewWatermarks(t)
+		watermarks.EXPECT().Get(prodID).Return(5, nil).Once()
+
+		v := &vault.Vault{ID: id}
+
+		guard := NewGuard(watermarks)
+		rolledBack, err := guard.Check(v, map[string]uint64{"prod": 3}, true)
+		assert.True(t, rolledBack)
+		assert.ErrorIs(t, err, nil)
+	})
+	t.Run("Newer vault", func(t *testing.T) {
+		t.Parallel()
+		watermarks := vmock.NewWatermarks(t)
+		watermarks.EXPECT().Get(prodID).Return(2, nil).Once()
+		watermarks.EXPECT().Set(prodID, uint64(3)).
+			Return(vault.ErrWriteWatermarksFailed).Once()
+
+		v := &vault.Vault{ID: id}
+		const expErr = vault.ErrWriteWatermarksFailed
+
+		guard := NewGuard(watermarks)
+		rolledBack, err := guard.Check(v, map[string]uint64{"prod": 3}, false)
+		assert.False(t, rolledBack)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Same vault", func(t *testing.T) {
+		t.Parallel()
+		watermarks := vmock.NewWatermarks(t)
+		watermarks.EXPECT().Get(prodID).Return(3, nil).Once()
+
+		v := &vault.Vault{ID: id}
+
+		guard := NewGuard(watermarks)
+		rolledBack, err := guard.Check(v, map[string]uint64{"prod": 3}, false)
+		assert.False(t, rolledBack)
+		assert.ErrorIs(t, err, nil)
+	})
+}
@@ -0,0 +1,1230 @@
+package vault_impl
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/reshifr/secure-env/core/audit"
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/reshifr/secure-env/core/env"
+	"github.com/reshifr/secure-env/core/failure"
+	"github.com/reshifr/secure-env/core/passphrase"
+	"github.com/reshifr/secure-env/core/vault"
+)
+
+type Keeper[
+	Authorizer crypto.Authorizer,
+	Cipher crypto.AE,
+	Stream crypto.Stream,
+	KDF crypto.KDF] struct {
+	fn         FnKeeper
+	authorizer Authorizer
+	cipher     Cipher
+	stream     Stream
+	kdf        KDF
+}
+
+type FnKeeper struct {
+	Now   func() time.Time
+	Audit func(key *crypto.Secret, record audit.Record) error
+	Head  func() (audit.Head, error)
+}
+
+func NewKeeper[
+	Authorizer crypto.Authorizer,
+	Cipher crypto.AE,
+	Stream crypto.Stream,
+	KDF crypto.KDF](
+	fn FnKeeper,
+	authorizer Authorizer,
+	cipher Cipher,
+	stream Stream,
+	kdf KDF) Keeper[Authorizer, Cipher, Stream, KDF] {
+	return Keeper[Authorizer, Cipher, Stream, KDF]{
+		fn:         fn,
+		authorizer: authorizer,
+		cipher:     cipher,
+		stream:     stream,
+		kdf:        kdf,
+	}
+}
+
+func (keeper Keeper[Authorizer, Cipher, Stream, KDF]) dataKey(
+	name string, accessKey *crypto.Secret) (*crypto.Secret, error) {
+	defer accessKey.Destroy()
+	return keeper.kdf.Key(
+		accessKey, []byte(name), keeper.cipher.KeyLen())
+}
+
+func entryAD(name string, varName string) []byte {
+	return []byte(name + "\x00" + varName)
+}
+
+// valueAD also binds the template kind, so a literal value cannot be turned
+// into a template without the env key.
+func valueAD(name string, varName string, kind string) []byte {
+	if kind == vault.EntryKindTemplate {
+		return entryAD(name, varName+"\x00"+kind)
+	}
+	return entryAD(name, varName)
+}
+
+func valueKind(variable env.Var) string {
+	if variable.Interpolate {
+		return vault.EntryKindTemplate
+	}
+	return vault.EntryKindVar
+}
+
+func setValue(e *vault.Env, variable env.Var, buf []byte) {
+	if variable.Interpolate {
+		e.SetTemplate(variable.Name, buf)
+		return
+	}
+	e.SetEntry(variable.Name, buf)
+}
+
+func (keeper Keeper[Authorizer, Cipher, Stream, KDF]) auditKey(
+	iv crypto.IV, e *vault.Env, key *crypto.Secret) (*crypto.Secret, error) {
+	ad := entryAD(e.Name, "")
+	if len(e.Audit) != 0 {
+		return keeper.cipher.Open(key, e.Audit, ad)
+	}
+	auditKey, err := keeper.kdf.Key(key, []byte(audit.KeySalt), audit.KeyLen)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := keeper.cipher.Seal(iv, key, auditKey.Bytes(), ad)
+	if err != nil {
+		auditKey.Destroy()
+		return nil, err
+	}
+	e.Audit = buf
+	return auditKey, nil
+}
+
+// retiredAuditKeys opens the audit keys replaced by earlier rotations,
+// concatenated oldest first. Their count is the epoch of the current key.
+func (keeper Keeper[Authorizer, Cipher, Stream, KDF]) retiredAuditKeys(
+	e *vault.Env, key *crypto.Secret) (*crypto.Secret, error) {
+	if len(e.RetiredAudit) == 0 {
+		return crypto.NewSecret(0)
+	}
+	retired, err := keeper.cipher.Open(
+		key, e.RetiredAudit, entryAD(e.Name, audit.RetiredLabel))
+	if err != nil {
+		return nil, err
+	}
+	if retired.Len()%audit.KeyLen != 0 {
+		retired.Destroy()
+		return nil, audit.ErrInvalidRecord
+	}
+	return retired, nil
+}
+
+func (keeper Keeper[Authorizer, Cipher, Stream, KDF]) audit(
+	iv crypto.IV,
+	e *vault.Env,
+	key *crypto.Secret,
+	record audit.Record) error {
+	if keeper.fn.Audit == nil {
+		return nil
+	}
+	retired, err := keeper.retiredAuditKeys(e, key)
+	if err != nil {
+		return err
+	}
+	epoch := retired.Len() / audit.KeyLen
+	retired.Destroy()
+	auditKey, err := keeper.auditKey(iv, e, key)
+	if err != nil {
+		return err
+	}
+	defer auditKey.Destroy()
+	record.Time = keeper.fn.Now()
+	record.Env = e.Name
+	record.Result = audit.ResultOK
+	record.Epoch = uint64(epoch)
+	return keeper.fn.Audit(auditKey, record)
+}
+
+func (keeper Keeper[Authorizer, Cipher, Stream, KDF]) deny(
+	name string, role string, err error) {
+	if keeper.fn.Audit == nil || (failure.KindOf(err) != failure.KindAuth &&
+		!errors.Is(err, vault.ErrSlotNotFound)) {
+		return
+	}
+	keeper.fn.Audit(nil, audit.Record{
+		Time:    keeper.fn.Now(),
+		Role:    role,
+		Command: vault.OpOpen,
+		Env:     name,
+		Result:  audit.ResultDenied,
+	})
+}
+
+func (keeper Keeper[Authorizer, Cipher, Stream, KDF]) Record(
+	iv crypto.IV,
+	v *vault.Vault,
+	keyring vault.Keyring,
+	name string,
+	role string,
+	command string) (err error) {
+	defer failure.Annotate(&err,
+		failure.Error{Op: command, Env: name, Role: role})
+	e, err := v.Env(name)
+	if err != nil {
+		return err
+	}
+	key, ok := keyring[name]
+	if !ok {
+		return vault.ErrSlotNotFound
+	}
+	return keeper.audit(iv, e, key, audit.Record{Role: role, Command: command})
+}
+
+func (keeper Keeper[Authorizer, Cipher, Stream, KDF]) Anchor(
+	iv crypto.IV, v *vault.Vault, keyring vault.Keyring) (err error) {
+	defer failure.Annotate(&err, failure.Error{Op: vault.OpAnchor})
+	if keeper.fn.Head == nil {
+		return nil
+	}
+	head, err := keeper.fn.Head()
+	if err != nil {
+		return err
+	}
+	buf, _ := json.Marshal(head)
+	for name, key := range keyring {
+		e, err := v.Env(name)
+		if err != nil {
+			return err
+		}
+		ad := entryAD(name, audit.HeadLabel)
+		if e.AuditHead, err = keeper.cipher.Seal(iv, key, buf, ad); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (keeper Keeper[Authorizer, Cipher, Stream, KDF]) AuditHeads(
+	v *vault.Vault, keyring vault.Keyring) (_ []audit.Head, err error) {
+	defer failure.Annotate(&err, failure.Error{Op: vault.OpAnchor})
+	heads := []audit.Head{}
+	for name, key := range keyring {
+		e, err := v.Env(name)
+		if err != nil {
+			return nil, err
+		}
+		if len(e.AuditHead) == 0 {
+			continue
+		}
+		buf, err := keeper.cipher.Open(
+			key, e.AuditHead, entryAD(name, audit.HeadLabel))
+		if err != nil {
+			return nil, err
+		}
+		head := audit.Head{}
+		err = json.Unmarshal(buf.Bytes(), &head)
+		buf.Destroy()
+		if err != nil {
+			return nil, audit.ErrInvalidRecord
+		}
+		heads = append(heads, head)
+	}
+	return heads, nil
+}
+
+func (keeper Keeper[Authorizer, Cipher, Stream, KDF]) AuditKeys(
+	v *vault.Vault, keyring vault.Keyring) (audit.Keys, error) {
+	keys := audit.Keys{}
+	for name, key := range keyring {
+		e, err := v.Env(name)
+		if err != nil {
+			keys.Destroy()
+			return nil, err
+		}
+		if len(e.Audit) == 0 && len(e.RetiredAudit) == 0 {
+			continue
+		}
+		retired, err := keeper.retiredAuditKeys(e, key)
+		if err != nil {
+			keys.Destroy()
+			return nil, err
+		}
+		epochs := []*crypto.Secret{}
+		for buf := retired.Bytes(); len(buf) != 0; buf = buf[audit.KeyLen:] {
+			retiredKey, err := crypto.NewSecretFrom(buf[:audit.KeyLen])
+			if err != nil {
+				retired.Destroy()
+				keys.Destroy()
+				return nil, err
+			}
+			epochs = append(epochs, retiredKey)
+		}
+		retired.Destroy()
+		keys[name] = epochs
+		if len(e.Audit) == 0 {
+			continue
+		}
+		auditKey, err := keeper.cipher.Open(key, e.Audit, entryAD(name, ""))
+		if err != nil {
+			keys.Destroy()
+			return nil, err
+		}
+		keys[name] = append(epochs, auditKey)
+	}
+	return keys, nil
+}
+
+func stampAD(v *vault.Vault, e *vault.Env, generation uint64) []byte {
+	return vault.StampAD(v.ID, e.Name, generation, v.SettingsDigest(e))
+}
+
+func (keeper Keeper[Authorizer, Cipher, Stream, KDF]) Stamp(
+	iv crypto.IV, v *vault.Vault, keyring vault.Keyring) (err error) {
+	defer failure.Annotate(&err, failure.Error{Op: vault.OpStamp})
+	if v.ID == "" {
+		return vault.ErrMissingVaultID
+	}
+	envs := make([]*vault.Env, 0, len(keyring))
+	for name := range keyring {
+		e, err := v.Env(name)
+		if err != nil {
+			return err
+		}
+		envs = append(envs, e)
+	}
+	generation := v.Generation
+	for _, e := range v.Envs {
+		generation = max(generation, e.Generation)
+	}
+	generation++
+	stamps := make([][]byte, len(envs))
+	for i, e := range envs {
+		ad := stampAD(v, e, generation)
+		stamps[i], err = keeper.cipher.Seal(iv, keyring[e.Name], []byte{}, ad)
+		if err != nil {
+			return err
+		}
+	}
+	v.Generation = generation
+	for i, e := range envs {
+		e.Generation = generation
+		e.Stamp = stamps[i]
+	}
+	return nil
+}
+
+func (keeper Keeper[Authorizer, Cipher, Stream, KDF]) Generations(
+	v *vault.Vault, keyring vault.Keyring) (_ map[string]uint64, err error) {
+	defer failure.Annotate(&err, failure.Error{Op: vault.OpStamp})
+	if v.ID == "" {
+		return nil, vault.ErrMissingVaultID
+	}
+	generations := map[string]uint64{}
+	for name, key := range keyring {
+		e, err := v.Env(name)
+		if err != nil {
+			return nil, err
+		}
+		if len(e.Stamp) == 0 {
+			generations[name] = 0
+			continue
+		}
+		ad := stampAD(v, e, e.Generation)
+		empty, err := keeper.cipher.Open(key, e.Stamp, ad)
+		if err != nil {
+			return nil, err
+		}
+		empty.Destroy()
+		generations[name] = e.Generation
+	}
+	return generations, nil
+}
+
+func (keeper Keeper[Authorizer, Cipher, Stream, KDF]) newSlot(
+	role string, block []byte) vault.Slot {
+	return vault.Slot{
+		Role:    role,
+		Kind:    keeper.authorizer.Kind(),
+		KDF:     keeper.authorizer.KDFParams(),
+		Created: keeper.fn.Now(),
+		Block:   block,
+	}
+}
+
+func (keeper Keeper[Authorizer, Cipher, Stream, KDF]) enforce(
+	secret *crypto.Secret) error {
+	enforcer, ok := any(keeper.authorizer).(passphrase.Enforcer)
+	if !ok {
+		return nil
+	}
+	return enforcer.Enforce(secret)
+}
+
+func (keeper Keeper[Authorizer, Cipher, Stream, KDF]) CreateEnv(
+	iv crypto.IV,
+	v *vault.Vault,
+	name string,
+	parent string,
+	role string,
+	passphrase *crypto.Secret) (_ vault.Keyring, err error) {
+	defer failure.Annotate(&err,
+		failure.Error{Op: vault.OpCreate, Env: name, Role: role})
+	keyring := vault.Keyring{}
+	if parent != "" {
+		parentKeyring, err := keeper.Open(v, parent, role, passphrase)
+		if err != nil {
+			return nil, err
+		}
+		keyring = parentKeyring
+	} else if err := keeper.enforce(passphrase); err != nil {
+		return nil, err
+	}
+	if _, err := v.Env(name); err == nil {
+		keyring.Destroy()
+		return nil, vault.ErrEnvExists
+	}
+	accessKey, block, err := keeper.authorizer.Make(
+		iv, passphrase, keeper.cipher.KeyLen())
+	if err != nil {
+		keyring.Destroy()
+		return nil, err
+	}
+	key, err := keeper.dataKey(name, accessKey)
+	if err != nil {
+		keyring.Destroy()
+		return nil, err
+	}
+	e, _ := v.AddEnv(name, parent)
+	e.SetSlot(keeper.newSlot(role, block))
+	keyring[name] = key
+	record := audit.Record{Role: role, Command: vault.OpCreate}
+	if err := keeper.audit(iv, e, key, record); err != nil {
+		keyring.Destroy()
+		return nil, err
+	}
+	return keyring, nil
+}
+
+func (keeper Keeper[Authorizer, Cipher, Stream, KDF]) passphraseSlot(
+	e *vault.Env, role string) (vault.Slot, error) {
+	slot, err := e.Slot(role)
+	if err != nil {
+		return vault.Slot{}, err
+	}
+	if slot.Kind != "" && slot.Kind != keeper.authorizer.Kind() {
+		return vault.Slot{}, vault.ErrSlotKindMismatch
+	}
+	return slot, nil
+}
+
+func (keeper Keeper[Authorizer, Cipher, Stream, KDF]) open(
+	v *vault.Vault,
+	name string,
+	role string,
+	unlock func(e *vault.Env) (*crypto.Secret, error)) (vault.Keyring, error) {
+	chain, err := v.Chain(name)
+	if err != nil {
+		return nil, err
+	}
+	keyring := vault.Keyring{}
+	for _, e := range chain {
+		accessKey, err := unlock(e)
+		if err != nil {
+			keyring.Destroy()
+			keeper.deny(e.Name, role, err)
+			return nil, err
+		}
+		key, err := keeper.dataKey(e.Name, accessKey)
+		if err != nil {
+			keyring.Destroy()
+			return nil, err
+		}
+		keyring[e.Name] = key
+	}
+	return keyring, nil
+}
+
+func (keeper Keeper[Authorizer, Cipher, Stream, KDF]) Open(
+	v *vault.Vault,
+	name string,
+	role string,
+	passphrase *crypto.Secret) (_ vault.Keyring, err error) {
+	defer failure.Annotate(&err,
+		failure.Error{Op: vault.OpOpen, Env: name, Role: role})
+	unlock := func(e *vault.Env) (*crypto.Secret, error) {
+		slot, err := keeper.passphraseSlot(e, role)
+		if err != nil {
+			return nil, err
+		}
+		return keeper.authorizer.Open(slot.KDF, passphrase, slot.Block)
+	}
+	return keeper.open(v, name, role, unlock)
+}
+
+func (keeper Keeper[Authorizer, Cipher, Stream, KDF]) OpenRecipient(
+	v *vault.Vault,
+	name string,
+	role string,
+	recipient crypto.Recipient,
+	identity *crypto.Secret) (_ vault.Keyring, err error) {
+	defer failure.Annotate(&err,
+		failure.Error{Op: vault.OpOpen, Env: name, Role: role})
+	unlock := func(e *vault.Env) (*crypto.Secret, error) {
+		slot, err := e.Slot(role)
+		if err != nil {
+			return nil, err
+		}
+		if slot.Kind != recipient.Kind() {
+			return nil, vault.ErrSlotKindMismatch
+		}
+		return recipient.Unwrap(identity, slot.Block)
+	}
+	return keeper.open(v, name, role, unlock)
+}
+
+func (keeper Keeper[Authorizer, Cipher, Stream, KDF]) rewrap(
+	iv crypto.IV,
+	targets []*vault.Env,
+	role string,
+	passphrase *crypto.Secret,
+	newPassphrase *crypto.Secret) (
+	[]*vault.Env, []vault.Slot, vault.Keyring, error) {
+	envs := []*vault.Env{}
+	slots := []vault.Slot{}
+	keyring := vault.Keyring{}
+	for _, e := range targets {
+		if _, err := e.Slot(role); err != nil {
+			continue
+		}
+		slot, err := keeper.passphraseSlot(e, role)
+		if err != nil {
+			keyring.Destroy()
+			return nil, nil, nil, err
+		}
+		accessKey, block, err := keeper.authorizer.Inherit(
+			iv, slot.KDF, passphrase, newPassphrase, slot.Block)
+		if err != nil {
+			keyring.Destroy()
+			return nil, nil, nil, err
+		}
+		if keeper.fn.Audit == nil {
+			accessKey.Destroy()
+		} else if keyring[e.Name], err = keeper.dataKey(
+			e.Name, accessKey); err != nil {
+			keyring.Destroy()
+			return nil, nil, nil, err
+		}
+		slot.KDF = keeper.authorizer.KDFParams()
+		slot.Block = block
+		envs = append(envs, e)
+		slots = append(slots, slot)
+	}
+	if len(envs) == 0 {
+		return nil, nil, nil, vault.ErrSlotNotFound
+	}
+	return envs, slots, keyring, nil
+}
+
+func (keeper Keeper[Authorizer, Cipher, Stream, KDF]) Passwd(
+	iv crypto.IV,
+	v *vault.Vault,
+	role string,
+	passphrase *crypto.Secret,
+	newPassphrase *crypto.Secret) (err error) {
+	defer failure.Annotate(&err,
+		failure.Error{Op: vault.OpPasswd, Role: role})
+	envs, slots, keyring, err := keeper.rewrap(
+		iv, v.Envs, role, passphrase, newPassphrase)
+	if err != nil {
+		return err
+	}
+	defer keyring.Destroy()
+	for i, e := range envs {
+		e.SetSlot(slots[i])
+	}
+	for _, e := range envs {
+		err := keeper.audit(iv, e, keyring[e.Name],
+			audit.Record{Role: role, Command: vault.OpPasswd})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func grantChain(v *vault.Vault,
+	name string, role string, newRole string) ([]*vault.Env, error) {
+	for _, e := range v.Envs {
+		if _, err := e.Slot(newRole); err == nil {
+			return nil, vault.ErrRoleExists
+		}
+	}
+	chain, err := v.Chain(name)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range chain {
+		if _, err := e.Slot(role); err != nil {
+			return nil, err
+		}
+	}
+	return chain, nil
+}
+
+func (keeper Keeper[Authorizer, Cipher, Stream, KDF]) AddRole(
+	iv crypto.IV,
+	v *vault.Vault,
+	name string,
+	role string,
+	passphrase *crypto.Secret,
+	newRole string,
+	newPassphrase *crypto.Secret) (err error) {
+	defer failure.Annotate(&err,
+		failure.Error{Op: vault.OpAddRole, Env: name, Role: newRole})
+	chain, err := grantChain(v, name, role, newRole)
+	if err != nil {
+		return err
+	}
+	envs, slots, keyring, err := keeper.rewrap(
+		iv, chain, role, passphrase, newPassphrase)
+	if err != nil {
+		return err
+	}
+	defer keyring.Destroy()
+	for i, e := range envs {
+		e.SetSlot(keeper.newSlot(newRole, slots[i].Block))
+	}
+	for _, e := range envs {
+		err := keeper.audit(iv, e, keyring[e.Name],
+			audit.Record{Role: role, Command: vault.OpAddRole})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (keeper Keeper[Authorizer, Cipher, Stream, KDF]) AddRecipient(
+	iv crypto.IV,
+	v *vault.Vault,
+	name string,
+	role string,
+	passphrase *crypto.Secret,
+	newRole string,
+	recipient crypto.Recipient,
+	publicKey []byte) (err error) {
+	defer failure.Annotate(&err,
+		failure.Error{Op: vault.OpAddRole, Env: name, Role: newRole})
+	chain, err := grantChain(v, name, role, newRole)
+	if err != nil {
+		return err
+	}
+	envs := []*vault.Env{}
+	blocks := [][]byte{}
+	keyring := vault.Keyring{}
+	defer keyring.Destroy()
+	for _, e := range chain {
+		slot, err := keeper.passphraseSlot(e, role)
+		if err != nil {
+			return err
+		}
+		accessKey, err := keeper.authorizer.Open(slot.KDF, passphrase, slot.Block)
+		if err != nil {
+			return err
+		}
+		block, err := recipient.Wrap(iv, publicKey, accessKey)
+		if err != nil {
+			accessKey.Destroy()
+			return err
+		}
+		if keeper.fn.Audit == nil {
+			accessKey.Destroy()
+		} else if keyring[e.Name], err = keeper.dataKey(
+			e.Name, accessKey); err != nil {
+			return err
+		}
+		envs = append(envs, e)
+		blocks = append(blocks, block)
+	}
+	for i, e := range envs {
+		e.SetSlot(vault.Slot{
+			Role:      newRole,
+			Kind:      recipient.Kind(),
+			Created:   keeper.fn.Now(),
+			Block:     blocks[i],
+			PublicKey: publicKey,
+		})
+	}
+	for _, e := range envs {
+		err := keeper.audit(iv, e, keyring[e.Name],
+			audit.Record{Role: role, Command: vault.OpAddRole})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (keeper Keeper[Authorizer, Cipher, Stream, KDF]) sealFile(
+	iv crypto.IV,
+	key *crypto.Secret,
+	ad []byte,
+	r io.Reader) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	w, err := keeper.stream.NewWriter(iv, key, ad, buf)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		w.Close()
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (keeper Keeper[Authorizer, Cipher, Stream, KDF]) reseal(
+	iv crypto.IV,
+	key *crypto.Secret,
+	newKey *crypto.Secret,
+	ad []byte,
+	entry vault.Entry) ([]byte, error) {
+	if entry.Kind == vault.EntryKindFile {
+		r, err := keeper.stream.NewReader(key, ad, bytes.NewReader(entry.Buf))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return keeper.sealFile(iv, newKey, ad, r)
+	}
+	value, err := keeper.cipher.Open(key, entry.Buf, ad)
+	if err != nil {
+		return nil, err
+	}
+	defer value.Destroy()
+	return keeper.cipher.Seal(iv, newKey, value.Bytes(), ad)
+}
+
+func slotRecipient(
+	recipients []crypto.Recipient, slot vault.Slot) (crypto.Recipient, bool) {
+	if len(slot.PublicKey) == 0 {
+		return nil, false
+	}
+	for _, recipient := range recipients {
+		if recipient.Kind() == slot.Kind {
+			return recipient, true
+		}
+	}
+	return nil, false
+}
+
+// retireAuditKey appends the current audit key to the retired ones and
+// seals them under the new data key, so that records written before the
+// rotation still verify while a role that knew the old key cannot forge
+// records after it.
+func (keeper Keeper[Authorizer, Cipher, Stream, KDF]) retireAuditKey(
+	iv crypto.IV,
+	e *vault.Env,
+	key *crypto.Secret,
+	newKey *crypto.Secret) ([]byte, error) {
+	if len(e.Audit) == 0 {
+		return nil, nil
+	}
+	retired, err := keeper.retiredAuditKeys(e, key)
+	if err != nil {
+		return nil, err
+	}
+	defer retired.Destroy()
+	auditKey, err := keeper.cipher.Open(key, e.Audit, entryAD(e.Name, ""))
+	if err != nil {
+		return nil, err
+	}
+	defer auditKey.Destroy()
+	buf, err := crypto.NewSecret(retired.Len() + auditKey.Len())
+	if err != nil {
+		return nil, err
+	}
+	defer buf.Destroy()
+	copy(buf.Bytes(), retired.Bytes())
+	copy(buf.Bytes()[retired.Len():], auditKey.Bytes())
+	return keeper.cipher.Seal(
+		iv, newKey, buf.Bytes(), entryAD(e.Name, audit.RetiredLabel))
+}
+
+func (keeper Keeper[Authorizer, Cipher, Stream, KDF]) Rotate(
+	iv crypto.IV,
+	v *vault.Vault,
+	name string,
+	role string,
+	passphrase *crypto.Secret,
+	recipients ...crypto.Recipient) (_ []string, err error) {
+	defer failure.Annotate(&err,
+		failure.Error{Op: vault.OpRotate, Env: name, Role: role})
+	e, err := v.Env(name)
+	if err != nil {
+		return nil, err
+	}
+	slot, err := keeper.passphraseSlot(e, role)
+	if err != nil {
+		return nil, err
+	}
+	accessKey, err := keeper.authorizer.Open(slot.KDF, passphrase, slot.Block)
+	if err != nil {
+		return nil, err
+	}
+	key, err := keeper.dataKey(name, accessKey)
+	if err != nil {
+		return nil, err
+	}
+	defer key.Destroy()
+	newAccessKey, block, err := keeper.authorizer.Make(
+		iv, passphrase, keeper.cipher.KeyLen())
+	if err != nil {
+		return nil, err
+	}
+	slots := []vault.Slot{}
+	dropped := []string{}
+	own := 0
+	for _, s := range e.Slots {
+		if s.Role == role {
+			own = len(slots)
+			slots = append(slots, s)
+			continue
+		}
+		recipient, ok := slotRecipient(recipients, s)
+		if !ok {
+			dropped = append(dropped, s.Role)
+			continue
+		}
+		s.Block, err = recipient.Wrap(iv, s.PublicKey, newAccessKey)
+		if err != nil {
+			newAccessKey.Destroy()
+			return nil, err
+		}
+		slots = append(slots, s)
+	}
+	newKey, err := keeper.dataKey(name, newAccessKey)
+	if err != nil {
+		return nil, err
+	}
+	defer newKey.Destroy()
+	entries := make([]vault.Entry, len(e.Entries))
+	for i, entry := range e.Entries {
+		ad := valueAD(name, entry.Name, entry.Kind)
+		buf, err := keeper.reseal(iv, key, newKey, ad, entry)
+		if err != nil {
+			return nil, err
+		}
+		entries[i] = vault.Entry{Name: entry.Name, Kind: entry.Kind, Buf: buf}
+	}
+	retiredBuf, err := keeper.retireAuditKey(iv, e, key, newKey)
+	if err != nil {
+		return nil, err
+	}
+	var stamp []byte
+	if len(e.Stamp) != 0 {
+		empty, err := keeper.cipher.Open(
+			key, e.Stamp, stampAD(v, e, e.Generation))
+		if err != nil {
+			return nil, err
+		}
+		empty.Destroy()
+		// Dropping the first slot passes the ownership on.
+		rotated := *e
+		rotated.Slots = slots
+		stamp, err = keeper.cipher.Seal(
+			iv, newKey, []byte{}, stampAD(v, &rotated, e.Generation))
+		if err != nil {
+			return nil, err
+		}
+	}
+	var headBuf []byte
+	if len(e.AuditHead) != 0 {
+		entry := vault.Entry{Buf: e.AuditHead}
+		ad := entryAD(name, audit.HeadLabel)
+		headBuf, err = keeper.reseal(iv, key, newKey, ad, entry)
+		if err != nil {
+			return nil, err
+		}
+	}
+	slots[own] = keeper.newSlot(role, block)
+	e.Entries = entries
+	e.Slots = slots
+	e.Audit = nil
+	e.RetiredAudit = retiredBuf
+	e.AuditHead = headBuf
+	e.Stamp = stamp
+	record := audit.Record{Role: role, Command: vault.OpRotate}
+	if err := keeper.audit(iv, e, newKey, record); err != nil {
+		return nil, err
+	}
+	return dropped, nil
+}
+
+func (keeper Keeper[Authorizer, Cipher, Stream, KDF]) RemoveRole(
+	iv crypto.IV,
+	v *vault.Vault,
+	role string,
+	passphrase *crypto.Secret,
+	oldRole string,
+	rotate bool,
+	recipients ...crypto.Recipient) (_ vault.RemoveReport, err error) {
+	defer failure.Annotate(&err,
+		failure.Error{Op: vault.OpRmRole, Role: oldRole})
+	for _, e := range v.Envs {
+		if _, err := e.Slot(oldRole); err != nil {
+			continue
+		}
+		if len(e.Slots) == 1 {
+			return vault.RemoveReport{}, vault.ErrLastSlot
+		}
+		if e.Parent == "" && e.Owner() == oldRole && role != oldRole {
+			return vault.RemoveReport{}, vault.ErrNotOwner
+		}
+	}
+	if !rotate {
+		keyring := vault.Keyring{}
+		defer keyring.Destroy()
+		for _, e := range v.Envs {
+			if _, err := e.Slot(oldRole); err != nil {
+				continue
+			}
+			slot, err := keeper.passphraseSlot(e, role)
+			if err != nil {
+				return vault.RemoveReport{}, err
+			}
+			accessKey, err := keeper.authorizer.Open(slot.KDF, passphrase, slot.Block)
+			if err != nil {
+				return vault.RemoveReport{}, err
+			}
+			if keyring[e.Name], err = keeper.dataKey(
+				e.Name, accessKey); err != nil {
+				return vault.RemoveReport{}, err
+			}
+		}
+		names, err := v.RemoveRole(oldRole)
+		if err != nil {
+			return vault.RemoveReport{}, err
+		}
+		for _, name := range names {
+			e, _ := v.Env(name)
+			key, ok := keyring[name]
+			if !ok {
+				continue
+			}
+			err := keeper.audit(iv, e, key,
+				audit.Record{Role: role, Command: vault.OpRmRole})
+			if err != nil {
+				return vault.RemoveReport{}, err
+			}
+			// Removing the owner's own slot passes the ownership on.
+			if len(e.Stamp) != 0 {
+				e.Stamp, err = keeper.cipher.Seal(
+					iv, key, []byte{}, stampAD(v, e, e.Generation))
+				if err != nil {
+					return vault.RemoveReport{}, err
+				}
+			}
+		}
+		return vault.RemoveReport{
+			Envs:    names,
+			Dropped: []string{},
+			Warning: vault.RemoveRoleWarning,
+		}, nil
+	}
+	if oldRole == role {
+		return vault.RemoveReport{}, vault.ErrSlotNotFound
+	}
+	for _, e := range v.Envs {
+		if _, err := e.Slot(oldRole); err != nil {
+			continue
+		}
+		if _, err := keeper.passphraseSlot(e, role); err != nil {
+			return vault.RemoveReport{}, err
+		}
+	}
+	names, err := v.RemoveRole(oldRole)
+	if err != nil {
+		return vault.RemoveReport{}, err
+	}
+	report := vault.RemoveReport{Envs: names, Dropped: []string{}}
+	for _, name := range names {
+		dropped, err := keeper.Rotate(
+			iv, v, name, role, passphrase, recipients...)
+		if err != nil {
+			return vault.RemoveReport{}, err
+		}
+		for _, d := range dropped {
+			if !slices.Contains(report.Dropped, d) {
+				report.Dropped = append(report.Dropped, d)
+			}
+		}
+	}
+	return report, nil
+}
+
+func (keeper Keeper[Authorizer, Cipher, Stream, KDF]) Set(
+	iv crypto.IV,
+	v *vault.Vault,
+	keyring vault.Keyring,
+	name string,
+	role string,
+	variable env.Var) (err error) {
+	defer failure.Annotate(&err,
+		failure.Error{Op: vault.OpSet, Env: name, Var: variable.Name})
+	if !env.ValidName(variable.Name) {
+		return env.ErrInvalidVarName
+	}
+	if !variable.Interpolate || !env.Interpolated(variable.Value) {
+		if err := v.Schema.CheckVar(variable); err != nil {
+			return err
+		}
+	}
+	e, err := v.Env(name)
+	if err != nil {
+		return err
+	}
+	key, ok := keyring[name]
+	if !ok {
+		return vault.ErrSlotNotFound
+	}
+	buf, err := keeper.cipher.Seal(iv, key, []byte(variable.Value),
+		valueAD(name, variable.Name, valueKind(variable)))
+	if err != nil {
+		return err
+	}
+	setValue(e, variable, buf)
+	return keeper.audit(iv, e, key, audit.Record{
+		Role:    role,
+		Command: vault.OpSet,
+		Vars:    []string{variable.Name},
+	})
+}
+
+func (keeper Keeper[Authorizer, Cipher, Stream, KDF]) Import(
+	iv crypto.IV,
+	v *vault.Vault,
+	keyring vault.Keyring,
+	name string,
+	role string,
+	vars []env.Var) (report vault.ImportReport, err error) {
+	defer failure.Annotate(&err, failure.Error{Op: vault.OpImport, Env: name})
+	for _, variable := range vars {
+		var err error
+		if !variable.Interpolate || !env.Interpolated(variable.Value) {
+			err = v.Schema.CheckVar(variable)
+		}
+		if !env.ValidName(variable.Name) {
+			err = env.ErrInvalidVarName
+		}
+		if err != nil {
+			return vault.ImportReport{},
+				failure.Wrap(err, failure.Error{Var: variable.Name})
+		}
+	}
+	e, err := v.Env(name)
+	if err != nil {
+		return vault.ImportReport{}, err
+	}
+	key, ok := keyring[name]
+	if !ok {
+		return vault.ImportReport{}, vault.ErrSlotNotFound
+	}
+	bufs := make([][]byte, len(vars))
+	for i, variable := range vars {
+		bufs[i], err = keeper.cipher.Seal(iv, key, []byte(variable.Value),
+			valueAD(name, variable.Name, valueKind(variable)))
+		if err != nil {
+			return vault.ImportReport{}, err
+		}
+	}
+	report = vault.ImportReport{
+		Env: name, Added: []string{}, Updated: []string{}}
+	seen := map[string]struct{}{}
+	for i, variable := range vars {
+		if _, ok := seen[variable.Name]; !ok {
+			seen[variable.Name] = struct{}{}
+			if _, err := e.Entry(variable.Name); err == nil {
+				report.Updated = append(report.Updated, variable.Name)
+			} else {
+				report.Added = append(report.Added, variable.Name)
+			}
+		}
+		setValue(e, variable, bufs[i])
+	}
+	err = keeper.audit(iv, e, key, audit.Record{
+		Role:    role,
+		Command: vault.OpImport,
+		Vars:    slices.Concat(report.Added, report.Updated),
+	})
+	if err != nil {
+		return vault.ImportReport{}, err
+	}
+	return report, nil
+}
+
+func (keeper Keeper[Authorizer, Cipher, Stream, KDF]) SetFile(
+	iv crypto.IV,
+	v *vault.Vault,
+	keyring vault.Keyring,
+	name string,
+	role string,
+	varName string,
+	r io.Reader) (err error) {
+	defer failure.Annotate(&err,
+		failure.Error{Op: vault.OpSetFile, Env: name, Var: varName})
+	if !env.ValidName(varName) {
+		return env.ErrInvalidVarName
+	}
+	if err := v.Schema.CheckFile(varName); err != nil {
+		return err
+	}
+	e, err := v.Env(name)
+	if err != nil {
+		return err
+	}
+	key, ok := keyring[name]
+	if !ok {
+		return vault.ErrSlotNotFound
+	}
+	buf, err := keeper.sealFile(iv, key, entryAD(name, varName), r)
+	if err != nil {
+		return err
+	}
+	e.SetFile(varName, buf)
+	return keeper.audit(iv, e, key, audit.Record{
+		Role:    role,
+		Command: vault.OpSetFile,
+		Vars:    []string{varName},
+	})
+}
+
+func (keeper Keeper[Authorizer, Cipher, Stream, KDF]) OpenFile(
+	v *vault.Vault,
+	keyring vault.Keyring,
+	name string,
+	varName string) (_ io.ReadCloser, err error) {
+	defer failure.Annotate(&err,
+		failure.Error{Op: vault.OpOpenFile, Env: name, Var: varName})
+	e, err := v.Env(name)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := e.File(varName)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := keyring[name]
+	if !ok {
+		return nil, vault.ErrSlotNotFound
+	}
+	return keeper.stream.NewReader(
+		key, entryAD(name, varName), bytes.NewReader(buf))
+}
+
+func (keeper Keeper[Authorizer, Cipher, Stream, KDF]) resolve(
+	v *vault.Vault,
+	keyring vault.Keyring,
+	name string,
+	interpolator env.Interpolator) ([]vault.ResolvedVar, env.Report, error) {
+	chain, err := v.Chain(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	vars := []vault.ResolvedVar{}
+	index := map[string]int{}
+	for _, e := range chain {
+		key, ok := keyring[e.Name]
+		if !ok {
+			return nil, nil, vault.ErrSlotNotFound
+		}
+		for _, entry := range e.Entries {
+			resolved := vault.ResolvedVar{
+				Var:    env.Var{Name: entry.Name},
+				Kind:   entry.Kind,
+				Origin: e.Name,
+			}
+			if entry.Kind != vault.EntryKindFile {
+				value, err := keeper.cipher.Open(key, entry.Buf,
+					valueAD(e.Name, entry.Name, entry.Kind))
+				if err != nil {
+					return nil, nil, err
+				}
+				resolved.Value = string(value.Bytes())
+				value.Destroy()
+			}
+			if i, ok := index[entry.Name]; ok {
+				vars[i] = resolved
+				continue
+			}
+			index[entry.Name] = len(vars)
+			vars = append(vars, resolved)
+		}
+	}
+	values := []env.Var{}
+	for _, resolved := range vars {
+		switch resolved.Kind {
+		case vault.EntryKindFile:
+		case vault.EntryKindTemplate:
+			values = append(values, resolved.Var)
+		default:
+			// Escaped so the interpolator hands literal values back as is.
+			values = append(values, env.Var{Name: resolved.Name,
+				Value: strings.ReplaceAll(resolved.Value, "$", "$$")})
+		}
+	}
+	expanded, err := interpolator.Expand(values)
+	if err != nil {
+		return nil, nil, err
+	}
+	checked := make([]env.Var, len(vars))
+	for i := range vars {
+		if vars[i].Kind != vault.EntryKindFile {
+			vars[i].Value, expanded = expanded[0].Value, expanded[1:]
+		}
+		checked[i] = vars[i].Var
+	}
+	return vars, v.Schema.Check(checked), nil
+}
+
+func (keeper Keeper[Authorizer, Cipher, Stream, KDF]) Resolve(
+	v *vault.Vault,
+	keyring vault.Keyring,
+	name string,
+	interpolator env.Interpolator) (_ []vault.ResolvedVar, err error) {
+	defer failure.Annotate(&err,
+		failure.Error{Op: vault.OpResolve, Env: name})
+	vars, report, err := keeper.resolve(v, keyring, name, interpolator)
+	if err != nil {
+		return nil, err
+	}
+	if len(report) != 0 {
+		return nil, failure.Wrap(report[0].Err,
+			failure.Error{Var: report[0].Rule.Name})
+	}
+	return vars, nil
+}
+
+func (keeper Keeper[Authorizer, Cipher, Stream, KDF]) Check(
+	v *vault.Vault,
+	keyring vault.Keyring,
+	name string,
+	interpolator env.Interpolator) (_ env.Report, err error) {
+	defer failure.Annotate(&err,
+		failure.Error{Op: vault.OpCheck, Env: name})
+	_, report, err := keeper.resolve(v, keyring, name, interpolator)
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
@@ -0,0 +1,2339 @@
+package vault_impl
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/reshifr/secure-env/core/audit"
+	"github.com/reshifr/secure-env/core/crypto"
+	cmock "github.com/reshifr/secure-env/core/crypto/mock"
+	"github.com/reshifr/secure-env/core/env"
+	emock "github.com/reshifr/secure-env/core/env/mock"
+	"github.com/reshifr/secure-env/core/failure"
+	"github.com/reshifr/secure-env/core/passphrase"
+	pmock "github.com/reshifr/secure-env/core/passphrase/mock"
+	"github.com/reshifr/secure-env/core/vault"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	errWeak  = passphrase.ErrPassphraseTooWeak
+	now      = time.Unix(1000, 0)
+	fnKeeper = FnKeeper{Now: func() time.Time { return now }}
+	params   = crypto.KDFParams{
+		Algorithm: "argon2i",
+		Time:      3,
+		Memory:    65536,
+		Threads:   4,
+	}
+)
+
+func newSecret(b ...byte) *crypto.Secret {
+	secret, _ := crypto.NewSecretFrom(b)
+	return secret
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+type enforcingAuthorizer struct {
+	*cmock.Authorizer
+	enforcer *pmock.Enforcer
+}
+
+func (authorizer enforcingAuthorizer) Enforce(secret *crypto.Secret) error {
+	return authorizer.enforcer.Enforce(secret)
+}
+
+func Test_NewKeeper(t *testing.T) {
+	t.Parallel()
+	authorizer := cmock.NewAuthorizer(t)
+	cipher := cmock.NewAE(t)
+	stream := cmock.NewStream(t)
+	kdf := cmock.NewKDF(t)
+	expKeeper := Keeper[
+		*cmock.Authorizer, *cmock.AE, *cmock.Stream, *cmock.KDF]{
+		authorizer: authorizer,
+		cipher:     cipher,
+		stream:     stream,
+		kdf:        kdf,
+	}
+
+	keeper := NewKeeper(FnKeeper{}, authorizer, cipher, stream, kdf)
+	assert.Equal(t, expKeeper, keeper)
+}
+
+func Test_Keeper_CreateEnv(t *testing.T) {
+	t.Parallel()
+	const keyLen = 32
+	passphrase := newSecret([]byte("+DF7Rc-X/MOYjkNj")...)
+
+	t.Run("ErrSlotNotFound error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		v := &vault.Vault{Envs: []*vault.Env{{Name: "base"}}}
+		var expKeyring vault.Keyring = nil
+		const expErr = vault.ErrSlotNotFound
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		keyring, err := keeper.CreateEnv(
+			nil, v, "prod", "base", "admin", passphrase)
+		assert.Equal(t, expKeyring, keyring)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrEnvExists error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		v := &vault.Vault{Envs: []*vault.Env{{Name: "prod"}}}
+		var expKeyring vault.Keyring = nil
+		const expErr = vault.ErrEnvExists
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		keyring, err := keeper.CreateEnv(
+			nil, v, "prod", "", "admin", passphrase)
+		assert.Equal(t, expKeyring, keyring)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrPassphraseTooWeak error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := enforcingAuthorizer{
+			Authorizer: cmock.NewAuthorizer(t),
+			enforcer:   pmock.NewEnforcer(t),
+		}
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		authorizer.enforcer.EXPECT().Enforce(passphrase).
+			Return(errWeak).Once()
+
+		v := &vault.Vault{}
+		var expKeyring vault.Keyring = nil
+		expErr := errWeak
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		keyring, err := keeper.CreateEnv(
+			nil, v, "prod", "", "admin", passphrase)
+		assert.Equal(t, expKeyring, keyring)
+		assert.ErrorIs(t, err, expErr)
+		assert.Empty(t, v.Envs)
+	})
+	t.Run("ErrReadEntropyFailed error", func(t *testing.T) {
+		t.Parallel()
+		iv := cmock.NewIV(t)
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		cipher.EXPECT().KeyLen().Return(keyLen).Once()
+		authorizer.EXPECT().Make(iv, passphrase, uint32(keyLen)).
+			Return(nil, nil, crypto.ErrReadEntropyFailed).Once()
+
+		v := &vault.Vault{}
+		var expKeyring vault.Keyring = nil
+		const expErr = crypto.ErrReadEntropyFailed
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		keyring, err := keeper.CreateEnv(
+			iv, v, "prod", "", "admin", passphrase)
+		assert.Equal(t, expKeyring, keyring)
+		assert.ErrorIs(t, err, expErr)
+		assert.Empty(t, v.Envs)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		iv := cmock.NewIV(t)
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+
+		baseBlock := []byte{0x01}
+		baseAccessKey := newSecret(0x11)
+		baseKey := newSecret(0x21)
+		prodBlock := []byte{0x02}
+		prodAccessKey := newSecret(0x12)
+		prodKey := newSecret(0x22)
+		cipher.EXPECT().KeyLen().Return(keyLen).Times(3)
+		authorizer.EXPECT().Open(crypto.KDFParams{}, passphrase, baseBlock).
+			Return(baseAccessKey, nil).Once()
+		kdf.EXPECT().Key(baseAccessKey, []byte("base"), uint32(keyLen)).
+			Return(baseKey, nil).Once()
+		authorizer.EXPECT().Make(iv, passphrase, uint32(keyLen)).
+			Return(prodAccessKey, prodBlock, nil).Once()
+		kdf.EXPECT().Key(prodAccessKey, []byte("prod"), uint32(keyLen)).
+			Return(prodKey, nil).Once()
+		authorizer.EXPECT().Kind().Return("passphrase").Once()
+		authorizer.EXPECT().KDFParams().Return(params).Once()
+
+		v := &vault.Vault{Envs: []*vault.Env{{
+			Name:  "base",
+			Slots: []vault.Slot{{Role: "admin", Block: baseBlock}},
+		}}}
+		expKeyring := vault.Keyring{"base": baseKey, "prod": prodKey}
+		expEnv := &vault.Env{
+			Name:   "prod",
+			Parent: "base",
+			Slots: []vault.Slot{{
+				Role:    "admin",
+				Kind:    "passphrase",
+				KDF:     params,
+				Created: now,
+				Block:   prodBlock,
+			}},
+		}
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		keyring, err := keeper.CreateEnv(
+			iv, v, "prod", "base", "admin", passphrase)
+		assert.Equal(t, expKeyring, keyring)
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, expEnv, v.Envs[1])
+	})
+}
+
+func Test_Keeper_Open(t *testing.T) {
+	t.Parallel()
+	const keyLen = 32
+	passphrase := newSecret([]byte("+DF7Rc-X/MOYjkNj")...)
+
+	t.Run("ErrEnvNotFound error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		v := &vault.Vault{}
+		var expKeyring vault.Keyring = nil
+		const expErr = vault.ErrEnvNotFound
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		keyring, err := keeper.Open(v, "prod", "admin", passphrase)
+		assert.Equal(t, expKeyring, keyring)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrSlotKindMismatch error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		authorizer.EXPECT().Kind().Return("passphrase").Once()
+
+		v := &vault.Vault{Envs: []*vault.Env{{
+			Name: "prod",
+			Slots: []vault.Slot{
+				{Role: "admin", Kind: "x25519-mlkem768", Block: []byte{0x01}},
+			},
+		}}}
+		var expKeyring vault.Keyring = nil
+		const expErr = vault.ErrSlotKindMismatch
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		keyring, err := keeper.Open(v, "prod", "admin", passphrase)
+		assert.Equal(t, expKeyring, keyring)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrAuthFailed error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		block := []byte{0x01}
+		authorizer.EXPECT().Open(crypto.KDFParams{}, passphrase, block).
+			Return(nil, crypto.ErrAuthFailed).Once()
+
+		v := &vault.Vault{Envs: []*vault.Env{{
+			Name:  "prod",
+			Slots: []vault.Slot{{Role: "admin", Block: block}},
+		}}}
+		var expKeyring vault.Keyring = nil
+		const expErr = crypto.ErrAuthFailed
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		keyring, err := keeper.Open(v, "prod", "admin", passphrase)
+		assert.Equal(t, expKeyring, keyring)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Denied record", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		block := []byte{0x01}
+		authorizer.EXPECT().Open(crypto.KDFParams{}, passphrase, block).
+			Return(nil, crypto.ErrAuthFailed).Once()
+
+		v := &vault.Vault{Envs: []*vault.Env{{
+			Name:  "prod",
+			Slots: []vault.Slot{{Role: "admin", Block: block}},
+		}}}
+		var records []audit.Record
+		fn := fnKeeper
+		fn.Audit = func(key *crypto.Secret, record audit.Record) error {
+			assert.Nil(t, key)
+			records = append(records, record)
+			return nil
+		}
+		expRecords := []audit.Record{{
+			Time:    now,
+			Role:    "admin",
+			Command: vault.OpOpen,
+			Env:     "prod",
+			Result:  audit.ResultDenied,
+		}}
+		const expErr = crypto.ErrAuthFailed
+
+		keeper := NewKeeper(fn, authorizer, cipher, stream, kdf)
+		_, err := keeper.Open(v, "prod", "admin", passphrase)
+		assert.ErrorIs(t, err, expErr)
+		assert.Equal(t, expRecords, records)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		block := []byte{0x01}
+		accessKey := newSecret(0x11)
+		key := newSecret(0x21)
+		cipher.EXPECT().KeyLen().Return(keyLen).Once()
+		authorizer.EXPECT().Open(params, passphrase, block).
+			Return(accessKey, nil).Once()
+		kdf.EXPECT().Key(accessKey, []byte("prod"), uint32(keyLen)).
+			Return(key, nil).Once()
+
+		v := &vault.Vault{Envs: []*vault.Env{{
+			Name: "prod",
+			Slots: []vault.Slot{
+				{Role: "admin", KDF: params, Block: block},
+			},
+		}}}
+		expKeyring := vault.Keyring{"prod": key}
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		keyring, err := keeper.Open(v, "prod", "admin", passphrase)
+		assert.Equal(t, expKeyring, keyring)
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_Keeper_OpenRecipient(t *testing.T) {
+	t.Parallel()
+	const keyLen = 32
+	identity := newSecret(0x31, 0x32)
+
+	t.Run("ErrSlotNotFound error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		recipient := cmock.NewRecipient(t)
+		v := &vault.Vault{Envs: []*vault.Env{{Name: "prod"}}}
+		var expKeyring vault.Keyring = nil
+		const expErr = vault.ErrSlotNotFound
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		keyring, err := keeper.OpenRecipient(
+			v, "prod", "ci", recipient, identity)
+		assert.Equal(t, expKeyring, keyring)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrSlotKindMismatch error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		recipient := cmock.NewRecipient(t)
+		recipient.EXPECT().Kind().Return("x25519-mlkem768").Once()
+
+		v := &vault.Vault{Envs: []*vault.Env{{
+			Name: "prod",
+			Slots: []vault.Slot{
+				{Role: "ci", Kind: "passphrase", Block: []byte{0x01}},
+			},
+		}}}
+		var expKeyring vault.Keyring = nil
+		const expErr = vault.ErrSlotKindMismatch
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		keyring, err := keeper.OpenRecipient(
+			v, "prod", "ci", recipient, identity)
+		assert.Equal(t, expKeyring, keyring)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrAuthFailed error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		recipient := cmock.NewRecipient(t)
+		block := []byte{0x01}
+		recipient.EXPECT().Kind().Return("x25519-mlkem768").Once()
+		recipient.EXPECT().Unwrap(identity, block).
+			Return(nil, crypto.ErrAuthFailed).Once()
+
+		v := &vault.Vault{Envs: []*vault.Env{{
+			Name: "prod",
+			Slots: []vault.Slot{
+				{Role: "ci", Kind: "x25519-mlkem768", Block: block},
+			},
+		}}}
+		var expKeyring vault.Keyring = nil
+		const expErr = crypto.ErrAuthFailed
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		keyring, err := keeper.OpenRecipient(
+			v, "prod", "ci", recipient, identity)
+		assert.Equal(t, expKeyring, keyring)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		recipient := cmock.NewRecipient(t)
+		block := []byte{0x01}
+		accessKey := newSecret(0x11)
+		key := newSecret(0x21)
+		cipher.EXPECT().KeyLen().Return(keyLen).Once()
+		recipient.EXPECT().Kind().Return("x25519-mlkem768").Once()
+		recipient.EXPECT().Unwrap(identity, block).
+			Return(accessKey, nil).Once()
+		kdf.EXPECT().Key(accessKey, []byte("prod"), uint32(keyLen)).
+			Return(key, nil).Once()
+
+		v := &vault.Vault{Envs: []*vault.Env{{
+			Name: "prod",
+			Slots: []vault.Slot{
+				{Role: "ci", Kind: "x25519-mlkem768", Block: block},
+			},
+		}}}
+		expKeyring := vault.Keyring{"prod": key}
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		keyring, err := keeper.OpenRecipient(
+			v, "prod", "ci", recipient, identity)
+		assert.Equal(t, expKeyring, keyring)
+		assert.ErrorIs(t, err, nil)
+		assert.Zero(t, accessKey.Len())
+	})
+}
+
+func Test_Keeper_Passwd(t *testing.T) {
+	t.Parallel()
+	passphrase := newSecret([]byte("+DF7Rc-X/MOYjkNj")...)
+	newPassphrase := newSecret([]byte("q7!Lw2#zR9@pXe4v")...)
+
+	t.Run("ErrSlotNotFound error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		v := &vault.Vault{Envs: []*vault.Env{{
+			Name:  "prod",
+			Slots: []vault.Slot{{Role: "dev", Block: []byte{0x01}}},
+		}}}
+		const expErr = vault.ErrSlotNotFound
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		err := keeper.Passwd(nil, v, "admin", passphrase, newPassphrase)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrAuthFailed error", func(t *testing.T) {
+		t.Parallel()
+		iv := cmock.NewIV(t)
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		authorizer.EXPECT().
+			Inherit(iv, crypto.KDFParams{}, passphrase, newPassphrase, []byte{0x01}).
+			Return(newSecret(0x11), []byte{0x03}, nil).Once()
+		authorizer.EXPECT().KDFParams().Return(params).Once()
+		authorizer.EXPECT().
+			Inherit(iv, crypto.KDFParams{}, passphrase, newPassphrase, []byte{0x02}).
+			Return(nil, nil, crypto.ErrAuthFailed).Once()
+
+		v := &vault.Vault{Envs: []*vault.Env{
+			{
+				Name:  "base",
+				Slots: []vault.Slot{{Role: "admin", Block: []byte{0x01}}},
+			},
+			{
+				Name:  "prod",
+				Slots: []vault.Slot{{Role: "admin", Block: []byte{0x02}}},
+			},
+		}}
+		expSlots := []vault.Slot{{Role: "admin", Block: []byte{0x01}}}
+		const expErr = crypto.ErrAuthFailed
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		err := keeper.Passwd(iv, v, "admin", passphrase, newPassphrase)
+		assert.ErrorIs(t, err, expErr)
+		assert.Equal(t, expSlots, v.Envs[0].Slots)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		iv := cmock.NewIV(t)
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		accessKey := newSecret(0x11)
+		authorizer.EXPECT().
+			Inherit(iv, crypto.KDFParams{}, passphrase, newPassphrase, []byte{0x01}).
+			Return(accessKey, []byte{0x03}, nil).Once()
+		authorizer.EXPECT().KDFParams().Return(params).Once()
+
+		created := time.Unix(500, 0)
+		v := &vault.Vault{Envs: []*vault.Env{
+			{
+				Name: "base",
+				Slots: []vault.Slot{
+					{Role: "dev", Block: []byte{0x02}},
+					{Role: "admin", Created: created, Block: []byte{0x01}},
+				},
+			},
+			{Name: "prod"},
+		}}
+		expSlots := []vault.Slot{
+			{Role: "dev", Block: []byte{0x02}},
+			{Role: "admin", KDF: params, Created: created, Block: []byte{0x03}},
+		}
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		err := keeper.Passwd(iv, v, "admin", passphrase, newPassphrase)
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, expSlots, v.Envs[0].Slots)
+		assert.Zero(t, accessKey.Len())
+	})
+}
+
+func Test_Keeper_AddRole(t *testing.T) {
+	t.Parallel()
+	passphrase := newSecret([]byte("+DF7Rc-X/MOYjkNj")...)
+	newPassphrase := newSecret([]byte("q7!Lw2#zR9@pXe4v")...)
+
+	t.Run("ErrRoleExists error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		v := &vault.Vault{Envs: []*vault.Env{{
+			Name: "prod",
+			Slots: []vault.Slot{
+				{Role: "admin", Block: []byte{0x01}},
+				{Role: "dev", Block: []byte{0x02}},
+			},
+		}}}
+		const expErr = vault.ErrRoleExists
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		err := keeper.AddRole(
+			nil, v, "prod", "admin", passphrase, "dev", newPassphrase)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrSlotNotFound error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		v := &vault.Vault{Envs: []*vault.Env{{Name: "prod"}}}
+		const expErr = vault.ErrSlotNotFound
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		err := keeper.AddRole(
+			nil, v, "prod", "admin", passphrase, "dev", newPassphrase)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrAuthFailed error", func(t *testing.T) {
+		t.Parallel()
+		iv := cmock.NewIV(t)
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		authorizer.EXPECT().
+			Inherit(iv, crypto.KDFParams{}, passphrase, newPassphrase, []byte{0x01}).
+			Return(newSecret(0x11), []byte{0x03}, nil).Once()
+		authorizer.EXPECT().KDFParams().Return(params).Once()
+		authorizer.EXPECT().
+			Inherit(iv, crypto.KDFParams{}, passphrase, newPassphrase, []byte{0x02}).
+			Return(nil, nil, crypto.ErrAuthFailed).Once()
+
+		v := &vault.Vault{Envs: []*vault.Env{
+			{
+				Name:  "base",
+				Slots: []vault.Slot{{Role: "admin", Block: []byte{0x01}}},
+			},
+			{
+				Name:   "prod",
+				Parent: "base",
+				Slots:  []vault.Slot{{Role: "admin", Block: []byte{0x02}}},
+			},
+		}}
+		expSlots := []vault.Slot{{Role: "admin", Block: []byte{0x01}}}
+		const expErr = crypto.ErrAuthFailed
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		err := keeper.AddRole(
+			iv, v, "prod", "admin", passphrase, "dev", newPassphrase)
+		assert.ErrorIs(t, err, expErr)
+		assert.Equal(t, expSlots, v.Envs[0].Slots)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		iv := cmock.NewIV(t)
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		accessKey := newSecret(0x11)
+		authorizer.EXPECT().
+			Inherit(iv, crypto.KDFParams{}, passphrase, newPassphrase, []byte{0x01}).
+			Return(accessKey, []byte{0x03}, nil).Once()
+		authorizer.EXPECT().Kind().Return("passphrase").Once()
+		authorizer.EXPECT().KDFParams().Return(params).Twice()
+
+		v := &vault.Vault{Envs: []*vault.Env{
+			{
+				Name:  "base",
+				Slots: []vault.Slot{{Role: "admin", Block: []byte{0x01}}},
+			},
+			{
+				Name:  "prod",
+				Slots: []vault.Slot{{Role: "admin", Block: []byte{0x02}}},
+			},
+		}}
+		expSlots := []vault.Slot{
+			{Role: "admin", Block: []byte{0x01}},
+			{
+				Role:    "dev",
+				Kind:    "passphrase",
+				KDF:     params,
+				Created: now,
+				Block:   []byte{0x03},
+			},
+		}
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		err := keeper.AddRole(
+			iv, v, "base", "admin", passphrase, "dev", newPassphrase)
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, expSlots, v.Envs[0].Slots)
+		assert.Len(t, v.Envs[1].Slots, 1)
+		assert.Zero(t, accessKey.Len())
+	})
+}
+
+func Test_Keeper_AddRecipient(t *testing.T) {
+	t.Parallel()
+	passphrase := newSecret([]byte("+DF7Rc-X/MOYjkNj")...)
+	publicKey := []byte{0x41, 0x42}
+
+	t.Run("ErrRoleExists error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		recipient := cmock.NewRecipient(t)
+		v := &vault.Vault{Envs: []*vault.Env{{
+			Name: "prod",
+			Slots: []vault.Slot{
+				{Role: "admin", Block: []byte{0x01}},
+				{Role: "ci", Block: []byte{0x02}},
+			},
+		}}}
+		const expErr = vault.ErrRoleExists
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		err := keeper.AddRecipient(
+			nil, v, "prod", "admin", passphrase, "ci", recipient, publicKey)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrSlotNotFound error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		recipient := cmock.NewRecipient(t)
+		v := &vault.Vault{Envs: []*vault.Env{{Name: "prod"}}}
+		const expErr = vault.ErrSlotNotFound
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		err := keeper.AddRecipient(
+			nil, v, "prod", "admin", passphrase, "ci", recipient, publicKey)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrInvalidRecipient error", func(t *testing.T) {
+		t.Parallel()
+		iv := cmock.NewIV(t)
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		recipient := cmock.NewRecipient(t)
+		accessKey := newSecret(0x11)
+		authorizer.EXPECT().Open(crypto.KDFParams{}, passphrase, []byte{0x01}).
+			Return(accessKey, nil).Once()
+		recipient.EXPECT().Wrap(iv, publicKey, accessKey).
+			Return(nil, crypto.ErrInvalidRecipient).Once()
+
+		v := &vault.Vault{Envs: []*vault.Env{{
+			Name:  "prod",
+			Slots: []vault.Slot{{Role: "admin", Block: []byte{0x01}}},
+		}}}
+		expSlots := []vault.Slot{{Role: "admin", Block: []byte{0x01}}}
+		const expErr = crypto.ErrInvalidRecipient
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		err := keeper.AddRecipient(
+			iv, v, "prod", "admin", passphrase, "ci", recipient, publicKey)
+		assert.ErrorIs(t, err, expErr)
+		assert.Equal(t, expSlots, v.Envs[0].Slots)
+		assert.Zero(t, accessKey.Len())
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		iv := cmock.NewIV(t)
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		recipient := cmock.NewRecipient(t)
+		accessKey := newSecret(0x11)
+		authorizer.EXPECT().Open(crypto.KDFParams{}, passphrase, []byte{0x01}).
+			Return(accessKey, nil).Once()
+		recipient.EXPECT().Wrap(iv, publicKey, accessKey).
+			Return([]byte{0x03}, nil).Once()
+		recipient.EXPECT().Kind().Return("x25519-mlkem768").Once()
+
+		v := &vault.Vault{Envs: []*vault.Env{
+			{
+				Name:  "base",
+				Slots: []vault.Slot{{Role: "admin", Block: []byte{0x01}}},
+			},
+			{
+				Name:  "prod",
+				Slots: []vault.Slot{{Role: "admin", Block: []byte{0x02}}},
+			},
+		}}
+		expSlots := []vault.Slot{
+			{Role: "admin", Block: []byte{0x01}},
+			{
+				Role:      "ci",
+				Kind:      "x25519-mlkem768",
+				Created:   now,
+				Block:     []byte{0x03},
+				PublicKey: publicKey,
+			},
+		}
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		err := keeper.AddRecipient(
+			iv, v, "base", "admin", passphrase, "ci", recipient, publicKey)
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, expSlots, v.Envs[0].Slots)
+		assert.Len(t, v.Envs[1].Slots, 1)
+		assert.Zero(t, accessKey.Len())
+	})
+}
+
+func Test_Keeper_Rotate(t *testing.T) {
+	t.Parallel()
+	const keyLen = 32
+	passphrase := newSecret([]byte("+DF7Rc-X/MOYjkNj")...)
+
+	t.Run("ErrEnvNotFound error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		v := &vault.Vault{}
+		var expDropped []string = nil
+		const expErr = vault.ErrEnvNotFound
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		dropped, err := keeper.Rotate(nil, v, "prod", "admin", passphrase)
+		assert.Equal(t, expDropped, dropped)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrSlotNotFound error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		v := &vault.Vault{Envs: []*vault.Env{{Name: "prod"}}}
+		var expDropped []string = nil
+		const expErr = vault.ErrSlotNotFound
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		dropped, err := keeper.Rotate(nil, v, "prod", "admin", passphrase)
+		assert.Equal(t, expDropped, dropped)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrAuthFailed error", func(t *testing.T) {
+		t.Parallel()
+		iv := cmock.NewIV(t)
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		accessKey := newSecret(0x11)
+		key := newSecret(0x21)
+		newAccessKey := newSecret(0x12)
+		newKey := newSecret(0x22)
+		cipher.EXPECT().KeyLen().Return(keyLen).Times(3)
+		authorizer.EXPECT().Open(crypto.KDFParams{}, passphrase, []byte{0x01}).
+			Return(accessKey, nil).Once()
+		kdf.EXPECT().Key(accessKey, []byte("prod"), uint32(keyLen)).
+			Return(key, nil).Once()
+		authorizer.EXPECT().Make(iv, passphrase, uint32(keyLen)).
+			Return(newAccessKey, []byte{0x02}, nil).Once()
+		kdf.EXPECT().Key(newAccessKey, []byte("prod"), uint32(keyLen)).
+			Return(newKey, nil).Once()
+		cipher.EXPECT().Open(key, []byte{0x31}, []byte("prod\x00DB_PASS")).
+			Return(nil, crypto.ErrAuthFailed).Once()
+
+		slots := []vault.Slot{{Role: "admin", Block: []byte{0x01}}}
+		entries := []vault.Entry{{Name: "DB_PASS", Buf: []byte{0x31}}}
+		v := &vault.Vault{Envs: []*vault.Env{{
+			Name:    "prod",
+			Slots:   slots,
+			Entries: entries,
+		}}}
+		var expDropped []string = nil
+		const expErr = crypto.ErrAuthFailed
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		dropped, err := keeper.Rotate(iv, v, "prod", "admin", passphrase)
+		assert.Equal(t, expDropped, dropped)
+		assert.ErrorIs(t, err, expErr)
+		assert.Equal(t, slots, v.Envs[0].Slots)
+		assert.Equal(t, entries, v.Envs[0].Entries)
+		assert.Zero(t, key.Len())
+		assert.Zero(t, newKey.Len())
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		iv := cmock.NewIV(t)
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		accessKey := newSecret(0x11)
+		key := newSecret(0x21)
+		newAccessKey := newSecret(0x12)
+		newKey := newSecret(0x22)
+		cipher.EXPECT().KeyLen().Return(keyLen).Times(3)
+		authorizer.EXPECT().Open(crypto.KDFParams{}, passphrase, []byte{0x01}).
+			Return(accessKey, nil).Once()
+		kdf.EXPECT().Key(accessKey, []byte("prod"), uint32(keyLen)).
+			Return(key, nil).Once()
+		authorizer.EXPECT().Make(iv, passphrase, uint32(keyLen)).
+			Return(newAccessKey, []byte{0x02}, nil).Once()
+		kdf.EXPECT().Key(newAccessKey, []byte("prod"), uint32(keyLen)).
+			Return(newKey, nil).Once()
+		cipher.EXPECT().Open(key, []byte{0x31}, []byte("prod\x00DB_PASS")).
+			Return(newSecret(), nil).Once()
+		cipher.EXPECT().Seal(iv, newKey, []byte{}, []byte("prod\x00DB_PASS")).
+			Return([]byte{0x32}, nil).Once()
+		oldOwner := &vault.Env{Slots: []vault.Slot{{Role: "dev"}}}
+		newOwner := &vault.Env{Slots: []vault.Slot{{Role: "admin"}}}
+		cipher.EXPECT().Open(key, []byte{0x51}, vault.StampAD("id", "prod", 3,
+			(&vault.Vault{}).SettingsDigest(oldOwner))).
+			Return(newSecret(), nil).Once()
+		cipher.EXPECT().Seal(iv, newKey, []byte{}, vault.StampAD("id", "prod", 3,
+			(&vault.Vault{}).SettingsDigest(newOwner))).
+			Return([]byte{0x52}, nil).Once()
+		authorizer.EXPECT().Kind().Return("passphrase").Once()
+		authorizer.EXPECT().KDFParams().Return(params).Once()
+		recipient := cmock.NewRecipient(t)
+		recipient.EXPECT().Kind().Return("x25519-mlkem768").Once()
+		recipient.EXPECT().Wrap(iv, []byte{0x41}, newAccessKey).
+			Return([]byte{0x05}, nil).Once()
+
+		v := &vault.Vault{ID: "id", Envs: []*vault.Env{{
+			Name:       "prod",
+			Generation: 3,
+			Stamp:      []byte{0x51},
+			Slots: []vault.Slot{
+				{Role: "dev", Block: []byte{0x03}},
+				{Role: "admin", Block: []byte{0x01}},
+				{
+					Role:      "ci",
+					Kind:      "x25519-mlkem768",
+					Block:     []byte{0x04},
+					PublicKey: []byte{0x41},
+				},
+			},
+			Entries: []vault.Entry{{Name: "DB_PASS", Buf: []byte{0x31}}},
+		}}}
+		expDropped := []string{"dev"}
+		expSlots := []vault.Slot{
+			{
+				Role:    "admin",
+				Kind:    "passphrase",
+				KDF:     params,
+				Created: now,
+				Block:   []byte{0x02},
+			},
+			{
+				Role:      "ci",
+				Kind:      "x25519-mlkem768",
+				Block:     []byte{0x05},
+				PublicKey: []byte{0x41},
+			},
+		}
+		expEntries := []vault.Entry{{Name: "DB_PASS", Buf: []byte{0x32}}}
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		dropped, err := keeper.Rotate(
+			iv, v, "prod", "admin", passphrase, recipient)
+		assert.Equal(t, expDropped, dropped)
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, expSlots, v.Envs[0].Slots)
+		assert.Equal(t, expEntries, v.Envs[0].Entries)
+		assert.Equal(t, []byte{0x52}, v.Envs[0].Stamp)
+		assert.Zero(t, key.Len())
+		assert.Zero(t, newKey.Len())
+	})
+}
+
+func Test_Keeper_RemoveRole(t *testing.T) {
+	t.Parallel()
+	const keyLen = 32
+	passphrase := newSecret([]byte("+DF7Rc-X/MOYjkNj")...)
+
+	t.Run("ErrSlotNotFound error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		slots := []vault.Slot{
+			{Role: "ops", Block: []byte{0x04}},
+			{Role: "dev", Block: []byte{0x03}},
+		}
+		v := &vault.Vault{Envs: []*vault.Env{{Name: "prod", Slots: slots}}}
+		expReport := vault.RemoveReport{}
+		const expErr = vault.ErrSlotNotFound
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		report, err := keeper.RemoveRole(
+			nil, v, "admin", passphrase, "dev", true)
+		assert.Equal(t, expReport, report)
+		assert.ErrorIs(t, err, expErr)
+		report, err = keeper.RemoveRole(
+			nil, v, "admin", passphrase, "dev", false)
+		assert.Equal(t, expReport, report)
+		assert.ErrorIs(t, err, expErr)
+		report, err = keeper.RemoveRole(
+			nil, v, "dev", passphrase, "dev", true)
+		assert.Equal(t, expReport, report)
+		assert.ErrorIs(t, err, expErr)
+		assert.Equal(t, slots, v.Envs[0].Slots)
+	})
+	t.Run("ErrNotOwner error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		slots := []vault.Slot{
+			{Role: "admin", Block: []byte{0x01}},
+			{Role: "dev", Block: []byte{0x03}},
+		}
+		v := &vault.Vault{Envs: []*vault.Env{{Name: "prod", Slots: slots}}}
+		expReport := vault.RemoveReport{}
+		const expErr = vault.ErrNotOwner
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		report, err := keeper.RemoveRole(
+			nil, v, "dev", passphrase, "admin", false)
+		assert.Equal(t, expReport, report)
+		assert.ErrorIs(t, err, expErr)
+		assert.Equal(t, slots, v.Envs[0].Slots)
+	})
+	t.Run("ErrLastSlot error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		v := &vault.Vault{Envs: []*vault.Env{
+			{
+				Name: "base",
+				Slots: []vault.Slot{
+					{Role: "admin", Block: []byte{0x01}},
+					{Role: "dev", Block: []byte{0x02}},
+				},
+			},
+			{
+				Name:  "dev",
+				Slots: []vault.Slot{{Role: "dev", Block: []byte{0x03}}},
+			},
+		}}
+		expReport := vault.RemoveReport{}
+		const expErr = vault.ErrLastSlot
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		report, err := keeper.RemoveRole(
+			nil, v, "admin", passphrase, "dev", false)
+		assert.Equal(t, expReport, report)
+		assert.ErrorIs(t, err, expErr)
+		assert.Len(t, v.Envs[0].Slots, 2)
+	})
+	t.Run("ErrAuthFailed error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		authorizer.EXPECT().Open(crypto.KDFParams{}, passphrase, []byte{0x01}).
+			Return(nil, crypto.ErrAuthFailed).Once()
+
+		slots := []vault.Slot{
+			{Role: "admin", Block: []byte{0x01}},
+			{Role: "dev", Block: []byte{0x03}},
+		}
+		v := &vault.Vault{Envs: []*vault.Env{{Name: "prod", Slots: slots}}}
+		expReport := vault.RemoveReport{}
+		const expErr = crypto.ErrAuthFailed
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		report, err := keeper.RemoveRole(
+			nil, v, "admin", passphrase, "dev", false)
+		assert.Equal(t, expReport, report)
+		assert.ErrorIs(t, err, expErr)
+		assert.Equal(t, slots, v.Envs[0].Slots)
+	})
+	t.Run("Without rotation", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		accessKey := newSecret(0x11)
+		key := newSecret(0x21)
+		authorizer.EXPECT().Open(crypto.KDFParams{}, passphrase, []byte{0x01}).
+			Return(accessKey, nil).Once()
+		cipher.EXPECT().KeyLen().Return(keyLen).Once()
+		kdf.EXPECT().Key(accessKey, []byte("prod"), uint32(keyLen)).
+			Return(key, nil).Once()
+
+		v := &vault.Vault{Envs: []*vault.Env{{
+			Name: "prod",
+			Slots: []vault.Slot{
+				{Role: "admin", Block: []byte{0x01}},
+				{Role: "dev", Block: []byte{0x03}},
+			},
+		}}}
+		expReport := vault.RemoveReport{
+			Envs:    []string{"prod"},
+			Dropped: []string{},
+			Warning: vault.RemoveRoleWarning,
+		}
+		expSlots := []vault.Slot{{Role: "admin", Block: []byte{0x01}}}
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		report, err := keeper.RemoveRole(
+			nil, v, "admin", passphrase, "dev", false)
+		assert.Equal(t, expReport, report)
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, expSlots, v.Envs[0].Slots)
+		assert.Zero(t, key.Len())
+	})
+	t.Run("Owner leaves", func(t *testing.T) {
+		t.Parallel()
+		iv := cmock.NewIV(t)
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		accessKey := newSecret(0x11)
+		key := newSecret(0x21)
+		newOwner := &vault.Env{Slots: []vault.Slot{{Role: "dev"}}}
+		authorizer.EXPECT().Open(crypto.KDFParams{}, passphrase, []byte{0x01}).
+			Return(accessKey, nil).Once()
+		cipher.EXPECT().KeyLen().Return(keyLen).Once()
+		kdf.EXPECT().Key(accessKey, []byte("prod"), uint32(keyLen)).
+			Return(key, nil).Once()
+		cipher.EXPECT().Seal(iv, key, []byte{}, vault.StampAD("id", "prod", 4,
+			(&vault.Vault{}).SettingsDigest(newOwner))).
+			Return([]byte{0x52}, nil).Once()
+
+		v := &vault.Vault{ID: "id", Envs: []*vault.Env{{
+			Name: "prod",
+			Slots: []vault.Slot{
+				{Role: "admin", Block: []byte{0x01}},
+				{Role: "dev", Block: []byte{0x03}},
+			},
+			Generation: 4,
+			Stamp:      []byte{0x51},
+		}}}
+		expReport := vault.RemoveReport{
+			Envs:    []string{"prod"},
+			Dropped: []string{},
+			Warning: vault.RemoveRoleWarning,
+		}
+		expSlots := []vault.Slot{{Role: "dev", Block: []byte{0x03}}}
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		report, err := keeper.RemoveRole(
+			iv, v, "admin", passphrase, "admin", false)
+		assert.Equal(t, expReport, report)
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, expSlots, v.Envs[0].Slots)
+		assert.Equal(t, []byte{0x52}, v.Envs[0].Stamp)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		iv := cmock.NewIV(t)
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		cipher.EXPECT().KeyLen().Return(keyLen).Times(6)
+		authorizer.EXPECT().Kind().Return("passphrase").Times(6)
+		authorizer.EXPECT().KDFParams().Return(params).Times(2)
+		for i, name := range []string{"base", "prod"} {
+			accessKey := newSecret(0x11)
+			key := newSecret(0x21)
+			newAccessKey := newSecret(0x12)
+			newKey := newSecret(0x22)
+			authorizer.EXPECT().Open(crypto.KDFParams{}, passphrase, []byte{byte(i)}).
+				Return(accessKey, nil).Once()
+			kdf.EXPECT().Key(accessKey, []byte(name), uint32(keyLen)).
+				Return(key, nil).Once()
+			authorizer.EXPECT().Make(iv, passphrase, uint32(keyLen)).
+				Return(newAccessKey, []byte{byte(i + 2)}, nil).Once()
+			kdf.EXPECT().Key(newAccessKey, []byte(name), uint32(keyLen)).
+				Return(newKey, nil).Once()
+		}
+
+		v := &vault.Vault{Envs: []*vault.Env{
+			{
+				Name: "base",
+				Slots: []vault.Slot{
+					{Role: "admin", Kind: "passphrase", Block: []byte{0x00}},
+					{Role: "dev", Block: []byte{0x04}},
+					{Role: "ops", Block: []byte{0x05}},
+				},
+			},
+			{
+				Name: "prod",
+				Slots: []vault.Slot{
+					{Role: "admin", Kind: "passphrase", Block: []byte{0x01}},
+					{Role: "dev", Block: []byte{0x06}},
+					{Role: "ops", Block: []byte{0x07}},
+				},
+			},
+			{
+				Name:  "dev",
+				Slots: []vault.Slot{{Role: "ops", Block: []byte{0x08}}},
+			},
+		}}
+		expReport := vault.RemoveReport{
+			Envs:    []string{"base", "prod"},
+			Dropped: []string{"ops"},
+		}
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		report, err := keeper.RemoveRole(
+			iv, v, "admin", passphrase, "dev", true)
+		assert.Equal(t, expReport, report)
+		assert.ErrorIs(t, err, nil)
+		for i, e := range v.Envs[:2] {
+			expSlots := []vault.Slot{{
+				Role:    "admin",
+				Kind:    "passphrase",
+				KDF:     params,
+				Created: now,
+				Block:   []byte{byte(i + 2)},
+			}}
+			assert.Equal(t, expSlots, e.Slots)
+		}
+		assert.Len(t, v.Envs[2].Slots, 1)
+	})
+}
+
+func Test_Keeper_Set(t *testing.T) {
+	t.Parallel()
+	key := newSecret(0x21)
+	variable := env.Var{Name: "DB_PASS", Value: "s3cr3t"}
+
+	t.Run("ErrInvalidVarName error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		v := &vault.Vault{Envs: []*vault.Env{{Name: "prod"}}}
+		const expErr = env.ErrInvalidVarName
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		err := keeper.Set(nil, v, vault.Keyring{"prod": key},
+			"prod", "admin", env.Var{Name: "DB PASS"})
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrPatternMismatch error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		v := &vault.Vault{
+			Schema: env.Schema{Rules: []env.Rule{{
+				Name:    "DB_PASS",
+				Type:    env.TypePattern,
+				Pattern: ".{12,}",
+			}}},
+			Envs: []*vault.Env{{Name: "prod"}},
+		}
+		const expErr = env.ErrPatternMismatch
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		err := keeper.Set(nil, v, vault.Keyring{"prod": key},
+			"prod", "admin", variable)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Interpolated value", func(t *testing.T) {
+		t.Parallel()
+		iv := cmock.NewIV(t)
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		variable := env.Var{
+			Name:        "DB_PORT",
+			Value:       "${PORT:-5432}",
+			Interpolate: true,
+		}
+		cipher.EXPECT().Seal(iv, key, []byte(variable.Value),
+			[]byte("prod\x00DB_PORT\x00template")).
+			Return([]byte{0x31}, nil).Once()
+
+		v := &vault.Vault{
+			Schema: env.Schema{Rules: []env.Rule{{
+				Name: "DB_PORT",
+				Type: env.TypePort,
+			}}},
+			Envs: []*vault.Env{{Name: "prod"}},
+		}
+		expEntries := []vault.Entry{{
+			Name: "DB_PORT",
+			Kind: vault.EntryKindTemplate,
+			Buf:  []byte{0x31},
+		}}
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		err := keeper.Set(iv, v, vault.Keyring{"prod": key},
+			"prod", "admin", variable)
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, expEntries, v.Envs[0].Entries)
+	})
+	t.Run("ErrInvalidPort literal", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		v := &vault.Vault{
+			Schema: env.Schema{Rules: []env.Rule{{
+				Name: "DB_PORT",
+				Type: env.TypePort,
+			}}},
+			Envs: []*vault.Env{{Name: "prod"}},
+		}
+		const expErr = env.ErrInvalidPort
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		err := keeper.Set(nil, v, vault.Keyring{"prod": key}, "prod", "admin",
+			env.Var{Name: "DB_PORT", Value: "${PORT:-5432}"})
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrSlotNotFound error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		v := &vault.Vault{Envs: []*vault.Env{{Name: "prod"}}}
+		const expErr = vault.ErrSlotNotFound
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		err := keeper.Set(nil, v, vault.Keyring{"dev": key},
+			"prod", "admin", variable)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrInvalidIVLen error", func(t *testing.T) {
+		t.Parallel()
+		iv := cmock.NewIV(t)
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		cipher.EXPECT().Seal(iv, key,
+			[]byte(variable.Value), []byte("prod\x00DB_PASS")).
+			Return(nil, crypto.ErrInvalidIVLen).Once()
+
+		v := &vault.Vault{Envs: []*vault.Env{{Name: "prod"}}}
+		const expErr = crypto.ErrInvalidIVLen
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		err := keeper.Set(iv, v, vault.Keyring{"prod": key},
+			"prod", "admin", variable)
+		assert.ErrorIs(t, err, expErr)
+		assert.Empty(t, v.Envs[0].Entries)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		iv := cmock.NewIV(t)
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		buf := []byte{0x31}
+		cipher.EXPECT().Seal(iv, key,
+			[]byte(variable.Value), []byte("prod\x00DB_PASS")).
+			Return(buf, nil).Once()
+
+		v := &vault.Vault{Envs: []*vault.Env{{Name: "prod"}}}
+		expEntries := []vault.Entry{{Name: "DB_PASS", Buf: buf}}
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		err := keeper.Set(iv, v, vault.Keyring{"prod": key},
+			"prod", "admin", variable)
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, expEntries, v.Envs[0].Entries)
+	})
+	t.Run("Audit record", func(t *testing.T) {
+		t.Parallel()
+		iv := cmock.NewIV(t)
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		auditKey := newSecret()
+		cipher.EXPECT().Seal(iv, key,
+			[]byte(variable.Value), []byte("prod\x00DB_PASS")).
+			Return([]byte{0x31}, nil).Once()
+		kdf.EXPECT().Key(key, []byte(audit.KeySalt), uint32(audit.KeyLen)).
+			Return(auditKey, nil).Once()
+		cipher.EXPECT().Seal(iv, key, []byte{}, []byte("prod\x00")).
+			Return([]byte{0x42}, nil).Once()
+
+		v := &vault.Vault{Envs: []*vault.Env{{Name: "prod"}}}
+		var records []audit.Record
+		fn := fnKeeper
+		fn.Audit = func(key *crypto.Secret, record audit.Record) error {
+			assert.Equal(t, auditKey, key)
+			records = append(records, record)
+			return nil
+		}
+		expRecords := []audit.Record{{
+			Time:    now,
+			Role:    "admin",
+			Command: vault.OpSet,
+			Env:     "prod",
+			Vars:    []string{"DB_PASS"},
+			Result:  audit.ResultOK,
+		}}
+
+		keeper := NewKeeper(fn, authorizer, cipher, stream, kdf)
+		err := keeper.Set(iv, v, vault.Keyring{"prod": key},
+			"prod", "admin", variable)
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, expRecords, records)
+		assert.Equal(t, []byte{0x42}, v.Envs[0].Audit)
+		assert.Zero(t, auditKey.Len())
+	})
+}
+
+func Test_Keeper_AuditKeys(t *testing.T) {
+	t.Parallel()
+	key := newSecret(0x21)
+
+	t.Run("ErrEnvNotFound error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		v := &vault.Vault{}
+		var expKeys audit.Keys = nil
+		const expErr = vault.ErrEnvNotFound
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		keys, err := keeper.AuditKeys(v, vault.Keyring{"prod": key})
+		assert.Equal(t, expKeys, keys)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrAuthFailed error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		cipher.EXPECT().Open(key, []byte{0x42}, []byte("prod\x00")).
+			Return(nil, crypto.ErrAuthFailed).Once()
+
+		v := &vault.Vault{Envs: []*vault.Env{{
+			Name:  "prod",
+			Audit: []byte{0x42},
+		}}}
+		var expKeys audit.Keys = nil
+		const expErr = crypto.ErrAuthFailed
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		keys, err := keeper.AuditKeys(v, vault.Keyring{"prod": key})
+		assert.Equal(t, expKeys, keys)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		auditKey := newSecret(0x41)
+		cipher.EXPECT().Open(key, []byte{0x42}, []byte("prod\x00")).
+			Return(auditKey, nil).Once()
+
+		v := &vault.Vault{Envs: []*vault.Env{
+			{Name: "base"},
+			{Name: "prod", Audit: []byte{0x42}},
+		}}
+		expKeys := audit.Keys{"prod": {auditKey}}
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		keys, err := keeper.AuditKeys(
+			v, vault.Keyring{"base": key, "prod": key})
+		assert.Equal(t, expKeys, keys)
+		assert.ErrorIs(t, err, nil)
+	})
+	t.Run("Retired keys", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		retired := bytes.Repeat([]byte{0x43}, 2*audit.KeyLen)
+		auditKey := newSecret(0x41)
+		cipher.EXPECT().Open(key, []byte{0x44}, []byte("prod\x00audit-retired")).
+			Return(newSecret(retired...), nil).Once()
+		cipher.EXPECT().Open(key, []byte{0x42}, []byte("prod\x00")).
+			Return(auditKey, nil).Once()
+
+		v := &vault.Vault{Envs: []*vault.Env{{
+			Name:         "prod",
+			Audit:        []byte{0x42},
+			RetiredAudit: []byte{0x44},
+		}}}
+		expRetired := bytes.Repeat([]byte{0x43}, audit.KeyLen)
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		keys, err := keeper.AuditKeys(v, vault.Keyring{"prod": key})
+		assert.Len(t, keys["prod"], 3)
+		assert.Equal(t, expRetired, keys["prod"][0].Bytes())
+		assert.Equal(t, expRetired, keys["prod"][1].Bytes())
+		assert.Equal(t, auditKey, keys["prod"][2])
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_Keeper_Record(t *testing.T) {
+	t.Parallel()
+	key := newSecret(0x21)
+
+	t.Run("ErrSlotNotFound error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		v := &vault.Vault{Envs: []*vault.Env{{Name: "prod"}}}
+		const expErr = vault.ErrSlotNotFound
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		err := keeper.Record(nil, v, vault.Keyring{"dev": key},
+			"prod", "admin", vault.OpExport)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		auditKey := newSecret(0x41)
+		cipher.EXPECT().Open(key, []byte{0x42}, []byte("prod\x00")).
+			Return(auditKey, nil).Once()
+
+		v := &vault.Vault{Envs: []*vault.Env{
+			{Name: "prod", Audit: []byte{0x42}},
+		}}
+		var records []audit.Record
+		fn := fnKeeper
+		fn.Audit = func(key *crypto.Secret, record audit.Record) error {
+			assert.Equal(t, auditKey, key)
+			records = append(records, record)
+			return nil
+		}
+		expRecords := []audit.Record{{
+			Time:    now,
+			Role:    "admin",
+			Command: vault.OpExport,
+			Env:     "prod",
+			Result:  audit.ResultOK,
+		}}
+
+		keeper := NewKeeper(fn, authorizer, cipher, stream, kdf)
+		err := keeper.Record(nil, v, vault.Keyring{"prod": key},
+			"prod", "admin", vault.OpExport)
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, expRecords, records)
+	})
+}
+
+func Test_Keeper_Anchor(t *testing.T) {
+	t.Parallel()
+	key := newSecret(0x21)
+
+	t.Run("ErrOpenLogFailed error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		v := &vault.Vault{Envs: []*vault.Env{{Name: "prod"}}}
+		fn := fnKeeper
+		fn.Head = func() (audit.Head, error) {
+			return audit.Head{}, audit.ErrOpenLogFailed
+		}
+		const expErr = audit.ErrOpenLogFailed
+
+		keeper := NewKeeper(fn, authorizer, cipher, stream, kdf)
+		err := keeper.Anchor(nil, v, vault.Keyring{"prod": key})
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		iv := cmock.NewIV(t)
+		cipher.EXPECT().Seal(iv, key,
+			[]byte(`{"Records":2,"Hash":"AQ=="}`),
+			[]byte("prod\x00audit-head")).
+			Return([]byte{0x43}, nil).Once()
+
+		v := &vault.Vault{Envs: []*vault.Env{{Name: "prod"}}}
+		fn := fnKeeper
+		fn.Head = func() (audit.Head, error) {
+			return audit.Head{Records: 2, Hash: []byte{0x01}}, nil
+		}
+
+		keeper := NewKeeper(fn, authorizer, cipher, stream, kdf)
+		err := keeper.Anchor(iv, v, vault.Keyring{"prod": key})
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, []byte{0x43}, v.Envs[0].AuditHead)
+	})
+}
+
+func Test_Keeper_AuditHeads(t *testing.T) {
+	t.Parallel()
+	key := newSecret(0x21)
+
+	t.Run("ErrAuthFailed error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		cipher.EXPECT().Open(key, []byte{0x43},
+			[]byte("prod\x00audit-head")).
+			Return(nil, crypto.ErrAuthFailed).Once()
+
+		v := &vault.Vault{Envs: []*vault.Env{{
+			Name:      "prod",
+			AuditHead: []byte{0x43},
+		}}}
+		var expHeads []audit.Head = nil
+		const expErr = crypto.ErrAuthFailed
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		heads, err := keeper.AuditHeads(v, vault.Keyring{"prod": key})
+		assert.Equal(t, expHeads, heads)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		cipher.EXPECT().Open(key, []byte{0x43},
+			[]byte("prod\x00audit-head")).
+			Return(newSecret([]byte(`{"Records":2,"Hash":"AQ=="}`)...),
+				nil).Once()
+
+		v := &vault.Vault{Envs: []*vault.Env{
+			{Name: "base"},
+			{Name: "prod", AuditHead: []byte{0x43}},
+		}}
+		expHeads := []audit.Head{{Records: 2, Hash: []byte{0x01}}}
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		heads, err := keeper.AuditHeads(
+			v, vault.Keyring{"base": key, "prod": key})
+		assert.Equal(t, expHeads, heads)
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_Keeper_Stamp(t *testing.T) {
+	t.Parallel()
+	const id = "00112233445566778899aabbccddeeff"
+	key := newSecret(0x21)
+	settings := (&vault.Vault{}).SettingsDigest(&vault.Env{})
+
+	t.Run("ErrMissingVaultID error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		v := &vault.Vault{Envs: []*vault.Env{{Name: "prod"}}}
+		const expErr = vault.ErrMissingVaultID
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		err := keeper.Stamp(nil, v, vault.Keyring{"prod": key})
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrAllocSecretFailed error", func(t *testing.T) {
+		t.Parallel()
+		iv := cmock.NewIV(t)
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		cipher.EXPECT().Seal(iv, key, []byte{}, vault.StampAD(id, "prod", 3, settings)).
+			Return(nil, crypto.ErrAllocSecretFailed).Once()
+
+		v := &vault.Vault{
+			ID:         id,
+			Generation: 2,
+			Envs:       []*vault.Env{{Name: "prod"}},
+		}
+		expVault := &vault.Vault{
+			ID:         id,
+			Generation: 2,
+			Envs:       []*vault.Env{{Name: "prod"}},
+		}
+		const expErr = crypto.ErrAllocSecretFailed
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		err := keeper.Stamp(iv, v, vault.Keyring{"prod": key})
+		assert.ErrorIs(t, err, expErr)
+		assert.Equal(t, expVault, v)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		iv := cmock.NewIV(t)
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		cipher.EXPECT().Seal(iv, key, []byte{}, vault.StampAD(id, "prod", 6, settings)).
+			Return([]byte{0x51}, nil).Once()
+
+		v := &vault.Vault{
+			ID:         id,
+			Generation: 2,
+			Envs: []*vault.Env{
+				{Name: "base", Generation: 5, Stamp: []byte{0x50}},
+				{Name: "prod"},
+			},
+		}
+		expEnvs := []*vault.Env{
+			{Name: "base", Generation: 5, Stamp: []byte{0x50}},
+			{Name: "prod", Generation: 6, Stamp: []byte{0x51}},
+		}
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		err := keeper.Stamp(iv, v, vault.Keyring{"prod": key})
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, uint64(6), v.Generation)
+		assert.Equal(t, expEnvs, v.Envs)
+	})
+}
+
+func Test_Keeper_Generations(t *testing.T) {
+	t.Parallel()
+	const id = "00112233445566778899aabbccddeeff"
+	key := newSecret(0x21)
+	settings := (&vault.Vault{}).SettingsDigest(&vault.Env{})
+
+	t.Run("ErrMissingVaultID error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		v := &vault.Vault{Envs: []*vault.Env{{Name: "prod"}}}
+		var expGenerations map[string]uint64 = nil
+		const expErr = vault.ErrMissingVaultID
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		generations, err := keeper.Generations(v, vault.Keyring{"prod": key})
+		assert.Equal(t, expGenerations, generations)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrAuthFailed error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		cipher.EXPECT().
+			Open(key, []byte{0x51}, vault.StampAD(id, "prod", 9, settings)).
+			Return(nil, crypto.ErrAuthFailed).Once()
+
+		v := &vault.Vault{ID: id, Envs: []*vault.Env{
+			{Name: "prod", Generation: 9, Stamp: []byte{0x51}},
+		}}
+		var expGenerations map[string]uint64 = nil
+		const expErr = crypto.ErrAuthFailed
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		generations, err := keeper.Generations(v, vault.Keyring{"prod": key})
+		assert.Equal(t, expGenerations, generations)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		cipher.EXPECT().
+			Open(key, []byte{0x51}, vault.StampAD(id, "prod", 6, settings)).
+			Return(newSecret(), nil).Once()
+
+		v := &vault.Vault{ID: id, Generation: 9, Envs: []*vault.Env{
+			{Name: "base"},
+			{Name: "prod", Generation: 6, Stamp: []byte{0x51}},
+		}}
+		expGenerations := map[string]uint64{"base": 0, "prod": 6}
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		generations, err := keeper.Generations(
+			v, vault.Keyring{"base": key, "prod": key})
+		assert.Equal(t, expGenerations, generations)
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_Keeper_Import(t *testing.T) {
+	t.Parallel()
+	key := newSecret(0x21)
+	vars := []env.Var{
+		{Name: "DB_PASS", Value: "s3cr3t"},
+		{Name: "DB_USER", Value: "app"},
+	}
+
+	t.Run("ErrInvalidVarName error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		v := &vault.Vault{Envs: []*vault.Env{{Name: "prod"}}}
+		expReport := vault.ImportReport{}
+		const expErr = env.ErrInvalidVarName
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		report, err := keeper.Import(nil, v, vault.Keyring{"prod": key},
+			"prod", "admin", append(vars, env.Var{Name: "DB HOST"}))
+		assert.Equal(t, expReport, report)
+		assert.ErrorIs(t, err, expErr)
+		assert.Empty(t, v.Envs[0].Entries)
+	})
+	t.Run("ErrInvalidPort error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		v := &vault.Vault{
+			Schema: env.Schema{Rules: []env.Rule{{
+				Name: "DB_PORT",
+				Type: env.TypePort,
+			}}},
+			Envs: []*vault.Env{{Name: "prod"}},
+		}
+		expReport := vault.ImportReport{}
+		const expErr = env.ErrInvalidPort
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		report, err := keeper.Import(nil, v, vault.Keyring{"prod": key},
+			"prod", "admin", []env.Var{
+				{Name: "DB_HOST", Value: "${HOST}", Interpolate: true},
+				{Name: "DB_PORT", Value: "${PORT}x", Interpolate: true},
+				{Name: "DB_PORT", Value: "http"},
+			})
+		assert.Equal(t, expReport, report)
+		assert.ErrorIs(t, err, expErr)
+		assert.Empty(t, v.Envs[0].Entries)
+	})
+	t.Run("ErrEnvNotFound error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		v := &vault.Vault{}
+		expReport := vault.ImportReport{}
+		const expErr = vault.ErrEnvNotFound
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		report, err := keeper.Import(
+			nil, v, vault.Keyring{"prod": key}, "prod", "admin", vars)
+		assert.Equal(t, expReport, report)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrSlotNotFound error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		v := &vault.Vault{Envs: []*vault.Env{{Name: "prod"}}}
+		expReport := vault.ImportReport{}
+		const expErr = vault.ErrSlotNotFound
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		report, err := keeper.Import(
+			nil, v, vault.Keyring{"dev": key}, "prod", "admin", vars)
+		assert.Equal(t, expReport, report)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrInvalidIVLen error", func(t *testing.T) {
+		t.Parallel()
+		iv := cmock.NewIV(t)
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		cipher.EXPECT().Seal(iv, key,
+			[]byte(vars[0].Value), []byte("prod\x00DB_PASS")).
+			Return([]byte{0x31}, nil).Once()
+		cipher.EXPECT().Seal(iv, key,
+			[]byte(vars[1].Value), []byte("prod\x00DB_USER")).
+			Return(nil, crypto.ErrInvalidIVLen).Once()
+
+		v := &vault.Vault{Envs: []*vault.Env{{Name: "prod"}}}
+		expReport := vault.ImportReport{}
+		const expErr = crypto.ErrInvalidIVLen
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		report, err := keeper.Import(
+			iv, v, vault.Keyring{"prod": key}, "prod", "admin", vars)
+		assert.Equal(t, expReport, report)
+		assert.ErrorIs(t, err, expErr)
+		assert.Empty(t, v.Envs[0].Entries)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		iv := cmock.NewIV(t)
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		cipher.EXPECT().Seal(iv, key,
+			[]byte(vars[0].Value), []byte("prod\x00DB_PASS")).
+			Return([]byte{0x31}, nil).Once()
+		cipher.EXPECT().Seal(iv, key,
+			[]byte(vars[1].Value), []byte("prod\x00DB_USER")).
+			Return([]byte{0x32}, nil).Once()
+
+		v := &vault.Vault{Envs: []*vault.Env{{
+			Name:    "prod",
+			Entries: []vault.Entry{{Name: "DB_USER", Buf: []byte{0x30}}},
+		}}}
+		expReport := vault.ImportReport{
+			Env:     "prod",
+			Added:   []string{"DB_PASS"},
+			Updated: []string{"DB_USER"},
+		}
+		expEntries := []vault.Entry{
+			{Name: "DB_USER", Buf: []byte{0x32}},
+			{Name: "DB_PASS", Buf: []byte{0x31}},
+		}
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		report, err := keeper.Import(
+			iv, v, vault.Keyring{"prod": key}, "prod", "admin", vars)
+		assert.Equal(t, expReport, report)
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, expEntries, v.Envs[0].Entries)
+	})
+	t.Run("Interpolated value", func(t *testing.T) {
+		t.Parallel()
+		iv := cmock.NewIV(t)
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		vars := []env.Var{
+			{Name: "DB_URL", Value: "${HOST}:5432", Interpolate: true},
+			{Name: "DB_PASS", Value: "pa$${PASS}"},
+		}
+		cipher.EXPECT().Seal(iv, key, []byte(vars[0].Value),
+			[]byte("prod\x00DB_URL\x00template")).
+			Return([]byte{0x31}, nil).Once()
+		cipher.EXPECT().Seal(iv, key,
+			[]byte(vars[1].Value), []byte("prod\x00DB_PASS")).
+			Return([]byte{0x32}, nil).Once()
+
+		v := &vault.Vault{Envs: []*vault.Env{{Name: "prod"}}}
+		expReport := vault.ImportReport{
+			Env:     "prod",
+			Added:   []string{"DB_URL", "DB_PASS"},
+			Updated: []string{},
+		}
+		expEntries := []vault.Entry{
+			{Name: "DB_URL", Kind: vault.EntryKindTemplate, Buf: []byte{0x31}},
+			{Name: "DB_PASS", Buf: []byte{0x32}},
+		}
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		report, err := keeper.Import(
+			iv, v, vault.Keyring{"prod": key}, "prod", "admin", vars)
+		assert.Equal(t, expReport, report)
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, expEntries, v.Envs[0].Entries)
+	})
+}
+
+func Test_Keeper_SetFile(t *testing.T) {
+	t.Parallel()
+	key := newSecret(0x21)
+	const content = `{"type":"service_account"}`
+
+	t.Run("ErrInvalidVarName error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		v := &vault.Vault{Envs: []*vault.Env{{Name: "prod"}}}
+		const expErr = env.ErrInvalidVarName
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		err := keeper.SetFile(nil, v, vault.Keyring{"prod": key},
+			"prod", "admin", "GCP CREDENTIALS", strings.NewReader(content))
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrInvalidSchema error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		v := &vault.Vault{
+			Schema: env.Schema{Rules: []env.Rule{{
+				Name: "GCP_CREDENTIALS",
+				Type: env.TypeURL,
+			}}},
+			Envs: []*vault.Env{{Name: "prod"}},
+		}
+		const expErr = env.ErrInvalidSchema
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		err := keeper.SetFile(nil, v, vault.Keyring{"prod": key},
+			"prod", "admin", "GCP_CREDENTIALS", strings.NewReader(content))
+		assert.ErrorIs(t, err, expErr)
+		assert.Empty(t, v.Envs[0].Entries)
+	})
+	t.Run("ErrSlotNotFound error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		v := &vault.Vault{Envs: []*vault.Env{{Name: "prod"}}}
+		const expErr = vault.ErrSlotNotFound
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		err := keeper.SetFile(nil, v, vault.Keyring{"dev": key},
+			"prod", "admin", "GCP_CREDENTIALS", strings.NewReader(content))
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrInvalidIVLen error", func(t *testing.T) {
+		t.Parallel()
+		iv := cmock.NewIV(t)
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		stream.EXPECT().NewWriter(iv, key,
+			[]byte("prod\x00GCP_CREDENTIALS"), &bytes.Buffer{}).
+			Return(nil, crypto.ErrInvalidIVLen).Once()
+
+		v := &vault.Vault{Envs: []*vault.Env{{Name: "prod"}}}
+		const expErr = crypto.ErrInvalidIVLen
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		err := keeper.SetFile(iv, v, vault.Keyring{"prod": key},
+			"prod", "admin", "GCP_CREDENTIALS", strings.NewReader(content))
+		assert.ErrorIs(t, err, expErr)
+		assert.Empty(t, v.Envs[0].Entries)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		iv := cmock.NewIV(t)
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		stream.EXPECT().NewWriter(iv, key,
+			[]byte("prod\x00GCP_CREDENTIALS"), &bytes.Buffer{}).RunAndReturn(
+			func(_ crypto.IV, _ *crypto.Secret, _ []byte,
+				w io.Writer) (io.WriteCloser, error) {
+				return nopWriteCloser{Writer: w}, nil
+			}).Once()
+
+		v := &vault.Vault{Envs: []*vault.Env{{Name: "prod"}}}
+		expEntries := []vault.Entry{{
+			Name: "GCP_CREDENTIALS",
+			Kind: vault.EntryKindFile,
+			Buf:  []byte(content),
+		}}
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		err := keeper.SetFile(iv, v, vault.Keyring{"prod": key},
+			"prod", "admin", "GCP_CREDENTIALS", strings.NewReader(content))
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, expEntries, v.Envs[0].Entries)
+	})
+}
+
+func Test_Keeper_OpenFile(t *testing.T) {
+	t.Parallel()
+	key := newSecret(0x21)
+	v := &vault.Vault{Envs: []*vault.Env{{
+		Name: "prod",
+		Entries: []vault.Entry{
+			{Name: "DB_PASS", Buf: []byte{0x31}},
+			{Name: "GCP_CREDENTIALS", Kind: vault.EntryKindFile, Buf: []byte{0x32}},
+		},
+	}}}
+
+	t.Run("ErrNotFile error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		var expReader io.ReadCloser = nil
+		const expErr = vault.ErrNotFile
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		r, err := keeper.OpenFile(v, vault.Keyring{"prod": key},
+			"prod", "DB_PASS")
+		assert.Equal(t, expReader, r)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrSlotNotFound error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		var expReader io.ReadCloser = nil
+		const expErr = vault.ErrSlotNotFound
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		r, err := keeper.OpenFile(v, vault.Keyring{"dev": key},
+			"prod", "GCP_CREDENTIALS")
+		assert.Equal(t, expReader, r)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		expReader := io.NopCloser(strings.NewReader("content"))
+		stream.EXPECT().NewReader(key,
+			[]byte("prod\x00GCP_CREDENTIALS"), bytes.NewReader([]byte{0x32})).
+			Return(expReader, nil).Once()
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		r, err := keeper.OpenFile(v, vault.Keyring{"prod": key},
+			"prod", "GCP_CREDENTIALS")
+		assert.Equal(t, expReader, r)
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_Keeper_Resolve(t *testing.T) {
+	t.Parallel()
+	baseKey := newSecret(0x21)
+	prodKey := newSecret(0x22)
+	keyring := vault.Keyring{"base": baseKey, "prod": prodKey}
+	newVault := func(schema env.Schema) *vault.Vault {
+		return &vault.Vault{Schema: schema, Envs: []*vault.Env{
+			{
+				Name: "base",
+				Entries: []vault.Entry{
+					{
+						Name: "DB_HOST",
+						Kind: vault.EntryKindTemplate,
+						Buf:  []byte{0x31},
+					},
+					{Name: "DB_PASS", Buf: []byte{0x32}},
+				},
+			},
+			{
+				Name:   "prod",
+				Parent: "base",
+				Entries: []vault.Entry{
+					{Name: "DB_PASS", Buf: []byte{0x33}},
+					{
+						Name: "CA_BUNDLE",
+						Kind: vault.EntryKindFile,
+						Buf:  []byte{0x34},
+					},
+				},
+			},
+		}}
+	}
+	expectOpen := func(cipher *cmock.AE) {
+		cipher.EXPECT().Open(baseKey, []byte{0x31},
+			[]byte("base\x00DB_HOST\x00template")).
+			Return(newSecret([]byte("${HOST}")...), nil).Once()
+		cipher.EXPECT().Open(baseKey, []byte{0x32}, []byte("base\x00DB_PASS")).
+			Return(newSecret([]byte("dev-pass")...), nil).Once()
+		cipher.EXPECT().Open(prodKey, []byte{0x33}, []byte("prod\x00DB_PASS")).
+			Return(newSecret([]byte("pa$$${PASS}")...), nil).Once()
+	}
+	rawVars := []env.Var{
+		{Name: "DB_HOST", Value: "${HOST}"},
+		{Name: "DB_PASS", Value: "pa$$$$$${PASS}"},
+	}
+	expandedVars := []env.Var{
+		{Name: "DB_HOST", Value: "db.local"},
+		{Name: "DB_PASS", Value: "pa$$${PASS}"},
+	}
+
+	t.Run("ErrSlotNotFound error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		interpolator := emock.NewInterpolator(t)
+		v := newVault(env.Schema{})
+		var expVars []vault.ResolvedVar = nil
+		const expErr = vault.ErrSlotNotFound
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		vars, err := keeper.Resolve(v,
+			vault.Keyring{"prod": prodKey}, "prod", interpolator)
+		assert.Equal(t, expVars, vars)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrAuthFailed error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		interpolator := emock.NewInterpolator(t)
+		cipher.EXPECT().Open(baseKey, []byte{0x31},
+			[]byte("base\x00DB_HOST\x00template")).
+			Return(nil, crypto.ErrAuthFailed).Once()
+
+		v := newVault(env.Schema{})
+		var expVars []vault.ResolvedVar = nil
+		const expErr = crypto.ErrAuthFailed
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		vars, err := keeper.Resolve(v, keyring, "prod", interpolator)
+		assert.Equal(t, expVars, vars)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrUndefinedVar error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		interpolator := emock.NewInterpolator(t)
+		expectOpen(cipher)
+		interpolator.EXPECT().Expand(rawVars).
+			Return(nil, env.ErrUndefinedVar).Once()
+
+		v := newVault(env.Schema{})
+		var expVars []vault.ResolvedVar = nil
+		const expErr = env.ErrUndefinedVar
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		vars, err := keeper.Resolve(v, keyring, "prod", interpolator)
+		assert.Equal(t, expVars, vars)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrRequiredVar error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		interpolator := emock.NewInterpolator(t)
+		expectOpen(cipher)
+		interpolator.EXPECT().Expand(rawVars).
+			Return(expandedVars, nil).Once()
+
+		v := newVault(env.Schema{Rules: []env.Rule{
+			{Name: "DB_PORT", Type: env.TypePort, Required: true},
+		}})
+		var expVars []vault.ResolvedVar = nil
+		const expErr = env.ErrRequiredVar
+		const expVar = "DB_PORT"
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		vars, err := keeper.Resolve(v, keyring, "prod", interpolator)
+		assert.Equal(t, expVars, vars)
+		assert.ErrorIs(t, err, expErr)
+		assert.Equal(t, expVar, failure.NewReport(err).Var)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		interpolator := emock.NewInterpolator(t)
+		expectOpen(cipher)
+		interpolator.EXPECT().Expand(rawVars).
+			Return(expandedVars, nil).Once()
+
+		v := newVault(env.Schema{Rules: []env.Rule{
+			{Name: "DB_HOST", Type: env.TypePattern, Pattern: "[a-z.]+"},
+			{Name: "CA_BUNDLE", Required: true},
+		}})
+		expVars := []vault.ResolvedVar{
+			{
+				Var:    env.Var{Name: "DB_HOST", Value: "db.local"},
+				Kind:   vault.EntryKindTemplate,
+				Origin: "base",
+			},
+			{Var: env.Var{Name: "DB_PASS", Value: "pa$$${PASS}"}, Origin: "prod"},
+			{
+				Var:    env.Var{Name: "CA_BUNDLE"},
+				Kind:   vault.EntryKindFile,
+				Origin: "prod",
+			},
+		}
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		vars, err := keeper.Resolve(v, keyring, "prod", interpolator)
+		assert.Equal(t, expVars, vars)
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_Keeper_Check(t *testing.T) {
+	t.Parallel()
+	key := newSecret(0x21)
+	schema := env.Schema{Rules: []env.Rule{
+		{Name: "DB_PORT", Type: env.TypePort},
+		{Name: "DB_USER", Required: true},
+	}}
+
+	t.Run("ErrEnvNotFound error", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		interpolator := emock.NewInterpolator(t)
+		v := &vault.Vault{Schema: schema}
+		var expReport env.Report = nil
+		const expErr = vault.ErrEnvNotFound
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		report, err := keeper.Check(
+			v, vault.Keyring{"prod": key}, "prod", interpolator)
+		assert.Equal(t, expReport, report)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		authorizer := cmock.NewAuthorizer(t)
+		cipher := cmock.NewAE(t)
+		stream := cmock.NewStream(t)
+		kdf := cmock.NewKDF(t)
+		interpolator := emock.NewInterpolator(t)
+		cipher.EXPECT().Open(key, []byte{0x31},
+			[]byte("prod\x00DB_PORT\x00template")).
+			Return(newSecret([]byte("${PORT}")...), nil).Once()
+		interpolator.EXPECT().
+			Expand([]env.Var{{Name: "DB_PORT", Value: "${PORT}"}}).
+			Return([]env.Var{{Name: "DB_PORT", Value: "http"}}, nil).Once()
+
+		v := &vault.Vault{Schema: schema, Envs: []*vault.Env{{
+			Name: "prod",
+			Entries: []vault.Entry{{
+				Name: "DB_PORT",
+				Kind: vault.EntryKindTemplate,
+				Buf:  []byte{0x31},
+			}},
+		}}}
+		expReport := env.Report{
+			{Rule: schema.Rules[0], Err: env.ErrInvalidPort},
+			{Rule: schema.Rules[1], Err: env.ErrRequiredVar},
+		}
+
+		keeper := NewKeeper(fnKeeper, authorizer, cipher, stream, kdf)
+		report, err := keeper.Check(
+			v, vault.Keyring{"prod": key}, "prod", interpolator)
+		assert.Equal(t, expReport, report)
+		assert.ErrorIs(t, err, nil)
+	})
+}
@@ -0,0 +1,19 @@
+//go:build windows
+
+package vault_impl
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+func lockFile(file *os.File) error {
+	return windows.LockFileEx(windows.Handle(file.Fd()),
+		windows.LOCKFILE_EXCLUSIVE_LOCK, 0, 1, 0, &windows.Overlapped{})
+}
+
+func unlockFile(file *os.File) error {
+	return windows.UnlockFileEx(
+		windows.Handle(file.Fd()), 0, 1, 0, &windows.Overlapped{})
+}
@@ -0,0 +1,90 @@
+package vault_impl
+
+import (
+	"bytes"
+	"slices"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/reshifr/secure-env/core/vault"
+)
+
+type Notary[Verifier crypto.Verifier] struct {
+	verifier Verifier
+}
+
+func NewNotary[Verifier crypto.Verifier](
+	verifier Verifier) Notary[Verifier] {
+	return Notary[Verifier]{verifier: verifier}
+}
+
+func (notary Notary[Verifier]) Sign(
+	v *vault.Vault, name string, signer crypto.Signer) error {
+	trusted := v.Signers
+	if n := len(v.Signatures); n > 0 {
+		trusted = v.Signatures[n-1].Signers
+	}
+	pinned, err := vault.FindSigner(trusted, name)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(pinned.PublicKey, signer.PublicKey()) {
+		return vault.ErrSignerNotPinned
+	}
+	signature := vault.Signature{
+		Signer:  name,
+		Signers: slices.Clone(v.Signers),
+		Digest:  v.Digest(),
+	}
+	if n := len(v.Signatures); n > 0 {
+		signature.Prev = v.Signatures[n-1].Hash()
+	}
+	sig, err := signer.Sign(signature.Message())
+	if err != nil {
+		return err
+	}
+	signature.Sig = sig
+	v.Signatures = append(v.Signatures, signature)
+	return nil
+}
+
+func (notary Notary[Verifier]) Verify(
+	v *vault.Vault, roots []vault.Signer) error {
+	if len(v.Signatures) == 0 {
+		return vault.ErrUnsigned
+	}
+	trusted := roots
+	var prev []byte
+	for _, signature := range v.Signatures {
+		pinned, err := vault.FindSigner(trusted, signature.Signer)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(signature.Prev, prev) {
+			return vault.ErrSignatureMismatch
+		}
+		err = notary.verifier.Verify(
+			pinned.PublicKey, signature.Message(), signature.Sig)
+		if err != nil {
+			return err
+		}
+		trusted = signature.Signers
+		prev = signature.Hash()
+	}
+	last := v.Signatures[len(v.Signatures)-1]
+	if !bytes.Equal(last.Digest, v.Digest()) ||
+		!slices.EqualFunc(last.Signers, v.Signers, equalSigner) {
+		return vault.ErrSignatureMismatch
+	}
+	return nil
+}
+
+func equalSigner(a vault.Signer, b vault.Signer) bool {
+	return a.Name == b.Name && bytes.Equal(a.PublicKey, b.PublicKey)
+}
+
+func (notary Notary[Verifier]) Check(v *vault.Vault, trust vault.Trust) error {
+	if !trust.Require {
+		return nil
+	}
+	return notary.Verify(v, trust.Signers)
+}
@@ -0,0 +1,268 @@
+package vault_impl
+
+import (
+	"testing"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	cmock "github.com/reshifr/secure-env/core/crypto/mock"
+	"github.com/reshifr/secure-env/core/vault"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	admin = vault.Signer{Name: "admin", PublicKey: []byte{0x01}}
+	ci    = vault.Signer{Name: "ci", PublicKey: []byte{0x02}}
+	roots = []vault.Signer{admin}
+)
+
+func newSignedVault() *vault.Vault {
+	v := &vault.Vault{
+		Signers: []vault.Signer{admin},
+		Envs:    []*vault.Env{{Name: "prod"}},
+	}
+	first := vault.Signature{
+		Signer:  "admin",
+		Signers: []vault.Signer{admin},
+		Digest:  v.Digest(),
+		Sig:     []byte{0x11},
+	}
+	v.Envs[0].SetEntry("DB_PASS", []byte{0x31})
+	second := vault.Signature{
+		Signer:  "admin",
+		Signers: []vault.Signer{admin},
+		Digest:  v.Digest(),
+		Prev:    first.Hash(),
+		Sig:     []byte{0x12},
+	}
+	v.Signatures = []vault.Signature{first, second}
+	return v
+}
+
+func sign(v *vault.Vault, name string, sig byte) {
+	signature := vault.Signature{
+		Signer:  name,
+		Signers: v.Signers,
+		Digest:  v.Digest(),
+		Prev:    v.Signatures[len(v.Signatures)-1].Hash(),
+		Sig:     []byte{sig},
+	}
+	v.Signatures = append(v.Signatures, signature)
+}
+
+func Test_NewNotary(t *testing.T) {
+	t.Parallel()
+	verifier := cmock.NewVerifier(t)
+	expNotary := Notary[*cmock.Verifier]{verifier: verifier}
+
+	notary := NewNotary(verifier)
+	assert.Equal(t, expNotary, notary)
+}
+
+func Test_Notary_Sign(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrSignerNotPinned error", func(t *testing.T) {
+		t.Parallel()
+		verifier := cmock.NewVerifier(t)
+		signer := cmock.NewSigner(t)
+		v := &vault.Vault{}
+		const expErr = vault.ErrSignerNotPinned
+
+		notary := NewNotary(verifier)
+		err := notary.Sign(v, "admin", signer)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Unpinned public key", func(t *testing.T) {
+		t.Parallel()
+		verifier := cmock.NewVerifier(t)
+		signer := cmock.NewSigner(t)
+		signer.EXPECT().PublicKey().Return([]byte{0x02}).Once()
+
+		v := &vault.Vault{Signers: []vault.Signer{admin}}
+		const expErr = vault.ErrSignerNotPinned
+
+		notary := NewNotary(verifier)
+		err := notary.Sign(v, "admin", signer)
+		assert.ErrorIs(t, err, expErr)
+		assert.Empty(t, v.Signatures)
+	})
+	t.Run("Signer pinned after the last signature", func(t *testing.T) {
+		t.Parallel()
+		verifier := cmock.NewVerifier(t)
+		signer := cmock.NewSigner(t)
+		v := newSignedVault()
+		v.PinSigner("ci", ci.PublicKey)
+		const expErr = vault.ErrSignerNotPinned
+
+		notary := NewNotary(verifier)
+		err := notary.Sign(v, "ci", signer)
+		assert.ErrorIs(t, err, expErr)
+		assert.Len(t, v.Signatures, 2)
+	})
+	t.Run("ErrInvalidSeedLen error", func(t *testing.T) {
+		t.Parallel()
+		verifier := cmock.NewVerifier(t)
+		signer := cmock.NewSigner(t)
+		v := &vault.Vault{Signers: []vault.Signer{admin}}
+		msg := vault.Signature{
+			Signers: []vault.Signer{admin},
+			Digest:  v.Digest(),
+		}.Message()
+		signer.EXPECT().PublicKey().Return([]byte{0x01}).Once()
+		signer.EXPECT().Sign(msg).
+			Return(nil, crypto.ErrInvalidSeedLen).Once()
+
+		const expErr = crypto.ErrInvalidSeedLen
+
+		notary := NewNotary(verifier)
+		err := notary.Sign(v, "admin", signer)
+		assert.ErrorIs(t, err, expErr)
+		assert.Empty(t, v.Signatures)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		verifier := cmock.NewVerifier(t)
+		signer := cmock.NewSigner(t)
+		v := newSignedVault()
+		v.PinSigner("ci", ci.PublicKey)
+		expSignature := vault.Signature{
+			Signer:  "admin",
+			Signers: []vault.Signer{admin, ci},
+			Digest:  v.Digest(),
+			Prev:    v.Signatures[1].Hash(),
+			Sig:     []byte{0x13},
+		}
+		signer.EXPECT().PublicKey().Return([]byte{0x01}).Once()
+		signer.EXPECT().Sign(expSignature.Message()).
+			Return([]byte{0x13}, nil).Once()
+
+		notary := NewNotary(verifier)
+		err := notary.Sign(v, "admin", signer)
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, expSignature, v.Signatures[2])
+	})
+}
+
+func Test_Notary_Verify(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrUnsigned error", func(t *testing.T) {
+		t.Parallel()
+		verifier := cmock.NewVerifier(t)
+		v := &vault.Vault{}
+		const expErr = vault.ErrUnsigned
+
+		notary := NewNotary(verifier)
+		err := notary.Verify(v, roots)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrSignerNotPinned error", func(t *testing.T) {
+		t.Parallel()
+		verifier := cmock.NewVerifier(t)
+		v := newSignedVault()
+		const expErr = vault.ErrSignerNotPinned
+
+		notary := NewNotary(verifier)
+		err := notary.Verify(v, []vault.Signer{ci})
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrInvalidSignature error", func(t *testing.T) {
+		t.Parallel()
+		verifier := cmock.NewVerifier(t)
+		v := newSignedVault()
+		first := v.Signatures[0]
+		verifier.EXPECT().Verify([]byte{0x01}, first.Message(), first.Sig).
+			Return(crypto.ErrInvalidSignature).Once()
+
+		const expErr = crypto.ErrInvalidSignature
+
+		notary := NewNotary(verifier)
+		err := notary.Verify(v, roots)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Deleted signature", func(t *testing.T) {
+		t.Parallel()
+		verifier := cmock.NewVerifier(t)
+		v := newSignedVault()
+		v.Signatures = v.Signatures[1:]
+		const expErr = vault.ErrSignatureMismatch
+
+		notary := NewNotary(verifier)
+		err := notary.Verify(v, roots)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Unsigned change", func(t *testing.T) {
+		t.Parallel()
+		verifier := cmock.NewVerifier(t)
+		v := newSignedVault()
+		for _, signature := range v.Signatures {
+			verifier.EXPECT().
+				Verify([]byte{0x01}, signature.Message(), signature.Sig).
+				Return(nil).Once()
+		}
+		v.Envs[0].SetEntry("DB_PASS", []byte{0x32})
+		const expErr = vault.ErrSignatureMismatch
+
+		notary := NewNotary(verifier)
+		err := notary.Verify(v, roots)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Signer added by itself", func(t *testing.T) {
+		t.Parallel()
+		verifier := cmock.NewVerifier(t)
+		v := newSignedVault()
+		for _, signature := range v.Signatures {
+			verifier.EXPECT().
+				Verify([]byte{0x01}, signature.Message(), signature.Sig).
+				Return(nil).Once()
+		}
+		v.PinSigner("ci", ci.PublicKey)
+		sign(v, "ci", 0x21)
+		const expErr = vault.ErrSignerNotPinned
+
+		notary := NewNotary(verifier)
+		err := notary.Verify(v, roots)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		verifier := cmock.NewVerifier(t)
+		v := newSignedVault()
+		v.PinSigner("ci", ci.PublicKey)
+		sign(v, "admin", 0x13)
+		v.UnpinSigner("admin")
+		sign(v, "ci", 0x21)
+		for _, signature := range v.Signatures {
+			signer, _ := vault.FindSigner(
+				[]vault.Signer{admin, ci}, signature.Signer)
+			verifier.EXPECT().
+				Verify(signer.PublicKey, signature.Message(), signature.Sig).
+				Return(nil).Once()
+		}
+
+		notary := NewNotary(verifier)
+		err := notary.Verify(v, roots)
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_Notary_Check(t *testing.T) {
+	t.Parallel()
+	t.Run("Not required", func(t *testing.T) {
+		t.Parallel()
+		verifier := cmock.NewVerifier(t)
+		v := &vault.Vault{}
+
+		notary := NewNotary(verifier)
+		err := notary.Check(v, vault.Trust{Signers: roots})
+		assert.ErrorIs(t, err, nil)
+	})
+	t.Run("Required by reader", func(t *testing.T) {
+		t.Parallel()
+		verifier := cmock.NewVerifier(t)
+		v := &vault.Vault{}
+		const expErr = vault.ErrUnsigned
+
+		notary := NewNotary(verifier)
+		err := notary.Check(v, vault.Trust{Require: true, Signers: roots})
+		assert.ErrorIs(t, err, expErr)
+	})
+}
@@ -0,0 +1,94 @@
+package vault
+
+import (
+	"encoding/binary"
+	"path/filepath"
+
+	"github.com/reshifr/secure-env/core/failure"
+)
+
+const (
+	WatermarksPathEnv = "SENV_WATERMARKS"
+	WatermarksFile    = "watermarks.json"
+	RollbackWarning   = "The vault is older than the last version seen " +
+		"on this machine. It may have been replaced or reverted, and may " +
+		"still grant access to revoked roles."
+	StampContext = "secure-env vault stamp v1"
+)
+
+const (
+	OpReadWatermarks  = "read-watermarks"
+	OpWriteWatermarks = "write-watermarks"
+	OpStamp           = "stamp"
+)
+
+type RollbackError int
+
+const (
+	ErrRollback RollbackError = iota + 1
+	ErrMissingVaultID
+	ErrWatermarkNotFound
+	ErrReadWatermarksFailed
+	ErrWriteWatermarksFailed
+)
+
+func (err RollbackError) Error() string {
+	switch err {
+	case ErrRollback:
+		return "ErrRollback: the vault is older than the last one seen."
+	case ErrMissingVaultID:
+		return "ErrMissingVaultID: the vault has no ID."
+	case ErrWatermarkNotFound:
+		return "ErrWatermarkNotFound: the vault has not been seen before."
+	case ErrReadWatermarksFailed:
+		return "ErrReadWatermarksFailed: failed to read the high-water marks."
+	case ErrWriteWatermarksFailed:
+		return "ErrWriteWatermarksFailed: " +
+			"failed to write the high-water marks."
+	default:
+		return "Error: unknown."
+	}
+}
+
+func (err RollbackError) Kind() failure.Kind {
+	switch err {
+	case ErrRollback, ErrMissingVaultID:
+		return failure.KindIntegrity
+	case ErrWatermarkNotFound:
+		return failure.KindNotFound
+	case ErrReadWatermarksFailed, ErrWriteWatermarksFailed:
+		return failure.KindIO
+	default:
+		return failure.KindInternal
+	}
+}
+
+type Watermarks interface {
+	Get(id string) (generation uint64, err error)
+	Set(id string, generation uint64) (err error)
+}
+
+// StampAD also binds the settings digest of root environments, so their
+// owner, policy and schema cannot change without the env key.
+func StampAD(
+	id string, name string, generation uint64, settings []byte) []byte {
+	ad := make([]byte, 0,
+		len(StampContext)+len(id)+len(name)+10+len(settings))
+	ad = append(ad, StampContext...)
+	ad = append(ad, 0x00)
+	ad = append(ad, id...)
+	ad = append(ad, 0x00)
+	ad = append(ad, name...)
+	ad = binary.BigEndian.AppendUint64(ad, generation)
+	return append(ad, settings...)
+}
+
+func WatermarksPath(override string, stateHome string, home string) string {
+	if override != "" {
+		return override
+	}
+	if stateHome == "" {
+		stateHome = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(stateHome, "senv", WatermarksFile)
+}
@@ -0,0 +1,142 @@
+package vault
+
+import (
+	"testing"
+
+	"github.com/reshifr/secure-env/core/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RollbackError_Error(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrRollback value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrRollback
+		const expMsg = "ErrRollback: the vault is older than the last one seen."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrMissingVaultID value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrMissingVaultID
+		const expMsg = "ErrMissingVaultID: the vault has no ID."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrWatermarkNotFound value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrWatermarkNotFound
+		const expMsg = "ErrWatermarkNotFound: the vault has not been seen before."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrReadWatermarksFailed value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrReadWatermarksFailed
+		const expMsg = "ErrReadWatermarksFailed: " +
+			"failed to read the high-water marks."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrWriteWatermarksFailed value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrWriteWatermarksFailed
+		const expMsg = "ErrWriteWatermarksFailed: " +
+			"failed to write the high-water marks."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("Unknown value", func(t *testing.T) {
+		t.Parallel()
+		const err = RollbackError(613724)
+		const expMsg = "Error: unknown."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+}
+
+func Test_StampAD(t *testing.T) {
+	t.Parallel()
+	expAD := append([]byte(StampContext+"\x00id\x00prod"),
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x02, 0xaa, 0xbb)
+
+	ad := StampAD("id", "prod", 0x0102, []byte{0xaa, 0xbb})
+	assert.Equal(t, expAD, ad)
+}
+
+func Test_WatermarksPath(t *testing.T) {
+	t.Parallel()
+	t.Run("Override path", func(t *testing.T) {
+		t.Parallel()
+		const expPath = "/tmp/marks.json"
+
+		path := WatermarksPath("/tmp/marks.json", "/state", "/home/user")
+		assert.Equal(t, expPath, path)
+	})
+	t.Run("State home", func(t *testing.T) {
+		t.Parallel()
+		const expPath = "/state/senv/watermarks.json"
+
+		path := WatermarksPath("", "/state", "/home/user")
+		assert.Equal(t, expPath, path)
+	})
+	t.Run("Home directory", func(t *testing.T) {
+		t.Parallel()
+		const expPath = "/home/user/.local/state/senv/watermarks.json"
+
+		path := WatermarksPath("", "", "/home/user")
+		assert.Equal(t, expPath, path)
+	})
+}
+
+func Test_RollbackError_Kind(t *testing.T) {
+	t.Parallel()
+	t.Run("KindIntegrity value", func(t *testing.T) {
+		t.Parallel()
+		errs := []RollbackError{
+			ErrRollback,
+			ErrMissingVaultID,
+		}
+		const expKind = failure.KindIntegrity
+
+		for _, err := range errs {
+			kind := err.Kind()
+			assert.Equal(t, expKind, kind, err.Error())
+		}
+	})
+	t.Run("KindNotFound value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrWatermarkNotFound
+		const expKind = failure.KindNotFound
+
+		kind := err.Kind()
+		assert.Equal(t, expKind, kind)
+	})
+	t.Run("KindIO value", func(t *testing.T) {
+		t.Parallel()
+		errs := []RollbackError{
+			ErrReadWatermarksFailed,
+			ErrWriteWatermarksFailed,
+		}
+		const expKind = failure.KindIO
+
+		for _, err := range errs {
+			kind := err.Kind()
+			assert.Equal(t, expKind, kind, err.Error())
+		}
+	})
+	t.Run("Unknown value", func(t *testing.T) {
+		t.Parallel()
+		const err = RollbackError(613724)
+		const expKind = failure.KindInternal
+
+		kind := err.Kind()
+		assert.Equal(t, expKind, kind)
+	})
+}
@@ -0,0 +1,77 @@
+package vault_test
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	cimpl "github.com/reshifr/secure-env/core/crypto/impl"
+	"github.com/reshifr/secure-env/core/env"
+	"github.com/reshifr/secure-env/core/vault"
+	vimpl "github.com/reshifr/secure-env/core/vault/impl"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Guard_Check(t *testing.T) {
+	t.Parallel()
+	rng := cimpl.NewStdRNG(cimpl.FnStdRNG{Read: rand.Read})
+	cipher := cimpl.ChaChaPoly{}
+	stream, _ := cimpl.NewStream(cipher, cimpl.StreamChunkLen)
+	authorizer := cimpl.NewRoleAuthorizer(cimpl.Argon{}, rng, cipher)
+	keeper := vimpl.NewKeeper(vimpl.FnKeeper{Now: time.Now},
+		authorizer, cipher, stream, cimpl.NewHKDF([]byte("secure-env")))
+	rawIV, _ := rng.Block(cimpl.IV96Len)
+	iv, _ := cimpl.LoadIV96(rawIV)
+	id, err := vimpl.NewVaultID(rng)
+	assert.ErrorIs(t, err, nil)
+	path := vault.WatermarksPath("", t.TempDir(), "")
+	guard := vimpl.NewGuard(vimpl.NewFileWatermarks(path))
+
+	passphrase, _ := crypto.NewSecretFrom([]byte("+DF7Rc-X/MOYjkNj"))
+	current := &vault.Vault{ID: id}
+	keyring, _ := keeper.CreateEnv(iv, current, "prod", "", "admin", passphrase)
+	keeper.Set(iv, current, keyring, "prod", "admin",
+		env.Var{Name: "DB_PASS", Value: "s3cr3t"})
+	err = keeper.Stamp(iv, current, keyring)
+	assert.ErrorIs(t, err, nil)
+	buf, _ := json.Marshal(current)
+	old := &vault.Vault{}
+	json.Unmarshal(buf, old)
+	err = keeper.Stamp(iv, current, keyring)
+	assert.ErrorIs(t, err, nil)
+
+	generations, err := keeper.Generations(current, keyring)
+	assert.ErrorIs(t, err, nil)
+	rolledBack, err := guard.Check(current, generations, false)
+	assert.False(t, rolledBack)
+	assert.ErrorIs(t, err, nil)
+
+	oldGenerations, err := keeper.Generations(old, keyring)
+	assert.ErrorIs(t, err, nil)
+	rolledBack, err = guard.Check(old, oldGenerations, false)
+	assert.True(t, rolledBack)
+	assert.ErrorIs(t, err, vault.ErrRollback)
+
+	forged := &vault.Vault{}
+	json.Unmarshal(buf, forged)
+	forged.Envs[0].Generation = current.Generation
+	_, err = keeper.Generations(forged, keyring)
+	assert.ErrorIs(t, err, crypto.ErrAuthFailed)
+	forged.ID = ""
+	_, err = guard.Check(forged, generations, false)
+	assert.ErrorIs(t, err, vault.ErrMissingVaultID)
+
+	rolledBack, err = guard.Check(old, oldGenerations, true)
+	assert.True(t, rolledBack)
+	assert.ErrorIs(t, err, nil)
+
+	rolledBack, err = guard.Check(old, oldGenerations, false)
+	assert.True(t, rolledBack)
+	assert.ErrorIs(t, err, vault.ErrRollback)
+
+	rolledBack, err = guard.Check(current, generations, false)
+	assert.False(t, rolledBack)
+	assert.ErrorIs(t, err, nil)
+}
@@ -0,0 +1,574 @@
+package vault_test
+
+import (
+	"bytes"
+	gocrypto "crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/pem"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	cimpl "github.com/reshifr/secure-env/core/crypto/impl"
+	"github.com/reshifr/secure-env/core/env"
+	eimpl "github.com/reshifr/secure-env/core/env/impl"
+	"github.com/reshifr/secure-env/core/failure"
+	"github.com/reshifr/secure-env/core/passphrase"
+	pimpl "github.com/reshifr/secure-env/core/passphrase/impl"
+	"github.com/reshifr/secure-env/core/vault"
+	vimpl "github.com/reshifr/secure-env/core/vault/impl"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+var (
+	interpolator = eimpl.NewInterpolator(eimpl.FnInterpolator{})
+)
+
+func Test_Keeper_Resolve(t *testing.T) {
+	t.Parallel()
+	rng := cimpl.NewStdRNG(cimpl.FnStdRNG{Read: rand.Read})
+	cipher := cimpl.ChaChaPoly{}
+	stream, _ := cimpl.NewStream(cipher, cimpl.StreamChunkLen)
+	authorizer := cimpl.NewRoleAuthorizer(cimpl.Argon{}, rng, cipher)
+	keeper := vimpl.NewKeeper(vimpl.FnKeeper{Now: time.Now},
+		authorizer, cipher, stream, cimpl.NewHKDF([]byte("secure-env")))
+	rawIV, _ := rng.Block(cimpl.IV96Len)
+	iv, _ := cimpl.LoadIV96(rawIV)
+
+	adminPassphrase, _ := crypto.NewSecretFrom([]byte("+DF7Rc-X/MOYjkNj"))
+	devPassphrase, _ := crypto.NewSecretFrom([]byte("q7!Lw2#zR9@pXe4v"))
+	v := &vault.Vault{}
+
+	baseKeyring, err := keeper.CreateEnv(
+		iv, v, "base", "", "admin", adminPassphrase)
+	assert.ErrorIs(t, err, nil)
+	keeper.Set(iv, v, baseKeyring, "base", "admin",
+		env.Var{Name: "DB_HOST", Value: "db.local"})
+	keeper.Set(iv, v, baseKeyring, "base", "admin",
+		env.Var{Name: "DB_PASS", Value: "shared"})
+
+	prodKeyring, err := keeper.CreateEnv(
+		iv, v, "prod", "base", "admin", adminPassphrase)
+	assert.ErrorIs(t, err, nil)
+	keeper.Set(iv, v, prodKeyring, "prod", "admin",
+		env.Var{Name: "DB_PASS", Value: "prod-only"})
+
+	_, err = keeper.CreateEnv(iv, v, "dev", "", "dev", devPassphrase)
+	assert.ErrorIs(t, err, nil)
+
+	t.Run("Inherited values", func(t *testing.T) {
+		t.Parallel()
+		expVars := []vault.ResolvedVar{
+			{Var: env.Var{Name: "DB_HOST", Value: "db.local"}, Origin: "base"},
+			{Var: env.Var{Name: "DB_PASS", Value: "prod-only"}, Origin: "prod"},
+		}
+
+		keyring, err := keeper.Open(v, "prod", "admin", adminPassphrase)
+		assert.ErrorIs(t, err, nil)
+		vars, err := keeper.Resolve(v, keyring, "prod", interpolator)
+		assert.Equal(t, expVars, vars)
+		assert.ErrorIs(t, err, nil)
+	})
+	t.Run("Separate data keys", func(t *testing.T) {
+		t.Parallel()
+		assert.NotEqual(t,
+			prodKeyring["base"].Bytes(), prodKeyring["prod"].Bytes())
+
+		keyring, err := keeper.Open(v, "prod", "dev", devPassphrase)
+		assert.Equal(t, vault.Keyring(nil), keyring)
+		assert.ErrorIs(t, err, vault.ErrSlotNotFound)
+	})
+	t.Run("Swapped entries", func(t *testing.T) {
+		t.Parallel()
+		base, _ := v.Env("base")
+		swapped := *base
+		swapped.Entries = []vault.Entry{
+			{Name: "DB_HOST", Buf: base.Entries[1].Buf},
+			{Name: "DB_PASS", Buf: base.Entries[0].Buf},
+		}
+		sv := &vault.Vault{Envs: []*vault.Env{&swapped}}
+
+		vars, err := keeper.Resolve(sv, baseKeyring, "base", interpolator)
+		assert.Equal(t, []vault.ResolvedVar(nil), vars)
+		assert.ErrorIs(t, err, crypto.ErrAuthFailed)
+	})
+}
+
+func Test_Keeper_Passwd(t *testing.T) {
+	t.Parallel()
+	rng := cimpl.NewStdRNG(cimpl.FnStdRNG{Read: rand.Read})
+	cipher := cimpl.ChaChaPoly{}
+	stream, _ := cimpl.NewStream(cipher, cimpl.StreamChunkLen)
+	authorizer := cimpl.NewRoleAuthorizer(cimpl.Argon{}, rng, cipher)
+	keeper := vimpl.NewKeeper(vimpl.FnKeeper{Now: time.Now},
+		authorizer, cipher, stream, cimpl.NewHKDF([]byte("secure-env")))
+	rawIV, _ := rng.Block(cimpl.IV96Len)
+	iv, _ := cimpl.LoadIV96(rawIV)
+
+	passphrase, _ := crypto.NewSecretFrom([]byte("+DF7Rc-X/MOYjkNj"))
+	newPassphrase, _ := crypto.NewSecretFrom([]byte("q7!Lw2#zR9@pXe4v"))
+	v := &vault.Vault{}
+	keyring, err := keeper.CreateEnv(iv, v, "prod", "", "admin", passphrase)
+	assert.ErrorIs(t, err, nil)
+	keeper.Set(iv, v, keyring, "prod", "admin", env.Var{Name: "DB_PASS", Value: "s3cr3t"})
+	entries := v.Envs[0].Entries
+	slot, _ := v.Envs[0].Slot("admin")
+
+	err = keeper.Passwd(iv, v, "admin", passphrase, newPassphrase)
+	assert.ErrorIs(t, err, nil)
+	newSlot, _ := v.Envs[0].Slot("admin")
+	assert.NotEqual(t, slot.Block, newSlot.Block)
+	assert.Equal(t, slot.Created, newSlot.Created)
+	assert.Equal(t, entries, v.Envs[0].Entries)
+
+	_, err = keeper.Open(v, "prod", "admin", passphrase)
+	assert.ErrorIs(t, err, crypto.ErrAuthFailed)
+	report := failure.NewReport(err)
+	assert.Equal(t, "ErrAuthFailed", report.Code)
+	assert.Equal(t, vault.OpOpen, report.Op)
+	assert.Equal(t, "prod", report.Env)
+	assert.Equal(t, "admin", report.Role)
+	assert.Equal(t, failure.ExitAuth, report.Exit)
+
+	newKeyring, err := keeper.Open(v, "prod", "admin", newPassphrase)
+	assert.ErrorIs(t, err, nil)
+	assert.Equal(t, keyring["prod"].Bytes(), newKeyring["prod"].Bytes())
+	vars, err := keeper.Resolve(v, newKeyring, "prod", interpolator)
+	assert.Equal(t, "s3cr3t", vars[0].Value)
+	assert.ErrorIs(t, err, nil)
+}
+
+func Test_Keeper_AddRole(t *testing.T) {
+	t.Parallel()
+	rng := cimpl.NewStdRNG(cimpl.FnStdRNG{Read: rand.Read})
+	cipher := cimpl.ChaChaPoly{}
+	stream, _ := cimpl.NewStream(cipher, cimpl.StreamChunkLen)
+	authorizer := cimpl.NewRoleAuthorizer(cimpl.Argon{}, rng, cipher)
+	keeper := vimpl.NewKeeper(vimpl.FnKeeper{Now: time.Now},
+		authorizer, cipher, stream, cimpl.NewHKDF([]byte("secure-env")))
+	rawIV, _ := rng.Block(cimpl.IV96Len)
+	iv, _ := cimpl.LoadIV96(rawIV)
+
+	adminPassphrase, _ := crypto.NewSecretFrom([]byte("+DF7Rc-X/MOYjkNj"))
+	devPassphrase, _ := crypto.NewSecretFrom([]byte("q7!Lw2#zR9@pXe4v"))
+	v := &vault.Vault{}
+	keyring, err := keeper.CreateEnv(iv, v, "prod", "", "admin", adminPassphrase)
+	assert.ErrorIs(t, err, nil)
+	keeper.Set(iv, v, keyring, "prod", "admin", env.Var{Name: "DB_PASS", Value: "s3cr3t"})
+	keeper.CreateEnv(iv, v, "staging", "", "admin", adminPassphrase)
+
+	err = keeper.AddRole(iv, v, "prod", "admin", adminPassphrase, "dev", devPassphrase)
+	assert.ErrorIs(t, err, nil)
+	err = keeper.AddRole(iv, v, "prod", "admin", adminPassphrase, "dev", devPassphrase)
+	assert.ErrorIs(t, err, vault.ErrRoleExists)
+	_, err = keeper.Open(v, "staging", "dev", devPassphrase)
+	assert.ErrorIs(t, err, vault.ErrSlotNotFound)
+
+	devKeyring, err := keeper.Open(v, "prod", "dev", devPassphrase)
+	assert.ErrorIs(t, err, nil)
+	vars, err := keeper.Resolve(v, devKeyring, "prod", interpolator)
+	assert.Equal(t, "s3cr3t", vars[0].Value)
+	assert.ErrorIs(t, err, nil)
+
+	roles := v.Roles()
+	assert.Equal(t, "admin", roles[0].Name)
+	assert.Equal(t, "dev", roles[1].Name)
+	assert.Equal(t, cimpl.RoleAuthorizerKind, roles[1].Kind)
+	assert.Equal(t, cimpl.ArgonAlgorithm, roles[1].KDF.Algorithm)
+	assert.Equal(t, []string{"prod"}, roles[1].Envs)
+}
+
+func Test_Keeper_AddRecipient(t *testing.T) {
+	t.Parallel()
+	rng := cimpl.NewStdRNG(cimpl.FnStdRNG{Read: rand.Read})
+	cipher := cimpl.ChaChaPoly{}
+	stream, _ := cimpl.NewStream(cipher, cimpl.StreamChunkLen)
+	authorizer := cimpl.NewRoleAuthorizer(cimpl.Argon{}, rng, cipher)
+	recipient := cimpl.NewHybridRecipient(rng, cipher)
+	keeper := vimpl.NewKeeper(vimpl.FnKeeper{Now: time.Now},
+		authorizer, cipher, stream, cimpl.NewHKDF([]byte("secure-env")))
+	rawIV, _ := rng.Block(cimpl.IV96Len)
+	iv, _ := cimpl.LoadIV96(rawIV)
+
+	passphrase, _ := crypto.NewSecretFrom([]byte("+DF7Rc-X/MOYjkNj"))
+	identity, _ := cimpl.NewHybridIdentity(rng)
+	otherIdentity, _ := cimpl.NewHybridIdentity(rng)
+	publicKey, _ := cimpl.HybridPublicKey(identity)
+	v := &vault.Vault{}
+	keeper.CreateEnv(iv, v, "base", "", "admin", passphrase)
+	keyring, err := keeper.CreateEnv(iv, v, "prod", "base", "admin", passphrase)
+	assert.ErrorIs(t, err, nil)
+	keeper.Set(iv, v, keyring, "base", "admin", env.Var{Name: "USER", Value: "app"})
+	keeper.Set(iv, v, keyring, "prod", "admin", env.Var{Name: "DB_PASS", Value: "s3cr3t"})
+
+	err = keeper.AddRecipient(iv, v, "prod", "admin", passphrase, "ci", recipient, publicKey)
+	assert.ErrorIs(t, err, nil)
+
+	ciKeyring, err := keeper.OpenRecipient(v, "prod", "ci", recipient, identity)
+	assert.ErrorIs(t, err, nil)
+	vars, err := keeper.Resolve(v, ciKeyring, "prod", interpolator)
+	assert.ErrorIs(t, err, nil)
+	assert.Equal(t, "app", vars[0].Value)
+	assert.Equal(t, "s3cr3t", vars[1].Value)
+
+	_, err = keeper.OpenRecipient(v, "prod", "ci", recipient, otherIdentity)
+	assert.ErrorIs(t, err, crypto.ErrAuthFailed)
+	_, err = keeper.Open(v, "prod", "ci", passphrase)
+	assert.ErrorIs(t, err, vault.ErrSlotKindMismatch)
+	_, err = keeper.OpenRecipient(v, "prod", "admin", recipient, identity)
+	assert.ErrorIs(t, err, vault.ErrSlotKindMismatch)
+
+	roles := v.Roles()
+	assert.Equal(t, "admin", roles[0].Name)
+	assert.Equal(t, cimpl.RoleAuthorizerKind, roles[0].Kind)
+	assert.Equal(t, "ci", roles[1].Name)
+	assert.Equal(t, cimpl.HybridRecipientKind, roles[1].Kind)
+	assert.Equal(t, []string{"base", "prod"}, roles[1].Envs)
+}
+
+func Test_Keeper_AddRecipient_SSH(t *testing.T) {
+	t.Parallel()
+	rng := cimpl.NewStdRNG(cimpl.FnStdRNG{Read: rand.Read})
+	cipher := cimpl.ChaChaPoly{}
+	stream, _ := cimpl.NewStream(cipher, cimpl.StreamChunkLen)
+	authorizer := cimpl.NewRoleAuthorizer(cimpl.Argon{}, rng, cipher)
+	recipients := map[string]crypto.Recipient{
+		cimpl.SSHEd25519RecipientKind: cimpl.NewSSHEd25519Recipient(rng, cipher),
+		cimpl.SSHRSARecipientKind:     cimpl.NewSSHRSARecipient(rng),
+	}
+	keeper := vimpl.NewKeeper(vimpl.FnKeeper{Now: time.Now},
+		authorizer, cipher, stream, cimpl.NewHKDF([]byte("secure-env")))
+	rawIV, _ := rng.Block(cimpl.IV96Len)
+	iv, _ := cimpl.LoadIV96(rawIV)
+
+	passphrase, _ := crypto.NewSecretFrom([]byte("+DF7Rc-X/MOYjkNj"))
+	v := &vault.Vault{}
+	keyring, err := keeper.CreateEnv(iv, v, "prod", "", "admin", passphrase)
+	assert.ErrorIs(t, err, nil)
+	keeper.Set(iv, v, keyring, "prod", "admin", env.Var{Name: "DB_PASS", Value: "s3cr3t"})
+
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, cimpl.SSHRSAMinBits)
+	dir := t.TempDir()
+	for _, user := range []struct {
+		role       string
+		key        any
+		passphrase string
+	}{
+		{role: "alice", key: edKey, passphrase: "q7!Lw2#zR9@pXe4v"},
+		{role: "bob", key: rsaKey},
+	} {
+		sshPublicKey, _ := ssh.NewPublicKey(user.key.(gocrypto.Signer).Public())
+		authorizedKey := ssh.MarshalAuthorizedKey(sshPublicKey)
+		kind, err := cimpl.SSHRecipientKind(authorizedKey)
+		assert.ErrorIs(t, err, nil)
+		err = keeper.AddRecipient(iv, v, "prod", "admin", passphrase,
+			user.role, recipients[kind], authorizedKey)
+		assert.ErrorIs(t, err, nil)
+
+		var block *pem.Block
+		var keyPassphrase *crypto.Secret
+		if user.passphrase == "" {
+			block, _ = ssh.MarshalPrivateKey(user.key, "")
+		} else {
+			block, _ = ssh.MarshalPrivateKeyWithPassphrase(
+				user.key, "", []byte(user.passphrase))
+			keyPassphrase, _ = crypto.NewSecretFrom([]byte(user.passphrase))
+		}
+		path := filepath.Join(dir, user.role)
+		os.WriteFile(path, pem.EncodeToMemory(block), 0600)
+
+		pemBytes, _ := os.ReadFile(path)
+		identity, kind, err := cimpl.LoadSSHIdentity(pemBytes, keyPassphrase)
+		assert.ErrorIs(t, err, nil)
+		userKeyring, err := keeper.OpenRecipient(
+			v, "prod", user.role, recipients[kind], identity)
+		assert.ErrorIs(t, err, nil)
+		vars, err := keeper.Resolve(v, userKeyring, "prod", interpolator)
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, "s3cr3t", vars[0].Value)
+	}
+
+	roles := v.Roles()
+	assert.Equal(t, cimpl.SSHEd25519RecipientKind, roles[1].Kind)
+	assert.Equal(t, cimpl.SSHRSARecipientKind, roles[2].Kind)
+}
+
+func Test_Keeper_AddRecipient_SSHAgent(t *testing.T) {
+	t.Parallel()
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	keyring := agent.NewKeyring()
+	keyring.Add(agent.AddedKey{PrivateKey: edKey})
+	path := filepath.Join(t.TempDir(), "agent.sock")
+	listener, err := net.Listen("unix", path)
+	assert.ErrorIs(t, err, nil)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go agent.ServeAgent(keyring, conn)
+		}
+	}()
+
+	sshAgent, conn, err := cimpl.DialSSHAgent(cimpl.FnSSHAgent{
+		LookupEnv: func(string) (string, bool) { return path, true },
+		Dial:      net.Dial,
+	})
+	assert.ErrorIs(t, err, nil)
+	defer conn.Close()
+
+	rng := cimpl.NewStdRNG(cimpl.FnStdRNG{Read: rand.Read})
+	cipher := cimpl.ChaChaPoly{}
+	stream, _ := cimpl.NewStream(cipher, cimpl.StreamChunkLen)
+	authorizer := cimpl.NewRoleAuthorizer(cimpl.Argon{}, rng, cipher)
+	recipient := cimpl.NewSSHAgentRecipient(sshAgent, rng, cipher)
+	keeper := vimpl.NewKeeper(vimpl.FnKeeper{Now: time.Now},
+		authorizer, cipher, stream, cimpl.NewHKDF([]byte("secure-env")))
+	rawIV, _ := rng.Block(cimpl.IV96Len)
+	iv, _ := cimpl.LoadIV96(rawIV)
+
+	passphrase, _ := crypto.NewSecretFrom([]byte("+DF7Rc-X/MOYjkNj"))
+	v := &vault.Vault{}
+	adminKeyring, err := keeper.CreateEnv(iv, v, "prod", "", "admin", passphrase)
+	assert.ErrorIs(t, err, nil)
+	keeper.Set(iv, v, adminKeyring, "prod", "admin", env.Var{Name: "DB_PASS", Value: "s3cr3t"})
+
+	sshPublicKey, _ := ssh.NewPublicKey(edKey.Public())
+	authorizedKey := ssh.MarshalAuthorizedKey(sshPublicKey)
+	err = keeper.AddRecipient(iv, v, "prod", "admin", passphrase, "alice", recipient, authorizedKey)
+	assert.ErrorIs(t, err, nil)
+
+	aliceKeyring, err := keeper.OpenRecipient(v, "prod", "alice", recipient, nil)
+	assert.ErrorIs(t, err, nil)
+	vars, err := keeper.Resolve(v, aliceKeyring, "prod", interpolator)
+	assert.ErrorIs(t, err, nil)
+	assert.Equal(t, "s3cr3t", vars[0].Value)
+
+	keyring.RemoveAll()
+	_, err = keeper.OpenRecipient(v, "prod", "alice", recipient, nil)
+	assert.ErrorIs(t, err, crypto.ErrAgentFailed)
+}
+
+func Test_Keeper_Rotate(t *testing.T) {
+	t.Parallel()
+	rng := cimpl.NewStdRNG(cimpl.FnStdRNG{Read: rand.Read})
+	cipher := cimpl.ChaChaPoly{}
+	stream, _ := cimpl.NewStream(cipher, cimpl.StreamChunkLen)
+	authorizer := cimpl.NewRoleAuthorizer(cimpl.Argon{}, rng, cipher)
+	recipient := cimpl.NewHybridRecipient(rng, cipher)
+	keeper := vimpl.NewKeeper(vimpl.FnKeeper{Now: time.Now},
+		authorizer, cipher, stream, cimpl.NewHKDF([]byte("secure-env")))
+	rawIV, _ := rng.Block(cimpl.IV96Len)
+	iv, _ := cimpl.LoadIV96(rawIV)
+
+	adminPassphrase, _ := crypto.NewSecretFrom([]byte("+DF7Rc-X/MOYjkNj"))
+	devPassphrase, _ := crypto.NewSecretFrom([]byte("q7!Lw2#zR9@pXe4v"))
+	opsPassphrase, _ := crypto.NewSecretFrom([]byte("Vb8$uN3&kT6^mQ1z"))
+	identity, _ := cimpl.NewHybridIdentity(rng)
+	publicKey, _ := cimpl.HybridPublicKey(identity)
+	v := &vault.Vault{}
+	keeper.CreateEnv(iv, v, "base", "", "admin", adminPassphrase)
+	keyring, err := keeper.CreateEnv(iv, v, "prod", "base", "admin", adminPassphrase)
+	assert.ErrorIs(t, err, nil)
+	keeper.Set(iv, v, keyring, "base", "admin", env.Var{Name: "USER", Value: "app"})
+	keeper.Set(iv, v, keyring, "prod", "admin", env.Var{Name: "DB_PASS", Value: "s3cr3t"})
+	keeper.AddRole(iv, v, "prod", "admin", adminPassphrase, "dev", devPassphrase)
+	keeper.AddRole(iv, v, "prod", "admin", adminPassphrase, "ops", opsPassphrase)
+	keeper.AddRecipient(iv, v, "prod", "admin", adminPassphrase, "ci", recipient, publicKey)
+	devKeyring, _ := keeper.Open(v, "prod", "dev", devPassphrase)
+
+	report, err := keeper.RemoveRole(
+		iv, v, "admin", adminPassphrase, "dev", false)
+	assert.Equal(t, vault.RemoveRoleWarning, report.Warning)
+	assert.ErrorIs(t, err, nil)
+	_, err = keeper.Resolve(v, devKeyring, "prod", interpolator)
+	assert.ErrorIs(t, err, nil)
+
+	keeper.AddRole(iv, v, "prod", "admin", adminPassphrase, "dev", devPassphrase)
+	expReport := vault.RemoveReport{
+		Envs:    []string{"base", "prod"},
+		Dropped: []string{"ops"},
+	}
+	report, err = keeper.RemoveRole(
+		iv, v, "admin", adminPassphrase, "dev", true, recipient)
+	assert.Equal(t, expReport, report)
+	assert.ErrorIs(t, err, nil)
+
+	_, err = keeper.Resolve(v, devKeyring, "base", interpolator)
+	assert.ErrorIs(t, err, crypto.ErrAuthFailed)
+	_, err = keeper.Resolve(v, devKeyring, "prod", interpolator)
+	assert.ErrorIs(t, err, crypto.ErrAuthFailed)
+	_, err = keeper.Open(v, "prod", "ops", opsPassphrase)
+	assert.ErrorIs(t, err, vault.ErrSlotNotFound)
+
+	newKeyring, err := keeper.Open(v, "prod", "admin", adminPassphrase)
+	assert.ErrorIs(t, err, nil)
+	assert.NotEqual(t, keyring["prod"].Bytes(), newKeyring["prod"].Bytes())
+	vars, err := keeper.Resolve(v, newKeyring, "prod", interpolator)
+	assert.Equal(t, "s3cr3t", vars[1].Value)
+	assert.ErrorIs(t, err, nil)
+	ciKeyring, err := keeper.OpenRecipient(v, "prod", "ci", recipient, identity)
+	assert.ErrorIs(t, err, nil)
+	vars, err = keeper.Resolve(v, ciKeyring, "prod", interpolator)
+	assert.Equal(t, "app", vars[0].Value)
+	assert.ErrorIs(t, err, nil)
+}
+
+func Test_Keeper_Policy(t *testing.T) {
+	t.Parallel()
+	rng := cimpl.NewStdRNG(cimpl.FnStdRNG{Read: rand.Read})
+	cipher := cimpl.ChaChaPoly{}
+	stream, _ := cimpl.NewStream(cipher, cimpl.StreamChunkLen)
+	kdf := cimpl.NewHKDF([]byte("secure-env"))
+	roleAuthorizer := cimpl.NewRoleAuthorizer(cimpl.Argon{}, rng, cipher)
+	estimator := pimpl.NewEstimator(pimpl.FnEstimator{Now: time.Now})
+	rawIV, _ := rng.Block(cimpl.IV96Len)
+	iv, _ := cimpl.LoadIV96(rawIV)
+
+	weak, _ := crypto.NewSecretFrom([]byte("Password2024"))
+	weaker, _ := crypto.NewSecretFrom([]byte("password1234"))
+	strong, _ := crypto.NewSecretFrom([]byte("+DF7Rc-X/MOYjkNj"))
+	v := &vault.Vault{Policy: &passphrase.Policy{}}
+	authorizer := pimpl.NewPolicyAuthorizer(
+		roleAuthorizer, estimator, v.PassphrasePolicy())
+	keeper := vimpl.NewKeeper(vimpl.FnKeeper{Now: time.Now},
+		authorizer, cipher, stream, kdf)
+	_, err := keeper.CreateEnv(iv, v, "base", "", "admin", weak)
+	assert.ErrorIs(t, err, nil)
+
+	v.Policy = nil
+	authorizer = pimpl.NewPolicyAuthorizer(
+		roleAuthorizer, estimator, v.PassphrasePolicy())
+	keeper = vimpl.NewKeeper(vimpl.FnKeeper{Now: time.Now},
+		authorizer, cipher, stream, kdf)
+	_, err = keeper.CreateEnv(iv, v, "prod", "", "admin", weak)
+	assert.ErrorIs(t, err, passphrase.ErrPassphraseTooWeak)
+	_, err = keeper.CreateEnv(iv, v, "prod", "", "admin", strong)
+	assert.ErrorIs(t, err, nil)
+
+	_, err = keeper.CreateEnv(iv, v, "dev", "base", "admin", weak)
+	assert.ErrorIs(t, err, nil)
+	_, err = keeper.Rotate(iv, v, "base", "admin", weak)
+	assert.ErrorIs(t, err, nil)
+	err = keeper.Passwd(iv, v, "admin", weak, weaker)
+	assert.ErrorIs(t, err, passphrase.ErrPassphraseTooWeak)
+}
+
+func Test_Keeper_SetFile(t *testing.T) {
+	t.Parallel()
+	rng := cimpl.NewStdRNG(cimpl.FnStdRNG{Read: rand.Read})
+	cipher := cimpl.ChaChaPoly{}
+	stream, _ := cimpl.NewStream(cipher, 16)
+	authorizer := cimpl.NewRoleAuthorizer(cimpl.Argon{}, rng, cipher)
+	keeper := vimpl.NewKeeper(vimpl.FnKeeper{Now: time.Now},
+		authorizer, cipher, stream, cimpl.NewHKDF([]byte("secure-env")))
+	rawIV, _ := rng.Block(cimpl.IV96Len)
+	iv, _ := cimpl.LoadIV96(rawIV)
+
+	passphrase, _ := crypto.NewSecretFrom([]byte("+DF7Rc-X/MOYjkNj"))
+	content := `{"type":"service_account","private_key":"-----BEGIN"}`
+	v := &vault.Vault{}
+	keyring, err := keeper.CreateEnv(iv, v, "prod", "", "admin", passphrase)
+	assert.ErrorIs(t, err, nil)
+	err = keeper.SetFile(iv, v, keyring, "prod", "admin",
+		"GCP_CREDENTIALS", strings.NewReader(content))
+	assert.ErrorIs(t, err, nil)
+	assert.NotContains(t, string(v.Envs[0].Entries[0].Buf), "service_account")
+
+	vars, err := keeper.Resolve(v, keyring, "prod", interpolator)
+	assert.ErrorIs(t, err, nil)
+	assert.Equal(t, vault.EntryKindFile, vars[0].Kind)
+	assert.Equal(t, "", vars[0].Value)
+
+	_, err = keeper.Rotate(iv, v, "prod", "admin", passphrase)
+	assert.ErrorIs(t, err, nil)
+	newKeyring, err := keeper.Open(v, "prod", "admin", passphrase)
+	assert.ErrorIs(t, err, nil)
+
+	r, err := keeper.OpenFile(v, newKeyring, "prod", "GCP_CREDENTIALS")
+	assert.ErrorIs(t, err, nil)
+	buf, err := io.ReadAll(r)
+	r.Close()
+	assert.Equal(t, content, string(buf))
+	assert.ErrorIs(t, err, nil)
+}
+
+func Test_Keeper_Age(t *testing.T) {
+	t.Parallel()
+	rng := cimpl.NewStdRNG(cimpl.FnStdRNG{Read: rand.Read})
+	cipher := cimpl.ChaChaPoly{}
+	stream, _ := cimpl.NewStream(cipher, cimpl.StreamChunkLen)
+	authorizer := cimpl.NewRoleAuthorizer(cimpl.Argon{}, rng, cipher)
+	keeper := vimpl.NewKeeper(vimpl.FnKeeper{Now: time.Now},
+		authorizer, cipher, stream, cimpl.NewHKDF([]byte("secure-env")))
+	age, _ := cimpl.NewAge(rng, 10)
+	rawIV, _ := rng.Block(cimpl.IV96Len)
+	iv, _ := cimpl.LoadIV96(rawIV)
+
+	rawIdentity, _ := rng.Block(cimpl.HybridX25519Len)
+	identity, _ := crypto.NewSecretFrom(rawIdentity)
+	recipient, _ := cimpl.AgeRecipient(identity)
+	other, _ := cimpl.EncodeAgeRecipient(bytes.Repeat([]byte{0x09}, 32))
+	passphrase, _ := crypto.NewSecretFrom([]byte("+DF7Rc-X/MOYjkNj"))
+	filePassphrase, _ := crypto.NewSecretFrom([]byte("q7!Lw2#zR9@pXe4v"))
+	expVars := []env.Var{
+		{Name: "DB_PASS", Value: "s3cr3t"},
+		{Name: "DB_URL", Value: "postgres://app@db/app?ssl=\"$on\""},
+	}
+
+	plaintext, _ := eimpl.Dotenv{}.Encode(expVars)
+	sealed := &bytes.Buffer{}
+	w, err := age.Encrypt([][]byte{other, recipient}, sealed)
+	assert.ErrorIs(t, err, nil)
+	w.Write(plaintext)
+	assert.ErrorIs(t, w.Close(), nil)
+
+	r, err := age.Decrypt([]*crypto.Secret{identity}, nil, sealed)
+	assert.ErrorIs(t, err, nil)
+	buf, err := io.ReadAll(r)
+	r.Close()
+	assert.ErrorIs(t, err, nil)
+	vars, err := eimpl.Dotenv{}.Decode(buf)
+	assert.ErrorIs(t, err, nil)
+	v := &vault.Vault{}
+	keyring, err := keeper.CreateEnv(iv, v, "prod", "", "admin", passphrase)
+	assert.ErrorIs(t, err, nil)
+	for _, variable := range vars {
+		assert.ErrorIs(t, keeper.Set(iv, v, keyring, "prod", "admin", variable), nil)
+	}
+
+	resolved, err := keeper.Resolve(v, keyring, "prod", interpolator)
+	assert.ErrorIs(t, err, nil)
+	exported := make([]env.Var, 0, len(resolved))
+	for _, variable := range resolved {
+		exported = append(exported, variable.Var)
+	}
+	plaintext, _ = eimpl.Dotenv{}.Encode(exported)
+	sealed.Reset()
+	w, err = age.EncryptPassphrase(filePassphrase, sealed)
+	assert.ErrorIs(t, err, nil)
+	w.Write(plaintext)
+	assert.ErrorIs(t, w.Close(), nil)
+	assert.NotContains(t, sealed.String(), "s3cr3t")
+
+	r, err = age.Decrypt(nil, filePassphrase, sealed)
+	assert.ErrorIs(t, err, nil)
+	buf, err = io.ReadAll(r)
+	r.Close()
+	assert.ErrorIs(t, err, nil)
+	vars, err = eimpl.Dotenv{}.Decode(buf)
+	assert.Equal(t, expVars, vars)
+	assert.ErrorIs(t, err, nil)
+}
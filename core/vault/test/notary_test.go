@@ -0,0 +1,67 @@
+package vault_test
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	cimpl "github.com/reshifr/secure-env/core/crypto/impl"
+	"github.com/reshifr/secure-env/core/vault"
+	vimpl "github.com/reshifr/secure-env/core/vault/impl"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Notary_Verify(t *testing.T) {
+	t.Parallel()
+	rng := cimpl.NewStdRNG(cimpl.FnStdRNG{Read: rand.Read})
+	admin, _ := cimpl.NewEd25519(rng)
+	ci, _ := cimpl.NewEd25519(rng)
+	intruder, _ := cimpl.NewEd25519(rng)
+	notary := vimpl.NewNotary(cimpl.Ed25519Verifier{})
+	trust := vault.Trust{
+		Require: true,
+		Signers: []vault.Signer{{Name: "admin", PublicKey: admin.PublicKey()}},
+	}
+
+	v := &vault.Vault{}
+	v.AddEnv("prod", "")
+	err := v.PinSigner("admin", admin.PublicKey())
+	assert.ErrorIs(t, err, nil)
+	assert.ErrorIs(t, notary.Check(v, trust), vault.ErrUnsigned)
+	assert.ErrorIs(t, notary.Check(v, vault.Trust{}), nil)
+
+	err = notary.Sign(v, "admin", admin)
+	assert.ErrorIs(t, err, nil)
+	v.Envs[0].SetEntry("DB_PASS", []byte{0x31})
+	err = notary.Sign(v, "admin", admin)
+	assert.ErrorIs(t, err, nil)
+	assert.ErrorIs(t, notary.Check(v, trust), nil)
+
+	err = notary.Sign(v, "admin", intruder)
+	assert.ErrorIs(t, err, vault.ErrSignerNotPinned)
+
+	v.PinSigner("ci", ci.PublicKey())
+	err = notary.Sign(v, "ci", ci)
+	assert.ErrorIs(t, err, vault.ErrSignerNotPinned)
+	err = notary.Sign(v, "admin", admin)
+	assert.ErrorIs(t, err, nil)
+	v.UnpinSigner("admin")
+	err = notary.Sign(v, "ci", ci)
+	assert.ErrorIs(t, err, nil)
+	assert.ErrorIs(t, notary.Check(v, trust), nil)
+
+	forged := *v
+	forged.Signers = []vault.Signer{{Name: "ci", PublicKey: intruder.PublicKey()}}
+	forged.Signatures = nil
+	assert.ErrorIs(t, notary.Sign(&forged, "ci", intruder), nil)
+	assert.ErrorIs(t, notary.Check(&forged, trust), vault.ErrSignerNotPinned)
+
+	v.Envs[0].SetEntry("DB_PASS", []byte{0x32})
+	assert.ErrorIs(t, notary.Verify(v, trust.Signers),
+		vault.ErrSignatureMismatch)
+
+	last := &v.Signatures[len(v.Signatures)-1]
+	last.Digest = v.Digest()
+	err = notary.Verify(v, trust.Signers)
+	assert.ErrorIs(t, err, crypto.ErrInvalidSignature)
+}
@@ -0,0 +1,469 @@
+package vault
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/reshifr/secure-env/core/crypto"
+	"github.com/reshifr/secure-env/core/env"
+	"github.com/reshifr/secure-env/core/failure"
+	"github.com/reshifr/secure-env/core/passphrase"
+)
+
+type VaultError int
+
+const (
+	ErrEnvNotFound VaultError = iota + 1
+	ErrEnvExists
+	ErrEnvCycle
+	ErrSlotNotFound
+	ErrEntryNotFound
+	ErrRoleExists
+	ErrSignerExists
+	ErrSignerNotPinned
+	ErrUnsigned
+	ErrSignatureMismatch
+	ErrNotFile
+	ErrSlotKindMismatch
+	ErrLastSlot
+	ErrNotOwner
+)
+
+const (
+	OpCreate   = "create"
+	OpOpen     = "open"
+	OpPasswd   = "passwd"
+	OpAddRole  = "add-role"
+	OpRmRole   = "remove-role"
+	OpRotate   = "rotate"
+	OpSet      = "set"
+	OpSetFile  = "set-file"
+	OpOpenFile = "open-file"
+	OpResolve  = "resolve"
+	OpImport   = "import"
+	OpCheck    = "check"
+	OpAnchor   = "anchor"
+	OpExport   = "export"
+	OpList     = "list"
+	OpRun      = "run"
+)
+
+const (
+	EntryKindVar      = ""
+	EntryKindFile     = "file"
+	EntryKindTemplate = "template"
+)
+
+const (
+	SignatureContext = "secure-env vault signature v1"
+)
+
+const (
+	RemoveRoleWarning = "Removing a role does not revoke anyone who already " +
+		"copied an access key. Use --rotate to re-key the affected " +
+		"environments; other passphrase roles will then need to be added again."
+)
+
+func (err VaultError) Error() string {
+	switch err {
+	case ErrEnvNotFound:
+		return "ErrEnvNotFound: the environment does not exist."
+	case ErrEnvExists:
+		return "ErrEnvExists: the environment already exists."
+	case ErrEnvCycle:
+		return "ErrEnvCycle: the environment inherits from itself."
+	case ErrSlotNotFound:
+		return "ErrSlotNotFound: the role has no access to the environment."
+	case ErrEntryNotFound:
+		return "ErrEntryNotFound: the variable does not exist."
+	case ErrRoleExists:
+		return "ErrRoleExists: the role already exists."
+	case ErrSignerExists:
+		return "ErrSignerExists: the signer is already pinned."
+	case ErrSignerNotPinned:
+		return "ErrSignerNotPinned: the signer is not pinned in the vault."
+	case ErrUnsigned:
+		return "ErrUnsigned: the vault is not signed."
+	case ErrSignatureMismatch:
+		return "ErrSignatureMismatch: " +
+			"the signature chain does not match the vault."
+	case ErrNotFile:
+		return "ErrNotFile: the variable is not a file."
+	case ErrSlotKindMismatch:
+		return "ErrSlotKindMismatch: " +
+			"the role is unlocked by a different method."
+	case ErrLastSlot:
+		return "ErrLastSlot: " +
+			"the environment would be left without any role."
+	case ErrNotOwner:
+		return "ErrNotOwner: " +
+			"only the role that created the root environment can do this."
+	default:
+		return "Error: unknown."
+	}
+}
+
+func (err VaultError) Kind() failure.Kind {
+	switch err {
+	case ErrEnvNotFound, ErrSlotNotFound, ErrEntryNotFound, ErrSignerNotPinned:
+		return failure.KindNotFound
+	case ErrEnvExists, ErrRoleExists, ErrSignerExists:
+		return failure.KindExists
+	case ErrUnsigned, ErrSignatureMismatch:
+		return failure.KindIntegrity
+	case ErrNotOwner:
+		return failure.KindAuth
+	case ErrEnvCycle, ErrNotFile, ErrSlotKindMismatch, ErrLastSlot:
+		return failure.KindInvalid
+	default:
+		return failure.KindInternal
+	}
+}
+
+type Slot struct {
+	Role      string
+	Kind      string
+	KDF       crypto.KDFParams
+	Created   time.Time
+	Block     []byte
+	PublicKey []byte `json:",omitempty"`
+}
+
+type Entry struct {
+	Name string
+	Kind string `json:",omitempty"`
+	Buf  []byte
+}
+
+type Env struct {
+	Name         string
+	Parent       string
+	Slots        []Slot
+	Entries      []Entry
+	Audit        []byte `json:",omitempty"`
+	RetiredAudit []byte `json:",omitempty"`
+	AuditHead    []byte `json:",omitempty"`
+	Generation   uint64 `json:",omitempty"`
+	Stamp        []byte `json:",omitempty"`
+}
+
+type Signer struct {
+	Name      string
+	PublicKey []byte
+}
+
+type Signature struct {
+	Signer  string
+	Signers []Signer
+	Digest  []byte
+	Prev    []byte
+	Sig     []byte
+}
+
+type Trust struct {
+	Require bool
+	Signers []Signer
+}
+
+type Vault struct {
+	ID         string
+	Generation uint64
+	Schema     env.Schema
+	Policy     *passphrase.Policy
+	Signers    []Signer
+	Envs       []*Env
+	Signatures []Signature
+}
+
+type RoleInfo struct {
+	Name    string
+	Kind    string
+	KDF     crypto.KDFParams
+	Created time.Time
+	Envs    []string
+}
+
+type ImportReport struct {
+	Env     string
+	Added   []string
+	Updated []string
+}
+
+type RemoveReport struct {
+	Envs    []string
+	Dropped []string
+	Warning string
+}
+
+type ResolvedVar struct {
+	env.Var
+	Kind   string
+	Origin string
+}
+
+func (vault *Vault) PassphrasePolicy() passphrase.Policy {
+	if vault.Policy == nil {
+		return passphrase.DefaultPolicy()
+	}
+	return *vault.Policy
+}
+
+func (vault *Vault) Digest() []byte {
+	unsigned := *vault
+	unsigned.Signatures = nil
+	buf, _ := json.Marshal(unsigned)
+	sum := sha256.Sum256(buf)
+	return sum[:]
+}
+
+// SettingsDigest covers what only the owner of a root environment may
+// change: the owner itself, the passphrase policy and the schema. Child
+// environments have none.
+func (vault *Vault) SettingsDigest(e *Env) []byte {
+	if e.Parent != "" {
+		return nil
+	}
+	buf, _ := json.Marshal(struct {
+		Owner  string
+		Policy *passphrase.Policy
+		Schema env.Schema
+	}{e.Owner(), vault.Policy, vault.Schema})
+	sum := sha256.Sum256(buf)
+	return sum[:]
+}
+
+func FindSigner(signers []Signer, name string) (Signer, error) {
+	for _, signer := range signers {
+		if signer.Name == name {
+			return signer, nil
+		}
+	}
+	return Signer{}, ErrSignerNotPinned
+}
+
+func (vault *Vault) Signer(name string) (Signer, error) {
+	return FindSigner(vault.Signers, name)
+}
+
+func (vault *Vault) PinSigner(name string, publicKey []byte) error {
+	if _, err := vault.Signer(name); err == nil {
+		return ErrSignerExists
+	}
+	vault.Signers = append(vault.Signers,
+		Signer{Name: name, PublicKey: publicKey})
+	return nil
+}
+
+func (vault *Vault) UnpinSigner(name string) error {
+	for i := range vault.Signers {
+		if vault.Signers[i].Name == name {
+			vault.Signers = slices.Concat(
+				vault.Signers[:i], vault.Signers[i+1:])
+			return nil
+		}
+	}
+	return ErrSignerNotPinned
+}
+
+func (signature Signature) Hash() []byte {
+	buf, _ := json.Marshal(signature)
+	sum := sha256.Sum256(buf)
+	return sum[:]
+}
+
+func (signature Signature) Message() []byte {
+	buf, _ := json.Marshal(signature.Signers)
+	signers := sha256.Sum256(buf)
+	msg := make([]byte, 0, len(SignatureContext)+
+		len(signature.Digest)+len(signature.Prev)+len(signers))
+	msg = append(msg, SignatureContext...)
+	msg = append(msg, signature.Digest...)
+	msg = append(msg, signature.Prev...)
+	return append(msg, signers[:]...)
+}
+
+func (vault *Vault) Env(name string) (*Env, error) {
+	for _, e := range vault.Envs {
+		if e.Name == name {
+			return e, nil
+		}
+	}
+	return nil, ErrEnvNotFound
+}
+
+func (vault *Vault) AddEnv(name string, parent string) (*Env, error) {
+	if _, err := vault.Env(name); err == nil {
+		return nil, ErrEnvExists
+	}
+	if parent != "" {
+		if _, err := vault.Chain(parent); err != nil {
+			return nil, err
+		}
+	}
+	e := &Env{Name: name, Parent: parent}
+	vault.Envs = append(vault.Envs, e)
+	return e, nil
+}
+
+func (vault *Vault) Chain(name string) ([]*Env, error) {
+	chain := []*Env{}
+	seen := map[string]struct{}{}
+	for name != "" {
+		if _, ok := seen[name]; ok {
+			return nil, ErrEnvCycle
+		}
+		seen[name] = struct{}{}
+		e, err := vault.Env(name)
+		if err != nil {
+			return nil, err
+		}
+		chain = append([]*Env{e}, chain...)
+		name = e.Parent
+	}
+	return chain, nil
+}
+
+func (vault *Vault) Roles() []RoleInfo {
+	roles := []RoleInfo{}
+	index := map[string]int{}
+	for _, e := range vault.Envs {
+		for _, slot := range e.Slots {
+			i, ok := index[slot.Role]
+			if !ok {
+				index[slot.Role] = len(roles)
+				roles = append(roles, RoleInfo{
+					Name:    slot.Role,
+					Kind:    slot.Kind,
+					KDF:     slot.KDF,
+					Created: slot.Created,
+					Envs:    []string{e.Name},
+				})
+				continue
+			}
+			if slot.Created.Before(roles[i].Created) {
+				roles[i].Created = slot.Created
+			}
+			roles[i].Envs = append(roles[i].Envs, e.Name)
+		}
+	}
+	sort.Slice(roles, func(i, j int) bool {
+		return roles[i].Name < roles[j].Name
+	})
+	return roles
+}
+
+func (vault *Vault) RemoveRole(role string) ([]string, error) {
+	names := []string{}
+	for _, e := range vault.Envs {
+		if e.RemoveSlot(role) == nil {
+			names = append(names, e.Name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, ErrSlotNotFound
+	}
+	return names, nil
+}
+
+func (e *Env) Slot(role string) (Slot, error) {
+	for _, slot := range e.Slots {
+		if slot.Role == role {
+			return slot, nil
+		}
+	}
+	return Slot{}, ErrSlotNotFound
+}
+
+// Owner is the role that created the environment. Slots keep their order,
+// so it holds the first one, and root stamps bind it.
+func (e *Env) Owner() string {
+	if len(e.Slots) == 0 {
+		return ""
+	}
+	return e.Slots[0].Role
+}
+
+func (e *Env) SetSlot(slot Slot) {
+	for i := range e.Slots {
+		if e.Slots[i].Role == slot.Role {
+			e.Slots[i] = slot
+			return
+		}
+	}
+	e.Slots = append(e.Slots, slot)
+}
+
+func (e *Env) RemoveSlot(role string) error {
+	for i := range e.Slots {
+		if e.Slots[i].Role == role {
+			e.Slots = append(e.Slots[:i], e.Slots[i+1:]...)
+			return nil
+		}
+	}
+	return ErrSlotNotFound
+}
+
+func (e *Env) Entry(name string) ([]byte, error) {
+	for _, entry := range e.Entries {
+		if entry.Name == name {
+			return entry.Buf, nil
+		}
+	}
+	return nil, ErrEntryNotFound
+}
+
+func (e *Env) File(name string) ([]byte, error) {
+	for _, entry := range e.Entries {
+		if entry.Name == name {
+			if entry.Kind != EntryKindFile {
+				return nil, ErrNotFile
+			}
+			return entry.Buf, nil
+		}
+	}
+	return nil, ErrEntryNotFound
+}
+
+func (e *Env) setEntry(entry Entry) {
+	for i := range e.Entries {
+		if e.Entries[i].Name == entry.Name {
+			e.Entries[i] = entry
+			return
+		}
+	}
+	e.Entries = append(e.Entries, entry)
+}
+
+func (e *Env) SetEntry(name string, buf []byte) {
+	e.setEntry(Entry{Name: name, Kind: EntryKindVar, Buf: buf})
+}
+
+func (e *Env) SetTemplate(name string, buf []byte) {
+	e.setEntry(Entry{Name: name, Kind: EntryKindTemplate, Buf: buf})
+}
+
+func (e *Env) SetFile(name string, buf []byte) {
+	e.setEntry(Entry{Name: name, Kind: EntryKindFile, Buf: buf})
+}
+
+func (e *Env) RemoveEntry(name string) error {
+	for i := range e.Entries {
+		if e.Entries[i].Name == name {
+			e.Entries = append(e.Entries[:i], e.Entries[i+1:]...)
+			return nil
+		}
+	}
+	return ErrEntryNotFound
+}
+
+type Keyring map[string]*crypto.Secret
+
+func (keyring Keyring) Destroy() {
+	for name, key := range keyring {
+		key.Destroy()
+		delete(keyring, name)
+	}
+}
@@ -0,0 +1,722 @@
+package vault
+
+import (
+	"crypto/sha256"
+	"testing"
+	"time"
+
+	"github.com/reshifr/secure-env/core/env"
+	"github.com/reshifr/secure-env/core/failure"
+	"github.com/reshifr/secure-env/core/passphrase"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_VaultError_Error(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrEnvNotFound value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrEnvNotFound
+		const expMsg = "ErrEnvNotFound: the environment does not exist."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrEnvExists value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrEnvExists
+		const expMsg = "ErrEnvExists: the environment already exists."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrEnvCycle value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrEnvCycle
+		const expMsg = "ErrEnvCycle: the environment inherits from itself."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrSlotNotFound value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrSlotNotFound
+		const expMsg = "ErrSlotNotFound: " +
+			"the role has no access to the environment."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrEntryNotFound value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrEntryNotFound
+		const expMsg = "ErrEntryNotFound: the variable does not exist."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrRoleExists value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrRoleExists
+		const expMsg = "ErrRoleExists: the role already exists."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrSignerExists value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrSignerExists
+		const expMsg = "ErrSignerExists: the signer is already pinned."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrSignerNotPinned value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrSignerNotPinned
+		const expMsg = "ErrSignerNotPinned: " +
+			"the signer is not pinned in the vault."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrUnsigned value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrUnsigned
+		const expMsg = "ErrUnsigned: the vault is not signed."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrSignatureMismatch value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrSignatureMismatch
+		const expMsg = "ErrSignatureMismatch: " +
+			"the signature chain does not match the vault."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrNotFile value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrNotFile
+		const expMsg = "ErrNotFile: the variable is not a file."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrSlotKindMismatch value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrSlotKindMismatch
+		const expMsg = "ErrSlotKindMismatch: " +
+			"the role is unlocked by a different method."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrLastSlot value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrLastSlot
+		const expMsg = "ErrLastSlot: " +
+			"the environment would be left without any role."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("ErrNotOwner value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrNotOwner
+		const expMsg = "ErrNotOwner: " +
+			"only the role that created the root environment can do this."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+	t.Run("Unknown value", func(t *testing.T) {
+		t.Parallel()
+		const err = VaultError(613724)
+		const expMsg = "Error: unknown."
+
+		msg := err.Error()
+		assert.Equal(t, expMsg, msg)
+	})
+}
+
+func Test_Vault_PassphrasePolicy(t *testing.T) {
+	t.Parallel()
+	t.Run("Default policy", func(t *testing.T) {
+		t.Parallel()
+		v := &Vault{}
+		expPolicy := passphrase.DefaultPolicy()
+
+		policy := v.PassphrasePolicy()
+		assert.Equal(t, expPolicy, policy)
+	})
+	t.Run("Vault policy", func(t *testing.T) {
+		t.Parallel()
+		expPolicy := passphrase.Policy{MinLen: 20}
+		v := &Vault{Policy: &expPolicy}
+
+		policy := v.PassphrasePolicy()
+		assert.Equal(t, expPolicy, policy)
+	})
+	t.Run("Disabled policy", func(t *testing.T) {
+		t.Parallel()
+		expPolicy := passphrase.Policy{}
+		v := &Vault{Policy: &passphrase.Policy{}}
+
+		policy := v.PassphrasePolicy()
+		assert.Equal(t, expPolicy, policy)
+	})
+}
+
+func Test_Vault_Digest(t *testing.T) {
+	t.Parallel()
+	v := &Vault{Envs: []*Env{{Name: "prod"}}}
+	digest := v.Digest()
+
+	v.Signatures = []Signature{{Signer: "admin", Sig: []byte{0x01}}}
+	assert.Len(t, digest, 32)
+	assert.Equal(t, digest, v.Digest())
+
+	v.Envs[0].SetEntry("DB_PASS", []byte{0x01})
+	assert.NotEqual(t, digest, v.Digest())
+}
+
+func Test_Vault_SettingsDigest(t *testing.T) {
+	t.Parallel()
+	t.Run("Child env", func(t *testing.T) {
+		t.Parallel()
+		v := &Vault{}
+		var expDigest []byte = nil
+
+		digest := v.SettingsDigest(&Env{Name: "dev", Parent: "prod"})
+		assert.Equal(t, expDigest, digest)
+	})
+	t.Run("Root env", func(t *testing.T) {
+		t.Parallel()
+		e := &Env{Name: "prod", Slots: []Slot{{Role: "admin"}, {Role: "dev"}}}
+		v := &Vault{Envs: []*Env{e}}
+		digest := v.SettingsDigest(e)
+
+		e.SetEntry("DB_PASS", []byte{0x01})
+		assert.Len(t, digest, 32)
+		assert.Equal(t, digest, v.SettingsDigest(e))
+
+		e.Slots[0], e.Slots[1] = e.Slots[1], e.Slots[0]
+		assert.NotEqual(t, digest, v.SettingsDigest(e))
+		e.Slots[0], e.Slots[1] = e.Slots[1], e.Slots[0]
+		v.Policy = &passphrase.Policy{}
+		assert.NotEqual(t, digest, v.SettingsDigest(e))
+		v.Policy = nil
+		v.Schema.Rules = []env.Rule{{Name: "DB_PASS"}}
+		assert.NotEqual(t, digest, v.SettingsDigest(e))
+	})
+}
+
+func Test_Vault_Signer(t *testing.T) {
+	t.Parallel()
+	v := &Vault{Signers: []Signer{{Name: "admin", PublicKey: []byte{0x01}}}}
+
+	t.Run("ErrSignerNotPinned error", func(t *testing.T) {
+		t.Parallel()
+		expSigner := Signer{}
+		const expErr = ErrSignerNotPinned
+
+		signer, err := v.Signer("ci")
+		assert.Equal(t, expSigner, signer)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		expSigner := Signer{Name: "admin", PublicKey: []byte{0x01}}
+
+		signer, err := v.Signer("admin")
+		assert.Equal(t, expSigner, signer)
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_Vault_PinSigner(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrSignerExists error", func(t *testing.T) {
+		t.Parallel()
+		v := &Vault{Signers: []Signer{{Name: "admin"}}}
+		const expErr = ErrSignerExists
+
+		err := v.PinSigner("admin", []byte{0x01})
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		v := &Vault{}
+		expSigners := []Signer{{Name: "admin", PublicKey: []byte{0x01}}}
+
+		err := v.PinSigner("admin", []byte{0x01})
+		assert.Equal(t, expSigners, v.Signers)
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_Vault_UnpinSigner(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrSignerNotPinned error", func(t *testing.T) {
+		t.Parallel()
+		v := &Vault{}
+		const expErr = ErrSignerNotPinned
+
+		err := v.UnpinSigner("admin")
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		signers := []Signer{{Name: "admin"}, {Name: "ci"}}
+		v := &Vault{
+			Signers:    signers,
+			Signatures: []Signature{{Signers: signers}},
+		}
+		expSigners := []Signer{{Name: "ci"}}
+		expSnapshot := []Signer{{Name: "admin"}, {Name: "ci"}}
+
+		err := v.UnpinSigner("admin")
+		assert.Equal(t, expSigners, v.Signers)
+		assert.Equal(t, expSnapshot, v.Signatures[0].Signers)
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_Signature_Hash(t *testing.T) {
+	t.Parallel()
+	signature := Signature{Signer: "admin", Sig: []byte{0x01}}
+	hash := signature.Hash()
+
+	signature.Sig = []byte{0x02}
+	assert.Len(t, hash, 32)
+	assert.NotEqual(t, hash, signature.Hash())
+}
+
+func Test_Signature_Message(t *testing.T) {
+	t.Parallel()
+	signature := Signature{
+		Signers: []Signer{{Name: "admin", PublicKey: []byte{0x03}}},
+		Digest:  []byte{0x01},
+		Prev:    []byte{0x02},
+	}
+	signers := sha256.Sum256(
+		[]byte(`[{"Name":"admin","PublicKey":"Aw=="}]`))
+	expMsg := append([]byte(SignatureContext), 0x01, 0x02)
+	expMsg = append(expMsg, signers[:]...)
+
+	msg := signature.Message()
+	assert.Equal(t, expMsg, msg)
+}
+
+func Test_Vault_Env(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrEnvNotFound error", func(t *testing.T) {
+		t.Parallel()
+		v := &Vault{}
+		var expEnv *Env = nil
+		const expErr = ErrEnvNotFound
+
+		e, err := v.Env("prod")
+		assert.Equal(t, expEnv, e)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		expEnv := &Env{Name: "prod"}
+		v := &Vault{Envs: []*Env{{Name: "dev"}, expEnv}}
+
+		e, err := v.Env("prod")
+		assert.Same(t, expEnv, e)
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_Vault_AddEnv(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrEnvExists error", func(t *testing.T) {
+		t.Parallel()
+		v := &Vault{Envs: []*Env{{Name: "prod"}}}
+		var expEnv *Env = nil
+		const expErr = ErrEnvExists
+
+		e, err := v.AddEnv("prod", "")
+		assert.Equal(t, expEnv, e)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrEnvNotFound error", func(t *testing.T) {
+		t.Parallel()
+		v := &Vault{}
+		var expEnv *Env = nil
+		const expErr = ErrEnvNotFound
+
+		e, err := v.AddEnv("prod", "base")
+		assert.Equal(t, expEnv, e)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		v := &Vault{Envs: []*Env{{Name: "base"}}}
+		expEnv := &Env{Name: "prod", Parent: "base"}
+
+		e, err := v.AddEnv("prod", "base")
+		assert.Equal(t, expEnv, e)
+		assert.ErrorIs(t, err, nil)
+		assert.Same(t, e, v.Envs[1])
+	})
+}
+
+func Test_Vault_Chain(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrEnvNotFound error", func(t *testing.T) {
+		t.Parallel()
+		v := &Vault{Envs: []*Env{{Name: "prod", Parent: "base"}}}
+		var expChain []*Env = nil
+		const expErr = ErrEnvNotFound
+
+		chain, err := v.Chain("prod")
+		assert.Equal(t, expChain, chain)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrEnvCycle error", func(t *testing.T) {
+		t.Parallel()
+		v := &Vault{Envs: []*Env{
+			{Name: "a", Parent: "b"},
+			{Name: "b", Parent: "a"},
+		}}
+		var expChain []*Env = nil
+		const expErr = ErrEnvCycle
+
+		chain, err := v.Chain("a")
+		assert.Equal(t, expChain, chain)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		base := &Env{Name: "base"}
+		staging := &Env{Name: "staging", Parent: "base"}
+		prod := &Env{Name: "prod", Parent: "staging"}
+		v := &Vault{Envs: []*Env{prod, base, staging}}
+		expChain := []*Env{base, staging, prod}
+
+		chain, err := v.Chain("prod")
+		assert.Equal(t, expChain, chain)
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_Vault_Roles(t *testing.T) {
+	t.Parallel()
+	early := time.Unix(1000, 0)
+	late := time.Unix(2000, 0)
+	v := &Vault{Envs: []*Env{
+		{Name: "base", Slots: []Slot{
+			{Role: "dev", Kind: "passphrase", Created: late},
+			{Role: "admin", Kind: "passphrase", Created: late},
+		}},
+		{Name: "prod", Slots: []Slot{
+			{Role: "admin", Kind: "passphrase", Created: early},
+		}},
+	}}
+	expRoles := []RoleInfo{
+		{Name: "admin", Kind: "passphrase",
+			Created: early, Envs: []string{"base", "prod"}},
+		{Name: "dev", Kind: "passphrase",
+			Created: late, Envs: []string{"base"}},
+	}
+
+	roles := v.Roles()
+	assert.Equal(t, expRoles, roles)
+}
+
+func Test_Vault_RemoveRole(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrSlotNotFound error", func(t *testing.T) {
+		t.Parallel()
+		v := &Vault{Envs: []*Env{{Name: "base"}}}
+		var expNames []string = nil
+		const expErr = ErrSlotNotFound
+
+		names, err := v.RemoveRole("dev")
+		assert.Equal(t, expNames, names)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		base := &Env{Name: "base", Slots: []Slot{{Role: "admin"}, {Role: "dev"}}}
+		prod := &Env{Name: "prod", Slots: []Slot{{Role: "admin"}}}
+		v := &Vault{Envs: []*Env{base, prod}}
+		expNames := []string{"base"}
+		expSlots := []Slot{{Role: "admin"}}
+
+		names, err := v.RemoveRole("dev")
+		assert.Equal(t, expNames, names)
+		assert.Equal(t, expSlots, base.Slots)
+		assert.Equal(t, expSlots, prod.Slots)
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_Env_Slot(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrSlotNotFound error", func(t *testing.T) {
+		t.Parallel()
+		e := &Env{Slots: []Slot{{Role: "admin", Block: []byte{0x01}}}}
+		expSlot := Slot{}
+		const expErr = ErrSlotNotFound
+
+		slot, err := e.Slot("dev")
+		assert.Equal(t, expSlot, slot)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		e := &Env{Slots: []Slot{{Role: "admin", Block: []byte{0x01}}}}
+		expSlot := Slot{Role: "admin", Block: []byte{0x01}}
+
+		slot, err := e.Slot("admin")
+		assert.Equal(t, expSlot, slot)
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_Env_Owner(t *testing.T) {
+	t.Parallel()
+	t.Run("No slots", func(t *testing.T) {
+		t.Parallel()
+		e := &Env{}
+
+		owner := e.Owner()
+		assert.Equal(t, "", owner)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		e := &Env{Slots: []Slot{{Role: "admin"}, {Role: "dev"}}}
+
+		owner := e.Owner()
+		assert.Equal(t, "admin", owner)
+	})
+}
+
+func Test_Env_SetSlot(t *testing.T) {
+	t.Parallel()
+	e := &Env{}
+	expSlots := []Slot{
+		{Role: "admin", Block: []byte{0x02}},
+		{Role: "dev", Block: []byte{0x03}},
+	}
+
+	e.SetSlot(Slot{Role: "admin", Block: []byte{0x01}})
+	e.SetSlot(Slot{Role: "dev", Block: []byte{0x03}})
+	e.SetSlot(Slot{Role: "admin", Block: []byte{0x02}})
+	assert.Equal(t, expSlots, e.Slots)
+}
+
+func Test_Env_RemoveSlot(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrSlotNotFound error", func(t *testing.T) {
+		t.Parallel()
+		e := &Env{}
+		const expErr = ErrSlotNotFound
+
+		err := e.RemoveSlot("dev")
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		e := &Env{Slots: []Slot{{Role: "admin"}, {Role: "dev"}}}
+		expSlots := []Slot{{Role: "admin"}}
+
+		err := e.RemoveSlot("dev")
+		assert.Equal(t, expSlots, e.Slots)
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_Env_Entry(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrEntryNotFound error", func(t *testing.T) {
+		t.Parallel()
+		e := &Env{Entries: []Entry{{Name: "USER", Buf: []byte{0x01}}}}
+		var expBuf []byte = nil
+		const expErr = ErrEntryNotFound
+
+		buf, err := e.Entry("PASS")
+		assert.Equal(t, expBuf, buf)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		e := &Env{Entries: []Entry{{Name: "USER", Buf: []byte{0x01}}}}
+		expBuf := []byte{0x01}
+
+		buf, err := e.Entry("USER")
+		assert.Equal(t, expBuf, buf)
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_Env_SetEntry(t *testing.T) {
+	t.Parallel()
+	e := &Env{}
+	expEntries := []Entry{
+		{Name: "USER", Buf: []byte{0x02}},
+		{Name: "PASS", Buf: []byte{0x03}},
+	}
+
+	e.SetEntry("USER", []byte{0x01})
+	e.SetEntry("PASS", []byte{0x03})
+	e.SetEntry("USER", []byte{0x02})
+	assert.Equal(t, expEntries, e.Entries)
+}
+
+func Test_Env_File(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrEntryNotFound error", func(t *testing.T) {
+		t.Parallel()
+		e := &Env{}
+		var expBuf []byte = nil
+		const expErr = ErrEntryNotFound
+
+		buf, err := e.File("CREDENTIALS")
+		assert.Equal(t, expBuf, buf)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("ErrNotFile error", func(t *testing.T) {
+		t.Parallel()
+		e := &Env{Entries: []Entry{{Name: "USER", Buf: []byte{0x01}}}}
+		var expBuf []byte = nil
+		const expErr = ErrNotFile
+
+		buf, err := e.File("USER")
+		assert.Equal(t, expBuf, buf)
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		e := &Env{Entries: []Entry{
+			{Name: "CREDENTIALS", Kind: EntryKindFile, Buf: []byte{0x01}},
+		}}
+		expBuf := []byte{0x01}
+
+		buf, err := e.File("CREDENTIALS")
+		assert.Equal(t, expBuf, buf)
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_Env_SetFile(t *testing.T) {
+	t.Parallel()
+	e := &Env{}
+	expEntries := []Entry{
+		{Name: "USER", Buf: []byte{0x03}},
+		{Name: "CREDENTIALS", Kind: EntryKindFile, Buf: []byte{0x02}},
+	}
+
+	e.SetFile("USER", []byte{0x01})
+	e.SetFile("CREDENTIALS", []byte{0x02})
+	e.SetEntry("USER", []byte{0x03})
+	assert.Equal(t, expEntries, e.Entries)
+}
+
+func Test_Env_RemoveEntry(t *testing.T) {
+	t.Parallel()
+	t.Run("ErrEntryNotFound error", func(t *testing.T) {
+		t.Parallel()
+		e := &Env{}
+		const expErr = ErrEntryNotFound
+
+		err := e.RemoveEntry("USER")
+		assert.ErrorIs(t, err, expErr)
+	})
+	t.Run("Succeed", func(t *testing.T) {
+		t.Parallel()
+		e := &Env{Entries: []Entry{
+			{Name: "USER", Buf: []byte{0x01}},
+			{Name: "PASS", Buf: []byte{0x02}},
+		}}
+		expEntries := []Entry{{Name: "PASS", Buf: []byte{0x02}}}
+
+		err := e.RemoveEntry("USER")
+		assert.Equal(t, expEntries, e.Entries)
+		assert.ErrorIs(t, err, nil)
+	})
+}
+
+func Test_VaultError_Kind(t *testing.T) {
+	t.Parallel()
+	t.Run("KindNotFound value", func(t *testing.T) {
+		t.Parallel()
+		errs := []VaultError{
+			ErrEnvNotFound,
+			ErrSlotNotFound,
+			ErrEntryNotFound,
+			ErrSignerNotPinned,
+		}
+		const expKind = failure.KindNotFound
+
+		for _, err := range errs {
+			kind := err.Kind()
+			assert.Equal(t, expKind, kind, err.Error())
+		}
+	})
+	t.Run("KindExists value", func(t *testing.T) {
+		t.Parallel()
+		errs := []VaultError{
+			ErrEnvExists,
+			ErrRoleExists,
+			ErrSignerExists,
+		}
+		const expKind = failure.KindExists
+
+		for _, err := range errs {
+			kind := err.Kind()
+			assert.Equal(t, expKind, kind, err.Error())
+		}
+	})
+	t.Run("KindIntegrity value", func(t *testing.T) {
+		t.Parallel()
+		errs := []VaultError{
+			ErrUnsigned,
+			ErrSignatureMismatch,
+		}
+		const expKind = failure.KindIntegrity
+
+		for _, err := range errs {
+			kind := err.Kind()
+			assert.Equal(t, expKind, kind, err.Error())
+		}
+	})
+	t.Run("KindAuth value", func(t *testing.T) {
+		t.Parallel()
+		const err = ErrNotOwner
+		const expKind = failure.KindAuth
+
+		kind := err.Kind()
+		assert.Equal(t, expKind, kind)
+	})
+	t.Run("KindInvalid value", func(t *testing.T) {
+		t.Parallel()
+		errs := []VaultError{
+			ErrEnvCycle,
+			ErrNotFile,
+			ErrSlotKindMismatch,
+			ErrLastSlot,
+		}
+		const expKind = failure.KindInvalid
+
+		for _, err := range errs {
+			kind := err.Kind()
+			assert.Equal(t, expKind, kind, err.Error())
+		}
+	})
+	t.Run("Unknown value", func(t *testing.T) {
+		t.Parallel()
+		const err = VaultError(613724)
+		const expKind = failure.KindInternal
+
+		kind := err.Kind()
+		assert.Equal(t, expKind, kind)
+	})
+}
@@ -1,16 +1,17 @@
 module github.com/reshifr/secure-env
 
-go 1.22.0
+go 1.24.0
 
 require (
+	filippo.io/edwards25519 v1.1.0
 	github.com/stretchr/testify v1.9.0
 	golang.org/x/crypto v0.22.0
+	golang.org/x/sys v0.19.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/stretchr/objx v0.5.2 // indirect
-	golang.org/x/sys v0.19.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )